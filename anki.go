@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ankiAudioPadding 音频片段在分段前后各多截取的秒数，避免切掉开头和结尾的音节
+const ankiAudioPadding = 0.25
+
+// wantAnkiTranslation 是否需要为 anki 格式翻译分段
+func wantAnkiTranslation(config *Config, formatList []string) bool {
+	if config.AnkiTranslate == "" {
+		return false
+	}
+	for _, format := range formatList {
+		if format == "anki" {
+			return true
+		}
+	}
+	return false
+}
+
+// translateForAnki 将分段翻译为 anki_translate 指定的语言，译文只写入卡片的 Translation 列；翻译失败时卡片不带译文
+func translateForAnki(client *openai.Client, result *TranscriptionResult, config *Config) {
+	if len(result.Segments) == 0 {
+		return
+	}
+	translated, err := translateResult(client, result, config.AnkiTranslateModel, config.AnkiTranslate)
+	if err != nil {
+		logWarn(tr("翻译 Anki 卡片失败，卡片不带译文: %v"), err)
+		return
+	}
+	result.AnkiTranslations = make([]string, len(translated.Segments))
+	for i, seg := range translated.Segments {
+		result.AnkiTranslations[i] = strings.TrimSpace(seg.Text)
+	}
+}
+
+// saveAnki 保存为 Anki 可直接导入的 CSV（文件头注明分隔符和列名），每个分段一张卡片。
+// 开启 anki_audio 时用 ffmpeg 为每个分段截取 MP3 片段，放在同名的 _media 目录中，卡片以 [sound:] 引用；
+// 导入前需要把这些文件复制到 Anki 的 collection.media 目录
+func saveAnki(result *TranscriptionResult, inputFile string, config *Config, outputPath string) error {
+	columns := []string{"Text"}
+	withTranslation := len(result.AnkiTranslations) == len(result.Segments) && len(result.Segments) > 0
+	if withTranslation {
+		columns = append(columns, "Translation")
+	}
+	if config.AnkiAudio {
+		columns = append(columns, "Audio")
+	}
+	columns = append(columns, "Time", "Source")
+
+	var mediaDir, stem string
+	if config.AnkiAudio {
+		stem = strings.TrimSuffix(filepath.Base(outputPath), ".anki.csv")
+		mediaDir = filepath.Join(filepath.Dir(outputPath), stem+"_media")
+		if err := os.MkdirAll(mediaDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "#separator:comma")
+	fmt.Fprintln(&buf, "#html:false")
+	fmt.Fprintf(&buf, "#columns:%s\n", strings.Join(columns, ","))
+	w := csv.NewWriter(&buf)
+	source := filepath.Base(inputFile)
+	for i, seg := range result.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		record := []string{text}
+		if withTranslation {
+			record = append(record, result.AnkiTranslations[i])
+		}
+		if config.AnkiAudio {
+			// 片段文件名带输出文件名前缀，导入 collection.media 后不会与其他牌组的文件重名
+			name := fmt.Sprintf("%s_%04d.mp3", stem, i+1)
+			if err := cutAnkiAudio(inputFile, seg.Start, seg.End, filepath.Join(mediaDir, name)); err != nil {
+				return fmt.Errorf(tr("截取第 %d 个分段的音频失败: %w"), i+1, err)
+			}
+			record = append(record, "[sound:"+name+"]")
+		}
+		record = append(record, formatChapterTime(seg.Start), source)
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	if config.AnkiAudio {
+		logInfo(tr("Anki 音频片段: %s（导入前复制到 Anki 的 collection.media 目录）"), mediaDir)
+	}
+	return os.WriteFile(outputPath, buf.Bytes(), 0644)
+}
+
+// cutAnkiAudio 用 ffmpeg 截取 [start, end] 的音频（前后各留 ankiAudioPadding 秒）并编码为单声道 MP3
+func cutAnkiAudio(inputFile string, start, end float64, outputPath string) error {
+	from := max(start-ankiAudioPadding, 0)
+	args := append([]string{"-y", "-hide_banner", "-loglevel", "error"}, ffmpegTools.InputArgs...)
+	args = append(args,
+		"-ss", fmt.Sprintf("%.3f", from),
+		"-t", fmt.Sprintf("%.3f", end+ankiAudioPadding-from),
+		"-i", inputFile,
+		"-vn",
+		"-ac", "1",
+		"-codec:a", "libmp3lame",
+		"-q:a", "4",
+		outputPath,
+	)
+	var stderr bytes.Buffer
+	cmd := exec.Command(ffmpegTools.FFmpeg, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, lastLine(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 标注层默认名称
+const (
+	defaultTierName     = "transcript"
+	defaultWordTierName = "words"
+)
+
+// annotationTier 一个标注层：名称和按时间排序的区间
+type annotationTier struct {
+	Name      string
+	Intervals []annotationInterval
+}
+
+// annotationInterval 标注区间（秒）
+type annotationInterval struct {
+	Start, End float64
+	Text       string
+}
+
+// annotationTiers 分段层，以及有词级时间戳时的词层（词与分段文本不一致的分段不写入词层）
+func annotationTiers(result *TranscriptionResult, config *Config) []annotationTier {
+	tiers := []annotationTier{{Name: config.TierName}}
+	words := annotationTier{Name: config.WordTierName}
+	for _, seg := range result.Segments {
+		tiers[0].Intervals = append(tiers[0].Intervals, annotationInterval{seg.Start, seg.End, strings.TrimSpace(seg.Text)})
+		for _, w := range alignedWords(seg) {
+			words.Intervals = append(words.Intervals, annotationInterval{w.Start, w.End, strings.TrimSpace(w.Word)})
+		}
+	}
+	if len(words.Intervals) > 0 {
+		tiers = append(tiers, words)
+	}
+	return tiers
+}
+
+// annotationDuration 标注的总时长：优先使用音频时长，否则取最后一个分段的结束时间
+func annotationDuration(result *TranscriptionResult) float64 {
+	duration := result.Duration
+	for _, seg := range result.Segments {
+		duration = max(duration, seg.End)
+	}
+	return duration
+}
+
+// ELAN EAF 文档结构（EAF 3.0，只包含可对齐的独立标注层）
+type eafDocument struct {
+	XMLName        xml.Name          `xml:"ANNOTATION_DOCUMENT"`
+	Author         string            `xml:"AUTHOR,attr"`
+	Date           string            `xml:"DATE,attr"`
+	Format         string            `xml:"FORMAT,attr"`
+	Version        string            `xml:"VERSION,attr"`
+	XSI            string            `xml:"xmlns:xsi,attr"`
+	Schema         string            `xml:"xsi:noNamespaceSchemaLocation,attr"`
+	Header         eafHeader         `xml:"HEADER"`
+	TimeSlots      []eafTimeSlot     `xml:"TIME_ORDER>TIME_SLOT"`
+	Tiers          []eafTier         `xml:"TIER"`
+	LinguisticType eafLinguisticType `xml:"LINGUISTIC_TYPE"`
+}
+
+// eafHeader 文件头：时间单位和关联的媒体文件
+type eafHeader struct {
+	MediaFile  string           `xml:"MEDIA_FILE,attr"`
+	TimeUnits  string           `xml:"TIME_UNITS,attr"`
+	Descriptor *eafMediaDescrip `xml:"MEDIA_DESCRIPTOR,omitempty"`
+}
+
+// eafMediaDescrip 媒体文件描述
+type eafMediaDescrip struct {
+	MediaURL         string `xml:"MEDIA_URL,attr"`
+	MimeType         string `xml:"MIME_TYPE,attr"`
+	RelativeMediaURL string `xml:"RELATIVE_MEDIA_URL,attr,omitempty"`
+}
+
+// eafTimeSlot 时间点，标注通过 ID 引用起止时间
+type eafTimeSlot struct {
+	ID    string `xml:"TIME_SLOT_ID,attr"`
+	Value int64  `xml:"TIME_VALUE,attr"`
+}
+
+// eafTier 标注层
+type eafTier struct {
+	ID             string          `xml:"TIER_ID,attr"`
+	LinguisticType string          `xml:"LINGUISTIC_TYPE_REF,attr"`
+	Annotations    []eafAnnotation `xml:"ANNOTATION"`
+}
+
+// eafAnnotation 标注，每个 ANNOTATION 只包含一个可对齐的标注
+type eafAnnotation struct {
+	Alignable eafAlignable `xml:"ALIGNABLE_ANNOTATION"`
+}
+
+// eafAlignable 可对齐的标注
+type eafAlignable struct {
+	ID    string `xml:"ANNOTATION_ID,attr"`
+	Ref1  string `xml:"TIME_SLOT_REF1,attr"`
+	Ref2  string `xml:"TIME_SLOT_REF2,attr"`
+	Value string `xml:"ANNOTATION_VALUE"`
+}
+
+// eafLinguisticType 所有标注层共用的语言类型
+type eafLinguisticType struct {
+	ID            string `xml:"LINGUISTIC_TYPE_ID,attr"`
+	TimeAlignable bool   `xml:"TIME_ALIGNABLE,attr"`
+	Graphic       bool   `xml:"GRAPHIC_REFERENCES,attr"`
+}
+
+// saveEAF 保存为 ELAN 标注文件，媒体文件以绝对地址和相对地址引用
+func saveEAF(result *TranscriptionResult, inputFile string, config *Config, outputPath string) error {
+	doc := eafDocument{
+		Date:           time.Now().Format(time.RFC3339),
+		Format:         "3.0",
+		Version:        "3.0",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		Schema:         "http://www.mpi.nl/tools/elan/EAFv3.0.xsd",
+		Header:         eafHeader{TimeUnits: "milliseconds"},
+		LinguisticType: eafLinguisticType{ID: "default-lt", TimeAlignable: true},
+	}
+	if absInput, err := filepath.Abs(inputFile); err == nil && !isRemoteURI(inputFile) {
+		doc.Header.Descriptor = &eafMediaDescrip{
+			MediaURL:         (&url.URL{Scheme: "file", Path: filepath.ToSlash(absInput)}).String(),
+			MimeType:         elanMimeType(inputFile),
+			RelativeMediaURL: htmlMediaURL(inputFile, filepath.Dir(outputPath)),
+		}
+	}
+
+	slot := func(seconds float64) string {
+		id := fmt.Sprintf("ts%d", len(doc.TimeSlots)+1)
+		doc.TimeSlots = append(doc.TimeSlots, eafTimeSlot{ID: id, Value: int64(seconds*1000 + 0.5)})
+		return id
+	}
+	annotationID := 0
+	for _, tier := range annotationTiers(result, config) {
+		t := eafTier{ID: tier.Name, LinguisticType: doc.LinguisticType.ID}
+		for _, iv := range tier.Intervals {
+			annotationID++
+			t.Annotations = append(t.Annotations, eafAnnotation{eafAlignable{
+				ID:    fmt.Sprintf("a%d", annotationID),
+				Ref1:  slot(iv.Start),
+				Ref2:  slot(iv.End),
+				Value: iv.Text,
+			}})
+		}
+		doc.Tiers = append(doc.Tiers, t)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
+
+// elanMimeType ELAN 使用的媒体 MIME 类型
+func elanMimeType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		return "audio/x-wav"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".mpg", ".mpeg":
+		return "video/mpeg"
+	}
+	if isVideoFile(path) {
+		return "video/mp4"
+	}
+	return "audio/*"
+}
+
+// saveTextGrid 保存为 Praat TextGrid（长格式）。IntervalTier 需要首尾相接地覆盖整个时长，
+// 分段之间的空隙补为空区间，重叠部分截到上一个区间的结束时间
+func saveTextGrid(result *TranscriptionResult, config *Config, outputPath string) error {
+	duration := annotationDuration(result)
+	tiers := annotationTiers(result, config)
+
+	var b strings.Builder
+	b.WriteString("File type = \"ooTextFile\"\nObject class = \"TextGrid\"\n\n")
+	fmt.Fprintf(&b, "xmin = 0\nxmax = %s\ntiers? <exists>\nsize = %d\nitem []:\n", praatNumber(duration), len(tiers))
+	for i, tier := range tiers {
+		intervals := contiguousIntervals(tier.Intervals, duration)
+		fmt.Fprintf(&b, "    item [%d]:\n", i+1)
+		fmt.Fprintf(&b, "        class = \"IntervalTier\"\n        name = %s\n", praatString(tier.Name))
+		fmt.Fprintf(&b, "        xmin = 0\n        xmax = %s\n        intervals: size = %d\n", praatNumber(duration), len(intervals))
+		for j, iv := range intervals {
+			fmt.Fprintf(&b, "        intervals [%d]:\n", j+1)
+			fmt.Fprintf(&b, "            xmin = %s\n            xmax = %s\n            text = %s\n", praatNumber(iv.Start), praatNumber(iv.End), praatString(iv.Text))
+		}
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
+
+// contiguousIntervals 补齐空隙、消除重叠，得到从 0 到 duration 首尾相接的区间
+func contiguousIntervals(intervals []annotationInterval, duration float64) []annotationInterval {
+	var out []annotationInterval
+	pos := 0.0
+	for _, iv := range intervals {
+		start := max(iv.Start, pos)
+		end := min(iv.End, duration)
+		if end <= start {
+			continue
+		}
+		if start > pos {
+			out = append(out, annotationInterval{Start: pos, End: start})
+		}
+		out = append(out, annotationInterval{Start: start, End: end, Text: iv.Text})
+		pos = end
+	}
+	if pos < duration || len(out) == 0 {
+		out = append(out, annotationInterval{Start: pos, End: max(duration, pos)})
+	}
+	return out
+}
+
+// praatNumber 格式化时间，去掉多余的 0
+func praatNumber(v float64) string {
+	s := fmt.Sprintf("%.6f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// praatString Praat 字符串字面量，双引号写为两个双引号
+func praatString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API key 轮换方式
+const (
+	keyRotationRoundRobin = "round-robin" // 每个请求依次使用下一个 key（默认）
+	keyRotationFailover   = "failover"    // 一直使用同一个 key，被限流或额度用尽后才换下一个
+)
+
+// apiKeyCooldown 被限流的 key 在响应没有 Retry-After 时暂停使用的时长
+const apiKeyCooldown = 60 * time.Second
+
+// apiKeyPool api_key 和 api_keys 组成的 key 池，由同一配置复制出的所有任务共享
+type apiKeyPool struct {
+	mu        sync.Mutex
+	keys      []string
+	rotation  string
+	next      int
+	coolUntil []time.Time
+}
+
+// newAPIKeyPool 合并 api_key 和 api_keys（去重、去空），少于两个 key 时返回 nil
+func newAPIKeyPool(primary string, extra []string, rotation string) *apiKeyPool {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, key := range append([]string{primary}, extra...) {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	if len(keys) < 2 {
+		return nil
+	}
+	return &apiKeyPool{keys: keys, rotation: rotation, coolUntil: make([]time.Time, len(keys))}
+}
+
+// applyAPIKeyDefaults 校验 api_key_rotation，只配置了 api_keys 时以第一个作为 api_key，并创建 key 池
+func (c *Config) applyAPIKeyDefaults() error {
+	switch c.APIKeyRotation {
+	case "":
+		c.APIKeyRotation = keyRotationRoundRobin
+	case keyRotationRoundRobin, keyRotationFailover:
+	default:
+		return fmt.Errorf(tr("无效的 api_key_rotation 配置: %s（可选 round-robin, failover）"), c.APIKeyRotation)
+	}
+	if c.APIKey == "" {
+		for _, key := range c.APIKeys {
+			if key = strings.TrimSpace(key); key != "" {
+				c.APIKey = key
+				break
+			}
+		}
+	}
+	c.apiKeys = newAPIKeyPool(c.APIKey, c.APIKeys, c.APIKeyRotation)
+	return nil
+}
+
+// pick 选出本次请求使用的 key：跳过冷却中的 key，全部冷却时使用最早恢复的那个
+func (p *apiKeyPool) pick() (int, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	chosen := -1
+	for n := 0; n < len(p.keys); n++ {
+		i := (p.next + n) % len(p.keys)
+		if !now.Before(p.coolUntil[i]) {
+			chosen = i
+			break
+		}
+	}
+	if chosen < 0 {
+		chosen = 0
+		for i := range p.keys {
+			if p.coolUntil[i].Before(p.coolUntil[chosen]) {
+				chosen = i
+			}
+		}
+	}
+	if p.rotation == keyRotationRoundRobin {
+		p.next = (chosen + 1) % len(p.keys)
+	} else {
+		p.next = chosen
+	}
+	return chosen, p.keys[chosen]
+}
+
+// cool 暂停使用第 i 个 key，返回是否还有其他可用的 key
+func (p *apiKeyPool) cool(i int, d time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.coolUntil[i] = now.Add(d)
+	if p.next == i {
+		p.next = (i + 1) % len(p.keys)
+	}
+	for j := range p.keys {
+		if !now.Before(p.coolUntil[j]) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyRotatingTransport 按 key 池替换请求 Authorization 头中的 api_key。响应为 429（限流或额度用尽）或 402 时暂停该 key，
+// 请求体可以重放时立即换下一个 key 重发，否则把响应交给上层的重试逻辑，重试时会自动使用其他 key
+type keyRotatingTransport struct {
+	base http.RoundTripper
+	pool *apiKeyPool
+}
+
+// RoundTrip 用 key 池中的 key 发送请求
+func (t *keyRotatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	auth := req.Header.Get("Authorization")
+	primary := t.pool.keys[0]
+	if !strings.Contains(auth, primary) {
+		return t.base.RoundTrip(req)
+	}
+	replayable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		i, key := t.pool.pick()
+		r := req.Clone(req.Context())
+		if attempt > 1 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		r.Header.Set("Authorization", strings.Replace(auth, primary, key, 1))
+		recordUsedKey(req.Context(), key)
+
+		resp, err := t.base.RoundTrip(r)
+		if err != nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusPaymentRequired) {
+			return resp, err
+		}
+		available := t.pool.cool(i, retryAfter(resp))
+		if !replayable || !available || attempt >= len(t.pool.keys) {
+			return resp, nil
+		}
+		logWarn(tr("API key %s 被限流或额度用尽（%s），换用下一个 key\n"), maskAPIKey(key), resp.Status)
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+	}
+}
+
+// retryAfter 读取响应的 Retry-After（秒），没有时为 apiKeyCooldown
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return apiKeyCooldown
+}
+
+// maskAPIKey 日志中只显示 key 的最后 4 个字符
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
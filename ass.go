@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// assHeader ASS 字幕的脚本信息和样式。卡拉 OK 效果中 SecondaryColour 为未唱到的颜色（白色），
+// PrimaryColour 为唱到后的高亮颜色（黄色），颜色格式为 &HAABBGGRR
+const assHeader = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+WrapStyle: 0
+ScaledBorderAndShadow: yes
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,64,&H0000FFFF,&H00FFFFFF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,1,2,60,60,60,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+// saveASS 保存为 ASS 字幕。分段有词级时间戳时用 \k 标签逐词高亮（卡拉 OK 效果），
+// 适合歌词视频和语言学习；没有词级时间戳、或词与分段文本不再一致的分段输出为普通字幕行
+func saveASS(result *TranscriptionResult, outputPath string) error {
+	var ass strings.Builder
+	ass.WriteString(assHeader)
+	for _, seg := range result.Segments {
+		var text string
+		if len(alignedWords(seg)) > 0 {
+			text = assKaraokeText(seg)
+		} else {
+			text = assEscape(strings.TrimSpace(seg.Text))
+		}
+		if text == "" {
+			continue
+		}
+		name := assEscape(seg.Speaker)
+		fmt.Fprintf(&ass, "Dialogue: 0,%s,%s,Default,%s,0,0,0,,%s\n", formatASSTime(seg.Start), formatASSTime(seg.End), name, text)
+	}
+	return os.WriteFile(outputPath, []byte(ass.String()), 0644)
+}
+
+// assKaraokeText 生成带 \k 标签的分段文本。每个词的时长从该词开始算到下一个词开始，
+// 词间停顿计入前一个词；分段开头到第一个词之间的停顿用空的 \k 标签占位
+func assKaraokeText(seg Segment) string {
+	var text strings.Builder
+	last := ""
+	if lead := assCentiseconds(seg.Words[0].Start - seg.Start); lead > 0 {
+		fmt.Fprintf(&text, "{\\k%d}", lead)
+	}
+	for i, w := range seg.Words {
+		word := strings.TrimSpace(w.Word)
+		if word == "" {
+			continue
+		}
+		end := seg.End
+		if i+1 < len(seg.Words) {
+			end = seg.Words[i+1].Start
+		}
+		end = max(end, w.End)
+		// 与 joinSegmentText 相同：前一个词以 ASCII 字符结尾时用空格分隔，中日文等直接相连
+		if last != "" && []rune(last)[len([]rune(last))-1] < 0x80 {
+			text.WriteString(" ")
+		}
+		fmt.Fprintf(&text, "{\\k%d}%s", assCentiseconds(end-w.Start), assEscape(word))
+		last = word
+	}
+	return text.String()
+}
+
+// assCentiseconds 秒数转换为 \k 标签使用的厘秒，负数按 0 处理
+func assCentiseconds(seconds float64) int {
+	return max(int(math.Round(seconds*100)), 0)
+}
+
+// assEscape 去掉会被解析为覆盖标签的花括号，换行转换为 ASS 的 \N
+func assEscape(s string) string {
+	s = strings.NewReplacer("{", "(", "}", ")", "\r\n", "\\N", "\n", "\\N").Replace(s)
+	return s
+}
+
+// formatASSTime 将秒数格式化为 ASS 时间格式 H:MM:SS.cc
+func formatASSTime(seconds float64) string {
+	cs := int(math.Round(max(seconds, 0) * 100))
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AssemblyAI 默认接口地址、模型和轮询间隔
+const (
+	assemblyAIDefaultBaseURL = "https://api.assemblyai.com"
+	assemblyAIDefaultModel   = "best"
+	assemblyAIPollInterval   = 3 * time.Second
+)
+
+// assemblyAIBackend AssemblyAI 异步转写接口：上传音频、创建任务、轮询结果
+type assemblyAIBackend struct {
+	baseURL string
+	apiKey  string
+	diarize bool
+	client  *http.Client
+}
+
+// newAssemblyAIBackend 按配置创建 AssemblyAI 后端
+func newAssemblyAIBackend(config *Config) *assemblyAIBackend {
+	return &assemblyAIBackend{
+		baseURL: strings.TrimRight(config.APIBaseURL, "/"),
+		apiKey:  config.APIKey,
+		diarize: config.Diarize,
+		client:  backendHTTPClient(config),
+	}
+}
+
+// assemblyAIWord AssemblyAI 返回的词，时间单位为毫秒
+type assemblyAIWord struct {
+	Text       string  `json:"text"`
+	Start      int64   `json:"start"`
+	End        int64   `json:"end"`
+	Confidence float64 `json:"confidence"`
+}
+
+// assemblyAITranscript 转写任务（只解析用到的字段）
+type assemblyAITranscript struct {
+	ID            string           `json:"id"`
+	Status        string           `json:"status"`
+	Error         string           `json:"error"`
+	Text          string           `json:"text"`
+	LanguageCode  string           `json:"language_code"`
+	AudioDuration float64          `json:"audio_duration"`
+	Words         []assemblyAIWord `json:"words"`
+	Utterances    []struct {
+		Text       string           `json:"text"`
+		Start      int64            `json:"start"`
+		End        int64            `json:"end"`
+		Confidence float64          `json:"confidence"`
+		Speaker    string           `json:"speaker"`
+		Words      []assemblyAIWord `json:"words"`
+	} `json:"utterances"`
+}
+
+// Transcribe 上传音频、创建转写任务并等待完成
+func (b *assemblyAIBackend) Transcribe(ctx context.Context, audioPath string, req backendRequest) (*TranscriptionResult, error) {
+	logDebug(tr("正在转写音频: %s\n"), audioPath)
+
+	audioData, err := os.ReadFile(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf(tr("打开音频文件失败: %w"), err)
+	}
+
+	var upload struct {
+		UploadURL string `json:"upload_url"`
+	}
+	if err := retryRequest(ctx, req.MaxRetries, func() error {
+		return b.do(ctx, http.MethodPost, "/v2/upload", bytes.NewReader(audioData), "application/octet-stream", &upload)
+	}); err != nil {
+		return nil, err
+	}
+
+	params := map[string]any{
+		"audio_url":      upload.UploadURL,
+		"speech_model":   req.Model,
+		"speaker_labels": b.diarize,
+	}
+	if req.AutoDetect || req.Language == "" {
+		params["language_detection"] = true
+	} else {
+		params["language_code"] = req.Language
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var job assemblyAITranscript
+	if err := retryRequest(ctx, req.MaxRetries, func() error {
+		return b.do(ctx, http.MethodPost, "/v2/transcript", bytes.NewReader(body), "application/json", &job)
+	}); err != nil {
+		return nil, err
+	}
+	logDebug(tr("已创建 AssemblyAI 转写任务: %s\n"), job.ID)
+
+	// 轮询直到任务完成或失败
+	for job.Status != "completed" {
+		if job.Status == "error" {
+			return nil, fmt.Errorf(tr("AssemblyAI 转写失败: %s"), job.Error)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(assemblyAIPollInterval):
+		}
+		id := job.ID
+		if err := retryRequest(ctx, req.MaxRetries, func() error {
+			job = assemblyAITranscript{}
+			return b.do(ctx, http.MethodGet, "/v2/transcript/"+id, nil, "", &job)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	logDebug(tr("转写完成"))
+	return b.toResult(&job, req), nil
+}
+
+// SupportsTemperature AssemblyAI 没有温度参数
+func (b *assemblyAIBackend) SupportsTemperature() bool {
+	return false
+}
+
+// do 发送请求并将 JSON 返回解析到 out
+func (b *assemblyAIBackend) do(ctx context.Context, method, path string, body io.Reader, contentType string, out any) error {
+	httpReq, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", b.apiKey)
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newBackendHTTPError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf(tr("解析 AssemblyAI 返回失败: %w"), err)
+	}
+	return nil
+}
+
+// toResult 将完成的任务映射为转写结果。有 utterances（开启说话人标签）时每个 utterance 为一个分段，
+// 否则按句末标点和停顿组合词
+func (b *assemblyAIBackend) toResult(job *assemblyAITranscript, req backendRequest) *TranscriptionResult {
+	result := &TranscriptionResult{
+		Text:     strings.TrimSpace(job.Text),
+		Language: job.LanguageCode,
+		Duration: job.AudioDuration,
+	}
+	if result.Language == "" {
+		result.Language = req.Language
+	}
+
+	for _, u := range job.Utterances {
+		seg := Segment{
+			ID:         len(result.Segments) + 1,
+			Start:      float64(u.Start) / 1000,
+			End:        float64(u.End) / 1000,
+			Text:       strings.TrimSpace(u.Text),
+			Words:      assemblyAIWords(u.Words),
+			Speaker:    u.Speaker,
+			AvgLogprob: confidenceLogprob(u.Confidence),
+		}
+		result.Segments = append(result.Segments, seg)
+	}
+	if len(result.Segments) == 0 {
+		result.Segments = segmentsFromWords(assemblyAIWords(job.Words))
+	}
+	return result
+}
+
+// assemblyAIWords 转换词级时间戳（毫秒转为秒）
+func assemblyAIWords(words []assemblyAIWord) []Word {
+	out := make([]Word, 0, len(words))
+	for _, w := range words {
+		out = append(out, Word{Word: w.Text, Start: float64(w.Start) / 1000, End: float64(w.End) / 1000})
+	}
+	return out
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// 转写服务商
+const (
+	providerOpenAI     = "openai"     // OpenAI 兼容的 /audio/transcriptions 接口（默认）
+	providerDeepgram   = "deepgram"   // Deepgram 预录音频接口
+	providerAssemblyAI = "assemblyai" // AssemblyAI 异步转写接口
+	providerGroq       = "groq"       // Groq 的 OpenAI 兼容 Whisper 接口
+	providerWhisperCpp = "whispercpp" // 本地 whisper.cpp（离线）
+)
+
+// Groq 预设：接口地址、单文件上限和可用的 Whisper 模型
+const (
+	groqBaseURL       = "https://api.groq.com/openai/v1"
+	groqMaxFileSizeMB = 25
+)
+
+var groqModels = []string{"whisper-large-v3", "whisper-large-v3-turbo", "distil-whisper-large-v3-en"}
+
+// TranscriptionBackend 转写后端，各实现负责调用服务商接口并把返回映射为 TranscriptionResult
+type TranscriptionBackend interface {
+	// Transcribe 转写单个音频文件（或切片），失败时按 req.MaxRetries 重试
+	Transcribe(ctx context.Context, audioPath string, req backendRequest) (*TranscriptionResult, error)
+	// SupportsTemperature 是否支持温度参数，不支持时不进行温度回退
+	SupportsTemperature() bool
+}
+
+// backendRequest 单次转写的参数
+type backendRequest struct {
+	Model       string
+	Language    string
+	Prompt      string
+	AutoDetect  bool
+	Temperature float32
+	MaxRetries  int
+	Verbose     bool
+}
+
+// newBackend 按配置的 provider 创建转写后端，OpenAI 兼容后端复用已有的客户端
+func newBackend(client *openai.Client, config *Config) TranscriptionBackend {
+	switch config.Provider {
+	case providerDeepgram:
+		return newDeepgramBackend(config)
+	case providerAssemblyAI:
+		return newAssemblyAIBackend(config)
+	case providerWhisperCpp:
+		return newWhisperCppBackend(config)
+	default:
+		return &openaiBackend{client: client}
+	}
+}
+
+// applyProviderDefaults 校验 provider 并填入服务商的默认地址和模型，在通用默认值之前调用
+func (c *Config) applyProviderDefaults() error {
+	switch c.Provider {
+	case "", providerOpenAI:
+		c.Provider = providerOpenAI
+	case providerDeepgram:
+		if c.APIBaseURL == "" {
+			c.APIBaseURL = deepgramDefaultBaseURL
+		}
+		if c.Model == "" {
+			c.Model = deepgramDefaultModel
+		}
+	case providerAssemblyAI:
+		if c.APIBaseURL == "" {
+			c.APIBaseURL = assemblyAIDefaultBaseURL
+		}
+		if c.Model == "" {
+			c.Model = assemblyAIDefaultModel
+		}
+	case providerGroq:
+		if c.APIBaseURL == "" {
+			c.APIBaseURL = groqBaseURL
+		}
+		if c.MaxFileSizeMB == 0 {
+			c.MaxFileSizeMB = groqMaxFileSizeMB
+		}
+		if c.MaxFileSizeMB > groqMaxFileSizeMB {
+			return fmt.Errorf(tr("provider 为 groq 时 max_file_size_mb 不能超过 %d"), groqMaxFileSizeMB)
+		}
+		if c.Model == "" {
+			c.Model = groqModels[0]
+		}
+	case providerWhisperCpp:
+		if c.WhisperCppModel == "" {
+			return errors.New(tr("provider 为 whispercpp 时需要配置 whispercpp_model（模型文件路径）"))
+		}
+		if c.WhisperCppPath == "" {
+			c.WhisperCppPath = whisperCppDefaultPath
+		}
+		if c.MaxFileSizeMB == 0 {
+			c.MaxFileSizeMB = whisperCppMaxFileSize
+		}
+	default:
+		return fmt.Errorf(tr("无效的 provider 配置: %s（可选 openai, deepgram, assemblyai, groq, whispercpp）"), c.Provider)
+	}
+	return c.validateModel()
+}
+
+// validateModel 检查模型名是否为服务商提供的模型（目前只校验 groq）
+func (c *Config) validateModel() error {
+	if c.Provider != providerGroq {
+		return nil
+	}
+	for _, m := range groqModels {
+		if c.Model == m {
+			return nil
+		}
+	}
+	return fmt.Errorf(tr("groq 不支持模型 %s（可选 %s）"), c.Model, strings.Join(groqModels, ", "))
+}
+
+// needsAPIKey 服务商是否需要 api_key（本地 whisper.cpp 不需要）
+func (c *Config) needsAPIKey() bool {
+	return c.Provider != providerWhisperCpp
+}
+
+// openaiCompatible 服务商是否使用 OpenAI 兼容接口（对话模型功能和连接检查依赖此接口）
+func (c *Config) openaiCompatible() bool {
+	return c.Provider == providerOpenAI || c.Provider == providerGroq
+}
+
+// openaiBackend OpenAI 兼容的转写接口
+type openaiBackend struct {
+	client *openai.Client
+}
+
+// Transcribe 调用 /audio/transcriptions
+func (b *openaiBackend) Transcribe(ctx context.Context, audioPath string, req backendRequest) (*TranscriptionResult, error) {
+	return transcribeAudio(ctx, b.client, audioPath, req.Model, req.Language, req.Prompt, req.AutoDetect, req.Temperature, req.MaxRetries, req.Verbose)
+}
+
+// SupportsTemperature Whisper 接口支持温度参数
+func (b *openaiBackend) SupportsTemperature() bool {
+	return true
+}
+
+// backendHTTPError 服务商接口返回的非 2xx 响应，状态码用于判断是否重试
+type backendHTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *backendHTTPError) Error() string {
+	return fmt.Sprintf(tr("服务器返回 %s: %s"), e.Status, e.Body)
+}
+
+// newBackendHTTPError 读取响应正文（截断）生成错误
+func newBackendHTTPError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &backendHTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(body))}
+}
+
+// backendHTTPClient 按 request_timeout 创建 HTTP 客户端，使用配置的代理、证书和额外请求头，配置了多个 API key 时按 key 池轮换
+func backendHTTPClient(config *Config) *http.Client {
+	client := &http.Client{Transport: config.apiTransport}
+	if config.RequestTimeout > 0 {
+		client.Timeout = time.Duration(config.RequestTimeout * float64(time.Second))
+	}
+	if config.apiKeys != nil {
+		client.Transport = &keyRotatingTransport{base: config.baseTransport(), pool: config.apiKeys}
+	}
+	return client
+}
+
+// retryRequest 执行请求，可重试的错误按指数退避重试最多 maxRetries 次，重试次数计入 ctx 中的计数器
+func retryRequest(ctx context.Context, maxRetries int, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxRetries || !isRetryableError(err) {
+			return fmt.Errorf(tr("API 调用失败: %w"), err)
+		}
+
+		delay := retryDelay(attempt + 1)
+		logWarn(tr("API 调用失败（%v），%s 后进行第 %d 次重试\n"), err, delay, attempt+1)
+		countRetry(ctx)
+		time.Sleep(delay)
+	}
+}
+
+// segmentsFromWords 没有句子级结果时按句末标点或长停顿把词组合成分段
+func segmentsFromWords(words []Word) []Segment {
+	const maxGap = 1.0 // 秒
+
+	var segments []Segment
+	var current []Word
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		var text string
+		for _, w := range current {
+			text = joinSegmentText(text, w.Word)
+		}
+		segments = append(segments, Segment{
+			ID:    len(segments) + 1,
+			Start: current[0].Start,
+			End:   current[len(current)-1].End,
+			Text:  text,
+			Words: current,
+		})
+		current = nil
+	}
+
+	for i, w := range words {
+		if len(current) > 0 && w.Start-words[i-1].End >= maxGap {
+			flush()
+		}
+		current = append(current, w)
+		if strings.ContainsAny(lastRune(w.Word), ".?!。？！") {
+			flush()
+		}
+	}
+	flush()
+	return segments
+}
+
+// confidenceLogprob 把服务商返回的置信度（0-1）换算为 avg_logprob：取对数近似，使 logprob_threshold 过滤同样适用；
+// 没有置信度时返回 0（不过滤）
+func confidenceLogprob(confidence float64) float64 {
+	if confidence <= 0 {
+		return 0
+	}
+	return math.Log(confidence)
+}
+
+// lastRune 返回字符串的最后一个字符
+func lastRune(s string) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) == 0 {
+		return ""
+	}
+	return string(r[len(r)-1])
+}
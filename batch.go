@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// batchItem 批量处理中一个文件的结果
+type batchItem struct {
+	Input       string
+	Result      *TranscriptionResult
+	OutputFiles []string
+	Err         error
+	Elapsed     time.Duration
+	Chunks      int // 转写的切片数（未切分时为 1）
+	Retries     int // 接口调用的重试次数
+}
+
+// stageLabel 进度阶段的显示名称
+func stageLabel(stage string) string {
+	switch stage {
+	case stageDownload:
+		return tr("下载")
+	case stageExtract:
+		return tr("提取音频")
+	case stageSplit:
+		return tr("切分音频")
+	case stageTranscribe:
+		return tr("转写")
+	case stageSave:
+		return tr("保存结果")
+	default:
+		return stage
+	}
+}
+
+// runBatch 同时转写多个输入文件（目录展开为其中的音视频文件），最多 jobs 个文件并行，
+// 与切片级的 chunk_workers 相互独立。每个文件输出自己的进度行，单个文件失败不影响其他文件，
+// 最后输出汇总表，并在输出目录写入 report.json 和 report.csv 供审计
+func runBatch(client *openai.Client, args []string, config *Config, formatList []string, jobs int, alert jobAlert, quiet, verbose bool) {
+	inputs, err := expandInputs(args)
+	if err != nil {
+		exitWith(exitBadInput, "%v", err)
+	}
+	jobs = max(min(jobs, len(inputs)), 1)
+	alert.start(fmt.Sprintf(tr("正在转写 %d 个文件"), len(inputs)))
+	logInfo(tr("共 %d 个文件，并行 %d 个\n"), len(inputs), jobs)
+
+	started := time.Now()
+	items := make([]batchItem, len(inputs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				items[i] = processBatchItem(client, inputs[i], i, len(inputs), config, formatList, verbose)
+			}
+		}()
+	}
+	for i := range inputs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	wall := time.Since(started)
+
+	if files, err := writeBatchReport(config.OutputDir, newBatchReport(items, started, wall)); err != nil {
+		logWarn(tr("写入批量报告失败: %v"), err)
+	} else {
+		logInfo(tr("批量报告: %s\n"), strings.Join(files, ", "))
+	}
+
+	exitCode, failed, partial := exitOK, 0, false
+	for _, item := range items {
+		switch {
+		case item.Err != nil:
+			failed++
+			if exitCode == exitOK {
+				exitCode = exitCodeOf(item.Err)
+			}
+		case item.Result.partial():
+			partial = true
+		}
+	}
+
+	if quiet {
+		// 安静模式只输出结果文件路径
+		for _, item := range items {
+			for _, file := range item.OutputFiles {
+				fmt.Println(file)
+			}
+		}
+	} else {
+		printBatchSummary(items, wall)
+	}
+
+	if failed > 0 {
+		alert.done(tr("whisper-go 批量转写失败"), fmt.Sprintf(tr("%d/%d 个文件失败"), failed, len(items)))
+		exit(exitCode)
+	}
+	alert.done(tr("whisper-go 转写完成"), fmt.Sprintf(tr("已转写 %d 个文件"), len(items)))
+	if partial {
+		exit(exitPartial)
+	}
+}
+
+// processBatchItem 转写批量中的第 i 个文件，阶段变化时输出带文件序号的进度行
+func processBatchItem(client *openai.Client, input string, i, total int, config *Config, formatList []string, verbose bool) batchItem {
+	prefix := fmt.Sprintf("[%d/%d] %s", i+1, total, filepath.Base(input))
+	logInfo(tr("%s: 开始\n"), prefix)
+
+	// 统计切片数和重试次数，写入批量报告
+	ctx, retries := withRetryCounter(config.jobContext())
+	fileConfig := *config.withContext(ctx)
+	var lastStage string
+	var chunks int
+	fileConfig.Progress = func(stage string, current, count int) {
+		if config.Progress != nil {
+			config.Progress(stage, current, count)
+		}
+		if stage == stageTranscribe {
+			chunks = max(chunks, count)
+		}
+		switch {
+		case count > 0:
+			logInfo("%s: %s %d/%d", prefix, stageLabel(stage), current, count)
+		case stage != lastStage:
+			logInfo("%s: %s", prefix, stageLabel(stage))
+		}
+		lastStage = stage
+	}
+
+	start := time.Now()
+	result, files, err := processFile(client, input, &fileConfig, formatList, verbose)
+	item := batchItem{Input: input, Result: result, OutputFiles: files, Err: err, Elapsed: time.Since(start), Chunks: chunks, Retries: int(retries.Load())}
+	if err != nil {
+		logError(tr("%s: 失败: %v\n"), prefix, err)
+	} else {
+		logInfo(tr("%s: 完成（%s）\n"), prefix, item.Elapsed.Round(time.Second))
+	}
+	return item
+}
+
+// printBatchSummary 输出批量处理的汇总表：每个文件的状态、音频时长、耗时和输出文件数，wall 为整批的实际用时
+func printBatchSummary(items []batchItem, wall time.Duration) {
+	fmt.Println(tr("\n=== 批量转写完成 ==="))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("文件\t状态\t时长\t耗时\t输出"))
+	var succeeded int
+	var audio float64
+	for _, item := range items {
+		if item.Err != nil {
+			fmt.Fprintf(w, "%s\t%s\t-\t%s\t%v\n", filepath.Base(item.Input), tr("失败"), item.Elapsed.Round(time.Second), item.Err)
+			continue
+		}
+		succeeded++
+		audio += item.Result.Duration
+		status := tr("成功")
+		if item.Result.DuplicateOf != "" {
+			status = tr("重复，已跳过")
+		} else if item.Result.partial() {
+			status = tr("部分成功")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", filepath.Base(item.Input), status, formatChapterTime(item.Result.Duration), item.Elapsed.Round(time.Second), len(item.OutputFiles))
+	}
+	w.Flush()
+	fmt.Printf(tr("\n成功 %d/%d 个文件，音频总时长 %s，用时 %s\n"), succeeded, len(items), formatChapterTime(audio), wall.Round(time.Second))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 批量报告的文件名，写在输出目录下，每次批量转写覆盖上一次的报告
+const (
+	batchReportJSON = "report.json"
+	batchReportCSV  = "report.csv"
+)
+
+// batchReportEntry 批量报告中一个输入文件的记录
+type batchReportEntry struct {
+	Input    string   `json:"input"`
+	Status   string   `json:"status"`   // ok、partial 或 failed
+	Duration float64  `json:"duration"` // 音频时长（秒）
+	Chunks   int      `json:"chunks"`
+	Retries  int      `json:"retries"` // 接口调用的重试次数
+	Elapsed  float64  `json:"elapsed"` // 处理耗时（秒）
+	Language string   `json:"language,omitempty"`
+	Outputs  []string `json:"outputs"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// batchReport 整批的报告
+type batchReport struct {
+	Started   time.Time          `json:"started"`
+	Elapsed   float64            `json:"elapsed"` // 整批的实际用时（秒）
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Files     []batchReportEntry `json:"files"`
+}
+
+// newBatchReport 由批量处理的结果生成报告
+func newBatchReport(items []batchItem, started time.Time, wall time.Duration) batchReport {
+	report := batchReport{Started: started, Elapsed: wall.Seconds(), Files: make([]batchReportEntry, 0, len(items))}
+	for _, item := range items {
+		entry := batchReportEntry{
+			Input:   item.Input,
+			Status:  "ok",
+			Chunks:  item.Chunks,
+			Retries: item.Retries,
+			Elapsed: item.Elapsed.Seconds(),
+			Outputs: item.OutputFiles,
+		}
+		if entry.Outputs == nil {
+			entry.Outputs = []string{}
+		}
+		switch {
+		case item.Err != nil:
+			entry.Status = "failed"
+			entry.Error = item.Err.Error()
+			report.Failed++
+		case item.Result.partial():
+			entry.Status = "partial"
+			report.Succeeded++
+		default:
+			report.Succeeded++
+		}
+		if item.Result != nil {
+			entry.Duration = item.Result.Duration
+			entry.Language = item.Result.Language
+		}
+		report.Files = append(report.Files, entry)
+	}
+	return report
+}
+
+// writeBatchReport 在 dir 下写入 report.json 和 report.csv，返回写入的文件
+func writeBatchReport(dir string, report batchReport) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf(tr("创建输出目录失败: %w"), err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	jsonPath := filepath.Join(dir, batchReportJSON)
+	if err := os.WriteFile(jsonPath, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf(tr("写入 %s 失败: %w"), jsonPath, err)
+	}
+
+	csvPath := filepath.Join(dir, batchReportCSV)
+	if err := writeBatchReportCSV(csvPath, report); err != nil {
+		return nil, fmt.Errorf(tr("写入 %s 失败: %w"), csvPath, err)
+	}
+	return []string{jsonPath, csvPath}, nil
+}
+
+// writeBatchReportCSV 每个输入文件一行，多个输出文件以分号分隔
+func writeBatchReportCSV(path string, report batchReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"input", "status", "duration", "chunks", "retries", "elapsed", "language", "outputs", "error"})
+	seconds := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	for _, e := range report.Files {
+		w.Write([]string{
+			e.Input,
+			e.Status,
+			seconds(e.Duration),
+			strconv.Itoa(e.Chunks),
+			strconv.Itoa(e.Retries),
+			seconds(e.Elapsed),
+			e.Language,
+			strings.Join(e.Outputs, ";"),
+			e.Error,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// BenchBackend bench_backends 中的一个待比较的后端，provider、api_base_url、api_key、model 的含义与 fallback_backends 相同；
+// Name 为对比表中显示的名称（默认为 provider/model），PricePerMinute 为该后端的单价（默认沿用 price_per_minute）
+type BenchBackend struct {
+	BackendFallback
+	Name           string  `json:"name,omitempty"`
+	PricePerMinute float64 `json:"price_per_minute,omitempty"`
+}
+
+// benchResult bench 子命令中一个后端的结果
+type benchResult struct {
+	Name     string     `json:"name"`
+	Provider string     `json:"provider"`
+	Model    string     `json:"model"`
+	Seconds  float64    `json:"seconds"` // 转写耗时
+	Speed    float64    `json:"speed"`   // 音频时长与耗时之比（几倍实时）
+	Cost     float64    `json:"cost"`    // 按单价估算的费用（美元）
+	Segments int        `json:"segments"`
+	WER      *errorRate `json:"wer,omitempty"` // 指定 -ref 时计算
+	CER      *errorRate `json:"cer,omitempty"`
+	Output   []string   `json:"output"`
+	Error    string     `json:"error,omitempty"`
+	text     string
+}
+
+// runBench 执行 bench 子命令：用多个模型或服务商依次转写同一个输入，记录耗时和估算费用，
+// 指定参考文本时计算 WER/CER，最后输出对比表，便于选择统一使用的后端
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	models := fs.String("models", "", tr("比较的模型（逗号分隔，provider:model 可切换服务商，如 whisper-1,groq:whisper-large-v3），默认使用配置中的 bench_backends"))
+	reference := fs.String("ref", "", tr("参考文本（.txt, .srt 或 .json），指定时计算 WER/CER"))
+	language := fs.String("language", "", tr("语言代码（如 zh, en, ja）"))
+	outputDir := fs.String("output", "", tr("输出目录（默认为配置的输出目录下的 bench），每个后端的结果保存在以名称命名的子目录中"))
+	formats := fs.String("formats", "txt", tr("输出格式（逗号分隔）"))
+	asJSON := fs.Bool("json", false, tr("以 JSON 输出"))
+	verbose := fs.Bool("verbose", false, tr("显示详细输出"))
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Println(tr("用法: whisper-go bench [options] <input>"))
+		fmt.Println(tr("示例: whisper-go bench -models whisper-1,groq:whisper-large-v3 -ref truth.txt meeting.mp3"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(exitBadInput)
+	}
+	input := fs.Arg(0)
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		exitWith(exitConfig, tr("加载配置失败: %v"), err)
+	}
+	// 每个后端都要实际请求一次：不读取缓存和断点续传，也不推送通知
+	config.Cache = false
+	config.Resume = false
+	config.WebhookURL = ""
+	config.MQTTBroker = ""
+	if *language != "" {
+		config.Language = *language
+	}
+	if *outputDir == "" {
+		*outputDir = filepath.Join(config.OutputDir, "bench")
+	}
+
+	backends := config.BenchBackends
+	if *models != "" {
+		backends = parseBenchModels(*models)
+	}
+	if len(backends) == 0 {
+		exitWith(exitBadInput, "%s", tr("没有要比较的后端：使用 -models 或在配置中设置 bench_backends"))
+	}
+
+	var refWords, refChars []string
+	opts := evalOptions{Chinese: config.Chinese}
+	if *reference != "" {
+		text, err := readEvalText(*reference)
+		if err != nil {
+			exitWith(exitBadInput, tr("读取 %s 失败: %v"), *reference, err)
+		}
+		if refWords, refChars = evalTokens(text, opts); len(refWords) == 0 {
+			exitWith(exitBadInput, tr("参考文本为空: %s"), *reference)
+		}
+	}
+
+	formatList := strings.Split(*formats, ",")
+	var results []benchResult
+	// 依次运行，避免后端之间争用带宽影响耗时
+	failed := 0
+	for i, backend := range backends {
+		r := runBenchBackend(config, backend, input, *outputDir, formatList, *verbose)
+		prefix := fmt.Sprintf("[%d/%d] %s", i+1, len(backends), r.Name)
+		if r.Error != "" {
+			failed++
+			logError(tr("%s: 失败: %v\n"), prefix, r.Error)
+		} else {
+			logInfo(tr("%s: 完成（%s）\n"), prefix, time.Duration(r.Seconds*float64(time.Second)).Round(10*time.Millisecond))
+		}
+		results = append(results, r)
+	}
+
+	if refWords != nil {
+		for i := range results {
+			if results[i].Error != "" {
+				continue
+			}
+			words, chars := evalTokens(results[i].text, opts)
+			wer, cer := editRate(refWords, words), editRate(refChars, chars)
+			results[i].WER, results[i].CER = &wer, &cer
+		}
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		printBenchTable(results)
+	}
+	if failed == len(results) {
+		exit(exitFailure)
+	}
+}
+
+// parseBenchModels 解析 -models 参数：model 使用配置中的服务商，provider:model 切换到该服务商的默认地址
+func parseBenchModels(list string) []BenchBackend {
+	var backends []BenchBackend
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		var backend BenchBackend
+		provider, model, found := strings.Cut(item, ":")
+		switch provider {
+		case providerOpenAI, providerDeepgram, providerAssemblyAI, providerGroq, providerWhisperCpp:
+			if found {
+				backend.Provider, backend.Model = provider, model
+				break
+			}
+			fallthrough
+		default:
+			backend.Model = item
+		}
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+// runBenchBackend 用一个后端转写输入文件并记录耗时、费用和输出文件
+func runBenchBackend(config *Config, backend BenchBackend, input, outputDir string, formatList []string, verbose bool) benchResult {
+	r := benchResult{Name: backend.Name, Output: []string{}}
+	cfg, err := config.backendConfig(backend.BackendFallback)
+	if err == nil && cfg.needsAPIKey() && cfg.APIKey == "" {
+		err = errors.New(tr("缺少 api_key（切换服务商时需要在 bench_backends 中配置）"))
+	}
+	if cfg != nil {
+		r.Provider, r.Model = cfg.Provider, cfg.Model
+	}
+	if r.Name == "" {
+		r.Name = r.Provider + "/" + r.Model
+	}
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	if backend.PricePerMinute > 0 {
+		cfg.PricePerMinute = backend.PricePerMinute
+	}
+	cfg.OutputDir = filepath.Join(outputDir, sanitizeFilename(strings.ReplaceAll(r.Name, "/", "_")))
+
+	start := time.Now()
+	result, files, err := processFile(newClient(cfg), input, cfg, formatList, verbose)
+	r.Seconds = time.Since(start).Seconds()
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	if r.Seconds > 0 {
+		r.Speed = result.Duration / r.Seconds
+	}
+	if cfg.needsAPIKey() {
+		r.Cost = result.Duration / 60 * cfg.PricePerMinute
+	}
+	r.Segments = len(result.Segments)
+	r.Output = append(r.Output, files...)
+	r.text = result.Text
+	return r
+}
+
+// printBenchTable 输出各后端的对比表
+func printBenchTable(results []benchResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("后端\t耗时\t速度\t估算费用\tWER\tCER\t分段"))
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\t-\n", r.Name, tr("失败"))
+			continue
+		}
+		wer, cer := "-", "-"
+		if r.WER != nil {
+			wer, cer = fmt.Sprintf("%.2f%%", 100*r.WER.Rate), fmt.Sprintf("%.2f%%", 100*r.CER.Rate)
+		}
+		fmt.Fprintf(w, "%s\t%.1fs\t%.1fx\t$%.4f\t%s\t%s\t%d\n", r.Name, r.Seconds, r.Speed, r.Cost, wer, cer, r.Segments)
+	}
+	w.Flush()
+}
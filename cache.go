@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// responseCache 按音频内容和转写参数缓存转写结果，重新运行（如只改输出格式或合并设置）时不再调用 API
+type responseCache struct {
+	dir string
+}
+
+// newResponseCache 未开启缓存或无法确定缓存目录时返回 nil
+func newResponseCache(config *Config) *responseCache {
+	if !config.Cache {
+		return nil
+	}
+	dir := config.CacheDir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil
+		}
+		dir = filepath.Join(cacheDir, "whisper-go", "responses")
+	}
+	return &responseCache{dir: dir}
+}
+
+// key 由音频内容哈希和影响转写结果的参数计算缓存键，读取音频失败时返回空字符串
+func (c *responseCache) key(audioPath string, config *Config, prompt string) string {
+	if c == nil {
+		return ""
+	}
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+
+	language := config.Language
+	if config.AutoDetect {
+		language = "auto"
+	}
+	// 附加请求头可能切换网关路由或服务端选项，按名称排序后计入
+	headers := make([]string, 0, len(config.ExtraHeaders))
+	for name, value := range config.ExtraHeaders {
+		headers = append(headers, name+"="+value)
+	}
+	sort.Strings(headers)
+	params := []string{
+		hex.EncodeToString(h.Sum(nil)),
+		config.Provider, config.APIBaseURL, config.Model, language, prompt,
+		fmt.Sprint(config.TemperatureFallback), fmt.Sprint(config.CompressionRatioThreshold), fmt.Sprint(config.FallbackLogprobThreshold),
+		config.UploadCodec, config.UploadBitrate, fmt.Sprint(config.Diarize), strings.Join(headers, "\n"),
+		config.WhisperCppPath, config.WhisperCppModel, strings.Join(config.WhisperCppArgs, " "),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(params, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// path 缓存文件路径，按键的前两位分目录
+func (c *responseCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// load 读取缓存的结果，未命中时返回 nil
+func (c *responseCache) load(key string) *TranscriptionResult {
+	if c == nil || key == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var result TranscriptionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+// save 保存结果，失败只记录调试日志（缓存不影响转写）
+func (c *responseCache) save(key string, result *TranscriptionResult) {
+	if c == nil || key == "" {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logDebug(tr("写入缓存失败: %v"), err)
+		return
+	}
+	// 先写临时文件再重命名，并行切片同时写入时不会读到不完整的文件
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp_")
+	if err != nil {
+		logDebug(tr("写入缓存失败: %v"), err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		logDebug(tr("写入缓存失败: %v"), err)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 实时字幕 WebSocket 连接的超时设置
+const (
+	captionWriteTimeout = 10 * time.Second
+	captionPingInterval = 30 * time.Second
+	// captionSendBuffer 每个连接待发送的消息数，写满说明客户端跟不上，直接断开
+	captionSendBuffer = 64
+)
+
+// captionSegment 推送给浏览器的分段，只保留显示字幕需要的字段
+type captionSegment struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"`
+}
+
+// captionMessage 一条推送消息：新产生的分段和当前应显示的最近几行字幕
+type captionMessage struct {
+	Segments []captionSegment `json:"segments"`
+	Caption  string           `json:"caption"`
+}
+
+// captionHub 将转写产生的分段广播给订阅了同一范围的 WebSocket 连接。进程内只有一个，
+// live 每个片段、grpc 每个任务创建的实时输出都写入同一个 hub：live 和单文件转写的范围为空，
+// 服务模式下每个任务的范围为任务 ID，连接通过 /captions?job=<任务 ID> 订阅，看不到其他任务的字幕
+type captionHub struct {
+	mu      sync.Mutex
+	clients map[chan captionMessage]string
+	scopes  map[string]*captionScope
+	lines   int
+	token   string
+	origins map[string]bool
+}
+
+// captionScope 一个范围内的当前字幕
+type captionScope struct {
+	caption rollingCaption
+	current string
+}
+
+// captions 进程内的字幕 hub 及已启动的监听地址
+var captions struct {
+	mu        sync.Mutex
+	hub       *captionHub
+	listening map[string]bool
+}
+
+// enableCaptions 创建（已存在时直接返回）进程内的字幕 hub：保留 caption_lines 行字幕，
+// 配置 web_token 时连接需要携带令牌，caption_origins 为同源之外允许连接的网页来源
+func enableCaptions(config *Config) *captionHub {
+	captions.mu.Lock()
+	defer captions.mu.Unlock()
+	if captions.hub == nil {
+		captions.hub = &captionHub{
+			clients: make(map[chan captionMessage]string),
+			scopes:  make(map[string]*captionScope),
+			lines:   config.CaptionLines,
+			token:   config.WebToken,
+			origins: make(map[string]bool),
+		}
+		for _, origin := range config.CaptionOrigins {
+			captions.hub.origins[strings.TrimRight(origin, "/")] = true
+		}
+	}
+	return captions.hub
+}
+
+// activeCaptions 返回已启用的字幕 hub，未启用时为 nil
+func activeCaptions() *captionHub {
+	captions.mu.Lock()
+	defer captions.mu.Unlock()
+	return captions.hub
+}
+
+// listenCaptions 在 caption_listen 上启动实时字幕服务（/captions 为 WebSocket，/overlay 为叠加层页面），同一地址只启动一次
+func listenCaptions(config *Config) error {
+	addr := config.CaptionListen
+	hub := enableCaptions(config)
+	captions.mu.Lock()
+	defer captions.mu.Unlock()
+	if captions.listening[addr] {
+		return nil
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if captions.listening == nil {
+		captions.listening = make(map[string]bool)
+	}
+	captions.listening[addr] = true
+
+	mux := http.NewServeMux()
+	hub.register(mux)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			logError(tr("实时字幕服务异常退出: %v"), err)
+		}
+	}()
+	logInfo(tr("实时字幕已启动: ws://%s/captions，叠加层页面 http://%s/overlay"), lis.Addr(), lis.Addr())
+	return nil
+}
+
+// register 在 mux 上注册 /captions 和 /overlay
+func (h *captionHub) register(mux *http.ServeMux) {
+	mux.Handle("/captions", h)
+	mux.HandleFunc("/overlay", serveCaptionOverlay)
+}
+
+// serveCaptionOverlay 返回透明背景的字幕叠加层页面，可直接作为 OBS 浏览器源
+func serveCaptionOverlay(w http.ResponseWriter, r *http.Request) {
+	page, err := webFS.ReadFile("web/overlay.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// checkOrigin 浏览器发起的连接只接受同源页面（如本服务的 /overlay）和 caption_origins 中的来源，
+// 防止任意网页在访问者的浏览器里读取内网的字幕；没有 Origin 请求头的非浏览器客户端不受限制
+func (h *captionHub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if h.origins[origin] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// ServeHTTP 校验令牌和来源后升级为 WebSocket 连接，先发送所订阅范围的当前字幕，之后推送该范围新产生的分段
+func (h *captionHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !validToken(r, h.token) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="whisper-go"`)
+		http.Error(w, tr("缺少访问令牌或令牌错误"), http.StatusUnauthorized)
+		return
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	scope := r.URL.Query().Get("job")
+	send := make(chan captionMessage, captionSendBuffer)
+	h.mu.Lock()
+	current := ""
+	if s := h.scopes[scope]; s != nil {
+		current = s.current
+	}
+	send <- captionMessage{Segments: []captionSegment{}, Caption: current}
+	h.clients[send] = scope
+	h.mu.Unlock()
+
+	// 读取客户端消息只为处理关闭和 pong，读取出错说明连接已断开
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(captionPingInterval)
+	defer ping.Stop()
+	defer h.remove(send)
+	for {
+		select {
+		case msg, ok := <-send:
+			if !ok {
+				// 客户端跟不上推送被移除
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ""), time.Now().Add(captionWriteTimeout))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(captionWriteTimeout))
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(captionWriteTimeout)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// remove 移除连接，已被移除时忽略
+func (h *captionHub) remove(send chan captionMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[send]; ok {
+		delete(h.clients, send)
+		close(send)
+	}
+}
+
+// broadcast 更新 scope 范围的当前字幕并推送给订阅该范围的连接，待发送队列已满的连接会被断开
+func (h *captionHub) broadcast(scope string, segments []Segment) {
+	msg := captionMessage{}
+	for _, seg := range segments {
+		msg.Segments = append(msg.Segments, captionSegment{
+			Start:   seg.Start,
+			End:     seg.End,
+			Text:    strings.TrimSpace(seg.Text),
+			Speaker: seg.Speaker,
+		})
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.scopes[scope]
+	if s == nil {
+		s = &captionScope{caption: rollingCaption{max: h.lines}}
+		h.scopes[scope] = s
+	}
+	s.current = s.caption.add(segments)
+	msg.Caption = s.current
+	for send, subscribed := range h.clients {
+		if subscribed != scope {
+			continue
+		}
+		select {
+		case send <- msg:
+		default:
+			delete(h.clients, send)
+			close(send)
+		}
+	}
+}
+
+// forget 任务结束后丢弃其范围的字幕，live 等未分范围的字幕保留给之后连接的叠加层
+func (h *captionHub) forget(scope string) {
+	if scope == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.scopes, scope)
+}
+
+// captionHubSink 将分段写入字幕 hub 中 scope 范围；hub 在进程内共享，关闭单个输出不影响其他任务
+type captionHubSink struct {
+	hub   *captionHub
+	scope string
+}
+
+func (c captionHubSink) WriteSegments(segments []Segment) error {
+	c.hub.broadcast(c.scope, segments)
+	return nil
+}
+
+func (c captionHubSink) Close() error {
+	c.hub.forget(c.scope)
+	return nil
+}
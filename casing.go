@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// 英文大小写和标点的整理方式
+const (
+	casingRules = "rules" // 只按规则整理
+	casingLLM   = "llm"   // 规则整理后再由 casing_model 指定的对话模型修正
+)
+
+// casingBatchSize 每次请求模型修正的分段数
+const casingBatchSize = 40
+
+// casingSentencePause 分段结尾没有标点且与下一个分段间隔至少这么多秒时视为句子结束，补上句号
+const casingSentencePause = 1.5
+
+var (
+	// casingSpaceBeforePunct 标点前多余的空格
+	casingSpaceBeforePunct = regexp.MustCompile(`\s+([,.!?;:])`)
+	// casingPronounI 单独的 i 及其缩写（i'm、i've、i'll、i'd）
+	casingPronounI = regexp.MustCompile(`\bi\b('(?:m|ve|ll|d))?`)
+	// casingSentenceStart 句末标点后的第一个字母
+	casingSentenceStart = regexp.MustCompile(`([.!?]["')\]]*\s+["'(\[]*)(\p{Ll})`)
+	// casingAbbreviation 以句点结尾但不结束句子的缩写
+	casingAbbreviation = regexp.MustCompile(`(?i)\b(?:e\.g|i\.e|etc|vs|approx|cf)\.["')\]]*\s+["'(\[]*$`)
+)
+
+// casingPrompt 模型修正大小写和标点的系统提示词
+const casingPrompt = `You fix casing and punctuation in English transcript lines.
+The user sends a JSON array of consecutive subtitle lines; a sentence may continue across lines.
+Restore sentence casing, capitalize proper nouns and acronyms, and add or fix punctuation.
+Do not add, remove, reorder or change any words, and do not merge or split lines.
+Reply with a JSON array of exactly the same length as the input, in the same order. Reply with JSON only.`
+
+// setEnglishCasing 设置 english_casing，llm 需要同时配置 casing_model
+func (c *Config) setEnglishCasing(mode string) error {
+	switch mode {
+	case "", casingRules:
+	case casingLLM:
+		if c.CasingModel == "" {
+			return errors.New(tr("english_casing 为 llm 时需要同时设置 casing_model（修正大小写用的对话模型）"))
+		}
+	default:
+		return fmt.Errorf(tr("无效的 english_casing 配置: %s（可选 rules, llm）"), mode)
+	}
+	c.EnglishCasing = mode
+	return nil
+}
+
+// normalizeEnglishCasing 开启 english_casing 且结果为英文时统一句首大写、代词 I 和标点；
+// llm 模式下再由对话模型修正专有名词和标点，模型改动了词语的行保留规则整理的结果
+func normalizeEnglishCasing(client *openai.Client, result *TranscriptionResult, config *Config) {
+	if config.EnglishCasing == "" || result.NoSpeech || languageCode(result.Language) != "en" {
+		return
+	}
+	if len(result.Segments) == 0 {
+		result.Text = casingSentences(casingSegment(result.Text), true, true)
+		return
+	}
+
+	sentenceStart := true
+	for i := range result.Segments {
+		seg := &result.Segments[i]
+		text := casingSegment(seg.Text)
+		// 最后一个分段或与下一个分段之间有较长停顿时，没有标点的结尾视为句子结束
+		last := i == len(result.Segments)-1 || result.Segments[i+1].Start-seg.End >= casingSentencePause
+		seg.Text = casingSentences(text, sentenceStart, last)
+		if seg.Text != "" {
+			sentenceStart = endsSentence(seg.Text)
+		}
+	}
+
+	if config.EnglishCasing == casingLLM {
+		fixed := 0
+		for start := 0; start < len(result.Segments); start += casingBatchSize {
+			end := min(start+casingBatchSize, len(result.Segments))
+			lines := make([]string, 0, end-start)
+			for _, seg := range result.Segments[start:end] {
+				lines = append(lines, seg.Text)
+			}
+			out, err := casingFromModel(client, config.CasingModel, lines)
+			if err != nil {
+				logWarn(tr("修正第 %d-%d 个分段的大小写失败，使用规则整理的结果: %v"), start+1, end, err)
+				continue
+			}
+			for i, line := range out {
+				if line = strings.TrimSpace(line); line != lines[i] && sameWords(line, lines[i]) {
+					result.Segments[start+i].Text = line
+					fixed++
+				}
+			}
+		}
+		logDebug(tr("模型修正了 %d 个分段的大小写和标点"), fixed)
+	}
+
+	for i := range result.Segments {
+		recaseWords(result.Segments[i].Words, result.Segments[i].Text)
+	}
+	rebuildResultText(result)
+}
+
+// casingSegment 整理一个分段：全大写的分段改为小写（之后按句首恢复大写），去掉标点前的空格，修正代词 I
+func casingSegment(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if isShouting(text) {
+		text = strings.ToLower(text)
+	}
+	text = casingSpaceBeforePunct.ReplaceAllString(text, "$1")
+	// 从后往前替换，i.e. 中的 i 不是代词
+	matches := casingPronounI.FindAllStringIndex(text, -1)
+	for k := len(matches) - 1; k >= 0; k-- {
+		i, j := matches[k][0], matches[k][1]
+		if j+1 < len(text) && text[j] == '.' && unicode.IsLetter(rune(text[j+1])) {
+			continue
+		}
+		text = text[:i] + "I" + text[i+1:]
+	}
+	return text
+}
+
+// isShouting 分段是否全部为大写字母（至少两个多字母单词，排除 OK、NASA 这样的单个缩写）
+func isShouting(text string) bool {
+	words := 0
+	for _, word := range strings.Fields(text) {
+		letters := 0
+		for _, r := range word {
+			if unicode.IsLower(r) {
+				return false
+			}
+			if unicode.IsUpper(r) {
+				letters++
+			}
+		}
+		if letters > 1 {
+			words++
+		}
+	}
+	return words >= 2
+}
+
+// casingSentences 句首字母大写：start 为分段开头是否为句首，end 为分段结尾是否为句末（没有标点时补句号）
+func casingSentences(text string, start, end bool) string {
+	if text == "" {
+		return text
+	}
+	if start {
+		text = capitalizeFirst(text)
+	}
+	// 从后往前替换，缩写（e.g.、etc.）后的字母不是句首
+	matches := casingSentenceStart.FindAllStringSubmatchIndex(text, -1)
+	for k := len(matches) - 1; k >= 0; k-- {
+		letter := matches[k][4]
+		if casingAbbreviation.MatchString(text[:letter]) {
+			continue
+		}
+		text = text[:letter] + capitalizeFirst(text[letter:])
+	}
+	if end && !endsSentence(text) {
+		last := []rune(text)[len([]rune(text))-1]
+		if unicode.IsLetter(last) || unicode.IsDigit(last) {
+			text += "."
+		}
+	}
+	return text
+}
+
+// capitalizeFirst 第一个字母大写（跳过开头的引号、括号等）
+func capitalizeFirst(text string) string {
+	for i, r := range text {
+		if unicode.IsLetter(r) {
+			return text[:i] + string(unicode.ToUpper(r)) + text[i+len(string(r)):]
+		}
+		if unicode.IsDigit(r) {
+			return text
+		}
+	}
+	return text
+}
+
+// sameWords 两行去掉标点并忽略大小写后词语是否相同，用于拒绝模型改写词语的结果
+func sameWords(a, b string) bool {
+	words := func(s string) []string {
+		return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+		})
+	}
+	wa, wb := words(a), words(b)
+	if len(wa) != len(wb) {
+		return false
+	}
+	for i := range wa {
+		if strings.Trim(wa[i], "'") != strings.Trim(wb[i], "'") {
+			return false
+		}
+	}
+	return true
+}
+
+// recaseWords 按整理后的分段文本更新词级时间戳中每个词的大小写和标点
+func recaseWords(words []Word, text string) {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		return
+	}
+	cursor := 0
+	for i := range words {
+		word := strings.TrimSpace(words[i].Word)
+		if word == "" {
+			continue
+		}
+		key := strings.ToLower(strings.Trim(word, ",.!?;:\"'()"))
+		if key == "" {
+			continue
+		}
+		j := strings.Index(lower[cursor:], key)
+		if j < 0 {
+			continue
+		}
+		start := cursor + j
+		end := start + len(key)
+		// 词后紧跟的标点一并带上
+		for end < len(text) && strings.ContainsRune(",.!?;:", rune(text[end])) {
+			end++
+		}
+		leading := words[i].Word[:len(words[i].Word)-len(strings.TrimLeft(words[i].Word, " "))]
+		words[i].Word = leading + text[start:end]
+		cursor = end
+	}
+}
+
+// casingFromModel 一次请求修正一批分段的大小写和标点（行数由本函数检查）
+func casingFromModel(client *openai.Client, model string, lines []string) ([]string, error) {
+	input, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: casingPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: string(input)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New(tr("模型没有返回结果"))
+	}
+
+	var out []string
+	if err := parseChatJSON(resp.Choices[0].Message.Content, &out); err != nil {
+		return nil, fmt.Errorf(tr("解析模型返回的文本失败: %w"), err)
+	}
+	if len(out) != len(lines) {
+		return nil, fmt.Errorf(tr("模型返回 %d 行，应为 %d 行"), len(out), len(lines))
+	}
+	return out, nil
+}
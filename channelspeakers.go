@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// 声道能量分析参数
+const (
+	// channelEnergySampleRate 分析用的采样率，判断哪一侧在说话不需要高采样率
+	channelEnergySampleRate = 8000
+	// channelEnergyWindow 能量窗口长度（秒）
+	channelEnergyWindow = 0.02
+	// channelSpeakerMarginDB 一侧能量比另一侧高出多少分贝才认定为该侧说话，否则视为双方同时说话或串音，不标记
+	channelSpeakerMarginDB = 6.0
+	// channelSilenceDBFS 两侧均低于此电平（dBFS）的分段视为静音，不标记
+	channelSilenceDBFS = -60.0
+)
+
+// channelEnergy 左右声道按窗口计算的均方值
+type channelEnergy struct {
+	left, right []float64
+}
+
+// tagChannelSpeakers 按分段时间范围比较左右声道能量，给分段标记说话人（默认左声道为 A、右声道为 B）。
+// 用于双方各占一个声道的通话录音，不需要外部说话人分离服务；分析失败只记录警告
+func tagChannelSpeakers(result *TranscriptionResult, inputFile string, config *Config) {
+	if len(result.Segments) == 0 {
+		return
+	}
+	energy, err := readChannelEnergy(inputFile)
+	if err != nil {
+		logWarn(tr("按声道区分说话人失败: %v"), err)
+		return
+	}
+
+	names := config.ChannelSpeakerNames
+	tagged := 0
+	for i := range result.Segments {
+		seg := &result.Segments[i]
+		switch energy.dominant(seg.Start, seg.End) {
+		case 0:
+			seg.Speaker = names[0]
+		case 1:
+			seg.Speaker = names[1]
+		default:
+			continue
+		}
+		tagged++
+	}
+	logDebug(tr("按声道能量标记说话人: %d/%d 个分段"), tagged, len(result.Segments))
+}
+
+// dominant 返回 [start, end) 内能量明显更高的声道（0 为左，1 为右），无法区分时返回 -1
+func (e *channelEnergy) dominant(start, end float64) int {
+	from := max(int(start/channelEnergyWindow), 0)
+	to := min(int(math.Ceil(end/channelEnergyWindow)), len(e.left))
+	if from >= to {
+		return -1
+	}
+	var left, right float64
+	for i := from; i < to; i++ {
+		left += e.left[i]
+		right += e.right[i]
+	}
+	n := float64(to - from)
+	leftDB, rightDB := powerDB(left/n), powerDB(right/n)
+	switch {
+	case leftDB < channelSilenceDBFS && rightDB < channelSilenceDBFS:
+		return -1
+	case leftDB-rightDB >= channelSpeakerMarginDB:
+		return 0
+	case rightDB-leftDB >= channelSpeakerMarginDB:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// powerDB 均方值转换为 dBFS
+func powerDB(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return 10 * math.Log10(meanSquare)
+}
+
+// readChannelEnergy 读取输入的左右声道能量：PCM WAV 直接读取，其他格式由 ffmpeg 解码为低采样率 PCM 后通过管道读取。
+// 输入必须至少有两个声道，多于两个时只比较前两个
+func readChannelEnergy(inputFile string) (*channelEnergy, error) {
+	if f, err := os.Open(inputFile); err == nil {
+		info, err := readWAVInfo(f)
+		if err == nil {
+			defer f.Close()
+			if info.Channels < 2 {
+				return nil, fmt.Errorf(tr("输入只有 %d 个声道，需要双声道录音"), info.Channels)
+			}
+			if _, err := f.Seek(info.DataOffset, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return measureChannelEnergy(io.LimitReader(f, info.DataSize), info)
+		}
+		f.Close()
+	}
+
+	channels, err := probeAudioChannels(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	if channels < 2 {
+		return nil, fmt.Errorf(tr("输入只有 %d 个声道，需要双声道录音"), channels)
+	}
+
+	args := ffmpegArgs(inputFile, "-vn", "-acodec", "pcm_s16le", "-ar", strconv.Itoa(channelEnergySampleRate), "-f", "s16le", "pipe:1")
+	cmd := exec.Command(ffmpegTools.FFmpeg, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf(tr("启动 ffmpeg 失败: %w"), err)
+	}
+	energy, err := measureChannelEnergy(stdout, &wavInfo{Channels: channels, SampleRate: channelEnergySampleRate, BitsPerSample: 16})
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf(tr("ffmpeg 提取音频失败: %w: %s"), err, lastLine(stderr.String()))
+	}
+	return energy, nil
+}
+
+// probeAudioChannels 用 ffprobe 读取第一条音轨的声道数
+func probeAudioChannels(inputFile string) (int, error) {
+	out, err := exec.Command(ffmpegTools.FFprobe,
+		"-v", "error",
+		"-select_streams", "a:0",
+		"-show_entries", "stream=channels",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		inputFile,
+	).Output()
+	if err != nil {
+		return 0, fmt.Errorf(tr("读取声道数失败: %w"), err)
+	}
+	channels, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, fmt.Errorf(tr("读取声道数失败: %w"), err)
+	}
+	return channels, nil
+}
+
+// measureChannelEnergy 按 channelEnergyWindow 窗口计算前两个声道的均方值
+func measureChannelEnergy(r io.Reader, info *wavInfo) (*channelEnergy, error) {
+	frameSize := info.frameSize()
+	sampleBytes := info.BitsPerSample / 8
+	windowFrames := max(int(float64(info.SampleRate)*channelEnergyWindow), 1)
+
+	reader := bufio.NewReaderSize(r, 1<<16)
+	buf := make([]byte, windowFrames*frameSize)
+	energy := &channelEnergy{}
+	for {
+		n, err := io.ReadFull(reader, buf)
+		n -= n % frameSize
+		if n > 0 {
+			var left, right float64
+			for i := 0; i < n; i += frameSize {
+				lv := sampleValue(buf[i:i+sampleBytes], info.BitsPerSample)
+				rv := sampleValue(buf[i+sampleBytes:i+2*sampleBytes], info.BitsPerSample)
+				left += lv * lv
+				right += rv * rv
+			}
+			frames := float64(n / frameSize)
+			energy.left = append(energy.left, left/frames)
+			energy.right = append(energy.right, right/frames)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return energy, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf(tr("读取音频失败: %w"), err)
+		}
+	}
+}
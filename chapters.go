@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// chapterTitleMaxLen 启发式章节标题的最大字符数
+const chapterTitleMaxLen = 40
+
+// Chapter 章节
+type Chapter struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Title string  `json:"title"`
+}
+
+// wantChapters 是否需要章节分析（开启 chapters 或请求了包含章节的格式）
+func wantChapters(config *Config, formatList []string) bool {
+	if config.Chapters {
+		return true
+	}
+	for _, format := range formatList {
+		if format == "chapters" || format == "ffmetadata" || format == "tagged" {
+			return true
+		}
+	}
+	return false
+}
+
+// detectChapters 生成章节：配置了 chapter_model 时由对话模型判断话题切换并命名，
+// 否则按分段间的停顿切分。模型调用失败时回退到停顿切分
+func detectChapters(client *openai.Client, result *TranscriptionResult, config *Config) []Chapter {
+	if len(result.Segments) == 0 {
+		return nil
+	}
+
+	var starts []int
+	titles := map[int]string{}
+	if config.ChapterModel != "" {
+		var err error
+		starts, titles, err = chapterBoundariesFromModel(client, result.Segments, config)
+		if err != nil {
+			logWarn(tr("章节分析失败，改用停顿切分: %v"), err)
+			starts = nil
+		}
+	}
+	if len(starts) == 0 {
+		starts = chapterBoundariesFromGaps(result.Segments, config.ChapterGap, config.ChapterMinLength)
+	}
+
+	chapters := make([]Chapter, 0, len(starts))
+	for i, start := range starts {
+		end := len(result.Segments)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		title := titles[start]
+		if title == "" {
+			title = chapterTitleFromText(result.Segments[start:end])
+		}
+		chapter := Chapter{Start: result.Segments[start].Start, Title: title}
+		// 第一章从 0 开始（YouTube 章节的要求）
+		if i == 0 {
+			chapter.Start = 0
+		}
+		chapters = append(chapters, chapter)
+	}
+	for i := range chapters {
+		if i+1 < len(chapters) {
+			chapters[i].End = chapters[i+1].Start
+		} else {
+			chapters[i].End = math.Max(result.Duration, result.Segments[len(result.Segments)-1].End)
+		}
+	}
+	return chapters
+}
+
+// chapterBoundariesFromGaps 在停顿不短于 gap 秒、且当前章节已不短于 minLength 秒处开始新章节，返回各章首个分段的下标
+func chapterBoundariesFromGaps(segments []Segment, gap, minLength float64) []int {
+	starts := []int{0}
+	chapterStart := segments[0].Start
+	for i := 1; i < len(segments); i++ {
+		if segments[i].Start-segments[i-1].End >= gap && segments[i].Start-chapterStart >= minLength {
+			starts = append(starts, i)
+			chapterStart = segments[i].Start
+		}
+	}
+	return starts
+}
+
+// chapterTitleFromText 取章节开头的文本作为标题
+func chapterTitleFromText(segments []Segment) string {
+	for _, seg := range segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		if utf8.RuneCountInString(text) > chapterTitleMaxLen {
+			text = string([]rune(text)[:chapterTitleMaxLen]) + "…"
+		}
+		return text
+	}
+	return ""
+}
+
+// chapterPrompt 让对话模型按话题切分章节的提示
+const chapterPrompt = `You split transcripts into chapters. Each line of the transcript is "[segment id] mm:ss text".
+Find the points where the topic changes and give every chapter a short title in the language of the transcript.
+The first chapter must start at the first segment. Prefer chapters of a few minutes; do not create chapters for small digressions.
+Reply with JSON only: {"chapters":[{"segment":<first segment id>,"title":"<title>"}]}`
+
+// chapterBoundariesFromModel 由对话模型判断话题切换点并生成标题
+func chapterBoundariesFromModel(client *openai.Client, segments []Segment, config *Config) ([]int, map[int]string, error) {
+	var transcript strings.Builder
+	for _, seg := range segments {
+		fmt.Fprintf(&transcript, "[%d] %s %s\n", seg.ID, formatChapterTime(seg.Start), strings.TrimSpace(seg.Text))
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: config.ChapterModel,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: chapterPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: transcript.String()},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, nil, errors.New(tr("模型没有返回结果"))
+	}
+
+	var reply struct {
+		Chapters []struct {
+			Segment int    `json:"segment"`
+			Title   string `json:"title"`
+		} `json:"chapters"`
+	}
+	if err := parseChatJSON(resp.Choices[0].Message.Content, &reply); err != nil {
+		return nil, nil, fmt.Errorf(tr("解析模型返回的章节失败: %w"), err)
+	}
+
+	index := make(map[int]int, len(segments))
+	for i, seg := range segments {
+		index[seg.ID] = i
+	}
+	titles := map[int]string{}
+	for _, c := range reply.Chapters {
+		if i, ok := index[c.Segment]; ok {
+			titles[i] = strings.TrimSpace(c.Title)
+		}
+	}
+	if len(titles) == 0 {
+		return nil, nil, errors.New(tr("模型返回的章节不包含有效的分段序号"))
+	}
+	// 第一章总是从第一个分段开始
+	if _, ok := titles[0]; !ok {
+		titles[0] = ""
+	}
+
+	starts := make([]int, 0, len(titles))
+	for i := range titles {
+		starts = append(starts, i)
+	}
+	sort.Ints(starts)
+	return starts, titles, nil
+}
+
+// formatChapterTime 格式化章节时间：不足一小时为 m:ss，否则为 h:mm:ss（YouTube 章节格式）
+func formatChapterTime(seconds float64) string {
+	total := int(seconds)
+	h, m, s := total/3600, total%3600/60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// saveChapters 保存为 YouTube 章节文本，可直接粘贴到视频简介
+func saveChapters(result *TranscriptionResult, outputPath string) error {
+	var b strings.Builder
+	for _, c := range result.Chapters {
+		fmt.Fprintf(&b, "%s %s\n", formatChapterTime(c.Start), c.Title)
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
+
+// saveFFMetadata 保存为 FFMETADATA 章节，可用 ffmpeg -i input -i chapters.ffmetadata -map_metadata 1 -codec copy 写入媒体文件
+func saveFFMetadata(result *TranscriptionResult, outputPath string) error {
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+	for _, c := range result.Chapters {
+		b.WriteString("\n[CHAPTER]\nTIMEBASE=1/1000\n")
+		fmt.Fprintf(&b, "START=%d\n", int64(math.Round(c.Start*1000)))
+		fmt.Fprintf(&b, "END=%d\n", int64(math.Round(c.End*1000)))
+		fmt.Fprintf(&b, "title=%s\n", escapeFFMetadata(c.Title))
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
+
+// escapeFFMetadata 转义 FFMETADATA 中的特殊字符
+func escapeFFMetadata(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n").Replace(s)
+}
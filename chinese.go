@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// 中文输出规范化方式
+const (
+	chineseSimplified  = "simplified"
+	chineseTraditional = "traditional"
+)
+
+// chineseConverter 简繁转换器：先按最长匹配查词组，未命中时逐字转换
+type chineseConverter struct {
+	chars     map[rune]rune
+	phrases   map[string]string
+	maxPhrase int // 最长词组的字数
+}
+
+var (
+	chineseConverters   = map[string]*chineseConverter{}
+	chineseConvertersMu sync.Mutex
+)
+
+// getChineseConverter 返回转换到目标写法的转换器，词典在首次使用时解析
+func getChineseConverter(target string) *chineseConverter {
+	chineseConvertersMu.Lock()
+	defer chineseConvertersMu.Unlock()
+
+	if c, ok := chineseConverters[target]; ok {
+		return c
+	}
+	c := &chineseConverter{chars: map[rune]rune{}, phrases: map[string]string{}}
+	switch target {
+	case chineseTraditional:
+		c.addEntries(s2tChars, false)
+		c.addEntries(s2tPhrases, false)
+	case chineseSimplified:
+		// 简繁词典反向使用，繁体词组反向后也是正确的简体写法（如 頭髮 -> 头发）
+		c.addEntries(s2tChars, true)
+		c.addEntries(t2sExtraChars, false)
+		c.addEntries(s2tPhrases, true)
+		c.addEntries(t2sPhrases, false)
+	}
+	chineseConverters[target] = c
+	return c
+}
+
+// addEntries 添加词典项：单字加入字表，多字加入词组表。reverse 为 true 时由后一半转换为前一半
+func (c *chineseConverter) addEntries(dict string, reverse bool) {
+	for _, entry := range strings.Fields(dict) {
+		runes := []rune(entry)
+		half := len(runes) / 2
+		from, to := runes[:half], runes[half:]
+		if reverse {
+			from, to = to, from
+		}
+		if half == 1 {
+			c.chars[from[0]] = to[0]
+			continue
+		}
+		c.phrases[string(from)] = string(to)
+		c.maxPhrase = max(c.maxPhrase, half)
+	}
+}
+
+// convert 转换一段文本
+func (c *chineseConverter) convert(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text))
+	for i := 0; i < len(runes); {
+		matched := false
+		for n := min(c.maxPhrase, len(runes)-i); n >= 2; n-- {
+			if to, ok := c.phrases[string(runes[i:i+n])]; ok {
+				b.WriteString(to)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if to, ok := c.chars[runes[i]]; ok {
+			b.WriteRune(to)
+		} else {
+			b.WriteRune(runes[i])
+		}
+		i++
+	}
+	return b.String()
+}
+
+// convertChinese 按配置将分段、全文和章节标题统一转换为简体或繁体
+func convertChinese(result *TranscriptionResult, config *Config) {
+	if config.Chinese == "" {
+		return
+	}
+	c := getChineseConverter(config.Chinese)
+	for i := range result.Segments {
+		result.Segments[i].Text = c.convert(result.Segments[i].Text)
+		for j := range result.Segments[i].Words {
+			result.Segments[i].Words[j].Word = c.convert(result.Segments[i].Words[j].Word)
+		}
+	}
+	for i := range result.Chapters {
+		result.Chapters[i].Title = c.convert(result.Chapters[i].Title)
+	}
+	result.Text = c.convert(result.Text)
+}
@@ -0,0 +1,137 @@
+package main
+
+// 简繁转换词典，参照 OpenCC 的 STCharacters / STPhrases 整理。
+// 每项为简体与繁体直接相连（字表为两个字，词组前一半为简体、后一半为繁体），项之间以空白分隔
+
+// s2tChars 简体到繁体的逐字对照（OpenCC 标准繁体），一简对多繁的字取最常用的写法
+const s2tChars = `
+认認 让讓 议議 记記 讯訊 讨討 训訓 讲講 许許 论論 设設 访訪 证證 评評 识識 诉訴 词詞 译譯 试試 诗詩 诚誠 话話 诞誕 该該
+详詳 语語 误誤 说說 请請 诸諸 读讀 课課 谁誰 调調 谈談 谊誼 谋謀 谎謊 谐諧 谓謂 谜謎 谢謝 谣謠 谦謙 谨謹 谱譜 谴譴 谬謬
+讽諷 诊診 诈詐 诵誦 诱誘 诲誨 诺諾 诽誹 谅諒 谍諜 谭譚 计計 订訂 讣訃 讥譏 讧訌 讪訕 讫訖 讹訛 讼訟 讳諱 讶訝 诀訣 诅詛
+诏詔 诂詁 诃訶 询詢 诣詣 诤諍 诧詫 诩詡 诫誡 诬誣 诘詰 诠詮 诡詭 诮誚 诳誑 诶誒 诿諉 谀諛 谂諗 谄諂 谆諄 谇誶 谏諫 谒謁
+谔諤 谕諭 谖諼 谗讒 谙諳 谚諺 谛諦 谝諞 谟謨 谠讜 谡謖 谤謗 谥謚 谧謐 谪謫 谮譖 谯譙 谰讕 谲譎 谳讞 谵譫 谶讖 变變 誉譽
+讴謳 讵詎 诋詆 诌謅 诓誆 诒詒 诔誄 诙詼 诛誅 诟詬 诨諢 谘諮 钉釘 针針 钓釣 钙鈣 钞鈔 钟鐘 钢鋼 钥鑰 钦欽 钧鈞 钩鉤 钮鈕
+钱錢 钳鉗 钻鑽 铁鐵 铃鈴 铅鉛 铜銅 铝鋁 铭銘 银銀 铸鑄 铺鋪 链鏈 销銷 锁鎖 锅鍋 锈鏽 锋鋒 锐銳 错錯 锡錫 锣鑼 锤錘 锦錦
+键鍵 锯鋸 锻鍛 镇鎮 镜鏡 镑鎊 镰鐮 镶鑲 钝鈍 钠鈉 钾鉀 铀鈾 铂鉑 铲鏟 铛鐺 铬鉻 铰鉸 锂鋰 锌鋅 锄鋤 锚錨 锥錐 锰錳 锹鍬
+镀鍍 镁鎂 镖鏢 镐鎬 镍鎳 镭鐳 钗釵 钛鈦 钨鎢 钵缽 铎鐸 铐銬 铆鉚 铢銖 铣銑 铮錚 铱銥 铵銨 锑銻 锗鍺 锭錠 镂鏤 镊鑷 镌鐫
+镣鐐 镯鐲 钒釩 钡鋇 钯鈀 钰鈺 钴鈷 钹鈸 钼鉬 铠鎧 铡鍘 铿鏗 锉銼 锢錮 锨鍁 镉鎘 镳鑣 钜鉅 纠糾 红紅 纤纖 约約 级級 纪紀
+纬緯 纯純 纱紗 纲綱 纳納 纵縱 纷紛 纸紙 纹紋 纺紡 纽紐 线線 练練 组組 细細 织織 终終 绍紹 经經 结結 绕繞 绘繪 给給 络絡
+绝絕 统統 绢絹 绣繡 继繼 绩績 绪緒 续續 绳繩 维維 绵綿 绸綢 综綜 绿綠 缀綴 缎緞 缓緩 编編 缘緣 缚縛 缝縫 缠纏 缩縮 缴繳
+缆纜 绑綁 绒絨 绞絞 绅紳 绊絆 绎繹 绚絢 绰綽 绷繃 绽綻 缅緬 缔締 缕縷 缤繽 缨纓 缭繚 缮繕 缰韁 纫紉 纭紜 纰紕 纶綸 纡紆
+纣紂 纨紈 绀紺 绌絀 绉縐 绛絳 绡綃 绥綏 绦絛 绫綾 绮綺 绯緋 绶綬 绾綰 缁緇 缄緘 缇緹 缈緲 缉緝 缑緱 缒縋 缙縉 缛縟 缜縝
+缟縞 缢縊 缥縹 缦縵 缪繆 缫繅 缬纈 缯繒 缱繾 缳繯 缵纘 辫辮 饥飢 饭飯 饮飲 饰飾 饱飽 饲飼 饺餃 饼餅 饿餓 馆館 馈饋 馅餡
+馒饅 饶饒 饵餌 饷餉 饴飴 饪飪 饨飩 饯餞 饽餑 馁餒 馄餛 馊餿 馋饞 馍饃 馏餾 馐饈 馑饉 馔饌 贝貝 负負 贡貢 财財 责責 贤賢
+败敗 账賬 货貨 质質 贩販 贪貪 贫貧 购購 贯貫 贴貼 贵貴 贷貸 贸貿 费費 贺賀 资資 赃贓 赋賦 赌賭 赎贖 赏賞 赐賜 赔賠 赖賴
+赚賺 赛賽 赞贊 赠贈 赢贏 赵趙 赶趕 贞貞 贬貶 贮貯 贱賤 贻貽 贼賊 贾賈 贿賄 赁賃 赂賂 赈賑 赊賒 赘贅 赡贍 赣贛 员員 圆圓
+损損 陨隕 则則 侧側 测測 厕廁 实實 买買 卖賣 见見 观觀 规規 觅覓 视視 览覽 觉覺 亲親 舰艦 现現 宽寬 砚硯 觊覬 觐覲 觑覷
+苋莧 车車 轧軋 军軍 轨軌 阵陣 库庫 连連 转轉 轮輪 软軟 轰轟 轴軸 轻輕 载載 轿轎 较較 辅輔 辆輛 辈輩 辉輝 输輸 辐輻 辑輯
+辕轅 辖轄 辗輾 舆輿 挥揮 浑渾 晕暈 荤葷 斩斬 渐漸 惭慚 暂暫 堑塹 崭嶄 轩軒 轭軛 轲軻 轶軼 轼軾 辄輒 辇輦 辊輥 辍輟 辘轆
+莲蓮 轸軫 门門 闪閃 闭閉 问問 闯闖 闲閒 间間 闷悶 闸閘 闹鬧 闻聞 阀閥 阁閣 阅閱 阎閻 阐闡 阔闊 闺閨 阂閡 阙闕 阖闔 闰閏
+闽閩 阉閹 阑闌 润潤 涧澗 悯憫 焖燜 娴嫻 简簡 锏鐧 闾閭 马馬 驭馭 驮馱 驯馴 驰馳 驱驅 驳駁 驴驢 驶駛 驻駐 驼駝 驾駕 驹駒
+驿驛 骂罵 骄驕 骆駱 验驗 骏駿 骑騎 骗騙 骚騷 骤驟 骡騾 妈媽 吗嗎 码碼 玛瑪 蚂螞 驸駙 驽駑 骇駭 骈駢 骊驪 骋騁 骛騖 骞騫
+骠驃 骥驥 笃篤 冯馮 鸟鳥 鸡雞 鸣鳴 鸥鷗 鸦鴉 鸭鴨 鸳鴛 鸯鴦 鸵鴕 鸽鴿 鹅鵝 鹊鵲 鹏鵬 鹤鶴 鹰鷹 鹦鸚 鹉鵡 鸠鳩 鸢鳶 鸪鴣
+鸾鸞 鹂鸝 鹃鵑 鹄鵠 鹌鵪 鹑鶉 鹜鶩 鹫鷲 鹭鷺 鹳鸛 岛島 枭梟 袅裊 鱼魚 鲁魯 鲍鮑 鲜鮮 鲤鯉 鲨鯊 鲸鯨 鳄鱷 鳞鱗 鲫鯽 鲢鰱
+鲶鯰 鳍鰭 鳗鰻 鳖鱉 鳝鱔 鲈鱸 鲑鮭 鲔鮪 鲛鮫 鲟鱘 鲠鯁 鳃鰓 鳅鰍 鳕鱈 鳟鱒 渔漁 苏蘇 页頁 顶頂 项項 顺順 须須 顽頑 顾顧
+顿頓 颁頒 颂頌 预預 领領 颇頗 频頻 颓頹 颗顆 题題 颜顏 额額 颠顛 颤顫 颈頸 颊頰 颖穎 颐頤 颅顱 颌頜 颚顎 颧顴 烦煩 硕碩
+顷頃 类類 风風 飘飄 飒颯 飓颶 飕颼 飙飆 枫楓 疯瘋 岚嵐 爱愛 罢罷 备備 笔筆 毕畢 边邊 宾賓 补補 参參 惨慘 蚕蠶 灿燦 仓倉
+层層 产產 长長 尝嘗 厂廠 场場 彻徹 尘塵 陈陳 衬襯 称稱 惩懲 迟遲 齿齒 冲沖 虫蟲 丑醜 筹籌 础礎 处處 触觸 传傳 疮瘡 创創
+从從 丛叢 聪聰 窜竄 达達 带帶 单單 担擔 胆膽 惮憚 弹彈 当當 挡擋 党黨 档檔 导導 祷禱 灯燈 邓鄧 敌敵 递遞 点點 电電 垫墊
+淀澱 叠疊 东東 冻凍 动動 栋棟 斗鬥 独獨 断斷 队隊 对對 吨噸 夺奪 堕墮 恶惡 儿兒 尔爾 发發 罚罰 矾礬 飞飛 废廢 坟墳 奋奮
+愤憤 粪糞 丰豐 凤鳳 妇婦 复復 盖蓋 干幹 冈岡 刚剛 岗崗 个個 巩鞏 沟溝 构構 关關 惯慣 广廣 归歸 龟龜 柜櫃 国國 过過 汉漢
+号號 护護 划劃 画畫 怀懷 坏壞 欢歡 环環 还還 换換 唤喚 涣渙 焕煥 痪瘓 汇匯 会會 秽穢 获獲 祸禍 几幾 机機 击擊 积積 极極
+际際 迹跡 济濟 挤擠 剂劑 忆憶 艺藝 亿億 义義 异異 阴陰 隐隱 应應 营營 蝇蠅 拥擁 痈癰 踊踴 优優 忧憂 邮郵 犹猶 于於 余餘
+与與 屿嶼 郁鬱 狱獄 渊淵 园園 远遠 愿願 跃躍 云雲 运運 酝醞 韵韻 杂雜 灾災 脏髒 凿鑿 枣棗 择擇 泽澤 斋齋 债債 毡氈 盏盞
+栈棧 战戰 张張 涨漲 帐帳 胀脹 这這 侦偵 争爭 挣掙 睁睜 狰猙 峥崢 筝箏 郑鄭 职職 执執 挚摯 掷擲 帜幟 滞滯 种種 肿腫 众眾
+皱皺 昼晝 猪豬 烛燭 嘱囑 瞩矚 筑築 专專 砖磚 桩樁 庄莊 装裝 妆妝 壮壯 状狀 坠墜 准準 浊濁 渍漬 踪蹤 总總 邹鄒 碍礙 肮骯
+袄襖 坝壩 办辦 帮幫 宝寶 报報 惫憊 币幣 毙斃 辩辯 标標 滨濱 槟檳 殡殯 鬓鬢 拨撥 残殘 苍蒼 舱艙 沧滄 册冊 搀攙 掺摻 蝉蟬
+偿償 肠腸 畅暢 痴癡 炽熾 宠寵 畴疇 踌躊 橱櫥 厨廚 雏雛 储儲 辞辭 葱蔥 凑湊 郸鄲 掸撣 荡蕩 捣搗 涤滌 丢丟 犊犢 兑兌 贰貳
+珐琺 肤膚 抚撫 秆稈 搁擱 龚龔 够夠 蛊蠱 剐剮 刽劊 滚滾 韩韓 鸿鴻 壶壺 沪滬 哗嘩 华華 烩燴 蓟薊 夹夾 荚莢 价價 歼殲 监監
+坚堅 笺箋 艰艱 茧繭 检檢 碱鹼 拣揀 捡撿 俭儉 减減 荐薦 槛檻 鉴鑒 践踐 剑劍 溅濺 将將 浆漿 蒋蔣 桨槳 奖獎 酱醬 胶膠 浇澆
+娇嬌 搅攪 矫矯 侥僥 脚腳 阶階 节節 洁潔 届屆 紧緊 仅僅 进進 烬燼 尽盡 劲勁 荆荊 茎莖 惊驚 径徑 痉痙 竞競 净淨 厩廄 旧舊
+举舉 剧劇 惧懼 据據 隽雋 决決 开開 凯凱 恺愷 垦墾 恳懇 抠摳 裤褲 夸誇 块塊 侩儈 矿礦 旷曠 况況 亏虧 岿巋 窥窺 溃潰 扩擴
+蜡蠟 腊臘 莱萊 来來 蓝藍 栏欄 拦攔 篮籃 兰蘭 澜瀾 揽攬 懒懶 烂爛 滥濫 捞撈 劳勞 涝澇 乐樂 垒壘 泪淚 篱籬 离離 里裏 礼禮
+丽麗 厉厲 励勵 砾礫 历歷 沥瀝 隶隸 俩倆 联聯 怜憐 涟漣 帘簾 敛斂 脸臉 恋戀 炼煉 粮糧 凉涼 两兩 疗療 辽遼 猎獵 临臨 邻鄰
+凛凜 龄齡 灵靈 岭嶺 刘劉 龙龍 聋聾 咙嚨 笼籠 垄壟 拢攏 陇隴 楼樓 娄婁 搂摟 篓簍 芦蘆 卢盧 庐廬 炉爐 掳擄 卤鹵 虏虜 录錄
+陆陸 吕呂 侣侶 屡屢 虑慮 滤濾 峦巒 挛攣 孪孿 乱亂 抡掄 伦倫 仑侖 沦淪 萝蘿 罗羅 逻邏 箩籮 麦麥 迈邁 脉脈 瞒瞞 蛮蠻 满滿
+谩謾 猫貓 么麼 没沒 们們 梦夢 眯瞇 弥彌 幂冪 庙廟 灭滅 亩畝 难難 挠撓 脑腦 恼惱 内內 拟擬 腻膩 撵攆 酿釀 聂聶 柠檸 狞獰
+宁寧 拧擰 泞濘 脓膿 浓濃 农農 疟瘧 欧歐 殴毆 呕嘔 沤漚 盘盤 庞龐 喷噴 苹蘋 凭憑 泼潑 扑撲 朴樸 栖棲 脐臍 齐齊 岂豈 启啟
+气氣 弃棄 牵牽 钎釺 迁遷 签簽 潜潛 浅淺 枪槍 呛嗆 墙牆 蔷薔 抢搶 桥橋 乔喬 侨僑 翘翹 窍竅 窃竊 寝寢 氢氫 倾傾 庆慶 琼瓊
+穷窮 趋趨 区區 躯軀 龋齲 权權 劝勸 却卻 确確 扰擾 热熱 韧韌 荣榮 洒灑 萨薩 伞傘 丧喪 扫掃 涩澀 杀殺 筛篩 晒曬 删刪 陕陝
+伤傷 烧燒 摄攝 慑懾 审審 婶嬸 肾腎 渗滲 声聲 胜勝 圣聖 师師 狮獅 湿濕 尸屍 时時 蚀蝕 势勢 适適 释釋 寿壽 兽獸 枢樞 书書
+属屬 术術 树樹 竖豎 数數 帅帥 双雙 烁爍 丝絲 耸聳 怂慫 擞擻 肃肅 虽雖 随隨 岁歲 孙孫 笋筍 琐瑣 獭獺 挞撻 台臺 后後 瘪癟
+态態 摊攤 瘫癱 滩灘 坛壇 叹嘆 汤湯 烫燙 涛濤 腾騰 誊謄 体體 屉屜 条條 厅廳 听聽 烃烴 头頭 秃禿 图圖 涂塗 团團 椭橢 洼窪
+袜襪 弯彎 湾灣 万萬 网網 韦韋 违違 围圍 为為 潍濰 苇葦 伟偉 伪偽 卫衛 稳穩 瓮甕 挝撾 蜗蝸 涡渦 窝窩 卧臥 呜嗚 乌烏 无無
+芜蕪 吴吳 坞塢 雾霧 务務 牺犧 袭襲 习習 戏戲 虾蝦 峡峽 侠俠 狭狹 厦廈 吓嚇 咸鹹 衔銜 显顯 险險 献獻 县縣 羡羨 宪憲 厢廂
+乡鄉 响響 萧蕭 嚣囂 晓曉 啸嘯 蝎蠍 协協 挟挾 携攜 胁脅 写寫 泻瀉 衅釁 兴興 汹洶 悬懸 选選 癣癬 学學 勋勳 寻尋 逊遜 压壓
+哑啞 亚亞 烟煙 盐鹽 严嚴 艳豔 厌厭 彦彥 杨楊 扬揚 疡瘍 阳陽 痒癢 养養 样樣 药藥 业業 叶葉 医醫 遗遺 仪儀 蚁蟻 荫蔭 樱櫻
+婴嬰 莹瑩 萤螢 荧熒 哟喲 咏詠 涌湧 娱娛 粤粵 郧鄖 匀勻 蕴蘊 攒攢 蛰蟄 辙轍 帧幀 爷爺 壳殼 挂掛 摆擺 摇搖 叙敘 呐吶 劢勱
+并並 占佔 悦悅 税稅 脱脫 蜕蛻 虚虛 黄黃 宫宮 户戶 别別 温溫 强強 横橫 禄祿 奥奧 晋晉
+`
+
+// s2tPhrases 一简对多繁时逐字转换会出错的词组
+const s2tPhrases = `
+头发頭髮 理发理髮 白发白髮 毛发毛髮 假发假髮 发型髮型 发廊髮廊 脱发脫髮
+染发染髮 烫发燙髮 金发金髮 黑发黑髮 长发長髮 短发短髮 卷发捲髮 发际髮際
+发夹髮夾 发丝髮絲 理发店理髮店 秀发秀髮 削发削髮 一发千钧一髮千鈞 皇后皇后 王后王后
+太后太后 后妃后妃 天后天后 影后影后 歌后歌后 皇太后皇太后 干净乾淨 干燥乾燥
+饼干餅乾 干杯乾杯 干脆乾脆 干旱乾旱 干货乾貨 干涸乾涸 干枯乾枯 干瘪乾癟
+晒干曬乾 烘干烘乾 风干風乾 擦干擦乾 干洗乾洗 干冰乾冰 干粮乾糧 干妈乾媽
+干爹乾爹 干儿子乾兒子 外强中干外強中乾 一干二净一乾二淨 干涉干涉 干扰干擾 干预干預 若干若干
+相干相干 干戈干戈 天干天干 干支干支 不相干不相干 吹干吹乾 干巴巴乾巴巴 口干口乾
+干咳乾咳 面条麵條 方便面方便麵 面包麵包 面粉麵粉 拉面拉麵 炒面炒麵 汤面湯麵
+面食麵食 挂面掛麵 凉面涼麵 面馆麵館 意大利面意大利麵 牛肉面牛肉麵 泡面泡麵 一只一隻
+两只兩隻 几只幾隻 三只三隻 四只四隻 五只五隻 每只每隻 船只船隻 只身隻身
+复杂複雜 复制複製 复印複印 复数複數 重复重複 复合複合 复式複式 复句複句
+复方複方 繁复繁複 复眼複眼 复诊複診 复姓複姓 复本複本 复利複利 复选複選
+制造製造 制作製作 制品製品 制成製成 制图製圖 制片製片 缝制縫製 绘制繪製
+录制錄製 研制研製 炼制煉製 特制特製 监制監製 定制定製 仿制仿製 印制印製
+配制配製 精制精製 烹制烹製 摄制攝製 制药製藥 制衣製衣 制冰製冰 复制品複製品
+钟情鍾情 钟爱鍾愛 一见钟情一見鍾情 放松放鬆 轻松輕鬆 松开鬆開 松散鬆散 松懈鬆懈
+松动鬆動 宽松寬鬆 蓬松蓬鬆 松弛鬆弛 松紧鬆緊 松口鬆口 松手鬆手 稀松稀鬆
+松软鬆軟 范围範圍 规范規範 模范模範 示范示範 范例範例 范畴範疇 防范防範
+典范典範 范本範本 风范風範 师范師範 范式範式 关系關係 联系聯繫 维系維繫
+系鞋带繫鞋帶 系上繫上 系好繫好 系着繫着 系安全带繫安全帶 日历日曆 农历農曆 阳历陽曆
+阴历陰曆 公历公曆 历法曆法 挂历掛曆 台历檯曆 旧历舊曆 年历年曆 历书曆書
+西历西曆 人云亦云人云亦云 云云云云 台风颱風 台灯檯燈 柜台櫃檯 吧台吧檯 台球檯球
+台面檯面 台布檯布 写字台寫字檯 梳妆台梳妝檯 批准批准 准许准許 准予准予 不准不准
+核准核准 准将准將 准入准入 准考证准考證 获准獲准 恩准恩准 允准允准 准生证准生證
+特征特徵 象征象徵 征求徵求 征收徵收 征兆徵兆 征集徵集 征稿徵稿 征婚徵婚
+征召徵召 征税徵稅 征询徵詢 征文徵文 应征應徵 征用徵用 征信徵信 征才徵才
+冲突衝突 冲动衝動 冲击衝擊 冲锋衝鋒 冲刺衝刺 冲破衝破 冲浪衝浪 冲撞衝撞
+要冲要衝 首当其冲首當其衝 缓冲緩衝 冲出衝出 冲进衝進 冲向衝向 冲上衝上 冲过衝過
+横冲直撞橫衝直撞 怒气冲冲怒氣衝衝 冲劲衝勁 北斗北斗 斗笠斗笠 漏斗漏斗 熨斗熨斗 烟斗煙斗
+斗胆斗膽 斗篷斗篷 一斗一斗 星斗星斗 斗室斗室 筋斗筋斗 车载斗量車載斗量 才高八斗才高八斗
+斗转星移斗轉星移 升斗升斗 抖斗抖斗 茶几茶几 几案几案 小丑小丑 丑时丑時 丑角丑角
+稻谷稻穀 谷物穀物 五谷五穀 谷子穀子 谷类穀類 谷仓穀倉 谷雨穀雨 划船划船
+划算划算 划拳划拳 划不来划不來 划桨划槳 划水划水 词汇詞彙 汇编彙編 汇总彙總
+字汇字彙 收获收穫 卷入捲入 卷起捲起 卷曲捲曲 席卷席捲 龙卷风龍捲風 卷土重来捲土重來
+卷尺捲尺 卷帘捲簾 手表手錶 钟表鐘錶 表带錶帶 怀表懷錶 腕表腕錶 秒表秒錶
+电子表電子錶 表盘錶盤 了解瞭解 明了明瞭 一目了然一目瞭然 尽管儘管 尽量儘量 尽快儘快
+尽早儘早 尽可能儘可能 占卜占卜 占星占星 占卦占卦 标签標籤 书签書籤 抽签抽籤
+牙签牙籤 签筒籤筒 胡子鬍子 胡须鬍鬚 八字胡八字鬍 络腮胡絡腮鬍 呼吁呼籲 精致精緻
+细致細緻 雅致雅緻 别致別緻 周末週末 周年週年 周刊週刊 周期週期 周报週報
+周一週一 周二週二 周三週三 周四週四 周五週五 周六週六 周日週日 每周每週
+上周上週 下周下週 本周本週 这周這週 注册註冊 注释註釋 注解註解 备注備註
+注明註明 注销註銷 批注批註 附注附註 杂志雜誌 标志標誌 日志日誌 志异誌異
+墓志墓誌 地方志地方誌 旅游旅遊 游戏遊戲 游客遊客 导游導遊 游览遊覽 游行遊行
+游乐遊樂 郊游郊遊 游玩遊玩 游艇遊艇 周游週遊 游记遊記 游历遊歷 网游網遊
+手游手遊 出游出遊 游说遊說 伙伴夥伴 合伙合夥 大伙大夥 伙计夥計 同伙同夥
+团伙團夥 入伙入夥 凶手兇手 凶残兇殘 凶猛兇猛 凶狠兇狠 行凶行兇 帮凶幫兇
+凶器兇器 凶案兇案 凶杀兇殺 凶恶兇惡 防御防禦 抵御抵禦 御寒禦寒 秋千鞦韆
+向往嚮往 向导嚮導 合并合併 吞并吞併 兼并兼併 并购併購 并吞併吞 心脏心臟
+内脏內臟 脏器臟器 肝脏肝臟 肾脏腎臟 五脏五臟 脏腑臟腑 公里公里 英里英里
+里程里程 千里千里 邻里鄰里 故里故里 海里海里 万里萬里 里数里數 华里華里
+乡里鄉里 里弄里弄 百里百里 前仆后继前仆後繼 沈阳瀋陽
+`
+
+// t2sExtraChars 繁体到简体时，s2tChars 反向对照之外的繁体和异体字
+const t2sExtraChars = `
+髮发 鬚须 裡里 乾干 麵面 隻只 係系 繫系 鍾钟 複复 鬆松 範范 製制 曆历 穫获 捲卷 錶表 瞭了 儘尽 籤签 鬍胡 籲吁 緻致 週周
+註注 誌志 遊游 夥伙 兇凶 禦御 鞦秋 韆千 嚮向 穀谷 衝冲 徵征 颱台 檯台 彙汇 纔才 齣出 併并 臟脏 啓启 爲为 衞卫 説说 衆众
+羣群 麽么 僞伪 峯峰 閑闲 餵喂 艷艳 溼湿 歎叹 睏困 綫线 汙污 嶽岳 妳你 喫吃 牠它 佈布
+`
+
+// t2sPhrases 繁体到简体时需要保留原字的词组
+const t2sPhrases = `
+乾隆乾隆 乾坤乾坤 乾卦乾卦 乾元乾元 瞭望瞭望 瞭如指掌了如指掌
+`
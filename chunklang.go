@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// 切片语言不一致时的处理方式
+const (
+	languageConsistencyWarn         = "warn"         // 只输出警告
+	languageConsistencyRetranscribe = "retranscribe" // 以多数语言重新转写不一致的切片
+	languageConsistencyOff          = "off"          // 不检查
+)
+
+// majorityLanguage 按切片时长统计各切片检测到的语言，返回时长最多的语言代码。
+// 没有文本或语言无法识别的切片不参与统计（静音切片检测出的语言通常不可靠）
+func majorityLanguage(results []*TranscriptionResult, chunks []AudioChunk) string {
+	weights := map[string]float64{}
+	var order []string
+	for i, result := range results {
+		code := languageCode(result.Language)
+		if code == "" || strings.TrimSpace(result.Text) == "" {
+			continue
+		}
+		if _, ok := weights[code]; !ok {
+			order = append(order, code)
+		}
+		weights[code] += chunkDuration(chunks[i], result)
+	}
+	majority := ""
+	for _, code := range order {
+		if majority == "" || weights[code] > weights[majority] {
+			majority = code
+		}
+	}
+	return majority
+}
+
+// chunkDuration 切片时长，最后一个切片（EndOffset 为 0）使用转写结果的时长
+func chunkDuration(chunk AudioChunk, result *TranscriptionResult) float64 {
+	if chunk.EndOffset == 0 {
+		return result.Duration
+	}
+	return max(chunk.EndOffset-chunk.StartOffset, 0)
+}
+
+// enforceChunkLanguage 自动检测语言时检查各切片的语言是否一致：不一致时警告，
+// language_consistency 为 retranscribe 时以多数语言重新转写不一致的切片并替换结果
+func enforceChunkLanguage(client *openai.Client, chunks []AudioChunk, results []*TranscriptionResult, config *Config, verbose bool) {
+	if !config.AutoDetect || config.LanguageConsistency == languageConsistencyOff || len(results) < 2 {
+		return
+	}
+	majority := majorityLanguage(results, chunks)
+	if majority == "" {
+		return
+	}
+
+	for i, result := range results {
+		code := languageCode(result.Language)
+		if code == "" || code == majority || strings.TrimSpace(result.Text) == "" {
+			continue
+		}
+		end := chunks[i].StartOffset + chunkDuration(chunks[i], result)
+		logWarn(tr("切片 %d（%s - %s）检测为 %s，与多数切片的 %s 不一致"), i+1,
+			formatChapterTime(chunks[i].StartOffset), formatChapterTime(end), code, majority)
+		if config.LanguageConsistency != languageConsistencyRetranscribe {
+			continue
+		}
+
+		forced := *config
+		forced.Language = majority
+		forced.AutoDetect = false
+		retried, err := transcribeWithSizeGuard(client, chunks[i].Path, &forced, verbose)
+		if err != nil {
+			logWarn(tr("切片 %d 以 %s 重新转写失败，保留原结果: %v"), i+1, majority, err)
+			continue
+		}
+		logInfo(tr("切片 %d 已以 %s 重新转写"), i+1, majority)
+		results[i] = retried
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands 各平台可用的剪贴板写入命令（按优先级排列）
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		// clip.exe 不能正确处理 UTF-8，优先使用 PowerShell
+		return [][]string{
+			{"powershell", "-NoProfile", "-Command", "$input | Set-Clipboard"},
+			{"clip"},
+		}
+	default:
+		return [][]string{
+			{"wl-copy"},
+			{"xclip", "-selection", "clipboard"},
+			{"xsel", "--clipboard", "--input"},
+		}
+	}
+}
+
+// copyToClipboard 将文本写入系统剪贴板
+func copyToClipboard(text string) error {
+	var lastErr error
+	for _, args := range clipboardCommands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		// 某个工具失败时（如非 Wayland 会话下的 wl-copy）继续尝试下一个
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			lastErr = fmt.Errorf(tr("写入剪贴板失败（%s）: %w"), args[0], err)
+			continue
+		}
+		return nil
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	return errors.New(tr("未找到可用的剪贴板工具"))
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// completionFlag 补全脚本中的一个参数
+type completionFlag struct {
+	Name  string
+	Usage string
+	Value bool // 是否需要取值
+}
+
+// completionCommand 补全脚本中的一个子命令，Args 为第一个位置参数的候选值（如 completion 的 shell 名称）
+type completionCommand struct {
+	Name  string
+	Usage string
+	Flags []completionFlag
+	Args  []string
+}
+
+// completionValues 有固定候选值的参数，List 为逗号分隔的多个值（如 --formats）
+type completionValues struct {
+	Flag   string
+	Values []string
+	List   bool
+}
+
+// completionData 生成补全脚本所需的全部信息
+type completionData struct {
+	Commands []completionCommand
+	Root     []completionFlag // 转写主命令的参数
+	Global   []completionFlag // 适用于所有子命令的全局参数
+	Values   []completionValues
+}
+
+// completionShells completion 子命令支持的 shell
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// runCompletion 执行 completion 子命令：输出 bash、zsh、fish 或 PowerShell 的补全脚本。
+// 需要在主命令的参数定义之后调用，以便列出全部参数
+func runCompletion(args []string) {
+	if len(args) != 1 || !slices.Contains(completionShells, args[0]) {
+		fmt.Println(tr("用法: whisper-go completion bash|zsh|fish|powershell"))
+		fmt.Println(tr("示例: source <(whisper-go completion bash)"))
+		os.Exit(exitBadInput)
+	}
+	if err := completionTemplates.ExecuteTemplate(os.Stdout, args[0], newCompletionData()); err != nil {
+		fatalf(tr("生成补全脚本失败: %v"), err)
+	}
+}
+
+// newCompletionData 收集子命令、主命令参数（来自 flag.CommandLine）和各参数的候选值。
+// 子命令的参数在各自的 run 函数中定义，新增参数时需要同步更新这里
+func newCompletionData() completionData {
+	// 参数名以 ? 结尾的为开关，不需要取值
+	flagList := func(names ...string) []completionFlag {
+		var flags []completionFlag
+		for _, name := range names {
+			name, isBool := strings.CutSuffix(name, "?")
+			flags = append(flags, completionFlag{Name: name, Value: !isBool})
+		}
+		return flags
+	}
+	data := completionData{
+		Commands: []completionCommand{
+			{Name: "review", Usage: tr("交互式校对"), Flags: flagList("config", "output", "formats", "plain?")},
+			{Name: "quick", Usage: tr("一键转写（系统集成）"), Flags: flagList("config")},
+			{Name: "grpc", Usage: tr("gRPC 转写服务"), Flags: flagList("config", "listen", "workers", "allow-paths?", "metrics", "queue-db", "web")},
+			{Name: "podcast", Usage: tr("播客 RSS 批量转写"), Flags: flagList("config", "output", "formats", "state", "limit", "verbose?")},
+			{Name: "split", Usage: tr("仅切片"), Flags: flagList("config", "output", "max-size", "chunk-workers", "vad", "splits", "verbose?")},
+			{Name: "doctor", Usage: tr("环境诊断"), Flags: flagList("config", "offline?")},
+			{Name: "live", Usage: tr("长时间直播转写"), Flags: flagList("config", "output", "formats", "name", "input-format", "segment", "rotate", "captions", "verbose?")},
+			{Name: "stitch", Usage: tr("合并分段文件"), Flags: flagList("output")},
+			{Name: "usage", Usage: tr("用量统计"), Flags: flagList("config", "month", "json?")},
+			{Name: "eval", Usage: tr("评估转写准确率"), Flags: flagList("ref", "keep-case?", "keep-punct?", "chinese", "json?")},
+			{Name: "diff", Usage: tr("对照已有字幕"), Flags: flagList("ref", "lang", "tolerance", "chinese", "all?", "html", "json?", "config")},
+			{Name: "bench", Usage: tr("比较模型和服务商"), Flags: flagList("config", "models", "ref", "language", "output", "formats", "json?", "verbose?")},
+			{Name: "config", Usage: tr("生成配置文件"), Flags: flagList("config", "force?", "offline?"), Args: []string{"init"}},
+			{Name: "watch", Usage: tr("监视目录自动转写"), Flags: flagList("config", "output", "formats", "existing?", "poll", "max-poll", "metrics", "verbose?")},
+			{Name: "publish", Usage: tr("一键发布"), Flags: flagList("verbose?")},
+			{Name: "update", Usage: tr("更新到最新发布"), Flags: flagList("config", "check?", "force?")},
+			{Name: "version", Usage: tr("显示版本号")},
+			{Name: "completion", Usage: tr("生成 shell 补全脚本"), Args: completionShells},
+		},
+		Global: flagList("lang-ui", "log-level", "log-format", "log-file"),
+		Values: []completionValues{
+			{Flag: "formats", Values: sortedKeys(outputFormats), List: true},
+			{Flag: "merge-formats", Values: []string{"txt", "md", "json"}, List: true},
+			{Flag: "organize", Values: []string{organizeFlat, organizeByDate, organizeBySource}},
+			{Flag: "chinese", Values: []string{chineseSimplified, chineseTraditional}},
+			{Flag: "fps", Values: sortedKeys(frameRates)},
+			{Flag: "vad", Values: []string{vadEnergy, vadSilero}},
+			{Flag: "english-casing", Values: []string{casingRules, casingLLM}},
+			{Flag: "dedup", Values: []string{dedupOff, dedupWarn, dedupSkip}},
+			{Flag: "lang-ui", Values: []string{"zh", "en"}},
+			{Flag: "log-level", Values: []string{"debug", "info", "warn", "error"}},
+			{Flag: "log-format", Values: []string{"text", "json"}},
+		},
+	}
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		data.Root = append(data.Root, completionFlag{Name: f.Name, Usage: shortUsage(f.Usage), Value: !ok || !b.IsBoolFlag()})
+	})
+	return data
+}
+
+// shortUsage 取参数说明的第一个短语（到第一个括号、冒号、逗号或分号为止），作为补全菜单中的描述
+func shortUsage(usage string) string {
+	for _, sep := range []string{"（", "：", "，", "；", " (", ": ", ", ", "; "} {
+		if i := strings.Index(usage, sep); i > 0 {
+			usage = usage[:i]
+		}
+	}
+	return strings.TrimSpace(usage)
+}
+
+// CommandNames 所有子命令的名称
+func (d completionData) CommandNames() []string {
+	var names []string
+	for _, c := range d.Commands {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// HasValues 参数是否有固定的候选值
+func (d completionData) HasValues(name string) bool {
+	return slices.ContainsFunc(d.Values, func(v completionValues) bool { return v.Flag == name })
+}
+
+// ValueFlags 所有需要取值的参数名（各子命令中同名参数是否取值一致）
+func (d completionData) ValueFlags() []string {
+	var names []string
+	add := func(flags []completionFlag) {
+		for _, f := range flags {
+			if f.Value && !slices.Contains(names, f.Name) {
+				names = append(names, f.Name)
+			}
+		}
+	}
+	add(d.Root)
+	add(d.Global)
+	for _, c := range d.Commands {
+		add(c.Flags)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// sortedKeys 按字母顺序返回 map 的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// completionFuncs 补全脚本模板中使用的函数
+var completionFuncs = template.FuncMap{
+	"join": func(items []string) string { return strings.Join(items, " ") },
+	// dashes 把参数名列表转为 --a --b 形式
+	"dashes": func(flags []completionFlag) string {
+		var names []string
+		for _, f := range flags {
+			names = append(names, "--"+f.Name)
+		}
+		return strings.Join(names, " ")
+	},
+	// pattern 生成同时匹配 -name 和 --name 的 case 模式
+	"pattern": func(name string) string { return "-" + name + "|--" + name },
+	"patterns": func(names []string) string {
+		var patterns []string
+		for _, name := range names {
+			patterns = append(patterns, "-"+name, "--"+name)
+		}
+		return strings.Join(patterns, "|")
+	},
+	"names": func(flags []completionFlag) []string {
+		var names []string
+		for _, f := range flags {
+			names = append(names, f.Name)
+		}
+		return names
+	},
+	// sq 转义 sh/zsh 单引号字符串
+	"sq": func(s string) string { return strings.ReplaceAll(s, "'", `'\''`) },
+	// fishq 转义 fish 单引号字符串
+	"fishq": func(s string) string { return strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) },
+	// psq 转义 PowerShell 单引号字符串
+	"psq": func(s string) string { return strings.ReplaceAll(s, "'", "''") },
+}
+
+// completionTemplates 各 shell 的补全脚本，按模板名称（shell 名称）选择
+var completionTemplates = template.Must(template.New("completion").Funcs(completionFuncs).Parse(`
+{{- define "bash" -}}
+# bash completion for whisper-go
+# source <(whisper-go completion bash)
+
+_whisper_go() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local cmd="" i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            {{patterns (names .Global)}}) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    case "$prev" in
+{{- range .Values}}
+        {{pattern .Flag}})
+{{- if .List}}
+            local head="${cur%"${cur##*,}"}"
+            COMPREPLY=($(compgen -P "$head" -W "{{join .Values}}" -- "${cur##*,}"))
+            compopt -o nospace
+{{- else}}
+            COMPREPLY=($(compgen -W "{{join .Values}}" -- "$cur"))
+{{- end}}
+            return ;;
+{{- end}}
+        {{patterns .ValueFlags}})
+            return ;;
+    esac
+
+    local flags args=""
+    case "$cmd" in
+{{- range .Commands}}
+        {{.Name}}) flags="{{dashes .Flags}}"{{if .Args}}; args="{{join .Args}}"{{end}} ;;
+{{- end}}
+        *)
+            flags="{{dashes .Root}}"
+            if [[ -z "$cmd" && "$cur" != -* ]]; then
+                compopt -o filenames
+                COMPREPLY=($(compgen -W "{{join .CommandNames}}" -- "$cur") $(compgen -f -- "$cur"))
+                return
+            fi ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "$flags {{dashes .Global}}" -- "$cur"))
+    elif [[ -n "$args" && "$prev" == "$cmd" ]]; then
+        COMPREPLY=($(compgen -W "$args" -- "$cur"))
+    fi
+}
+
+complete -o default -F _whisper_go whisper-go
+{{end}}
+
+{{- define "zsh" -}}
+#compdef whisper-go
+# zsh completion for whisper-go
+# source <(whisper-go completion zsh)
+
+_whisper_go() {
+    local cmd="" i
+    for ((i = 2; i < CURRENT; i++)); do
+        case $words[i] in
+            {{patterns (names .Global)}}) ((i++)) ;;
+            -*) ;;
+            *) cmd=$words[i]; break ;;
+        esac
+    done
+
+    case $words[CURRENT-1] in
+{{- range .Values}}
+        {{pattern .Flag}})
+{{- if .List}}
+            compset -P '*,'
+            compadd -q -S , -- {{join .Values}}
+{{- else}}
+            compadd -- {{join .Values}}
+{{- end}}
+            return ;;
+{{- end}}
+        {{patterns .ValueFlags}})
+            _files
+            return ;;
+    esac
+
+    local -a flags args
+    case $cmd in
+{{- range .Commands}}
+        {{.Name}})
+            flags=({{range .Flags}} '--{{.Name}}'{{end}})
+            args=({{range .Args}} '{{.}}'{{end}}) ;;
+{{- end}}
+        *)
+            flags=(
+{{- range .Root}}
+                '--{{.Name}}:{{sq .Usage}}'
+{{- end}}
+            )
+            args=(
+{{- range .Commands}}
+                '{{.Name}}:{{sq .Usage}}'
+{{- end}}
+            )
+            if [[ -n $cmd ]]; then
+                args=()
+            fi ;;
+    esac
+    flags+=({{range .Global}} '--{{.Name}}'{{end}})
+
+    if [[ $PREFIX == -* ]]; then
+        _describe -t options option flags
+    elif [[ -z $cmd ]]; then
+        _describe -t commands command args
+        _files
+    elif (( ${#args} )) && [[ $words[CURRENT-1] == $cmd ]]; then
+        _describe -t values value args
+    else
+        _files
+    fi
+}
+
+if [[ $zsh_eval_context[-1] == loadautofunc ]]; then
+    _whisper_go "$@"
+else
+    compdef _whisper_go whisper-go
+fi
+{{end}}
+
+{{- define "fish" -}}
+# fish completion for whisper-go
+# whisper-go completion fish > ~/.config/fish/completions/whisper-go.fish
+
+function __whisper_go_cmd
+    set -l skip 0
+    for t in (commandline -opc)[2..-1]
+        if test $skip = 1
+            set skip 0
+            continue
+        end
+        switch $t
+            case {{range $i, $f := .Global}}{{if $i}} {{end}}-{{$f.Name}} --{{$f.Name}}{{end}}
+                set skip 1
+            case '-*'
+            case '*'
+                echo $t
+                return
+        end
+    end
+end
+
+function __whisper_go_none
+    set -l cmd (__whisper_go_cmd)
+    test -z "$cmd"
+end
+
+function __whisper_go_root
+    set -l cmd (__whisper_go_cmd)
+    not contains -- "$cmd" {{join .CommandNames}}
+end
+
+function __whisper_go_using
+    set -l cmd (__whisper_go_cmd)
+    test "$cmd" = "$argv[1]"
+end
+{{range .Commands}}
+complete -c whisper-go -n __whisper_go_none -a {{.Name}} -d '{{fishq .Usage}}'
+{{- end}}
+{{- $values := .Values}}
+{{- range .Root}}
+complete -c whisper-go -n __whisper_go_root -l {{.Name}}{{if .Value}} -r{{end}} -d '{{fishq .Usage}}'
+{{- end}}
+{{- range .Commands}}
+{{- $cmd := .Name}}
+{{- range .Flags}}
+complete -c whisper-go -n '__whisper_go_using {{$cmd}}' -l {{.Name}}{{if .Value}} -r{{end}}
+{{- end}}
+{{- if .Args}}
+complete -c whisper-go -n '__whisper_go_using {{$cmd}}' -f -a '{{join .Args}}'
+{{- end}}
+{{- end}}
+{{- range .Global}}
+{{- if not ($.HasValues .Name)}}
+complete -c whisper-go -l {{.Name}} -r
+{{- end}}
+{{- end}}
+{{- range .Values}}
+complete -c whisper-go -l {{.Flag}} -x -a '{{join .Values}}'
+{{- end}}
+{{end}}
+
+{{- define "powershell" -}}
+# PowerShell completion for whisper-go
+# whisper-go completion powershell | Out-String | Invoke-Expression
+
+Register-ArgumentCompleter -Native -CommandName 'whisper-go', 'whisper-go.exe' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $commands = [ordered]@{
+{{- range .Commands}}
+        '{{.Name}}' = @{ Usage = '{{psq .Usage}}'; Flags = @({{range $i, $f := .Flags}}{{if $i}}, {{end}}'--{{$f.Name}}'{{end}}); Args = @({{range $i, $a := .Args}}{{if $i}}, {{end}}'{{$a}}'{{end}}) }
+{{- end}}
+    }
+    $rootFlags = [ordered]@{
+{{- range .Root}}
+        '--{{.Name}}' = '{{psq .Usage}}'
+{{- end}}
+    }
+    $globalFlags = @({{range $i, $f := .Global}}{{if $i}}, {{end}}'--{{$f.Name}}'{{end}})
+    $values = @{
+{{- range .Values}}
+        '--{{.Flag}}' = @({{range $i, $v := .Values}}{{if $i}}, {{end}}'{{$v}}'{{end}})
+{{- end}}
+    }
+    $valueFlags = @({{range $i, $f := .ValueFlags}}{{if $i}}, {{end}}'--{{$f}}'{{end}})
+
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -and $words.Count -gt 0) {
+        $words = @($words | Select-Object -SkipLast 1)
+    }
+    $cmd = ''
+    for ($i = 0; $i -lt $words.Count; $i++) {
+        $word = $words[$i]
+        if ($globalFlags -contains ('--' + $word.TrimStart('-'))) { $i++; continue }
+        if ($word.StartsWith('-')) { continue }
+        $cmd = $word
+        break
+    }
+    $prev = if ($words.Count -gt 0) { $words[-1] } else { '' }
+    $prevFlag = '--' + $prev.TrimStart('-')
+
+    $candidates = @()
+    if ($prev.StartsWith('-') -and $values.ContainsKey($prevFlag)) {
+        $prefix = ''
+        if ($wordToComplete -match '^(.*,)') { $prefix = $Matches[1] }
+        $candidates = $values[$prevFlag] | ForEach-Object { @{ Text = $prefix + $_; Tip = $_ } }
+    } elseif ($prev.StartsWith('-') -and $valueFlags -contains $prevFlag) {
+        return
+    } elseif ($wordToComplete.StartsWith('-')) {
+        if ($commands.Contains($cmd)) {
+            $candidates = @($commands[$cmd].Flags) + $globalFlags | ForEach-Object { @{ Text = $_; Tip = $_ } }
+        } else {
+            $candidates = @($rootFlags.Keys | ForEach-Object { @{ Text = $_; Tip = $rootFlags[$_] } }) +
+                @($globalFlags | ForEach-Object { @{ Text = $_; Tip = $_ } })
+        }
+    } elseif ($cmd -eq '') {
+        $candidates = $commands.Keys | ForEach-Object { @{ Text = $_; Tip = $commands[$_].Usage } }
+    } elseif ($commands.Contains($cmd) -and $prev -eq $cmd) {
+        $candidates = $commands[$cmd].Args | ForEach-Object { @{ Text = $_; Tip = $_ } }
+    }
+
+    $candidates | Where-Object { $_.Text -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_.Text, $_.Text, 'ParameterValue', $_.Tip)
+    }
+}
+{{end}}
+`))
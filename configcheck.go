@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// decodeConfig 严格解析配置文件（包括单文件配置）：拒绝拼写错误等未知字段并提示最接近的字段名，
+// 语法错误和类型错误指出所在的行列和字段
+func decodeConfig(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		end := dec.InputOffset()
+		if _, err := dec.Token(); err != io.EOF {
+			rest := data[end:]
+			line, col := lineColumn(data, end+int64(len(rest)-len(bytes.TrimLeft(rest, " \t\r\n"))))
+			return fmt.Errorf(tr("第 %d 行第 %d 列: JSON 对象结束后还有多余内容"), line, col)
+		}
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := lineColumn(data, syntaxErr.Offset)
+		return fmt.Errorf(tr("第 %d 行第 %d 列: JSON 语法错误: %v"), line, col, syntaxErr)
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return errors.New(tr("配置文件应为 JSON 对象"))
+	case errors.As(err, &typeErr):
+		line, col := lineColumn(data, typeErr.Offset)
+		return fmt.Errorf(tr("第 %d 行第 %d 列: 配置项 %s 应为 %s，实际为 %s"), line, col, typeErr.Field, jsonKind(typeErr.Type), typeErr.Value)
+	case errors.Is(err, io.EOF):
+		return errors.New(tr("配置文件为空"))
+	}
+
+	// DisallowUnknownFields 的错误没有单独的类型，只能从错误信息中取出字段名
+	if name, ok := strings.CutPrefix(err.Error(), `json: unknown field "`); ok {
+		name = strings.TrimSuffix(name, `"`)
+		msg := fmt.Sprintf(tr("未知的配置项 %q"), name)
+		if loc := regexp.MustCompile(`"` + regexp.QuoteMeta(name) + `"\s*:`).FindIndex(data); loc != nil {
+			line, _ := lineColumn(data, int64(loc[0]))
+			msg = fmt.Sprintf(tr("第 %d 行: %s"), line, msg)
+		}
+		if suggestion := closestField(name, jsonFieldNames(reflect.TypeOf(v))); suggestion != "" {
+			msg += fmt.Sprintf(tr("，是否应为 %q？"), suggestion)
+		}
+		return errors.New(msg)
+	}
+	return err
+}
+
+// lineColumn 字节偏移对应的行号和列号（从 1 开始）
+func lineColumn(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len([]rune(string(before[bytes.LastIndexByte(before, '\n')+1:]))) + 1
+	return line, col
+}
+
+// jsonKind Go 类型对应的 JSON 值类型，用于类型错误提示
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Pointer:
+		return jsonKind(t.Elem())
+	}
+	return "object"
+}
+
+// jsonFieldNames 收集结构体（包括嵌入和嵌套的结构体）中所有字段的 JSON 名称
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if field.Anonymous && name == "" {
+				walk(field.Type)
+				continue
+			}
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			names = append(names, name)
+			walk(field.Type)
+		}
+	}
+	walk(t)
+	return names
+}
+
+// closestField 在已知字段中查找与 name 最接近的一个：忽略大小写、下划线和连字符后相同的优先，
+// 否则取编辑距离最小且不超过名称长度 1/3（至少 2）的，没有时返回空字符串
+func closestField(name string, fields []string) string {
+	normalize := func(s string) string {
+		return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(s))
+	}
+	best, bestDistance := "", max(2, len(name)/3)+1
+	for _, field := range fields {
+		if normalize(field) == normalize(name) {
+			return field
+		}
+		if d := levenshtein([]rune(strings.ToLower(name)), []rune(field)); d < bestDistance {
+			best, bestDistance = field, d
+		}
+	}
+	return best
+}
+
+// validateRanges 校验数值类配置的取值范围和 silence_threshold 的格式
+func (c *Config) validateRanges() error {
+	nonNegative := []struct {
+		name  string
+		value float64
+	}{
+		{"max_file_size_mb", c.MaxFileSizeMB},
+		{"silence_duration", c.SilenceDuration},
+		{"max_retries", float64(c.maxRetries())},
+		{"translate_batch", float64(c.TranslateBatch)},
+		{"request_timeout", c.RequestTimeout},
+		{"post_write_hook_timeout", c.PostWriteHookTimeout},
+		{"fallback_cooldown", c.FallbackCooldown},
+		{"monthly_budget_minutes", c.MonthlyBudgetMinutes},
+		{"price_per_minute", c.PricePerMinute},
+		{"compression_ratio_threshold", c.CompressionRatioThreshold},
+		{"web_max_upload_mb", c.WebMaxUploadMB},
+	}
+	for _, f := range nonNegative {
+		if f.value < 0 {
+			return fmt.Errorf(tr("%s 不能为负数: %g"), f.name, f.value)
+		}
+	}
+	if db, err := parseSilenceThreshold(c.SilenceThreshold); err != nil || db > 0 {
+		return fmt.Errorf(tr("silence_threshold 无效: %s（应为分贝值如 -30dB，或 0 到 1 之间的振幅比如 0.03）"), c.SilenceThreshold)
+	}
+	if c.NoSpeechThreshold < 0 || c.NoSpeechThreshold > 1 {
+		return fmt.Errorf(tr("no_speech_threshold 应在 0 到 1 之间: %g"), c.NoSpeechThreshold)
+	}
+	for _, t := range c.TemperatureFallback {
+		if t < 0 || t > 1 {
+			return fmt.Errorf(tr("temperature_fallback 中的 %g 超出范围（应在 0 到 1 之间）"), t)
+		}
+	}
+	if c.VADThreshold < 0 || c.VADThreshold > 1 {
+		return fmt.Errorf(tr("vad_threshold 应在 0 到 1 之间: %g"), c.VADThreshold)
+	}
+	if c.GlossaryFuzzy > 1 {
+		return fmt.Errorf(tr("glossary_fuzzy 应在 0 到 1 之间: %g"), c.GlossaryFuzzy)
+	}
+	switch c.FilterAction {
+	case "", "drop", "flag":
+	default:
+		return fmt.Errorf(tr("无效的 filter_action 配置: %s（可选 drop, flag）"), c.FilterAction)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// openAIDefaultBaseURL provider 为 openai 时向导提供的默认接口地址
+const openAIDefaultBaseURL = "https://api.openai.com/v1"
+
+// initConfig config init 写入的配置，只包含向导询问的字段
+type initConfig struct {
+	Provider        string `json:"provider,omitempty"`
+	APIBaseURL      string `json:"api_base_url,omitempty"`
+	APIKey          string `json:"api_key,omitempty"`
+	Model           string `json:"model,omitempty"`
+	WhisperCppModel string `json:"whispercpp_model,omitempty"`
+	Language        string `json:"language,omitempty"`
+	AutoDetect      bool   `json:"auto_detect,omitempty"`
+	OutputDir       string `json:"output_dir"`
+	Organize        string `json:"organize,omitempty"`
+	Chinese         string `json:"chinese,omitempty"`
+}
+
+// runConfig 执行 config 子命令，目前只有 init
+func runConfig(args []string) {
+	if len(args) < 1 || args[0] != "init" {
+		fmt.Println(tr("用法: whisper-go config init [options]"))
+		os.Exit(exitBadInput)
+	}
+	runConfigInit(args[1:])
+}
+
+// runConfigInit 执行 config init：交互式询问接口地址、API key、模型、语言和输出设置，测试连接后写入校验过的配置文件
+func runConfigInit(args []string) {
+	fs := flag.NewFlagSet("config init", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	force := fs.Bool("force", false, tr("配置文件已存在时直接覆盖"))
+	skipTest := fs.Bool("offline", false, tr("跳过连接测试"))
+	fs.Parse(args)
+
+	w := &initWizard{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		if !w.confirm(fmt.Sprintf(tr("%s 已存在，是否覆盖？"), *configPath), false) {
+			return
+		}
+	}
+
+	fmt.Fprintln(w.out, tr("创建 whisper-go 配置文件，直接回车使用方括号中的默认值。"))
+	cfg := w.ask()
+
+	data, _ := json.MarshalIndent(cfg, "", "  ")
+	config := &Config{}
+	json.Unmarshal(data, config)
+	if err := config.applyDefaults(); err != nil {
+		exitWith(exitConfig, tr("配置无效: %v"), err)
+	}
+
+	if !*skipTest && config.openaiCompatible() {
+		fmt.Fprintln(w.out, tr("\n测试连接:"))
+		r := &doctorReport{}
+		r.checkAPI(config)
+		if r.failed > 0 && !w.confirm(tr("连接测试失败，仍然保存配置？"), false) {
+			os.Exit(exitConfig)
+		}
+	}
+
+	if dir := filepath.Dir(*configPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fatalf(tr("创建配置目录失败: %v"), err)
+		}
+	}
+	// 配置中包含 API key，只允许当前用户读写
+	if err := os.WriteFile(*configPath, append(data, '\n'), 0600); err != nil {
+		fatalf(tr("写入配置文件失败: %v"), err)
+	}
+	fmt.Fprintf(w.out, tr("\n已写入 %s，可以运行 whisper-go doctor --config %s 检查运行环境\n"), *configPath, *configPath)
+}
+
+// initWizard config init 的交互输入
+type initWizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask 依次询问各项设置
+func (w *initWizard) ask() initConfig {
+	var cfg initConfig
+	cfg.Provider = w.choose(tr("转写服务商"), providerOpenAI, providerOpenAI, providerGroq, providerDeepgram, providerAssemblyAI, providerWhisperCpp)
+
+	if cfg.Provider == providerWhisperCpp {
+		cfg.WhisperCppModel = w.required(tr("ggml 模型文件路径"))
+	} else {
+		defaultURL, defaultModel := openAIDefaultBaseURL, "whisper-1"
+		switch cfg.Provider {
+		case providerGroq:
+			defaultURL, defaultModel = groqBaseURL, groqModels[0]
+		case providerDeepgram:
+			defaultURL, defaultModel = deepgramDefaultBaseURL, deepgramDefaultModel
+		case providerAssemblyAI:
+			defaultURL, defaultModel = assemblyAIDefaultBaseURL, assemblyAIDefaultModel
+		}
+		cfg.APIBaseURL = w.line(tr("API 地址"), defaultURL)
+		cfg.APIKey = w.required(tr("API key"))
+		cfg.Model = w.line(tr("模型"), defaultModel)
+		if cfg.Provider == providerGroq {
+			for !slices.Contains(groqModels, cfg.Model) {
+				fmt.Fprintf(w.out, tr("  groq 只支持 %s\n"), strings.Join(groqModels, ", "))
+				cfg.Model = w.line(tr("模型"), defaultModel)
+			}
+		}
+	}
+
+	cfg.Language = w.line(tr("语言代码（如 zh、en、ja，auto 为自动检测）"), "zh")
+	if cfg.Language == "auto" {
+		cfg.Language, cfg.AutoDetect = "", true
+	}
+	if cfg.Language == "zh" || cfg.AutoDetect {
+		switch w.choose(tr("中文输出统一为"), "-", "-", chineseSimplified, chineseTraditional) {
+		case chineseSimplified:
+			cfg.Chinese = chineseSimplified
+		case chineseTraditional:
+			cfg.Chinese = chineseTraditional
+		}
+	}
+
+	cfg.OutputDir = w.line(tr("输出目录"), "./outputs")
+	if organize := w.choose(tr("输出目录组织方式"), organizeFlat, organizeFlat, organizeByDate, organizeBySource); organize != organizeFlat {
+		cfg.Organize = organize
+	}
+	return cfg
+}
+
+// line 读取一行输入，为空时返回默认值；输入结束（如管道关闭）时退出
+func (w *initWizard) line(label, def string) string {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", label)
+	}
+	text, err := w.in.ReadString('\n')
+	text = strings.TrimSpace(text)
+	if errors.Is(err, io.EOF) && text == "" {
+		fmt.Fprintln(w.out)
+		exitWith(exitBadInput, "%s", tr("输入已结束，未写入配置文件"))
+	}
+	if text == "" {
+		return def
+	}
+	return text
+}
+
+// required 读取不能为空的一项
+func (w *initWizard) required(label string) string {
+	for {
+		if text := w.line(label, ""); text != "" {
+			return text
+		}
+		fmt.Fprintln(w.out, tr("  此项不能为空"))
+	}
+}
+
+// choose 从候选项中选择一项
+func (w *initWizard) choose(label, def string, options ...string) string {
+	prompt := fmt.Sprintf(tr("%s（%s）"), label, strings.Join(options, " / "))
+	for {
+		text := w.line(prompt, def)
+		if slices.Contains(options, text) {
+			return text
+		}
+		fmt.Fprintf(w.out, tr("  请输入 %s 之一\n"), strings.Join(options, " / "))
+	}
+}
+
+// confirm 询问是否继续
+func (w *initWizard) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Fprintf(w.out, "%s [%s]: ", question, hint)
+	text, _ := w.in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// cueMergeMaxGap 过短的字幕只与间隔不超过此值（秒）的相邻字幕合并
+const cueMergeMaxGap = 1.0
+
+// 字幕拆分位置的优先级：句末标点后 > 分句标点后 > 词之间
+const (
+	cueBreakWord = iota + 1
+	cueBreakClause
+	cueBreakSentence
+)
+
+// validateCueDurations 校验 min_cue_duration 和 max_cue_duration
+func (c *Config) validateCueDurations() error {
+	if c.MinCueDuration < 0 || c.MaxCueDuration < 0 {
+		return errors.New(tr("min_cue_duration 和 max_cue_duration 不能为负数"))
+	}
+	if c.MaxCueDuration > 0 && c.MinCueDuration*2 > c.MaxCueDuration {
+		return fmt.Errorf(tr("max_cue_duration（%g）至少应为 min_cue_duration（%g）的两倍"), c.MaxCueDuration, c.MinCueDuration)
+	}
+	return nil
+}
+
+// enforceCueDurations 按 max_cue_duration 拆分过长的字幕，再按 min_cue_duration 合并过短的字幕：
+// 优先合并到同一句话的相邻字幕，无法合并（说话人不同、间隔过大或合并后过长）时延长显示时间，不与下一条重叠
+func enforceCueDurations(result *TranscriptionResult, config *Config) {
+	if config.MinCueDuration <= 0 && config.MaxCueDuration <= 0 {
+		return
+	}
+
+	if config.MaxCueDuration > 0 {
+		var segments []Segment
+		for _, seg := range result.Segments {
+			segments = append(segments, splitLongCue(seg, config.MaxCueDuration)...)
+		}
+		result.Segments = segments
+	}
+
+	if config.MinCueDuration > 0 {
+		result.Segments = mergeShortCues(result.Segments, config.MinCueDuration, config.MaxCueDuration)
+		for i := range result.Segments {
+			seg := &result.Segments[i]
+			if seg.End-seg.Start >= config.MinCueDuration {
+				continue
+			}
+			end := seg.Start + config.MinCueDuration
+			if i+1 < len(result.Segments) {
+				end = min(end, result.Segments[i+1].Start)
+			} else if result.Duration > 0 {
+				end = min(end, result.Duration)
+			}
+			seg.End = max(seg.End, end)
+		}
+	}
+
+	for i := range result.Segments {
+		result.Segments[i].ID = i + 1
+	}
+}
+
+// splitLongCue 把超过 maxDuration 的字幕在最合适的位置一分为二，递归直到每段都不超过上限或无法再拆分（如只有一个词）
+func splitLongCue(seg Segment, maxDuration float64) []Segment {
+	if seg.End-seg.Start <= maxDuration {
+		return []Segment{seg}
+	}
+	runes := []rune(strings.TrimSpace(seg.Text))
+	pos := cueBreakPosition(runes)
+	if pos <= 0 {
+		return []Segment{seg}
+	}
+
+	// 按字数比例分配时间，有词级时间戳时对齐到最近的词开始时间
+	splitTime := seg.Start + (seg.End-seg.Start)*float64(pos)/float64(len(runes))
+	if len(seg.Words) > 1 {
+		best := seg.Words[1].Start
+		for _, w := range seg.Words[1:] {
+			if math.Abs(w.Start-splitTime) < math.Abs(best-splitTime) {
+				best = w.Start
+			}
+		}
+		if best > seg.Start && best < seg.End {
+			splitTime = best
+		}
+	}
+
+	first, second := seg, seg
+	first.End, first.Text, first.Words = splitTime, strings.TrimSpace(string(runes[:pos])), nil
+	second.Start, second.Text, second.Words = splitTime, strings.TrimSpace(string(runes[pos:])), nil
+	for _, w := range seg.Words {
+		if w.Start < splitTime {
+			first.Words = append(first.Words, w)
+		} else {
+			second.Words = append(second.Words, w)
+		}
+	}
+	return append(splitLongCue(first, maxDuration), splitLongCue(second, maxDuration)...)
+}
+
+// cueBreakPosition 选择拆分位置（拆分后前一段为 runes[:pos]），没有合适位置时返回 0。
+// 优先级高的位置优先，同一优先级中选最接近中间的；两边都不少于 1/5 的位置优先，避免拆出很短的片段
+func cueBreakPosition(runes []rune) int {
+	n := len(runes)
+	best, bestLevel, bestBalanced := 0, 0, false
+	for p := 1; p < n; p++ {
+		level := cueBreakLevel(runes[p-1], runes[p])
+		if level == 0 {
+			continue
+		}
+		balanced := min(p, n-p)*5 >= n
+		better := false
+		switch {
+		case best == 0:
+			better = true
+		case balanced != bestBalanced:
+			better = balanced
+		case level != bestLevel:
+			better = level > bestLevel
+		default:
+			better = math.Abs(float64(2*p-n)) < math.Abs(float64(2*best-n))
+		}
+		if better {
+			best, bestLevel, bestBalanced = p, level, balanced
+		}
+	}
+	return best
+}
+
+// cueBreakLevel 在 prev 和 next 两个字符之间拆分的优先级，0 为不能拆分（单词中间）
+func cueBreakLevel(prev, next rune) int {
+	cjk := func(r rune) bool {
+		return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+	}
+	// 全角标点后不需要空格；半角标点后需要空格，避免拆开 3.14、e.g. 等
+	fullWidth := strings.ContainsRune("。！？…，；：、", prev)
+	if !fullWidth && !unicode.IsSpace(next) {
+		if cjk(prev) && cjk(next) {
+			return cueBreakWord
+		}
+		return 0
+	}
+	switch {
+	case strings.ContainsRune(".!?。！？…", prev):
+		return cueBreakSentence
+	case strings.ContainsRune(",;:，；：、", prev):
+		return cueBreakClause
+	case unicode.IsSpace(next) && !unicode.IsSpace(prev):
+		return cueBreakWord
+	}
+	return 0
+}
+
+// endsSentence 文本是否以句末标点结束
+func endsSentence(text string) bool {
+	text = strings.TrimRight(strings.TrimSpace(text), `"'”’」』)）`)
+	return strings.HasSuffix(text, ".") || strings.HasSuffix(text, "!") || strings.HasSuffix(text, "?") ||
+		strings.HasSuffix(text, "。") || strings.HasSuffix(text, "！") || strings.HasSuffix(text, "？") || strings.HasSuffix(text, "…")
+}
+
+// mergeShortCues 反复将最短的过短字幕并入相邻字幕，直到没有可合并的为止
+func mergeShortCues(segments []Segment, minDuration, maxDuration float64) []Segment {
+	canMerge := func(a, b Segment) bool {
+		return a.Speaker == b.Speaker && b.Start-a.End <= cueMergeMaxGap &&
+			(maxDuration <= 0 || b.End-a.Start <= maxDuration)
+	}
+	for {
+		target, into := -1, -1
+		for i, seg := range segments {
+			if seg.End-seg.Start >= minDuration || (target >= 0 && seg.End-seg.Start >= segments[target].End-segments[target].Start) {
+				continue
+			}
+			prev := i > 0 && canMerge(segments[i-1], seg)
+			next := i+1 < len(segments) && canMerge(seg, segments[i+1])
+			// 前一条没有说完时并入前一条，本条没有说完时并入后一条，否则并入间隔较小的一条
+			switch {
+			case prev && (!endsSentence(segments[i-1].Text) || !next):
+				target, into = i, i-1
+			case next && (!endsSentence(seg.Text) || !prev):
+				target, into = i, i+1
+			case prev && next && seg.Start-segments[i-1].End <= segments[i+1].Start-seg.End:
+				target, into = i, i-1
+			case prev && next:
+				target, into = i, i+1
+			}
+		}
+		if target < 0 {
+			return segments
+		}
+
+		a, b := min(target, into), max(target, into)
+		merged := segments[a]
+		merged.End = segments[b].End
+		merged.Text = joinSegmentText(merged.Text, segments[b].Text)
+		merged.Words = append(append([]Word{}, merged.Words...), segments[b].Words...)
+		for _, flag := range segments[b].Flags {
+			if !slices.Contains(merged.Flags, flag) {
+				merged.Flags = append(merged.Flags, flag)
+			}
+		}
+		segments[a] = merged
+		segments = append(segments[:b], segments[b+1:]...)
+	}
+}
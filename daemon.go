@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/whisper-client/go-whisper-go/proto/whisperpb"
+)
+
+// daemonState 常驻进程（watch、grpc）的运行状态，通过指标接口暴露空闲/工作状态
+type daemonState struct {
+	active       atomic.Int64
+	completed    atomic.Int64
+	failed       atomic.Int64
+	pollInterval atomic.Int64 // 当前轮询间隔（纳秒），0 表示不轮询
+	lastActivity atomic.Int64 // 最近一次任务开始或结束的时间（UnixNano）
+	// queueStats 任务队列统计（grpc），为空时不输出队列指标
+	queueStats func() (queueStats, error)
+}
+
+// newDaemonState 创建运行状态，初始为空闲
+func newDaemonState() *daemonState {
+	d := &daemonState{}
+	d.lastActivity.Store(time.Now().UnixNano())
+	return d
+}
+
+// begin 记录任务开始
+func (d *daemonState) begin() {
+	d.active.Add(1)
+	d.lastActivity.Store(time.Now().UnixNano())
+}
+
+// end 记录任务结束
+func (d *daemonState) end(err error) {
+	d.active.Add(-1)
+	if err != nil {
+		d.failed.Add(1)
+	} else {
+		d.completed.Add(1)
+	}
+	d.lastActivity.Store(time.Now().UnixNano())
+}
+
+// serveMetrics 在 addr 上以 Prometheus 文本格式提供 /metrics
+func (d *daemonState) serveMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", d.writeMetrics)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return server.ListenAndServe()
+}
+
+// writeMetrics 输出指标
+func (d *daemonState) writeMetrics(w http.ResponseWriter, r *http.Request) {
+	active := d.active.Load()
+	state := 0
+	if active > 0 {
+		state = 1
+	}
+	idle := 0.0
+	if active == 0 {
+		idle = time.Since(time.Unix(0, d.lastActivity.Load())).Seconds()
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP whisper_go_active 1 when transcribing, 0 when idle.")
+	fmt.Fprintln(w, "# TYPE whisper_go_active gauge")
+	fmt.Fprintf(w, "whisper_go_active %d\n", state)
+	fmt.Fprintln(w, "# HELP whisper_go_active_jobs Number of jobs currently transcribing.")
+	fmt.Fprintln(w, "# TYPE whisper_go_active_jobs gauge")
+	fmt.Fprintf(w, "whisper_go_active_jobs %d\n", active)
+	fmt.Fprintln(w, "# HELP whisper_go_idle_seconds Seconds since the last job finished, 0 while active.")
+	fmt.Fprintln(w, "# TYPE whisper_go_idle_seconds gauge")
+	fmt.Fprintf(w, "whisper_go_idle_seconds %.3f\n", idle)
+	if interval := d.pollInterval.Load(); interval > 0 {
+		fmt.Fprintln(w, "# HELP whisper_go_poll_interval_seconds Current polling interval.")
+		fmt.Fprintln(w, "# TYPE whisper_go_poll_interval_seconds gauge")
+		fmt.Fprintf(w, "whisper_go_poll_interval_seconds %.3f\n", time.Duration(interval).Seconds())
+	}
+	fmt.Fprintln(w, "# HELP whisper_go_jobs_total Finished jobs by result.")
+	fmt.Fprintln(w, "# TYPE whisper_go_jobs_total counter")
+	fmt.Fprintf(w, "whisper_go_jobs_total{result=\"completed\"} %d\n", d.completed.Load())
+	fmt.Fprintf(w, "whisper_go_jobs_total{result=\"failed\"} %d\n", d.failed.Load())
+
+	if d.queueStats == nil {
+		return
+	}
+	stats, err := d.queueStats()
+	if err != nil {
+		logWarn(tr("读取任务队列失败: %v"), err)
+		return
+	}
+	fmt.Fprintln(w, "# HELP whisper_go_queue_jobs Jobs in the queue by state.")
+	fmt.Fprintln(w, "# TYPE whisper_go_queue_jobs gauge")
+	for _, state := range []whisperpb.JobState{
+		whisperpb.JobState_JOB_STATE_QUEUED,
+		whisperpb.JobState_JOB_STATE_RUNNING,
+		whisperpb.JobState_JOB_STATE_SUCCEEDED,
+		whisperpb.JobState_JOB_STATE_FAILED,
+		whisperpb.JobState_JOB_STATE_CANCELLED,
+	} {
+		name := strings.ToLower(strings.TrimPrefix(state.String(), "JOB_STATE_"))
+		fmt.Fprintf(w, "whisper_go_queue_jobs{state=\"%s\"} %d\n", name, stats.counts[state])
+	}
+	fmt.Fprintln(w, "# HELP whisper_go_queue_oldest_wait_seconds Seconds the oldest queued job has been waiting, 0 when the queue is empty.")
+	fmt.Fprintln(w, "# TYPE whisper_go_queue_oldest_wait_seconds gauge")
+	fmt.Fprintf(w, "whisper_go_queue_oldest_wait_seconds %.3f\n", stats.oldestQueued.Seconds())
+}
+
+// pollBackoff 空闲时逐步延长轮询间隔，有变化时恢复最短间隔
+type pollBackoff struct {
+	min, max time.Duration
+	current  time.Duration
+}
+
+// newPollBackoff 创建轮询退避，max 小于 min 时按 min 处理
+func newPollBackoff(min, max time.Duration) *pollBackoff {
+	if max < min {
+		max = min
+	}
+	return &pollBackoff{min: min, max: max, current: min}
+}
+
+// idle 本轮没有变化，间隔翻倍直到 max
+func (b *pollBackoff) idle() time.Duration {
+	b.current *= 2
+	if b.current > b.max {
+		b.current = b.max
+	}
+	return b.current
+}
+
+// reset 有新文件或正在处理，恢复最短间隔
+func (b *pollBackoff) reset() time.Duration {
+	b.current = b.min
+	return b.current
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 重复内容的处理方式
+const (
+	dedupOff  = "off"  // 不计算指纹（默认）
+	dedupWarn = "warn" // 只警告，照常转写
+	dedupSkip = "skip" // 跳过转写，返回之前的输出文件
+)
+
+// fingerprintIndexMu 并行处理多个文件时串行写入指纹库
+var fingerprintIndexMu sync.Mutex
+
+// fingerprintEntry 指纹库中的一条记录（JSON Lines 的一行）
+type fingerprintEntry struct {
+	Fingerprint string    `json:"fingerprint"`
+	Input       string    `json:"input"`
+	Time        time.Time `json:"time"`
+	Duration    float64   `json:"duration"`
+	Outputs     []string  `json:"outputs,omitempty"`
+}
+
+// fingerprintIndexPath 指纹库路径，无法确定用户配置目录时返回空字符串
+func (c *Config) fingerprintIndexPath() string {
+	if c.DedupIndex != "" {
+		return c.DedupIndex
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "whisper-go", "fingerprints.jsonl")
+}
+
+// 感知指纹的参数：16kHz 单声道音频每 0.25 秒取一帧（帧长约 0.5 秒），在 300-4000Hz 之间按对数划分 33 个频带，
+// 相邻频带能量差在前后两帧之间的变化方向构成每帧 32 位的子指纹（Haitsma-Kalker 方法）
+const (
+	fingerprintFrame  = 8192
+	fingerprintHop    = 4000
+	fingerprintBands  = 33
+	fingerprintPrefix = "p1:"
+
+	// fingerprintMaxBitError 两个指纹逐位比较的误码率不超过该值时视为同一段音频。
+	// 同一录音有损重新编码后通常在 0.1 以内，不同的音频接近 0.5
+	fingerprintMaxBitError = 0.2
+)
+
+// audioFingerprint 把音频解码为 16kHz 单声道 PCM 后计算感知指纹。改名、重新封装、改元数据以及有损重新编码
+// （如 WAV 导出为 MP3）后指纹仍然相近；剪辑过长度的文件不会被识别。WAV、FLAC 和 MP3 在 Go 中解码，其他格式使用 ffmpeg
+func audioFingerprint(inputFile string) (string, error) {
+	var prints []uint32
+	if r, err := openNativePCM16k(inputFile); err == nil {
+		prints, err = fingerprintPCM(r)
+		r.Close()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		if !errors.Is(err, errNotNativeAudio) {
+			logDebug(tr("原生解码失败，回退到 ffmpeg: %v\n"), err)
+		}
+		if prints, err = fingerprintFFmpeg(inputFile); err != nil {
+			return "", err
+		}
+	}
+	if len(prints) == 0 {
+		return "", errors.New(tr("音频太短，无法计算指纹"))
+	}
+	data := make([]byte, 4*len(prints))
+	for i, p := range prints {
+		binary.BigEndian.PutUint32(data[4*i:], p)
+	}
+	return fingerprintPrefix + base64.RawStdEncoding.EncodeToString(data), nil
+}
+
+// fingerprintFFmpeg 用 ffmpeg 把音频解码为 16kHz 单声道 PCM 流并计算指纹
+func fingerprintFFmpeg(inputFile string) ([]uint32, error) {
+	cmd := exec.Command(ffmpegTools.FFmpeg, ffmpegArgs(inputFile,
+		"-vn",
+		"-ac", "1",
+		"-ar", "16000",
+		"-f", "s16le",
+		"pipe:1",
+	)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	prints, err := fingerprintPCM(stdout)
+	if err != nil {
+		cmd.Wait()
+		return nil, err
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf(tr("ffmpeg 解码失败: %w: %s"), err, lastLine(stderr.String()))
+	}
+	return prints, nil
+}
+
+// fingerprintPCM 从 16kHz 单声道 16 位 PCM 流计算每帧的 32 位子指纹
+func fingerprintPCM(r io.Reader) ([]uint32, error) {
+	window := make([]float64, fingerprintFrame)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/fingerprintFrame)
+	}
+	var edges [fingerprintBands + 1]int
+	for i := range edges {
+		freq := 300 * math.Pow(4000.0/300, float64(i)/fingerprintBands)
+		edges[i] = int(math.Round(freq * fingerprintFrame / float64(pcm16kInfo.SampleRate)))
+	}
+
+	br := bufio.NewReaderSize(r, 1<<16)
+	samples := make([]float64, 0, fingerprintFrame)
+	spectrum := make([]complex128, fingerprintFrame)
+	var prev, energy [fingerprintBands]float64
+	var prints []uint32
+	var raw [2]byte
+	for frame := 0; ; frame++ {
+		// 补足一帧的采样，之后每帧移动 fingerprintHop 个采样
+		for len(samples) < fingerprintFrame {
+			if _, err := io.ReadFull(br, raw[:]); err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+					return prints, nil
+				}
+				return nil, err
+			}
+			samples = append(samples, float64(int16(binary.LittleEndian.Uint16(raw[:])))/32768)
+		}
+		for i, v := range samples {
+			spectrum[i] = complex(v*window[i], 0)
+		}
+		fft(spectrum)
+		for b := range energy {
+			var sum float64
+			for k := edges[b]; k < edges[b+1]; k++ {
+				re, im := real(spectrum[k]), imag(spectrum[k])
+				sum += re*re + im*im
+			}
+			// 加一个很小的底噪，静音帧的各频带差为 0，不产生随机的位
+			energy[b] = math.Log(sum + 1e-6)
+		}
+		if frame > 0 {
+			var bits uint32
+			for b := 0; b < fingerprintBands-1; b++ {
+				if energy[b]-energy[b+1]-(prev[b]-prev[b+1]) > 0 {
+					bits |= 1 << b
+				}
+			}
+			prints = append(prints, bits)
+		}
+		prev = energy
+		samples = append(samples[:0], samples[fingerprintHop:]...)
+	}
+}
+
+// fft 原地计算长度为 2 的幂的复数 FFT（迭代基 2 算法）
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// decodeFingerprint 解析指纹库中的指纹，旧版本的 SHA-256 指纹等无法比较的记录返回 nil
+func decodeFingerprint(s string) []uint32 {
+	encoded, ok := strings.CutPrefix(s, fingerprintPrefix)
+	if !ok {
+		return nil
+	}
+	data, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	prints := make([]uint32, len(data)/4)
+	for i := range prints {
+		prints[i] = binary.BigEndian.Uint32(data[4*i:])
+	}
+	return prints
+}
+
+// fingerprintSimilarity 两个指纹的相似度（1 减去误码率）。帧数相差超过 2% 时视为不同的音频返回 0；
+// 比较时允许前后错开 2 帧，抵消重新编码带来的延迟
+func fingerprintSimilarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(b) == 0 || max(len(a), len(b))-min(len(a), len(b)) > max(4, max(len(a), len(b))/50) {
+		return 0
+	}
+	best := 0.0
+	for offset := -2; offset <= 2; offset++ {
+		diff, total := 0, 0
+		for i := range a {
+			j := i + offset
+			if j < 0 || j >= len(b) {
+				continue
+			}
+			diff += bits.OnesCount32(a[i] ^ b[j])
+			total += fingerprintBands - 1
+		}
+		if total > 0 {
+			best = max(best, 1-float64(diff)/float64(total))
+		}
+	}
+	return best
+}
+
+// dedupInputName 指纹库中记录的输入：本地文件为绝对路径，对象存储保留原始 URI
+func dedupInputName(inputFile string) string {
+	if isRemoteURI(inputFile) {
+		return inputFile
+	}
+	if abs, err := filepath.Abs(inputFile); err == nil {
+		return abs
+	}
+	return inputFile
+}
+
+// findDuplicate 在指纹库中查找与指纹足够相似的其他输入文件，返回最相似的一条（相同时取最近的）和相似度；
+// 同一文件再次转写不算重复
+func findDuplicate(path, fingerprint, input string) (*fingerprintEntry, float64) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0
+	}
+	defer f.Close()
+
+	query := decodeFingerprint(fingerprint)
+	var found *fingerprintEntry
+	var best float64
+	scanner := bufio.NewScanner(f)
+	// 每小时音频的指纹约 75KB
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry fingerprintEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || entry.Input == input {
+			continue
+		}
+		similarity := fingerprintSimilarity(query, decodeFingerprint(entry.Fingerprint))
+		if similarity >= 1-fingerprintMaxBitError && similarity >= best {
+			found, best = &entry, similarity
+		}
+	}
+	return found, best
+}
+
+// checkDuplicate 开启 dedup 时计算输入的指纹并在指纹库中查找重复内容，返回指纹（供转写完成后记录）和找到的记录。
+// 计算指纹失败只记录警告，照常转写
+func checkDuplicate(localInput, inputFile string, config *Config) (string, *fingerprintEntry) {
+	if config.Dedup == "" || config.Dedup == dedupOff {
+		return "", nil
+	}
+	path := config.fingerprintIndexPath()
+	if path == "" {
+		return "", nil
+	}
+	fingerprint, err := audioFingerprint(localInput)
+	if err != nil {
+		logWarn(tr("计算音频指纹失败，不检查重复: %v"), err)
+		return "", nil
+	}
+	fingerprintIndexMu.Lock()
+	duplicate, similarity := findDuplicate(path, fingerprint, dedupInputName(inputFile))
+	fingerprintIndexMu.Unlock()
+	if duplicate != nil {
+		when := duplicate.Time.Local().Format("2006-01-02 15:04")
+		if config.Dedup == dedupSkip {
+			logInfo(tr("%s 与 %s 内容相同（相似度 %.0f%%，%s 转写），跳过转写\n"), inputFile, duplicate.Input, similarity*100, when)
+		} else {
+			logWarn(tr("%s 与 %s 内容相同（相似度 %.0f%%，%s 转写），仍然转写（dedup 为 skip 时跳过）"), inputFile, duplicate.Input, similarity*100, when)
+		}
+	}
+	return fingerprint, duplicate
+}
+
+// duplicateResult 跳过重复文件时的结果：不含转写内容，输出文件为之前转写时仍然存在的输出
+func duplicateResult(duplicate *fingerprintEntry) (*TranscriptionResult, []string) {
+	var outputs []string
+	for _, output := range duplicate.Outputs {
+		if isRemoteURI(output) {
+			outputs = append(outputs, output)
+			continue
+		}
+		if _, err := os.Stat(output); err == nil {
+			outputs = append(outputs, output)
+		}
+	}
+	return &TranscriptionResult{DuplicateOf: duplicate.Input}, outputs
+}
+
+// recordFingerprint 转写成功后把指纹、输入和输出文件记入指纹库，写入失败只记录警告
+func recordFingerprint(config *Config, fingerprint, inputFile string, result *TranscriptionResult, outputFiles []string) {
+	path := config.fingerprintIndexPath()
+	if path == "" || fingerprint == "" {
+		return
+	}
+	entry := fingerprintEntry{
+		Fingerprint: fingerprint,
+		Input:       dedupInputName(inputFile),
+		Time:        time.Now(),
+		Duration:    result.Duration,
+		Outputs:     make([]string, len(outputFiles)),
+	}
+	// 本地输出记录绝对路径，跳过时从其他工作目录也能找到
+	for i, output := range outputFiles {
+		entry.Outputs[i] = output
+		if !isRemoteURI(output) {
+			if abs, err := filepath.Abs(output); err == nil {
+				entry.Outputs[i] = abs
+			}
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	fingerprintIndexMu.Lock()
+	defer fingerprintIndexMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logWarn(tr("写入指纹库失败: %v"), err)
+		return
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		logWarn(tr("写入指纹库失败: %v"), err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logWarn(tr("写入指纹库失败: %v"), err)
+	}
+}
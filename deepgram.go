@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Deepgram 默认接口地址和模型
+const (
+	deepgramDefaultBaseURL = "https://api.deepgram.com/v1"
+	deepgramDefaultModel   = "nova-2"
+)
+
+// deepgramBackend Deepgram 预录音频接口（POST /listen）
+type deepgramBackend struct {
+	baseURL string
+	apiKey  string
+	diarize bool
+	client  *http.Client
+}
+
+// newDeepgramBackend 按配置创建 Deepgram 后端
+func newDeepgramBackend(config *Config) *deepgramBackend {
+	return &deepgramBackend{
+		baseURL: strings.TrimRight(config.APIBaseURL, "/"),
+		apiKey:  config.APIKey,
+		diarize: config.Diarize,
+		client:  backendHTTPClient(config),
+	}
+}
+
+// deepgramWord Deepgram 返回的词
+type deepgramWord struct {
+	Word           string  `json:"word"`
+	PunctuatedWord string  `json:"punctuated_word"`
+	Start          float64 `json:"start"`
+	End            float64 `json:"end"`
+	Confidence     float64 `json:"confidence"`
+}
+
+// deepgramResponse Deepgram 预录音频接口的返回（只解析用到的字段）
+type deepgramResponse struct {
+	Metadata struct {
+		Duration float64 `json:"duration"`
+	} `json:"metadata"`
+	Results struct {
+		Channels []struct {
+			DetectedLanguage string `json:"detected_language"`
+			Alternatives     []struct {
+				Transcript string         `json:"transcript"`
+				Words      []deepgramWord `json:"words"`
+			} `json:"alternatives"`
+		} `json:"channels"`
+		Utterances []struct {
+			Start      float64        `json:"start"`
+			End        float64        `json:"end"`
+			Confidence float64        `json:"confidence"`
+			Transcript string         `json:"transcript"`
+			Speaker    int            `json:"speaker"`
+			Words      []deepgramWord `json:"words"`
+		} `json:"utterances"`
+	} `json:"results"`
+}
+
+// Transcribe 上传音频并把 utterances 映射为分段，词级时间戳写入 Words
+func (b *deepgramBackend) Transcribe(ctx context.Context, audioPath string, req backendRequest) (*TranscriptionResult, error) {
+	logDebug(tr("正在转写音频: %s\n"), audioPath)
+
+	audioData, err := os.ReadFile(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf(tr("打开音频文件失败: %w"), err)
+	}
+
+	query := url.Values{}
+	query.Set("model", req.Model)
+	query.Set("smart_format", "true")
+	query.Set("punctuate", "true")
+	query.Set("utterances", "true")
+	if b.diarize {
+		query.Set("diarize", "true")
+	}
+	if req.AutoDetect || req.Language == "" {
+		query.Set("detect_language", "true")
+	} else {
+		query.Set("language", req.Language)
+	}
+	endpoint := b.baseURL + "/listen?" + query.Encode()
+
+	var resp deepgramResponse
+	err = retryRequest(ctx, req.MaxRetries, func() error {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(audioData))
+		if err != nil {
+			return err
+		}
+		httpReq.Header.Set("Authorization", "Token "+b.apiKey)
+		httpReq.Header.Set("Content-Type", audioContentType(audioPath))
+
+		httpResp, err := b.client.Do(httpReq)
+		if err != nil {
+			return err
+		}
+		defer httpResp.Body.Close()
+		if httpResp.StatusCode != http.StatusOK {
+			return newBackendHTTPError(httpResp)
+		}
+		resp = deepgramResponse{}
+		if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+			return fmt.Errorf(tr("解析 Deepgram 返回失败: %w"), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logDebug(tr("转写完成"))
+	return b.toResult(&resp, req), nil
+}
+
+// SupportsTemperature Deepgram 没有温度参数
+func (b *deepgramBackend) SupportsTemperature() bool {
+	return false
+}
+
+// toResult 将 Deepgram 返回映射为转写结果。有 utterances 时每个 utterance 为一个分段，
+// 否则按句末标点和停顿组合第一个声道的词
+func (b *deepgramBackend) toResult(resp *deepgramResponse, req backendRequest) *TranscriptionResult {
+	result := &TranscriptionResult{
+		Language: req.Language,
+		Duration: resp.Metadata.Duration,
+	}
+	var channelWords []deepgramWord
+	if len(resp.Results.Channels) > 0 {
+		channel := resp.Results.Channels[0]
+		if channel.DetectedLanguage != "" {
+			result.Language = channel.DetectedLanguage
+		}
+		if len(channel.Alternatives) > 0 {
+			result.Text = strings.TrimSpace(channel.Alternatives[0].Transcript)
+			channelWords = channel.Alternatives[0].Words
+		}
+	}
+
+	for _, u := range resp.Results.Utterances {
+		seg := Segment{
+			ID:         len(result.Segments) + 1,
+			Start:      u.Start,
+			End:        u.End,
+			Text:       strings.TrimSpace(u.Transcript),
+			Words:      deepgramWords(u.Words),
+			AvgLogprob: confidenceLogprob(u.Confidence),
+		}
+		if b.diarize {
+			seg.Speaker = strconv.Itoa(u.Speaker)
+		}
+		result.Segments = append(result.Segments, seg)
+	}
+	if len(result.Segments) == 0 {
+		result.Segments = segmentsFromWords(deepgramWords(channelWords))
+	}
+	return result
+}
+
+// deepgramWords 转换词级时间戳，优先使用带标点的写法
+func deepgramWords(words []deepgramWord) []Word {
+	out := make([]Word, 0, len(words))
+	for _, w := range words {
+		text := w.PunctuatedWord
+		if text == "" {
+			text = w.Word
+		}
+		out = append(out, Word{Word: text, Start: w.Start, End: w.End})
+	}
+	return out
+}
+
+// audioContentType 按扩展名返回上传音频的 Content-Type
+func audioContentType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		return "audio/wav"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".ogg", ".opus":
+		return "audio/ogg"
+	case ".flac":
+		return "audio/flac"
+	case ".m4a", ".mp4":
+		return "audio/mp4"
+	case ".webm":
+		return "audio/webm"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 对照报告中一行的状态
+const (
+	diffSame    = "same"    // 文本相同（按 eval 的规则规范化后）
+	diffChanged = "changed" // 时间对应但文本不同
+	diffMissing = "missing" // 参考字幕有、转写结果中没有
+	diffExtra   = "extra"   // 转写结果有、参考字幕中没有
+)
+
+// diffRow 对照报告的一行：一条参考字幕和时间上对应的转写分段
+type diffRow struct {
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Status     string  `json:"status"`
+	Reference  string  `json:"reference"`
+	Generated  string  `json:"generated"`
+	Similarity float64 `json:"similarity"`
+}
+
+// diffReport diff 子命令的对照报告
+type diffReport struct {
+	Reference  string    `json:"reference"`
+	Transcript string    `json:"transcript"`
+	Same       int       `json:"same"`
+	Changed    int       `json:"changed"`
+	Missing    int       `json:"missing"`
+	Extra      int       `json:"extra"`
+	WER        errorRate `json:"wer"`
+	CER        errorRate `json:"cer"`
+	Rows       []diffRow `json:"rows"`
+}
+
+// runDiff 执行 diff 子命令：把转写结果按时间和文本与已有字幕（SRT、JSON 或视频的内嵌字幕）逐条对照，
+// 输出差异报告，用于检查自动翻译或重新转写的质量
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	reference := fs.String("ref", "", tr("参考字幕（.srt、.json，或带文本字幕轨的视频）"))
+	language := fs.String("lang", "", tr("参考为视频时使用该语言的字幕轨（如 zh、en），默认使用默认字幕轨"))
+	tolerance := fs.Float64("tolerance", 0.5, tr("时间容差（秒），时间相差不超过该值的分段视为对应"))
+	chinese := fs.String("chinese", "", tr("比较前将中文统一转换为简体或繁体（simplified, traditional）"))
+	all := fs.Bool("all", false, tr("同时列出文本相同的行"))
+	htmlPath := fs.String("html", "", tr("另存为左右对照的 HTML 报告"))
+	asJSON := fs.Bool("json", false, tr("以 JSON 输出"))
+	configPath := fs.String("config", "./config.json", tr("配置文件路径（可选，参考为视频时用于读取 ffmpeg 路径）"))
+	fs.Parse(args)
+
+	if *reference == "" || fs.NArg() != 1 {
+		fmt.Println(tr("用法: whisper-go diff -ref <reference> [options] <transcript>"))
+		fmt.Println(tr("示例: whisper-go diff -ref movie.en.srt -html diff.html outputs/movie.srt"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(exitBadInput)
+	}
+	switch *chinese {
+	case "", chineseSimplified, chineseTraditional:
+	default:
+		exitWith(exitBadInput, tr("无效的 -chinese 参数: %s（可选 simplified, traditional）"), *chinese)
+	}
+	if *tolerance < 0 {
+		exitWith(exitBadInput, tr("-tolerance 不能为负数"))
+	}
+	if isVideoFile(*reference) {
+		// 只需要 ffmpeg 路径，配置文件不存在时使用默认值
+		if _, err := loadConfig(*configPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			exitWith(exitConfig, tr("加载配置失败: %v"), err)
+		}
+	}
+	opts := evalOptions{Chinese: *chinese}
+
+	refSegments, err := readDiffSegments(*reference, *language)
+	if err != nil {
+		exitWith(exitBadInput, tr("读取 %s 失败: %v"), *reference, err)
+	}
+	transcript := fs.Arg(0)
+	hypSegments, err := readDiffSegments(transcript, *language)
+	if err != nil {
+		exitWith(exitBadInput, tr("读取 %s 失败: %v"), transcript, err)
+	}
+
+	report := newDiffReport(refSegments, hypSegments, *tolerance, opts)
+	report.Reference, report.Transcript = *reference, transcript
+
+	if *htmlPath != "" {
+		if err := saveDiffHTML(report, *htmlPath); err != nil {
+			fatalf(tr("保存失败: %v"), err)
+		}
+	}
+	if *asJSON {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	printDiffReport(report, *all)
+	if *htmlPath != "" {
+		fmt.Printf(tr("\n对照报告: %s\n"), *htmlPath)
+	}
+}
+
+// readDiffSegments 读取带时间的分段：视频读取内嵌文本字幕轨，其他文件按 SRT 或 JSON 读取
+func readDiffSegments(path, language string) ([]Segment, error) {
+	var segments []Segment
+	if isVideoFile(path) {
+		tracks, err := probeSubtitleTracks(path)
+		if err != nil {
+			return nil, err
+		}
+		track, ok := selectSubtitleTrack(tracks, &Config{Language: language, AutoDetect: language == ""})
+		if !ok {
+			return nil, errors.New(tr("没有可用的文本字幕轨"))
+		}
+		result, err := extractSubtitleTrack(path, track)
+		if err != nil {
+			return nil, err
+		}
+		segments = result.Segments
+	} else {
+		result, err := readPartFile(path)
+		if err != nil {
+			return nil, err
+		}
+		segments = result.Segments
+	}
+
+	timed := false
+	for _, seg := range segments {
+		if seg.End > 0 {
+			timed = true
+			break
+		}
+	}
+	if !timed {
+		return nil, errors.New(tr("没有时间信息，请使用 SRT 或 JSON 文件"))
+	}
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start })
+	return segments, nil
+}
+
+// newDiffReport 对齐参考字幕和转写分段：每个转写分段归入时间重叠（含容差）的参考字幕中
+// 重叠比例与文本相似度之和最高的一条，没有重叠的转写分段单独列为多出的行
+func newDiffReport(ref, hyp []Segment, tolerance float64, opts evalOptions) *diffReport {
+	assigned := make([][]int, len(ref))
+	var extra []int
+	lo := 0
+	for j, h := range hyp {
+		for lo < len(ref) && ref[lo].End+tolerance <= h.Start {
+			lo++
+		}
+		best, bestScore := -1, 0.0
+		for i := lo; i < len(ref) && ref[i].Start-tolerance < h.End; i++ {
+			overlap := min(h.End, ref[i].End+tolerance) - max(h.Start, ref[i].Start-tolerance)
+			if overlap <= 0 {
+				continue
+			}
+			score := min(overlap/max(h.End-h.Start, 0.01), 1) + textSimilarity(ref[i].Text, h.Text, opts)
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			extra = append(extra, j)
+			continue
+		}
+		assigned[best] = append(assigned[best], j)
+	}
+
+	report := &diffReport{}
+	for i, r := range ref {
+		row := diffRow{Start: r.Start, End: r.End, Reference: strings.TrimSpace(r.Text)}
+		for _, j := range assigned[i] {
+			row.Generated = joinSegmentText(row.Generated, hyp[j].Text)
+		}
+		row.Similarity = textSimilarity(row.Reference, row.Generated, opts)
+		switch {
+		case len(assigned[i]) == 0:
+			row.Status, row.Similarity = diffMissing, 0
+			report.Missing++
+		case row.Similarity == 1:
+			row.Status = diffSame
+			report.Same++
+		default:
+			row.Status = diffChanged
+			report.Changed++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	for _, j := range extra {
+		report.Rows = append(report.Rows, diffRow{Start: hyp[j].Start, End: hyp[j].End, Status: diffExtra, Generated: strings.TrimSpace(hyp[j].Text)})
+		report.Extra++
+	}
+	sort.SliceStable(report.Rows, func(i, j int) bool { return report.Rows[i].Start < report.Rows[j].Start })
+
+	refWords, refChars := evalTokens(segmentsText(ref), opts)
+	hypWords, hypChars := evalTokens(segmentsText(hyp), opts)
+	report.WER = editRate(refWords, hypWords)
+	report.CER = editRate(refChars, hypChars)
+	return report
+}
+
+// textSimilarity 两段文本按 eval 的规则规范化后的字符相似度：1 减去编辑距离与较长一方长度之比
+func textSimilarity(a, b string, opts evalOptions) float64 {
+	_, ca := evalTokens(a, opts)
+	_, cb := evalTokens(b, opts)
+	if len(ca) == 0 && len(cb) == 0 {
+		return 1
+	}
+	rate := editRate(ca, cb)
+	edits := rate.Substitutions + rate.Deletions + rate.Insertions
+	return 1 - float64(edits)/float64(max(len(ca), len(cb)))
+}
+
+// diffStatusLabel 状态的显示名称
+func diffStatusLabel(status string) string {
+	switch status {
+	case diffSame:
+		return tr("相同")
+	case diffChanged:
+		return tr("不同")
+	case diffMissing:
+		return tr("缺失")
+	default:
+		return tr("多出")
+	}
+}
+
+// printDiffReport 在终端输出对照报告：- 为参考字幕，+ 为转写结果，默认不列出相同的行
+func printDiffReport(report *diffReport, all bool) {
+	fmt.Printf(tr("参考: %s\n转写: %s\n"), report.Reference, report.Transcript)
+	fmt.Printf("WER %.2f%%  CER %.2f%%\n", 100*report.WER.Rate, 100*report.CER.Rate)
+	fmt.Printf(tr("相同 %d，不同 %d，缺失 %d，多出 %d")+"\n", report.Same, report.Changed, report.Missing, report.Extra)
+	for _, row := range report.Rows {
+		if row.Status == diffSame && !all {
+			continue
+		}
+		fmt.Printf("\n%s - %s  %s", formatSplitTime(row.Start), formatSplitTime(row.End), diffStatusLabel(row.Status))
+		if row.Status == diffChanged {
+			fmt.Printf(" %.0f%%", 100*row.Similarity)
+		}
+		fmt.Println()
+		if row.Status != diffExtra {
+			fmt.Printf("  - %s\n", row.Reference)
+		}
+		if row.Status != diffMissing {
+			fmt.Printf("  + %s\n", row.Generated)
+		}
+	}
+}
+
+// diffPiece 对照页面中的一段文本，Mark 为与另一侧不同的词或字
+type diffPiece struct {
+	Text string
+	Mark bool
+}
+
+// diffPageRow 对照页面的一行
+type diffPageRow struct {
+	Time       string
+	Status     string
+	Label      string
+	Similarity string
+	Reference  []diffPiece
+	Generated  []diffPiece
+}
+
+// diffPage 对照页面模板数据
+type diffPage struct {
+	Lang    string
+	Title   string
+	Summary string
+	Rows    []diffPageRow
+	Labels  map[string]string
+}
+
+// diffTemplate 左右对照的差异页面：参考中多出的词标为删除，转写中多出的词标为插入
+var diffTemplate = template.Must(template.New("diff").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 0 auto; padding: 0 16px 48px; line-height: 1.6; color: #222; }
+h1 { font-size: 1.2em; word-break: break-all; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { position: sticky; top: 0; background: #fff; }
+.ts { color: #888; font-family: monospace; white-space: nowrap; }
+.sim { white-space: nowrap; }
+.changed { background: #fff8e6; }
+.missing { background: #fdecea; }
+.extra { background: #eaf4fd; }
+del { background: #f5b7b1; text-decoration: none; }
+ins { background: #abebc6; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Summary}}</p>
+<table>
+<tr><th>{{index .Labels "time"}}</th><th>{{index .Labels "reference"}}</th><th>{{index .Labels "generated"}}</th><th>{{index .Labels "similarity"}}</th></tr>
+{{range .Rows}}<tr class="{{.Status}}"><td class="ts">{{.Time}}</td><td>{{range .Reference}}{{if .Mark}}<del>{{.Text}}</del>{{else}}{{.Text}}{{end}}{{end}}</td><td>{{range .Generated}}{{if .Mark}}<ins>{{.Text}}</ins>{{else}}{{.Text}}{{end}}{{end}}</td><td class="sim">{{.Label}} {{.Similarity}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// saveDiffHTML 保存左右对照的 HTML 报告，文本相同的行也列出
+func saveDiffHTML(report *diffReport, path string) error {
+	page := diffPage{
+		Lang:  uiLang,
+		Title: fmt.Sprintf("%s ↔ %s", report.Reference, report.Transcript),
+		Summary: fmt.Sprintf("WER %.2f%%  CER %.2f%%  ", 100*report.WER.Rate, 100*report.CER.Rate) +
+			fmt.Sprintf(tr("相同 %d，不同 %d，缺失 %d，多出 %d"), report.Same, report.Changed, report.Missing, report.Extra),
+		Labels: map[string]string{
+			"time":       tr("时间"),
+			"reference":  tr("参考"),
+			"generated":  tr("转写结果"),
+			"similarity": tr("相似度"),
+		},
+	}
+	for _, row := range report.Rows {
+		ref, hyp := markDiffPieces(row.Reference, row.Generated)
+		pageRow := diffPageRow{
+			Time:      formatChapterTime(row.Start),
+			Status:    row.Status,
+			Label:     diffStatusLabel(row.Status),
+			Reference: ref,
+			Generated: hyp,
+		}
+		if row.Status == diffChanged {
+			pageRow.Similarity = fmt.Sprintf("%.0f%%", 100*row.Similarity)
+		}
+		page.Rows = append(page.Rows, pageRow)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := diffTemplate.Execute(f, page); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// splitDiffPieces 把文本切分为首尾相接的片段：中日韩文字每个字一段，字母数字连续为一段，空白和标点各自成段。
+// 比较时只看字和词，空白和标点不参与
+func splitDiffPieces(text string) (pieces []string, words []bool) {
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			pieces = append(pieces, word.String())
+			words = append(words, true)
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			pieces = append(pieces, string(r))
+			words = append(words, true)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || (word.Len() > 0 && (r == '\'' || r == '’')):
+			word.WriteRune(r)
+		default:
+			flush()
+			pieces = append(pieces, string(r))
+			words = append(words, false)
+		}
+	}
+	flush()
+	return pieces, words
+}
+
+// markDiffPieces 按最长公共子序列标出两段文本中不同的字和词（不区分大小写和全半角）
+func markDiffPieces(a, b string) ([]diffPiece, []diffPiece) {
+	pa, wa := splitDiffPieces(a)
+	pb, wb := splitDiffPieces(b)
+	key := func(s string) string { return strings.ToLower(foldFullWidth(s)) }
+	var ka, kb []string
+	var ia, ib []int
+	for i, p := range pa {
+		if wa[i] {
+			ka, ia = append(ka, key(p)), append(ia, i)
+		}
+	}
+	for i, p := range pb {
+		if wb[i] {
+			kb, ib = append(kb, key(p)), append(ib, i)
+		}
+	}
+
+	markA, markB := make([]bool, len(pa)), make([]bool, len(pb))
+	for _, i := range ia {
+		markA[i] = true
+	}
+	for _, i := range ib {
+		markB[i] = true
+	}
+	// lcs[i][j] 为 ka[i:] 与 kb[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(ka)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(kb)+1)
+	}
+	for i := len(ka) - 1; i >= 0; i-- {
+		for j := len(kb) - 1; j >= 0; j-- {
+			if ka[i] == kb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(ka) && j < len(kb); {
+		switch {
+		case ka[i] == kb[j]:
+			markA[ia[i]], markB[ib[j]] = false, false
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	toPieces := func(pieces []string, marks []bool) []diffPiece {
+		var out []diffPiece
+		for i, p := range pieces {
+			// 相邻的同类片段合并，减少标签数量
+			if n := len(out); n > 0 && out[n-1].Mark == marks[i] {
+				out[n-1].Text += p
+				continue
+			}
+			out = append(out, diffPiece{Text: p, Mark: marks[i]})
+		}
+		return out
+	}
+	return toPieces(pa, markA), toPieces(pb, markB)
+}
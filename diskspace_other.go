@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package main
+
+// diskFree 当前平台不支持获取剩余空间
+func diskFree(dir string) (uint64, error) {
+	return 0, errDiskSpaceUnknown
+}
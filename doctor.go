@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// doctorTimeout API 连通性检查的超时时间
+const doctorTimeout = 15 * time.Second
+
+// doctorReport 诊断结果汇总
+type doctorReport struct {
+	failed int
+	warned int
+}
+
+// ok 输出通过的检查项
+func (r *doctorReport) ok(format string, a ...any) {
+	fmt.Printf("  [OK]   %s\n", fmt.Sprintf(format, a...))
+}
+
+// warn 输出警告及修复建议
+func (r *doctorReport) warn(msg, fix string) {
+	r.warned++
+	fmt.Printf("  [WARN] %s\n", msg)
+	if fix != "" {
+		fmt.Printf("         -> %s\n", fix)
+	}
+}
+
+// fail 输出错误及修复建议
+func (r *doctorReport) fail(msg, fix string) {
+	r.failed++
+	fmt.Printf("  [FAIL] %s\n", msg)
+	if fix != "" {
+		fmt.Printf("         -> %s\n", fix)
+	}
+}
+
+// runDoctor 执行 doctor 子命令：检查运行环境、配置、API 连通性和输出目录
+func runDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	skipAPI := fs.Bool("offline", false, tr("跳过 API 连通性检查"))
+	fs.Parse(args)
+
+	r := &doctorReport{}
+
+	fmt.Printf("whisper-go %s (%s %s/%s)\n\n", currentVersion(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Println(tr("外部工具:"))
+	config, configErr := loadConfig(*configPath)
+	if configErr != nil {
+		// 配置无法加载时按默认设置检查工具
+		config = &Config{}
+		config.applyDefaults()
+		useFFmpegConfig(config)
+	}
+	r.checkTool(ffmpegTools.FFmpeg, "ffmpeg_path", tr("视频和 WAV/FLAC/MP3 以外的音频需要 ffmpeg 提取、检测静音和切片，请安装 ffmpeg（https://ffmpeg.org）并加入 PATH"))
+	r.checkTool(ffmpegTools.FFprobe, "ffprobe_path", tr("WAV/FLAC/MP3 以外的文件获取时长需要 ffprobe，通常随 ffmpeg 一起安装"))
+
+	fmt.Printf(tr("\n配置文件 (%s):\n"), *configPath)
+	if configErr != nil {
+		r.fail(configErr.Error(), tr("按提示修改配置文件，或运行 whisper-go config init 重新生成"))
+	} else {
+		r.ok("%s", tr("已加载"))
+		r.checkConfig(config)
+	}
+
+	fmt.Println("\nAPI:")
+	switch {
+	case *skipAPI:
+		fmt.Println(tr("  [SKIP] 已跳过（--offline）"))
+	case configErr != nil || (config.APIKey == "" && config.needsAPIKey()):
+		fmt.Println(tr("  [SKIP] 配置不完整，跳过 API 检查"))
+	case !config.openaiCompatible():
+		fmt.Printf(tr("  [SKIP] provider %s 不支持连接检查\n"), config.Provider)
+	default:
+		r.checkAPI(config)
+	}
+
+	fmt.Println(tr("\n输出目录:"))
+	r.checkOutputDir(config.OutputDir)
+
+	fmt.Println(tr("\n工作目录:"))
+	r.checkWorkDir(config.WorkDir)
+
+	fmt.Printf(tr("\n检查完成: %d 个错误, %d 个警告\n"), r.failed, r.warned)
+	if r.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// checkTool 检查外部命令是否可用并输出版本
+func (r *doctorReport) checkTool(name, field, fix string) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		r.fail(fmt.Sprintf(tr("未找到 %s"), name), fix+fmt.Sprintf(tr("；如使用其他名称或路径，请在配置中设置 %s"), field))
+		return
+	}
+	out, err := exec.Command(path, "-version").Output()
+	if err != nil {
+		r.warn(fmt.Sprintf(tr("%s 无法获取版本: %v"), path, err), fmt.Sprintf(tr("确认 %s 是可执行的 ffmpeg 兼容程序"), path))
+		return
+	}
+	version, _, _ := strings.Cut(string(out), "\n")
+	r.ok("%s (%s)", strings.TrimSpace(version), path)
+}
+
+// checkConfig 校验配置字段
+func (r *doctorReport) checkConfig(config *Config) {
+	if config.APIKey == "" && config.needsAPIKey() {
+		r.fail(tr("未设置 api_key"), tr("在 config.json 中填写 api_key"))
+	} else if config.apiKeys != nil {
+		r.ok(tr("api_key 已设置（%d 个 key 按 %s 轮换）"), len(config.apiKeys.keys), config.APIKeyRotation)
+	} else if config.APIKey != "" {
+		r.ok("%s", tr("api_key 已设置"))
+	}
+
+	if config.VAD == vadSilero {
+		r.checkSilero(config)
+	}
+	if config.Provider == providerWhisperCpp {
+		r.checkWhisperCpp(config)
+	} else if config.APIBaseURL == "" {
+		r.warn(tr("未设置 api_base_url，将使用 OpenAI 官方地址"), tr("使用兼容服务时填写其地址，如 https://api.example.com/v1"))
+	} else if u, err := url.Parse(config.APIBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		r.fail(fmt.Sprintf(tr("api_base_url 无效: %s"), config.APIBaseURL), tr("应为 http(s):// 开头的完整地址，如 https://api.example.com/v1"))
+	} else {
+		r.ok("api_base_url: %s", config.APIBaseURL)
+	}
+
+	// 取值范围已由 loadConfig 校验，这里只检查可能出问题的有效取值
+	if config.MaxFileSizeMB > 25 && config.Provider == providerOpenAI {
+		r.warn(fmt.Sprintf(tr("max_file_size_mb 为 %.0f，超过 OpenAI 接口的 25MB 上限"), config.MaxFileSizeMB), tr("使用 OpenAI 官方接口时请设置为 25 以下"))
+	}
+	if config.InsecureSkipVerify {
+		r.warn(tr("insecure_skip_verify 已开启，API 请求不校验服务器证书"), tr("使用私有 CA 时改为配置 ca_bundle"))
+	}
+
+	for field, value := range map[string]string{"webhook_url": config.WebhookURL, "obs_websocket_url": config.OBSWebSocketURL, "mqtt_broker": config.MQTTBroker} {
+		if value == "" {
+			continue
+		}
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			r.fail(fmt.Sprintf(tr("%s 无效: %s"), field, value), tr("应为包含协议和主机的完整地址"))
+		}
+	}
+	for format, command := range config.PostWriteHooks {
+		if len(command) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(command[0]); err != nil {
+			r.fail(fmt.Sprintf(tr("%s 格式的后置命令不存在: %s"), format, command[0]), tr("检查 post_write_hooks 中的命令名或使用绝对路径"))
+		}
+	}
+	if config.OBSWebSocketURL != "" && config.OBSTextSource == "" {
+		r.fail(tr("已设置 obs_websocket_url 但未设置 obs_text_source"), tr("填写 OBS 中用于显示字幕的文本源名称"))
+	}
+}
+
+// checkWhisperCpp 检查 whisper.cpp 命令和模型文件是否存在
+func (r *doctorReport) checkWhisperCpp(config *Config) {
+	if path, err := exec.LookPath(config.WhisperCppPath); err != nil {
+		r.fail(fmt.Sprintf(tr("未找到 whisper.cpp: %s"), config.WhisperCppPath), tr("安装 whisper.cpp 或在 whispercpp_path 中填写 whisper-cli 的路径"))
+	} else {
+		r.ok("whisper.cpp: %s", path)
+	}
+	if _, err := os.Stat(config.WhisperCppModel); err != nil {
+		r.fail(fmt.Sprintf(tr("whisper.cpp 模型文件不存在: %s"), config.WhisperCppModel), tr("下载 ggml 模型（如 ggml-large-v3.bin）并在 whispercpp_model 中填写路径"))
+	} else {
+		r.ok(tr("whisper.cpp 模型: %s"), config.WhisperCppModel)
+	}
+}
+
+// checkSilero 检查 Silero VAD 模型能否加载（同时检查编译时是否包含 onnxruntime）
+func (r *doctorReport) checkSilero(config *Config) {
+	if _, err := os.Stat(config.SileroModel); err != nil {
+		r.fail(fmt.Sprintf(tr("Silero VAD 模型文件不存在: %s"), config.SileroModel), tr("下载 silero_vad.onnx（https://github.com/snakers4/silero-vad）并在 silero_model 中填写路径"))
+		return
+	}
+	detector, err := newSileroDetector(config)
+	if err != nil {
+		r.fail(fmt.Sprintf(tr("无法加载 Silero VAD: %v"), err), tr("使用 -tags silero 编译并安装 onnxruntime，或在 onnxruntime_lib 中填写动态库路径；也可以把 vad 改回 energy"))
+		return
+	}
+	detector.Close()
+	r.ok(tr("Silero VAD 模型: %s"), config.SileroModel)
+}
+
+// checkAPI 通过列出模型检查 API 地址和密钥是否可用
+func (r *doctorReport) checkAPI(config *Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	start := time.Now()
+	models, err := newClient(config).ListModels(ctx)
+	if err != nil {
+		switch httpStatusCode(err) {
+		case 401, 403:
+			r.fail(fmt.Sprintf(tr("API 认证失败: %v"), err), tr("检查 api_key 是否正确、是否有权限"))
+		case 404:
+			r.warn(fmt.Sprintf(tr("API 不支持列出模型: %v"), err), tr("部分兼容服务没有 /models 接口，可忽略；否则检查 api_base_url 是否包含 /v1"))
+		case 0:
+			r.fail(fmt.Sprintf(tr("无法连接 API: %v"), err), tr("检查网络、代理设置和 api_base_url"))
+		default:
+			r.fail(fmt.Sprintf(tr("API 返回错误: %v"), err), tr("检查 api_base_url 和服务状态"))
+		}
+		return
+	}
+	r.ok(tr("已连接，认证通过（%d ms）"), time.Since(start).Milliseconds())
+
+	for _, m := range models.Models {
+		if m.ID == config.Model {
+			r.ok(tr("模型 %s 可用"), config.Model)
+			return
+		}
+	}
+	if len(models.Models) > 0 {
+		r.warn(fmt.Sprintf(tr("模型列表中没有 %s"), config.Model), tr("确认 model 配置与服务端提供的模型名称一致"))
+	}
+}
+
+// checkWorkDir 检查中间文件目录能否创建并显示剩余空间
+func (r *doctorReport) checkWorkDir(dir string) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		r.fail(fmt.Sprintf(tr("无法创建工作目录 %s: %v"), dir, err), tr("检查 work_dir 的路径和权限"))
+		return
+	}
+	free, err := diskFree(dir)
+	if err != nil {
+		r.ok(tr("%s（无法获取剩余空间）"), dir)
+		return
+	}
+	if free < 1<<30 {
+		r.warn(fmt.Sprintf(tr("工作目录 %s 剩余空间仅 %.0f MB"), dir, float64(free)/(1<<20)), tr("长视频提取的音频可达数 GB，可通过 work_dir 指定空间更大的目录"))
+		return
+	}
+	r.ok(tr("%s（剩余 %.1f GB）"), dir, float64(free)/(1<<30))
+}
+
+// checkOutputDir 检查输出目录是否可写
+func (r *doctorReport) checkOutputDir(dir string) {
+	if isRemoteURI(dir) {
+		fmt.Printf(tr("  [SKIP] %s 为对象存储地址，跳过写入检查\n"), dir)
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		r.fail(fmt.Sprintf(tr("无法创建输出目录 %s: %v"), dir, err), tr("检查路径和权限，或通过 output_dir / --output 指定其他目录"))
+		return
+	}
+	f, err := os.CreateTemp(dir, ".whisper_doctor_")
+	if err != nil {
+		r.fail(fmt.Sprintf(tr("输出目录 %s 不可写: %v"), dir, err), tr("检查目录权限，或通过 output_dir / --output 指定其他目录"))
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	r.ok(tr("%s 可写"), dir)
+}
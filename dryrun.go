@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultPricePerMinute 默认转写单价（美元/分钟），与 OpenAI whisper-1 定价一致
+const defaultPricePerMinute = 0.006
+
+// extractedBytesPerSecond 提取后的 16kHz 单声道 16 位 PCM 音频每秒字节数，用于估算视频提取后的大小
+const extractedBytesPerSecond = 16000 * 2
+
+// chunkPlan 计划的切片区间（秒）
+type chunkPlan struct {
+	Start float64
+	End   float64
+}
+
+// dryRunPlan 预演模式下一个输入文件的处理计划
+type dryRunPlan struct {
+	Input       string
+	MediaType   string  // audio、video 或 remote
+	Duration    float64 // 0 表示无法获取
+	SizeMB      float64
+	AudioSizeMB float64 // 实际判断是否切片的音频大小，视频为提取后的估算值
+	NeedsSplit  bool
+	SplitsFile  string // 手动切点文件，为空时按静音点计算切点
+	Chunks      []chunkPlan
+	OutputFiles []string
+	Cost        float64
+	Notes       []string
+}
+
+// planDryRun 只读取文件信息（ffprobe 和静音检测，不调用 ffmpeg 编码和 API），生成处理计划
+func planDryRun(input string, config *Config, formatList []string) (*dryRunPlan, error) {
+	plan := &dryRunPlan{Input: input, MediaType: "audio"}
+	if isVideoFile(input) {
+		plan.MediaType = "video"
+	}
+	config, formatList, err := applySidecar(input, config, formatList)
+	if err != nil {
+		return nil, err
+	}
+	if config.Redact {
+		formatList = appendFormats(formatList, "redactions")
+	}
+
+	outputs, err := plannedOutputPaths(input, config, formatList)
+	if err != nil {
+		return nil, err
+	}
+	plan.OutputFiles = outputs
+	for _, format := range formatList {
+		if _, ok := formatExtension(format, input, config); !ok {
+			plan.Notes = append(plan.Notes, fmt.Sprintf(tr("不支持的格式: %s"), format))
+		}
+	}
+
+	// 对象存储中的输入需要下载后才能分析
+	if isRemoteURI(input) {
+		plan.MediaType = "remote"
+		plan.Notes = append(plan.Notes, tr("输入位于对象存储，实际运行时先下载，无法预先获取时长和大小"))
+		return plan, nil
+	}
+
+	if plan.SizeMB, err = getFileSizeMB(input); err != nil {
+		return nil, fmt.Errorf(tr("获取文件大小失败: %w"), err)
+	}
+	plan.Duration, err = getAudioDuration(input)
+	if err != nil {
+		plan.Duration = 0
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("无法获取时长，切片和费用无法估算: %v"), err))
+		return plan, nil
+	}
+
+	plan.AudioSizeMB = plan.SizeMB
+	if plan.MediaType == "video" {
+		plan.AudioSizeMB = plan.Duration * extractedBytesPerSecond / (1024 * 1024)
+	}
+	plan.NeedsSplit = plan.AudioSizeMB > config.MaxFileSizeMB
+	plan.Cost = plan.Duration / 60 * config.PricePerMinute
+	if config.EmbeddedSubtitles && plan.MediaType == "video" {
+		if tracks, err := probeSubtitleTracks(input); err == nil {
+			if track, ok := selectSubtitleTrack(tracks, config); ok {
+				plan.Cost = 0
+				plan.Notes = append(plan.Notes, fmt.Sprintf(tr("将使用内嵌字幕轨 %s，不调用 API"), track))
+			}
+		}
+	}
+
+	plan.Chunks = []chunkPlan{{Start: 0, End: plan.Duration}}
+	if path := manualSplitsFile(input, config); path != "" {
+		// 切点文件有误时仍给出按静音点计算的计划，--plan-splits 可以重新生成切点文件
+		if splitTimes, err := loadSplitTimes(path, plan.Duration); err != nil {
+			plan.Notes = append(plan.Notes, fmt.Sprintf(tr("切点文件无效，实际转写会失败: %v"), err))
+		} else {
+			plan.SplitsFile = path
+			plan.Chunks = chunkPlans(splitTimes, plan.Duration)
+		}
+	}
+	if plan.SplitsFile == "" && plan.NeedsSplit {
+		splitTimes, err := silenceSplitTimes(input, plan, config)
+		if err != nil {
+			plan.Notes = append(plan.Notes, fmt.Sprintf(tr("静音检测失败，切片边界按等长估算: %v"), err))
+		}
+		plan.Chunks = chunkPlans(splitTimes, plan.Duration)
+	}
+
+	if config.UploadCodec != "" {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("上传前压缩为 %s"), config.UploadCodec))
+	}
+	if config.ChapterModel != "" && wantChapters(config, formatList) {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("章节分析会调用对话模型 %s，费用未计入"), config.ChapterModel))
+	}
+	if config.MinutesModel != "" && wantMinutes(formatList) {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("会议纪要会调用对话模型 %s，费用未计入"), config.MinutesModel))
+	}
+	if config.KeywordsModel != "" && wantKeywords(formatList) {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("提取关键词会调用对话模型 %s，费用未计入"), config.KeywordsModel))
+	}
+	if config.SentimentModel != "" && wantSentiment(formatList) {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("情绪分析会调用对话模型 %s，费用未计入"), config.SentimentModel))
+	}
+	if config.EnglishCasing == casingLLM {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("英文大小写修正会调用对话模型 %s（仅英文结果），费用未计入"), config.CasingModel))
+	}
+	if config.Redact && config.RedactModel != "" {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("脱敏会调用对话模型 %s，费用未计入"), config.RedactModel))
+	}
+	if len(config.TranslateTo) > 0 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(tr("翻译为 %s 会调用对话模型 %s，费用未计入"), strings.Join(config.TranslateTo, ", "), config.TranslateModel))
+	}
+	return plan, nil
+}
+
+// silenceSplitTimes 按与 planSplitTimes 相同的规则估算切点；静音检测只解码不编码，失败时返回错误和按等长切分的切点
+func silenceSplitTimes(input string, plan *dryRunPlan, config *Config) ([]float64, error) {
+	numChunks := int(plan.AudioSizeMB/config.MaxFileSizeMB) + 1
+	silencePoints, err := detectSilence(input, config, false)
+	return calculateSplitTimes(plan.Duration, plan.Duration/float64(numChunks), silencePoints), err
+}
+
+// plannedOutputPaths 计算各输出格式的文件路径，输出目录为对象存储时返回上传后的地址
+func plannedOutputPaths(input string, config *Config, formatList []string) ([]string, error) {
+	outputDir := config.OutputDir
+	remote := isRemoteURI(outputDir)
+	if remote {
+		outputDir = ""
+	}
+	dir := organizedOutputDir(outputDir, input, config.Organize, time.Now())
+
+	var exts []string
+	for _, format := range formatList {
+		if ext, ok := formatExtension(format, input, config); ok {
+			exts = append(exts, ext)
+		}
+	}
+	for _, language := range config.TranslateTo {
+		for _, format := range translationFormats(formatList) {
+			exts = append(exts, translationExtension(language, format))
+		}
+	}
+
+	var paths []string
+	for _, ext := range exts {
+		path := generateOutputPath(input, dir, ext)
+		if remote {
+			base, err := parseRemoteURI(config.OutputDir)
+			if err != nil {
+				return nil, err
+			}
+			path = base.join(path).String()
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// runDryRun 输出每个输入文件的处理计划和预计总费用
+func runDryRun(inputs []string, config *Config, formatList []string) {
+	var totalDuration, totalCost float64
+	for i, input := range inputs {
+		plan, err := planDryRun(input, config, formatList)
+		if err != nil {
+			fatalf(tr("分析 %s 失败: %v"), input, err)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		printDryRunPlan(plan, config)
+		totalDuration += plan.Duration
+		totalCost += plan.Cost
+	}
+
+	if len(inputs) > 1 {
+		fmt.Println(tr("\n=== 合计 ==="))
+		fmt.Printf(tr("文件数: %d\n"), len(inputs))
+		fmt.Printf(tr("总时长: %s\n"), formatChapterTime(totalDuration))
+		fmt.Printf(tr("预计费用: $%.4f\n"), totalCost)
+	}
+}
+
+// printDryRunPlan 输出单个文件的处理计划
+func printDryRunPlan(plan *dryRunPlan, config *Config) {
+	fmt.Printf(tr("=== 预演: %s ===\n"), plan.Input)
+	fmt.Printf(tr("媒体类型: %s\n"), plan.MediaType)
+	if plan.MediaType != "remote" {
+		fmt.Printf(tr("文件大小: %.2f MB\n"), plan.SizeMB)
+	}
+	if plan.Duration > 0 {
+		fmt.Printf(tr("时长: %s（%.1f 秒）\n"), formatChapterTime(plan.Duration), plan.Duration)
+		if plan.MediaType == "video" {
+			fmt.Printf(tr("提取后音频: 约 %.2f MB\n"), plan.AudioSizeMB)
+			if config.StreamExtract {
+				fmt.Println(tr("流式提取: 通过管道边解码边切片，不生成完整的中间 WAV"))
+			}
+		}
+		if plan.SplitsFile != "" {
+			fmt.Printf(tr("切片: 按切点文件 %s，计划 %d 个切片\n"), plan.SplitsFile, len(plan.Chunks))
+			for i, chunk := range plan.Chunks {
+				fmt.Printf("  %3d. %s - %s\n", i+1, formatChapterTime(chunk.Start), formatChapterTime(chunk.End))
+			}
+		} else if plan.NeedsSplit {
+			fmt.Printf(tr("切片: 需要（%.2f MB 超过阈值 %.0f MB），计划 %d 个切片\n"), plan.AudioSizeMB, config.MaxFileSizeMB, len(plan.Chunks))
+			for i, chunk := range plan.Chunks {
+				fmt.Printf("  %3d. %s - %s\n", i+1, formatChapterTime(chunk.Start), formatChapterTime(chunk.End))
+			}
+		} else {
+			fmt.Printf(tr("切片: 不需要（%.2f MB 未超过阈值 %.0f MB）\n"), plan.AudioSizeMB, config.MaxFileSizeMB)
+		}
+		fmt.Printf(tr("预计费用: $%.4f（%.2f 分钟 × $%.4f/分钟，不含重试）\n"), plan.Cost, plan.Duration/60, config.PricePerMinute)
+	}
+	fmt.Print(tr("输出文件:\n"))
+	for _, file := range plan.OutputFiles {
+		fmt.Printf("  - %s\n", file)
+	}
+	if len(plan.Notes) > 0 {
+		fmt.Print(tr("说明:\n"))
+		for _, note := range plan.Notes {
+			fmt.Printf("  - %s\n", note)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EBU STL（EBU Tech 3264）文件由 1024 字节的 GSI 块和若干 128 字节的 TTI 块组成
+const (
+	stlGSISize       = 1024
+	stlTTISize       = 128
+	stlTextFieldSize = 112
+	stlMaxRowChars   = 40 // 图文电视每行最多显示的字符数（GSI 中的 MNC）
+	stlMaxRows       = 23 // 图文电视的行数（GSI 中的 MNR）
+	stlBottomRow     = 22 // 最后一行字幕所在的行（双倍高度占用 22、23 两行）
+)
+
+// TTI 文本字段中的控制码
+const (
+	stlDoubleHeight = 0x0D
+	stlStartBox     = 0x0B
+	stlEndBox       = 0x0A
+	stlNewline      = 0x8A
+	stlUnused       = 0x8F
+)
+
+// stlGSIFields ebu_stl_gsi 中可以设置的 GSI 字段和最大长度
+var stlGSIFields = map[string]int{
+	"OPT": 32, // 原节目名称
+	"OET": 32, // 原剧集名称
+	"TPT": 32, // 译制节目名称
+	"TET": 32, // 译制剧集名称
+	"TN":  32, // 译者
+	"TCD": 32, // 译者联系方式
+	"SLR": 16, // 字幕列表编号
+	"RN":  2,  // 修订号
+	"TCP": 8,  // 节目开始时间码 HHMMSSFF
+	"CO":  3,  // 原产国（ISO 3166 三字母代码）
+	"PUB": 32, // 发行方
+	"EN":  32, // 编辑
+	"ECD": 32, // 编辑联系方式
+	"LC":  2,  // 语言代码（EBU 十六进制代码，默认按识别出的语言）
+}
+
+var (
+	// stlTimecodeField TCP 字段：HHMMSSFF
+	stlTimecodeField = regexp.MustCompile(`^\d{8}$`)
+	// stlLanguageField LC 字段：两位十六进制
+	stlLanguageField = regexp.MustCompile(`^[0-9A-Fa-f]{2}$`)
+	// stlCountryField CO 字段：三个字母
+	stlCountryField = regexp.MustCompile(`^[A-Za-z]{3}$`)
+	// stlRevisionField RN 字段：一到两位数字
+	stlRevisionField = regexp.MustCompile(`^\d{1,2}$`)
+)
+
+// stlLanguageCodes ISO 639-1 语言代码对应的 EBU 语言代码（EBU Tech 3264 附录 3）
+var stlLanguageCodes = map[string]string{
+	"sq": "01", "br": "02", "ca": "03", "hr": "04", "cy": "05", "cs": "06", "da": "07", "de": "08",
+	"en": "09", "es": "0A", "eo": "0B", "et": "0C", "eu": "0D", "fo": "0E", "fr": "0F", "fy": "10",
+	"ga": "11", "gd": "12", "gl": "13", "is": "14", "it": "15", "la": "17", "lv": "18", "lb": "19",
+	"lt": "1A", "hu": "1B", "mt": "1C", "nl": "1D", "no": "1E", "nb": "1E", "nn": "1E", "oc": "1F",
+	"pl": "20", "pt": "21", "ro": "22", "rm": "23", "sr": "24", "sk": "25", "sl": "26", "fi": "27",
+	"sv": "28", "tr": "29",
+}
+
+// stlDiacritics ISO 6937 的非间距变音符号（写在字母之前）及其组合出的字母，与 base 中的字母一一对应
+var stlDiacritics = []struct {
+	code     byte
+	composed string
+	base     string
+}{
+	{0xC1, "ÀÈÌÒÙàèìòù", "AEIOUaeiou"},
+	{0xC2, "ÁĆÉÍĹŃÓŔŚÚÝŹáćéíĺńóŕśúýź", "ACEILNORSUYZaceilnorsuyz"},
+	{0xC3, "ÂĈÊĜĤÎĴÔŜÛŴŶâĉêĝĥîĵôŝûŵŷ", "ACEGHIJOSUWYaceghijosuwy"},
+	{0xC4, "ÃĨÑÕŨãĩñõũ", "AINOUainou"},
+	{0xC5, "ĀĒĪŌŪāēīōū", "AEIOUaeiou"},
+	{0xC6, "ĂĞŬăğŭ", "AGUagu"},
+	{0xC7, "ĊĖĠİŻċėġż", "CEGIZcegz"},
+	{0xC8, "ÄËÏÖÜŸäëïöüÿ", "AEIOUYaeiouy"},
+	{0xCA, "ÅŮåů", "AUau"},
+	{0xCB, "ÇĢĶĻŅŖŞŢçķļņŗşţ", "CGKLNRSTcklnrst"},
+	{0xCD, "ŐŰőű", "OUou"},
+	{0xCE, "ĄĘĮŲąęįų", "AEIUaeiu"},
+	{0xCF, "ČĎĚĽŇŘŠŤŽčďěľňřšťž", "CDELNRSTZcdelnrstz"},
+}
+
+// stlSpecialChars ISO 6937 中不在 ASCII 位置的字符
+var stlSpecialChars = map[rune]byte{
+	'$': 0xA4, '¡': 0xA1, '¢': 0xA2, '£': 0xA3, '¥': 0xA5, '§': 0xA7, '‘': 0xA9, '“': 0xAA, '«': 0xAB,
+	'°': 0xB0, '±': 0xB1, '²': 0xB2, '³': 0xB3, '×': 0xB4, 'µ': 0xB5, '¶': 0xB6, '·': 0xB7, '÷': 0xB8,
+	'’': 0xB9, '”': 0xBA, '»': 0xBB, '¼': 0xBC, '½': 0xBD, '¾': 0xBE, '¿': 0xBF,
+	'–': 0xD0, '—': 0xD0, '―': 0xD0, '¹': 0xD1, '®': 0xD2, '©': 0xD3, '™': 0xD4, '♪': 0xD5,
+	'Ω': 0xE0, 'Æ': 0xE1, 'Đ': 0xE2, 'ª': 0xE3, 'Ħ': 0xE4, 'Ĳ': 0xE6, 'Ŀ': 0xE7, 'Ł': 0xE8, 'Ø': 0xE9,
+	'Œ': 0xEA, 'º': 0xEB, 'Þ': 0xEC, 'Ŧ': 0xED, 'Ŋ': 0xEE, 'ŉ': 0xEF, 'ĸ': 0xF0, 'æ': 0xF1, 'đ': 0xF2,
+	'ð': 0xF3, 'ħ': 0xF4, 'ı': 0xF5, 'ĳ': 0xF6, 'ŀ': 0xF7, 'ł': 0xF8, 'ø': 0xF9, 'œ': 0xFA, 'ß': 0xFB,
+	'þ': 0xFC, 'ŧ': 0xFD, 'ŋ': 0xFE,
+}
+
+// validateEBUSTLGSI 检查 ebu_stl_gsi 中的字段名和取值
+func (c *Config) validateEBUSTLGSI() error {
+	for key, value := range c.EBUSTLGSI {
+		limit, ok := stlGSIFields[key]
+		if !ok {
+			return fmt.Errorf(tr("ebu_stl_gsi 中无效的字段: %s（可选 %s）"), key, strings.Join(sortedKeys(stlGSIFields), ", "))
+		}
+		if len(stlASCII(value)) > limit {
+			return fmt.Errorf(tr("ebu_stl_gsi 中的 %s 超过 %d 个字符"), key, limit)
+		}
+		switch {
+		case key == "TCP" && !stlTimecodeField.MatchString(value),
+			key == "LC" && !stlLanguageField.MatchString(value),
+			key == "CO" && !stlCountryField.MatchString(value),
+			key == "RN" && !stlRevisionField.MatchString(value):
+			return fmt.Errorf(tr("ebu_stl_gsi 中的 %s 格式无效: %s"), key, value)
+		}
+	}
+	return nil
+}
+
+// stlFrameRate EBU STL 只支持 25 帧（STL25.01）和 30 帧（STL30.01），按 timecode_fps 选择，默认 25 帧
+func stlFrameRate(config *Config) (frameRate, string, error) {
+	fps := config.TimecodeFPS
+	if fps == "" {
+		fps = defaultTimecodeFPS
+	}
+	switch fps {
+	case "25":
+		return frameRates[fps], "STL25.01", nil
+	case "29.97", "29.97df", "30":
+		return frameRates[fps], "STL30.01", nil
+	}
+	return frameRate{}, "", fmt.Errorf(tr("EBU STL 只支持 25、29.97 和 30 帧，当前 timecode_fps 为 %s"), fps)
+}
+
+// stlTimecode 时间对应的时间码各部分（时、分、秒、帧），超过 24 小时从 0 开始
+func stlTimecode(rate frameRate, seconds float64) [4]byte {
+	var tc [4]byte
+	parts := strings.FieldsFunc(rate.timecode(seconds), func(r rune) bool { return r == ':' || r == ';' })
+	for i, part := range parts[:4] {
+		n, _ := strconv.Atoi(part)
+		tc[i] = byte(n)
+	}
+	tc[0] %= 24
+	return tc
+}
+
+// stlEncodeText 把文本编码为 ISO 6937（字符代码表 00，拉丁字母），返回编码结果和无法编码（替换为 ?）的字符数
+func stlEncodeText(text string) ([]byte, int) {
+	var out []byte
+	unsupported := 0
+	for _, r := range text {
+		switch {
+		case r == '…':
+			out = append(out, "..."...)
+		case r >= 0x20 && r < 0x7F && r != '$':
+			out = append(out, byte(r))
+		case stlSpecialChars[r] != 0:
+			out = append(out, stlSpecialChars[r])
+		default:
+			if code, base, ok := stlDecompose(r); ok {
+				out = append(out, code, base)
+				continue
+			}
+			out = append(out, '?')
+			unsupported++
+		}
+	}
+	return out, unsupported
+}
+
+// stlDecompose 带变音符号的字母拆分为 ISO 6937 的变音符号和基本字母
+func stlDecompose(r rune) (byte, byte, bool) {
+	for _, d := range stlDiacritics {
+		if i := strings.IndexRune(d.composed, r); i >= 0 {
+			return d.code, d.base[len([]rune(d.composed[:i]))], true
+		}
+	}
+	return 0, 0, false
+}
+
+// stlASCII GSI 中的文本字段（代码页 850）只写入 ASCII：带变音符号的字母去掉变音符号，其他字符替换为 ?
+func stlASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r < 0x7F:
+			b.WriteRune(r)
+		default:
+			if _, base, ok := stlDecompose(r); ok {
+				b.WriteByte(base)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// stlWrapLines 按空白把文本折成每行不超过 stlMaxRowChars 个字符，保留原有的换行
+func stlWrapLines(text string) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && len([]rune(line))+1+len([]rune(word)) > stlMaxRowChars {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// stlTextField 一条字幕的文本字段：每行为双倍高度并加上开始框和结束框（图文电视字幕需要框住才会叠加显示），
+// 行之间用两个换行分隔（双倍高度占两行）
+func stlTextField(lines []string) ([]byte, int) {
+	var field []byte
+	unsupported := 0
+	for i, line := range lines {
+		if i > 0 {
+			field = append(field, stlNewline, stlNewline)
+		}
+		text, n := stlEncodeText(line)
+		unsupported += n
+		field = append(field, stlDoubleHeight, stlStartBox, stlStartBox)
+		field = append(field, text...)
+		field = append(field, stlEndBox, stlEndBox)
+	}
+	return field, unsupported
+}
+
+// stlPutField 把字段值写入 GSI 块中的指定位置，不足部分补空格
+func stlPutField(block []byte, offset, size int, value string) {
+	copy(block[offset:offset+size], bytes.Repeat([]byte{' '}, size))
+	copy(block[offset:offset+size], value)
+}
+
+// saveEBUSTL 保存为 EBU STL 二进制字幕（图文电视 1 级，拉丁字母），GSI 元数据取自 ebu_stl_gsi，
+// 帧率按 timecode_fps（25 或 30 帧）。超过 112 字节的字幕使用扩展块
+func saveEBUSTL(result *TranscriptionResult, config *Config, outputPath string) error {
+	rate, dfc, err := stlFrameRate(config)
+	if err != nil {
+		return err
+	}
+
+	var tti []byte
+	subtitles, blocks, unsupported := 0, 0, 0
+	var firstCue [4]byte
+	for _, seg := range jsonlSegments(result) {
+		lines := stlWrapLines(strings.TrimSpace(seg.Text))
+		if len(lines) == 0 || seg.End <= seg.Start {
+			continue
+		}
+		if subtitles == 0xFFFF {
+			return errors.New(tr("EBU STL 最多支持 65535 条字幕"))
+		}
+		field, n := stlTextField(lines)
+		unsupported += n
+		tci, tco := stlTimecode(rate, seg.Start), stlTimecode(rate, seg.End)
+		if subtitles == 0 {
+			firstCue = tci
+		}
+		// 双倍高度每行占两行，最后一行在 stlBottomRow
+		vp := max(stlBottomRow-2*(len(lines)-1), 1)
+
+		// 扩展块编号为 0x00-0xEF，最后一块为 0xFF
+		var chunks [][]byte
+		for len(field) > 0 && len(chunks) < 0xF0 {
+			chunk := field[:min(len(field), stlTextFieldSize)]
+			field = field[len(chunk):]
+			chunks = append(chunks, chunk)
+		}
+		for ebn, chunk := range chunks {
+			block := make([]byte, stlTTISize)
+			block[0] = 0 // SGN
+			binary.LittleEndian.PutUint16(block[1:3], uint16(subtitles))
+			block[3] = byte(ebn) // EBN
+			if ebn == len(chunks)-1 {
+				block[3] = 0xFF
+			}
+			block[4] = 0 // CS
+			copy(block[5:9], tci[:])
+			copy(block[9:13], tco[:])
+			block[13] = byte(vp)
+			block[14] = 2 // JC：居中
+			block[15] = 0 // CF
+			copy(block[16:], bytes.Repeat([]byte{stlUnused}, stlTextFieldSize))
+			copy(block[16:], chunk)
+			tti = append(tti, block...)
+			blocks++
+		}
+		subtitles++
+	}
+	if unsupported > 0 {
+		logWarn(tr("EBU STL 只支持拉丁字母，%d 个字符无法编码，已替换为 ?"), unsupported)
+	}
+
+	gsi := config.EBUSTLGSI
+	lc := gsi["LC"]
+	if lc == "" {
+		if lc = stlLanguageCodes[languageCode(result.Language)]; lc == "" {
+			lc = "00"
+		}
+	}
+	tcp := gsi["TCP"]
+	if tcp == "" {
+		tcp = "00000000"
+	}
+	rn, _ := strconv.Atoi(gsi["RN"])
+	now := time.Now().Format("060102")
+	tcf := fmt.Sprintf("%02d%02d%02d%02d", firstCue[0], firstCue[1], firstCue[2], firstCue[3])
+
+	header := make([]byte, stlGSISize)
+	fields := []struct {
+		offset, size int
+		value        string
+	}{
+		{0, 3, "850"},                                 // CPN 代码页
+		{3, 8, dfc},                                   // DFC 帧率
+		{11, 1, "1"},                                  // DSC 图文电视 1 级
+		{12, 2, "00"},                                 // CCT 拉丁字母
+		{14, 2, strings.ToUpper(lc)},                  // LC 语言
+		{16, 32, stlASCII(gsi["OPT"])},                // OPT
+		{48, 32, stlASCII(gsi["OET"])},                // OET
+		{80, 32, stlASCII(gsi["TPT"])},                // TPT
+		{112, 32, stlASCII(gsi["TET"])},               // TET
+		{144, 32, stlASCII(gsi["TN"])},                // TN
+		{176, 32, stlASCII(gsi["TCD"])},               // TCD
+		{208, 16, stlASCII(gsi["SLR"])},               // SLR
+		{224, 6, now},                                 // CD 创建日期
+		{230, 6, now},                                 // RD 修订日期
+		{236, 2, fmt.Sprintf("%02d", rn)},             // RN 修订号
+		{238, 5, fmt.Sprintf("%05d", blocks)},         // TNB TTI 块数
+		{243, 5, fmt.Sprintf("%05d", subtitles)},      // TNS 字幕数
+		{248, 3, "001"},                               // TNG 字幕组数
+		{251, 2, fmt.Sprintf("%02d", stlMaxRowChars)}, // MNC
+		{253, 2, fmt.Sprintf("%02d", stlMaxRows)},     // MNR
+		{255, 1, "1"},                                 // TCS 时间码用于播出
+		{256, 8, tcp},                                 // TCP 节目开始时间码
+		{264, 8, tcf},                                 // TCF 第一条字幕时间码
+		{272, 1, "1"},                                 // TND 盘数
+		{273, 1, "1"},                                 // DSN 盘序号
+		{274, 3, strings.ToUpper(gsi["CO"])},          // CO 原产国
+		{277, 32, stlASCII(gsi["PUB"])},               // PUB
+		{309, 32, stlASCII(gsi["EN"])},                // EN
+		{341, 32, stlASCII(gsi["ECD"])},               // ECD
+		{373, 75, ""},                                 // 保留
+		{448, 576, ""},                                // UDA 用户定义区
+	}
+	for _, f := range fields {
+		stlPutField(header, f.offset, f.size, f.value)
+	}
+
+	return os.WriteFile(outputPath, append(header, tti...), 0644)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSTLEncodeText(t *testing.T) {
+	tests := []struct {
+		name            string
+		in              string
+		want            []byte
+		wantUnsupported int
+	}{
+		{"ASCII", "Hi!", []byte("Hi!"), 0},
+		{"变音符号", "é", []byte{0xC2, 'e'}, 0},
+		{"变音符号大写", "Ü", []byte{0xC8, 'U'}, 0},
+		{"特殊字符", "£5", []byte{0xA3, '5'}, 0},
+		{"美元符号", "$", []byte{0xA4}, 0},
+		{"省略号", "…", []byte("..."), 0},
+		{"无法编码", "中a", []byte("?a"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n := stlEncodeText(tt.in)
+			if !bytes.Equal(got, tt.want) || n != tt.wantUnsupported {
+				t.Errorf("stlEncodeText(%q) = % x, %d, want % x, %d", tt.in, got, n, tt.want, tt.wantUnsupported)
+			}
+		})
+	}
+}
+
+func TestSTLWrapLines(t *testing.T) {
+	long := strings.Repeat("word ", 12) // 60 个字符
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"短文本", "hello world", []string{"hello world"}},
+		{"保留换行", "one\ntwo", []string{"one", "two"}},
+		{"按宽度折行", long, []string{strings.TrimSpace(strings.Repeat("word ", 8)), strings.TrimSpace(strings.Repeat("word ", 4))}},
+		{"空文本", "  ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stlWrapLines(tt.in)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("stlWrapLines(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateEBUSTLGSI(t *testing.T) {
+	tests := []struct {
+		name    string
+		gsi     map[string]string
+		wantErr bool
+	}{
+		{"有效", map[string]string{"OPT": "News", "TCP": "10000000", "LC": "0A", "CO": "GBR", "RN": "1"}, false},
+		{"未知字段", map[string]string{"XYZ": "1"}, true},
+		{"超长", map[string]string{"RN": "123"}, true},
+		{"时间码格式", map[string]string{"TCP": "10:00:00:00"}, true},
+		{"语言代码格式", map[string]string{"LC": "en"}, true},
+		{"国家代码格式", map[string]string{"CO": "G1B"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{EBUSTLGSI: tt.gsi}
+			if err := config.validateEBUSTLGSI(); (err != nil) != tt.wantErr {
+				t.Errorf("validateEBUSTLGSI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveEBUSTL(t *testing.T) {
+	result := &TranscriptionResult{
+		Language: "english",
+		Segments: []Segment{
+			{Start: 1.5, End: 2.5, Text: "Café"},
+			{Start: 3, End: 3, Text: "zero length"},
+			{Start: 4, End: 6, Text: strings.Repeat("subtitle ", 20)},
+		},
+	}
+	tests := []struct {
+		name    string
+		fps     string
+		gsi     map[string]string
+		wantDFC string
+		wantLC  string
+		wantTCF string
+		wantTCI [4]byte
+	}{
+		{"默认 25 帧", "", nil, "STL25.01", "09", "00000113", [4]byte{0, 0, 1, 13}},
+		{"30 帧和自定义语言", "30", map[string]string{"LC": "0f", "OPT": "Émission"}, "STL30.01", "0F", "00000115", [4]byte{0, 0, 1, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.stl")
+			config := &Config{TimecodeFPS: tt.fps, EBUSTLGSI: tt.gsi}
+			if err := saveEBUSTL(result, config, path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (len(data)-stlGSISize)%stlTTISize != 0 {
+				t.Fatalf("file size %d is not GSI + n*TTI", len(data))
+			}
+			gsi, tti := data[:stlGSISize], data[stlGSISize:]
+			field := func(offset, size int) string { return string(gsi[offset : offset+size]) }
+
+			if got := field(0, 3); got != "850" {
+				t.Errorf("CPN = %q", got)
+			}
+			if got := field(3, 8); got != tt.wantDFC {
+				t.Errorf("DFC = %q, want %q", got, tt.wantDFC)
+			}
+			if got := field(14, 2); got != tt.wantLC {
+				t.Errorf("LC = %q, want %q", got, tt.wantLC)
+			}
+			if got := strings.TrimRight(field(16, 32), " "); got != stlASCII(tt.gsi["OPT"]) {
+				t.Errorf("OPT = %q, want %q", got, stlASCII(tt.gsi["OPT"]))
+			}
+			// 零长度的字幕被跳过，超过 112 字节的字幕使用扩展块
+			if got := field(243, 5); got != "00002" {
+				t.Errorf("TNS = %q, want 00002", got)
+			}
+			if got, blocks := field(238, 5), len(tti)/stlTTISize; got != "00003" || blocks != 3 {
+				t.Fatalf("TNB = %q with %d blocks, want 00003", got, blocks)
+			}
+			if got := field(264, 8); got != tt.wantTCF {
+				t.Errorf("TCF = %q, want %q", got, tt.wantTCF)
+			}
+
+			first := tti[:stlTTISize]
+			if sn := binary.LittleEndian.Uint16(first[1:3]); sn != 0 || first[3] != 0xFF {
+				t.Errorf("first TTI SN = %d, EBN = %#x", sn, first[3])
+			}
+			if tci := [4]byte(first[5:9]); tci != tt.wantTCI {
+				t.Errorf("TCI = %v, want %v", tci, tt.wantTCI)
+			}
+			text := []byte{stlDoubleHeight, stlStartBox, stlStartBox, 'C', 'a', 'f', 0xC2, 'e', stlEndBox, stlEndBox, stlUnused}
+			if !bytes.HasPrefix(first[16:], text) {
+				t.Errorf("TF = % x, want prefix % x", first[16:16+len(text)], text)
+			}
+
+			second, third := tti[stlTTISize:2*stlTTISize], tti[2*stlTTISize:]
+			if binary.LittleEndian.Uint16(second[1:3]) != 1 || binary.LittleEndian.Uint16(third[1:3]) != 1 {
+				t.Errorf("extension blocks should share SN 1")
+			}
+			if second[3] != 0 || third[3] != 0xFF {
+				t.Errorf("EBN = %#x, %#x, want 0x00, 0xff", second[3], third[3])
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// textSubtitleCodecs 可以转换为 SRT 的文本字幕编码；PGS、VobSub 等图像字幕需要 OCR，不使用
+var textSubtitleCodecs = map[string]bool{
+	"subrip": true, "srt": true, "ass": true, "ssa": true,
+	"mov_text": true, "webvtt": true, "text": true,
+}
+
+// subtitleMarkup ffmpeg 转换为 SRT 后残留的样式标签（<i>、<font>、ASS 的 {\an8} 等）
+var subtitleMarkup = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+
+// embeddedTrack 媒体文件中已有的字幕轨
+type embeddedTrack struct {
+	Index     int    `json:"index"`
+	CodecName string `json:"codec_name"`
+	Tags      struct {
+		Language string `json:"language"`
+		Title    string `json:"title"`
+	} `json:"tags"`
+	Disposition struct {
+		Default int `json:"default"`
+		Forced  int `json:"forced"`
+	} `json:"disposition"`
+}
+
+// String 字幕轨的显示名称，如 #2 eng subrip（English）
+func (t embeddedTrack) String() string {
+	s := fmt.Sprintf("#%d %s %s", t.Index, t.Tags.Language, t.CodecName)
+	if t.Tags.Title != "" {
+		s += fmt.Sprintf(tr("（%s）"), t.Tags.Title)
+	}
+	return s
+}
+
+// probeSubtitleTracks 用 ffprobe 列出媒体文件中的字幕轨
+func probeSubtitleTracks(inputFile string) ([]embeddedTrack, error) {
+	out, err := exec.Command(ffmpegTools.FFprobe,
+		"-v", "error",
+		"-select_streams", "s",
+		"-show_entries", "stream=index,codec_name:stream_tags=language,title:stream_disposition=default,forced",
+		"-of", "json",
+		inputFile,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取字幕轨失败: %w"), err)
+	}
+	var probe struct {
+		Streams []embeddedTrack `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf(tr("读取字幕轨失败: %w"), err)
+	}
+	return probe.Streams, nil
+}
+
+// selectSubtitleTrack 选择可以代替转写的字幕轨：只考虑文本字幕，跳过只含部分台词的强制字幕；
+// 指定了语言时只使用该语言的字幕轨，自动检测时优先使用默认字幕轨
+func selectSubtitleTrack(tracks []embeddedTrack, config *Config) (embeddedTrack, bool) {
+	var candidates []embeddedTrack
+	for _, t := range tracks {
+		if !textSubtitleCodecs[t.CodecName] || t.Disposition.Forced == 1 {
+			continue
+		}
+		if !config.AutoDetect && config.Language != "" && !subtitleLanguageMatches(t.Tags.Language, config.Language) {
+			continue
+		}
+		candidates = append(candidates, t)
+	}
+	if len(candidates) == 0 {
+		return embeddedTrack{}, false
+	}
+	for _, t := range candidates {
+		if t.Disposition.Default == 1 {
+			return t, true
+		}
+	}
+	return candidates[0], true
+}
+
+// subtitleLanguageMatches 字幕轨的语言标签（ISO 639-2，如 chi、zho、eng）是否为指定的语言
+func subtitleLanguageMatches(tag, language string) bool {
+	tag, language = strings.ToLower(tag), strings.ToLower(language)
+	if tag == "" || tag == "und" {
+		return false
+	}
+	// ISO 639-2 的 B 和 T 两套代码，iso6392 返回的是 B 代码
+	terminology := map[string]string{"zho": "chi", "fra": "fre", "deu": "ger"}
+	if b, ok := terminology[tag]; ok {
+		tag = b
+	}
+	return tag == language || tag == iso6392(language)
+}
+
+// embeddedSubtitles 开启 embedded_subtitles 时读取视频中已有的文本字幕，转换为转写结果；
+// 没有合适的字幕轨或读取失败时返回 nil，照常转写
+func embeddedSubtitles(inputFile string, config *Config) *TranscriptionResult {
+	if !config.EmbeddedSubtitles || !isVideoFile(inputFile) {
+		return nil
+	}
+	tracks, err := probeSubtitleTracks(inputFile)
+	if err != nil {
+		logWarn(tr("%v，照常转写"), err)
+		return nil
+	}
+	track, ok := selectSubtitleTrack(tracks, config)
+	if !ok {
+		if len(tracks) > 0 {
+			logInfo(tr("%s 的 %d 个字幕轨都不可用（图像字幕、强制字幕或语言不符），照常转写\n"), inputFile, len(tracks))
+		}
+		return nil
+	}
+
+	result, err := extractSubtitleTrack(inputFile, track)
+	if err != nil {
+		logWarn(tr("提取字幕轨 %s 失败，照常转写: %v"), track, err)
+		return nil
+	}
+	if !config.AutoDetect && config.Language != "" {
+		result.Language = config.Language
+	}
+	logInfo(tr("使用内嵌字幕轨 %s，共 %d 条字幕，不调用 API（--force-transcribe 强制转写）\n"), track, len(result.Segments))
+	return result
+}
+
+// extractSubtitleTrack 用 ffmpeg 把字幕轨转换为 SRT 并解析为转写结果
+func extractSubtitleTrack(inputFile string, track embeddedTrack) (*TranscriptionResult, error) {
+	args := ffmpegArgs(inputFile,
+		"-map", fmt.Sprintf("0:%d", track.Index),
+		"-f", "srt",
+		"pipe:1",
+	)
+	cmd := exec.Command(ffmpegTools.FFmpeg, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf(tr("ffmpeg 提取字幕失败: %w: %s"), err, lastLine(stderr.String()))
+	}
+	cues, err := parseSRT(string(out))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TranscriptionResult{Language: track.Tags.Language}
+	for _, cue := range cues {
+		// 多行字幕合并为一行，去掉样式标签
+		var text string
+		for _, line := range strings.Split(subtitleMarkup.ReplaceAllString(cue.Text, ""), "\n") {
+			text = joinSegmentText(text, line)
+		}
+		if text == "" {
+			continue
+		}
+		cue.ID = len(result.Segments) + 1
+		cue.Text = text
+		result.Segments = append(result.Segments, cue)
+		result.Text = joinSegmentText(result.Text, text)
+	}
+	if len(result.Segments) == 0 {
+		return nil, errors.New(tr("字幕轨中没有字幕"))
+	}
+	return result, nil
+}
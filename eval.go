@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+)
+
+// evalOptions eval 子命令比较前的文本规范化选项
+type evalOptions struct {
+	KeepCase  bool   // 区分大小写
+	KeepPunct bool   // 保留标点（每个标点计为一个词）
+	Chinese   string // 比较前统一转换为 simplified 或 traditional
+}
+
+// errorRate 一种错误率（WER 或 CER）：最小编辑距离中的替换、删除、插入数与参考长度之比
+type errorRate struct {
+	Rate          float64 `json:"rate"`
+	Substitutions int     `json:"substitutions"`
+	Deletions     int     `json:"deletions"`
+	Insertions    int     `json:"insertions"`
+	Reference     int     `json:"reference_length"`
+}
+
+// evalResult 一个转写文件相对参考文本的评估结果
+type evalResult struct {
+	Transcript string    `json:"transcript"`
+	WER        errorRate `json:"wer"`
+	CER        errorRate `json:"cer"`
+}
+
+// runEval 执行 eval 子命令：把一个或多个转写结果与参考文本比较，计算词错误率（WER）和字错误率（CER），
+// 便于在自己的素材上量化比较不同模型和设置
+func runEval(args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	reference := fs.String("ref", "", tr("参考文本（.txt, .srt 或 .json）"))
+	keepCase := fs.Bool("keep-case", false, tr("区分大小写（默认忽略）"))
+	keepPunct := fs.Bool("keep-punct", false, tr("保留标点（默认忽略）"))
+	chinese := fs.String("chinese", "", tr("比较前将中文统一转换为简体或繁体（simplified, traditional）"))
+	asJSON := fs.Bool("json", false, tr("以 JSON 输出"))
+	fs.Parse(args)
+
+	if *reference == "" || fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go eval -ref <reference> [options] <transcripts...>"))
+		fmt.Println(tr("示例: whisper-go eval -ref truth.txt outputs/small/talk.json outputs/large/talk.json"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(exitBadInput)
+	}
+	switch *chinese {
+	case "", chineseSimplified, chineseTraditional:
+	default:
+		exitWith(exitBadInput, tr("无效的 -chinese 参数: %s（可选 simplified, traditional）"), *chinese)
+	}
+	opts := evalOptions{KeepCase: *keepCase, KeepPunct: *keepPunct, Chinese: *chinese}
+
+	refText, err := readEvalText(*reference)
+	if err != nil {
+		exitWith(exitBadInput, tr("读取 %s 失败: %v"), *reference, err)
+	}
+	refWords, refChars := evalTokens(refText, opts)
+	if len(refWords) == 0 {
+		exitWith(exitBadInput, tr("参考文本为空: %s"), *reference)
+	}
+
+	var results []evalResult
+	for _, path := range fs.Args() {
+		text, err := readEvalText(path)
+		if err != nil {
+			exitWith(exitBadInput, tr("读取 %s 失败: %v"), path, err)
+		}
+		words, chars := evalTokens(text, opts)
+		results = append(results, evalResult{
+			Transcript: path,
+			WER:        editRate(refWords, words),
+			CER:        editRate(refChars, chars),
+		})
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf(tr("参考: %s（%d 词，%d 字符）\n"), *reference, len(refWords), len(refChars))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("文件\tWER\t替换/删除/插入\tCER\t替换/删除/插入"))
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.2f%%\t%d/%d/%d\t%.2f%%\t%d/%d/%d\n", r.Transcript,
+			100*r.WER.Rate, r.WER.Substitutions, r.WER.Deletions, r.WER.Insertions,
+			100*r.CER.Rate, r.CER.Substitutions, r.CER.Deletions, r.CER.Insertions)
+	}
+	w.Flush()
+}
+
+// readEvalText 读取转写结果或参考文本的全文：JSON 和 SRT 取分段文本，其他文件按纯文本读取
+func readEvalText(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".srt":
+		result, err := readPartFile(path)
+		if err != nil {
+			return "", err
+		}
+		if result.Text == "" {
+			rebuildResultText(result)
+		}
+		return result.Text, nil
+	default:
+		data, err := os.ReadFile(path)
+		return strings.TrimPrefix(string(data), "\ufeff"), err
+	}
+}
+
+// evalTokens 规范化文本后切分为词和字符：中日韩文字每个字计为一个词（与 stats 的词数统计一致），
+// 其他语言按连续的字母数字切分；字符序列不含空白
+func evalTokens(text string, opts evalOptions) (words, chars []string) {
+	if opts.Chinese != "" {
+		text = getChineseConverter(opts.Chinese).convert(text)
+	}
+	text = foldFullWidth(text)
+	if !opts.KeepCase {
+		text = strings.ToLower(text)
+	}
+
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		isCJK := unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+		switch {
+		case isCJK:
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || (word.Len() > 0 && (r == '\'' || r == '’')):
+			word.WriteRune(r)
+		case unicode.IsSpace(r):
+			flush()
+			continue
+		default:
+			flush()
+			if !opts.KeepPunct {
+				continue
+			}
+			words = append(words, string(r))
+		}
+		chars = append(chars, string(r))
+	}
+	flush()
+	return words, chars
+}
+
+// foldFullWidth 把全角字母、数字、标点和空格转换为半角，避免全半角差异计为错误
+func foldFullWidth(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u3000':
+			return ' '
+		case r >= '\uff01' && r <= '\uff5e':
+			return r - 0xfee0
+		}
+		return r
+	}, text)
+}
+
+// editRate 计算 hyp 相对 ref 的最小编辑距离，并统计其中的替换、删除和插入数。
+// 只保留两行动态规划表，长文本也不会占用过多内存
+func editRate(ref, hyp []string) errorRate {
+	type cell struct{ cost, sub, del, ins int }
+	prev := make([]cell, len(hyp)+1)
+	curr := make([]cell, len(hyp)+1)
+	for j := range prev {
+		prev[j] = cell{cost: j, ins: j}
+	}
+	for i := 1; i <= len(ref); i++ {
+		curr[0] = cell{cost: i, del: i}
+		for j := 1; j <= len(hyp); j++ {
+			if ref[i-1] == hyp[j-1] {
+				curr[j] = prev[j-1]
+				continue
+			}
+			best := prev[j-1]
+			best.sub++
+			if d := prev[j]; d.cost < best.cost {
+				best = d
+				best.del++
+			}
+			if n := curr[j-1]; n.cost < best.cost {
+				best = n
+				best.ins++
+			}
+			best.cost++
+			curr[j] = best
+		}
+		prev, curr = curr, prev
+	}
+
+	last := prev[len(hyp)]
+	rate := errorRate{Substitutions: last.sub, Deletions: last.del, Insertions: last.ins, Reference: len(ref)}
+	if len(ref) > 0 {
+		rate.Rate = float64(last.cost) / float64(len(ref))
+	}
+	return rate
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestEditRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		ref, hyp  []string
+		want      errorRate
+		wantRatio float64
+	}{
+		{"相同", []string{"a", "b", "c"}, []string{"a", "b", "c"}, errorRate{Reference: 3}, 0},
+		{"替换", []string{"a", "b", "c"}, []string{"a", "x", "c"}, errorRate{Substitutions: 1, Reference: 3}, 1.0 / 3},
+		{"删除", []string{"a", "b", "c"}, []string{"a", "c"}, errorRate{Deletions: 1, Reference: 3}, 1.0 / 3},
+		{"插入", []string{"a", "b"}, []string{"a", "x", "b"}, errorRate{Insertions: 1, Reference: 2}, 0.5},
+		{"混合", []string{"the", "cat", "sat", "down"}, []string{"a", "cat", "sat", "down", "now"}, errorRate{Substitutions: 1, Insertions: 1, Reference: 4}, 0.5},
+		{"插入多于参考时超过 1", []string{"a"}, []string{"x", "y", "z"}, errorRate{Substitutions: 1, Insertions: 2, Reference: 1}, 3},
+		{"空参考", nil, []string{"a"}, errorRate{Insertions: 1}, 0},
+		{"空结果", []string{"a", "b"}, nil, errorRate{Deletions: 2, Reference: 2}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := editRate(tt.ref, tt.hyp)
+			if math.Abs(got.Rate-tt.wantRatio) > 1e-9 {
+				t.Errorf("Rate = %v, want %v", got.Rate, tt.wantRatio)
+			}
+			got.Rate = 0
+			if got != tt.want {
+				t.Errorf("editRate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		opts      evalOptions
+		wantWords []string
+		wantChars int
+	}{
+		{"英文忽略大小写和标点", "Hello, World!", evalOptions{}, []string{"hello", "world"}, 10},
+		{"保留大小写", "Hello World", evalOptions{KeepCase: true}, []string{"Hello", "World"}, 10},
+		{"保留标点", "Hi, there.", evalOptions{KeepPunct: true}, []string{"hi", ",", "there", "."}, 9},
+		{"撇号", "don't stop", evalOptions{}, []string{"don't", "stop"}, 9},
+		{"中文逐字", "你好，世界", evalOptions{}, []string{"你", "好", "世", "界"}, 4},
+		{"中英混合", "用GPT4写代码", evalOptions{}, []string{"用", "gpt4", "写", "代", "码"}, 8},
+		{"全角转半角", "ＡＢＣ　１２３", evalOptions{}, []string{"abc", "123"}, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, chars := evalTokens(tt.text, tt.opts)
+			if !slices.Equal(words, tt.wantWords) {
+				t.Errorf("words = %q, want %q", words, tt.wantWords)
+			}
+			if len(chars) != tt.wantChars {
+				t.Errorf("len(chars) = %d, want %d (%q)", len(chars), tt.wantChars, chars)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+)
+
+// 退出码，脚本可据此区分失败原因（中断退出的 130/143 见 shutdown.go）
+const (
+	exitOK         = 0
+	exitFailure    = 1 // 其他错误
+	exitBadInput   = 2 // 输入文件不存在或无法解码、命令行参数无效
+	exitConfig     = 3 // 配置文件、单文件配置或配置项错误
+	exitAPIFailure = 4 // 转写服务调用失败
+	exitPartial    = 5 // 转写完成，但部分输出格式或后置命令失败
+	exitBudget     = 6 // 开始转写前发现会超出月度预算
+)
+
+// exitCodeError 携带退出码的错误，被 %w 包装后仍可通过 exitCodeOf 识别
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// withExitCode 为错误标记退出码，err 为空时返回 nil
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitCodeError{code: code, err: err}
+}
+
+// exitCodeOf 返回错误链中最外层标记的退出码，没有标记时为 exitFailure
+func exitCodeOf(err error) int {
+	var coded *exitCodeError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return exitFailure
+}
+
+// partial 转写成功但有输出格式保存失败或后置命令失败
+func (r *TranscriptionResult) partial() bool {
+	return r.FailedOutputs > 0 || len(r.HookFailures) > 0
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// defaultFallbackCooldown 失败的后端被跳过的默认时长（秒），之后重新尝试
+const defaultFallbackCooldown = 300
+
+// BackendFallback fallback_backends 中的一个备用后端，未设置的字段沿用主配置。
+// provider 与主配置不同时地址和模型使用该服务商的默认值；只有 provider 和地址都不变时才沿用主配置的 api_key，避免把密钥发给其他服务
+type BackendFallback struct {
+	Provider   string `json:"provider,omitempty"`
+	APIBaseURL string `json:"api_base_url,omitempty"`
+	APIKey     string `json:"api_key,omitempty"`
+	Model      string `json:"model,omitempty"`
+}
+
+// BackendUsage 输出元数据中的一条记录：[Start, End] 这段音频（一个切片）由哪个后端转写
+type BackendUsage struct {
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Provider string  `json:"provider"`
+	Model    string  `json:"model"`
+	BaseURL  string  `json:"base_url,omitempty"`
+}
+
+// backendHealth 主后端和各备用后端的失败状态，由同一配置复制出的所有任务共享
+type backendHealth struct {
+	mu        sync.Mutex
+	downUntil map[int]time.Time
+}
+
+// order 返回本次尝试的后端顺序（0 为主后端，i 为第 i 个备用后端）：可用的按配置顺序在前，冷却中的排在最后，全部失败时仍会逐个尝试
+func (h *backendHealth) order(count int, now time.Time) []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var healthy, down []int
+	for i := 0; i < count; i++ {
+		if now.Before(h.downUntil[i]) {
+			down = append(down, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// markDown 将后端标记为失败，cooldown 内优先使用其他后端
+func (h *backendHealth) markDown(i int, cooldown time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.downUntil[i] = time.Now().Add(cooldown)
+}
+
+// validateFallbackBackends 校验 fallback_backends 并初始化共享的失败状态，在 applyDefaults 末尾调用
+func (c *Config) validateFallbackBackends() error {
+	if c.FallbackCooldown <= 0 {
+		c.FallbackCooldown = defaultFallbackCooldown
+	}
+	if len(c.FallbackBackends) == 0 {
+		return nil
+	}
+	for i := range c.FallbackBackends {
+		backend, err := c.fallbackConfig(i)
+		// 沿用主配置的后端缺少 api_key 时由主配置的检查报告
+		switched := backend != nil && (backend.Provider != c.Provider || backend.APIBaseURL != c.APIBaseURL)
+		if err == nil && switched && backend.needsAPIKey() && backend.APIKey == "" {
+			err = errors.New(tr("缺少 api_key（切换服务商或接口地址时需要单独配置）"))
+		}
+		if err != nil {
+			return fmt.Errorf(tr("fallback_backends 第 %d 项无效: %w"), i+1, err)
+		}
+	}
+	c.backendHealth = &backendHealth{downUntil: make(map[int]time.Time)}
+	return nil
+}
+
+// fallbackConfig 生成第 i 个备用后端的配置
+func (c *Config) fallbackConfig(i int) (*Config, error) {
+	return c.backendConfig(c.FallbackBackends[i])
+}
+
+// backendConfig 生成使用另一个后端的配置：复制当前配置（保留语言、提示词等任务参数），再覆盖后端相关字段，
+// 按主配置的规则填入服务商默认值、校验模型并重建代理和证书设置。切换到 openai 且未设置地址时使用官方接口和 whisper-1
+func (c *Config) backendConfig(fb BackendFallback) (*Config, error) {
+	backend := *c
+	backend.FallbackBackends = nil
+	backend.backendHealth = nil
+	if fb.Provider != "" && fb.Provider != c.Provider {
+		backend.Provider = fb.Provider
+		backend.APIBaseURL = ""
+		backend.APIKey = ""
+		backend.Model = ""
+		backend.MaxFileSizeMB = 0
+	}
+	if fb.APIBaseURL != "" && fb.APIBaseURL != backend.APIBaseURL {
+		backend.APIBaseURL = fb.APIBaseURL
+		backend.APIKey = ""
+	}
+	if fb.APIKey != "" {
+		backend.APIKey = fb.APIKey
+	}
+	if fb.Model != "" {
+		backend.Model = fb.Model
+	}
+	if backend.Provider == providerOpenAI && backend.Provider != c.Provider && backend.APIBaseURL == "" {
+		backend.APIBaseURL = openAIDefaultBaseURL
+		if backend.Model == "" {
+			backend.Model = "whisper-1"
+		}
+	}
+	if err := backend.applyProviderDefaults(); err != nil {
+		return nil, err
+	}
+	if backend.Model == "" {
+		backend.Model = "whisper-large-v3"
+	}
+	if backend.MaxFileSizeMB == 0 {
+		backend.MaxFileSizeMB = c.MaxFileSizeMB
+	}
+	if backend.APIKey != c.APIKey {
+		// key 池只属于主配置的 api_key
+		backend.APIKeys = nil
+		backend.apiKeys = nil
+	}
+	if err := backend.buildAPITransport(); err != nil {
+		return nil, err
+	}
+	return &backend, nil
+}
+
+// isFailoverError 判断错误是否应切换到下一个后端：认证失败、模型不存在、限流或服务端错误（已按 max_retries 重试）以及网络中断。
+// 请求本身有问题（如 400、413）时换后端也无济于事，交给调用方处理
+func isFailoverError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch httpStatusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return isRetryableError(err)
+}
+
+// describeBackend 日志中显示的后端名称
+func describeBackend(config *Config) string {
+	if config.APIBaseURL == "" || config.Provider == providerWhisperCpp {
+		return fmt.Sprintf("%s/%s", config.Provider, config.Model)
+	}
+	return fmt.Sprintf("%s/%s (%s)", config.Provider, config.Model, config.APIBaseURL)
+}
+
+// transcribeWithBackends 依次使用主后端和 fallback_backends 转写音频：当前后端持续失败时切换到下一个，
+// 失败的后端在 fallback_cooldown 秒内排到最后。配置了备用后端时结果的 Backends 记录实际使用的后端
+func transcribeWithBackends(client *openai.Client, audioPath string, config *Config, verbose bool) (*TranscriptionResult, error) {
+	if len(config.FallbackBackends) == 0 || config.backendHealth == nil {
+		return transcribeWithFallback(client, audioPath, config, verbose)
+	}
+
+	var lastErr error
+	order := config.backendHealth.order(len(config.FallbackBackends)+1, time.Now())
+	for n, i := range order {
+		backendConfig, backendClient := config, client
+		if i > 0 {
+			var err error
+			backendConfig, err = config.fallbackConfig(i - 1)
+			if err != nil {
+				return nil, err
+			}
+			backendClient = newClient(backendConfig)
+		}
+		if n > 0 {
+			logWarn(tr("切换到备用后端 %s\n"), describeBackend(backendConfig))
+		}
+
+		result, err := transcribeWithFallback(backendClient, audioPath, backendConfig, verbose)
+		if err == nil {
+			result.Backends = []BackendUsage{newBackendUsage(result, backendConfig)}
+			return result, nil
+		}
+		if !isFailoverError(err) {
+			return nil, err
+		}
+		logWarn(tr("后端 %s 转写失败: %v\n"), describeBackend(backendConfig), err)
+		config.backendHealth.markDown(i, time.Duration(config.FallbackCooldown*float64(time.Second)))
+		lastErr = err
+	}
+	return nil, fmt.Errorf(tr("所有转写后端均失败: %w"), lastErr)
+}
+
+// newBackendUsage 生成整段结果的后端记录，时长未知时以最后一个分段的结束时间为准
+func newBackendUsage(result *TranscriptionResult, config *Config) BackendUsage {
+	end := result.Duration
+	if end == 0 && len(result.Segments) > 0 {
+		end = result.Segments[len(result.Segments)-1].End
+	}
+	usage := BackendUsage{End: end, Provider: config.Provider, Model: config.Model}
+	if config.Provider != providerWhisperCpp {
+		usage.BaseURL = config.APIBaseURL
+	}
+	return usage
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"path/filepath"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// compressionRatio 计算文本的压缩比（与 Whisper 参考实现一致），重复循环的文本压缩比会明显偏高
+func compressionRatio(text string) float64 {
+	if text == "" {
+		return 0
+	}
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(text))
+	w.Close()
+	return float64(len(text)) / float64(buf.Len())
+}
+
+// averageLogprob 按分段时长加权的平均对数概率，后端未返回时为 0
+func averageLogprob(result *TranscriptionResult) float64 {
+	var sum, total float64
+	for _, seg := range result.Segments {
+		if seg.AvgLogprob == 0 {
+			continue
+		}
+		d := seg.End - seg.Start
+		if d <= 0 {
+			d = 1
+		}
+		sum += seg.AvgLogprob * d
+		total += d
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// isDegenerate 判断结果是否疑似退化（重复循环或置信度过低）
+func isDegenerate(result *TranscriptionResult, config *Config) bool {
+	if compressionRatio(result.Text) > config.CompressionRatioThreshold {
+		return true
+	}
+	logprob := averageLogprob(result)
+	return logprob != 0 && logprob < config.FallbackLogprobThreshold
+}
+
+// betterResult 比较两个候选结果：非退化优先，其次平均对数概率更高、压缩比更低者更好
+func betterResult(a, b *TranscriptionResult, config *Config) bool {
+	da, db := isDegenerate(a, config), isDegenerate(b, config)
+	if da != db {
+		return !da
+	}
+	la, lb := averageLogprob(a), averageLogprob(b)
+	if la != lb {
+		return la > lb
+	}
+	return compressionRatio(a.Text) < compressionRatio(b.Text)
+}
+
+// transcribeWithFallback 转写音频，结果退化时按配置的温度阶梯重试并保留最佳结果
+func transcribeWithFallback(client *openai.Client, audioPath string, config *Config, verbose bool) (*TranscriptionResult, error) {
+	temperatures := config.TemperatureFallback
+	if len(temperatures) == 0 {
+		temperatures = []float64{0}
+	}
+
+	// 相同音频和参数已转写过时直接使用缓存的结果
+	cache := newResponseCache(config)
+	prompt := config.transcriptionPrompt()
+	cacheKey := cache.key(audioPath, config, prompt)
+	if cached := cache.load(cacheKey); cached != nil {
+		logDebug(tr("使用缓存的转写结果: %s\n"), audioPath)
+		return cached, nil
+	}
+
+	backend := newBackend(client, config)
+	if !backend.SupportsTemperature() {
+		temperatures = temperatures[:1]
+	}
+
+	// 压缩一次，温度回退重试时复用
+	uploadPath, cleanup, err := prepareUpload(audioPath, config, verbose)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var best *TranscriptionResult
+	for i, temperature := range temperatures {
+		if i > 0 {
+			logDebug(tr("结果疑似退化，使用温度 %.1f 重试\n"), temperature)
+		}
+
+		ctx, span := startSpan(config, "transcribe_api",
+			attribute.String("provider", config.Provider),
+			attribute.String("model", config.Model),
+			attribute.Float64("temperature", temperature),
+			attribute.String("whisper.audio", filepath.Base(audioPath)),
+		)
+		ctx, usedKey := withUsedKey(ctx)
+		started := time.Now()
+		result, err := backend.Transcribe(ctx, uploadPath, backendRequest{
+			Model:       config.Model,
+			Language:    config.Language,
+			Prompt:      prompt,
+			AutoDetect:  config.AutoDetect,
+			Temperature: float32(temperature),
+			MaxRetries:  config.maxRetries(),
+			Verbose:     verbose,
+		})
+		recordAPICall(ctx, config, time.Since(started), err)
+		endSpan(ctx, span, operationAPI, err)
+		if err != nil {
+			// 首次请求失败直接返回；回退重试失败时保留已有结果
+			if best == nil {
+				return nil, withExitCode(exitAPIFailure, err)
+			}
+			logWarn(tr("温度 %.1f 重试失败: %v\n"), temperature, err)
+			continue
+		}
+
+		recordUsage(config, *usedKey, chunkSeconds(result, audioPath))
+
+		if best == nil || betterResult(result, best, config) {
+			best = result
+		}
+		if !isDegenerate(result, config) {
+			break
+		}
+	}
+
+	cache.save(cacheKey, best)
+	return best, nil
+}
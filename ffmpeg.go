@@ -0,0 +1,29 @@
+package main
+
+// ffmpegTools 外部 ffmpeg/ffprobe 命令设置，加载配置时由 useFFmpegConfig 设置
+var ffmpegTools = struct {
+	FFmpeg      string
+	FFprobe     string
+	InputArgs   []string // 插入在 -i 之前，如硬件解码参数
+	ExtractArgs []string // 追加到提取音频命令的输出参数中
+	SplitArgs   []string // 追加到切割切片命令的输出参数中
+}{
+	FFmpeg:  "ffmpeg",
+	FFprobe: "ffprobe",
+}
+
+// useFFmpegConfig 按配置设置 ffmpeg/ffprobe 路径和附加参数
+func useFFmpegConfig(config *Config) {
+	ffmpegTools.FFmpeg = config.FFmpegPath
+	ffmpegTools.FFprobe = config.FFprobePath
+	ffmpegTools.InputArgs = config.FFmpegInputArgs
+	ffmpegTools.ExtractArgs = config.FFmpegExtractArgs
+	ffmpegTools.SplitArgs = config.FFmpegSplitArgs
+}
+
+// ffmpegArgs 拼接 ffmpeg 参数：[输入参数] -i <input> [输出参数]
+func ffmpegArgs(input string, outputArgs ...string) []string {
+	args := append([]string{}, ffmpegTools.InputArgs...)
+	args = append(args, "-i", input)
+	return append(args, outputArgs...)
+}
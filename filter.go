@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// 分段过滤原因
+const (
+	filterReasonNoSpeech   = "no_speech"
+	filterReasonLowLogprob = "low_logprob"
+	filterReasonPhrase     = "hallucination_phrase"
+)
+
+// FilteredSegment 被过滤的分段记录
+type FilteredSegment struct {
+	Start   float64  `json:"start"`
+	End     float64  `json:"end"`
+	Text    string   `json:"text"`
+	Reasons []string `json:"reasons"`
+}
+
+// segmentFilterReasons 判断分段是否疑似幻觉或低置信度，返回命中的原因
+func segmentFilterReasons(seg Segment, config *Config) []string {
+	var reasons []string
+
+	if config.NoSpeechThreshold > 0 && seg.NoSpeechProb > config.NoSpeechThreshold {
+		reasons = append(reasons, filterReasonNoSpeech)
+	}
+	// avg_logprob 为 0 说明后端未返回该字段
+	if config.LogprobThreshold < 0 && seg.AvgLogprob != 0 && seg.AvgLogprob < config.LogprobThreshold {
+		reasons = append(reasons, filterReasonLowLogprob)
+	}
+
+	text := normalizePhrase(seg.Text)
+	for _, phrase := range config.HallucinationPhrases {
+		if p := normalizePhrase(phrase); p != "" && text == p {
+			reasons = append(reasons, filterReasonPhrase)
+			break
+		}
+	}
+
+	return reasons
+}
+
+// normalizePhrase 去掉空白和标点，便于匹配常见幻觉短语（如 "谢谢观看！"）
+func normalizePhrase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// filterSegments 按配置的阈值丢弃或标记疑似幻觉的分段
+func filterSegments(result *TranscriptionResult, config *Config) {
+	if config.NoSpeechThreshold <= 0 && config.LogprobThreshold >= 0 && len(config.HallucinationPhrases) == 0 {
+		return
+	}
+
+	drop := config.FilterAction == "drop"
+	var kept []Segment
+	for _, seg := range result.Segments {
+		reasons := segmentFilterReasons(seg, config)
+		if len(reasons) == 0 {
+			kept = append(kept, seg)
+			continue
+		}
+
+		if drop {
+			result.Filtered = append(result.Filtered, FilteredSegment{
+				Start:   seg.Start,
+				End:     seg.End,
+				Text:    seg.Text,
+				Reasons: reasons,
+			})
+			continue
+		}
+		seg.Flags = append(seg.Flags, reasons...)
+		kept = append(kept, seg)
+	}
+	result.Segments = kept
+
+	// 丢弃分段后按剩余分段重建全文
+	if len(result.Filtered) > 0 {
+		rebuildResultText(result)
+	}
+}
+
+// printFilterReport 在摘要中输出过滤报告
+func printFilterReport(result *TranscriptionResult) {
+	var flagged []Segment
+	for _, seg := range result.Segments {
+		if len(seg.Flags) > 0 {
+			flagged = append(flagged, seg)
+		}
+	}
+	if len(result.Filtered) == 0 && len(flagged) == 0 {
+		return
+	}
+
+	if len(result.Filtered) > 0 {
+		fmt.Printf(tr("已过滤分段: %d\n"), len(result.Filtered))
+		for _, f := range result.Filtered {
+			fmt.Printf("  [%s --> %s] %s (%s)\n", formatSRTTime(f.Start), formatSRTTime(f.End), strings.TrimSpace(f.Text), strings.Join(f.Reasons, ", "))
+		}
+	}
+	if len(flagged) > 0 {
+		fmt.Printf(tr("已标记可疑分段: %d\n"), len(flagged))
+		for _, seg := range flagged {
+			fmt.Printf("  #%d [%s --> %s] %s (%s)\n", seg.ID, formatSRTTime(seg.Start), formatSRTTime(seg.End), strings.TrimSpace(seg.Text), strings.Join(seg.Flags, ", "))
+		}
+	}
+}
+
+// printProblems 以 file:line:col: severity: message 的格式输出被标记的分段，
+// 位置指向生成的 SRT（没有 SRT 时指向 TXT）中对应的文本行，便于编辑器的 problem matcher 点击跳转
+func printProblems(result *TranscriptionResult, outputFiles []string) {
+	var srtPath, txtPath string
+	for _, file := range outputFiles {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".srt":
+			srtPath = file
+		case ".txt":
+			txtPath = file
+		}
+	}
+
+	path, lines := srtPath, srtTextLines(result)
+	if path == "" {
+		path, lines = txtPath, txtLines(result)
+	}
+	if path == "" {
+		logWarn(tr("未生成 SRT 或 TXT 输出，无法定位可疑分段"))
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	for i, seg := range result.Segments {
+		if len(seg.Flags) == 0 {
+			continue
+		}
+		fmt.Printf("%s:%d:1: warning: [%s] %s --> %s %s\n", path, lines[i], strings.Join(seg.Flags, ", "),
+			formatSRTTime(seg.Start), formatSRTTime(seg.End), strings.TrimSpace(seg.Text))
+	}
+}
+
+// srtTextLines 计算每个分段的文本在 SRT 文件中的行号（与 saveSRT 的输出格式对应）
+func srtTextLines(result *TranscriptionResult) []int {
+	lines := make([]int, len(result.Segments))
+	line := 1
+	for i, seg := range result.Segments {
+		// 序号行、时间行之后是文本行，最后是空行
+		lines[i] = line + 2
+		line += 3 + strings.Count(seg.Text, "\n") + 1
+	}
+	return lines
+}
+
+// txtLines 计算每个分段在 TXT 文件中的行号（与 saveTXT 的输出格式对应）
+func txtLines(result *TranscriptionResult) []int {
+	lines := make([]int, len(result.Segments))
+	line := 1
+	for i, seg := range result.Segments {
+		lines[i] = line
+		line += strings.Count(seg.Text, "\n") + 1
+	}
+	return lines
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// runFollow 跟随转写仍在写入的录制文件（如 OBS 正在录制的 mkv/flv）：ffmpeg 读到文件末尾后继续等待新数据，
+// 每积累 segment 秒音频转写一次并追加到输出；文件超过 idle 秒没有增长时视为录制结束，转写剩余部分后退出
+func runFollow(client *openai.Client, inputFile string, config *Config, formatList []string, segment, idle float64, verbose bool) {
+	if isRemoteURI(inputFile) {
+		exitWith(exitBadInput, "%s", tr("-follow 只支持本地文件"))
+	}
+	if strings.EqualFold(filepath.Ext(inputFile), ".mp4") || strings.EqualFold(filepath.Ext(inputFile), ".mov") {
+		logWarn(tr("MP4/MOV 录制结束前通常无法读取，请在录制软件中改用 MKV 或 FLV 格式"))
+	}
+	if segment <= 0 || idle <= 0 {
+		exitWith(exitBadInput, "%s", tr("-follow-segment 和 -follow-idle 必须大于 0"))
+	}
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		exitWith(exitFailure, tr("创建输出目录失败: %v"), err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+	prefix := fmt.Sprintf("%s_%s", name, time.Now().Format("20060102_150405"))
+	writer := newRollingWriter(config.OutputDir, prefix, formatList, 0)
+
+	// file 协议的 follow 选项在文件末尾重试读取，rw_timeout（微秒）内没有新数据时结束
+	inputArgs := []string{"-follow", "1", "-rw_timeout", fmt.Sprintf("%d", int64(idle*1e6))}
+	logInfo(tr("正在跟随转写: %s（%.0f 秒无新数据时结束，Ctrl+C 立即结束）\n"), inputFile, idle)
+	offset := runLiveSession(client, config, inputFile, inputArgs, segment, writer, verbose)
+	printLiveSummary(config, writer, offset)
+}
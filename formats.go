@@ -0,0 +1,111 @@
+package main
+
+import "strings"
+
+// outputFormat 一种输出格式：文件扩展名和保存函数。saveOutputs、预演、命令行补全、模板输出和译文输出都从 outputFormats 取格式
+type outputFormat struct {
+	ext string // 文件扩展名（不含开头的点）
+	// extFor 扩展名取决于输入文件或配置时使用（tagged、template），返回 false 表示无法确定
+	extFor func(input string, config *Config) (string, bool)
+	// mediaTime 时间戳对应输入媒体本身，不按 time_offset 平移：html 点击跳转、写入原文件的标签和章节、
+	// Audacity 标签、Anki 音频片段和统计中的时间窗口都以输入文件为准
+	mediaTime bool
+	subtitle  bool // 开启 translate_to 时可以输出译文的字幕格式
+	save      saveFunc
+}
+
+// outputFormats 所有输出格式，键为 --formats 中的名称
+var outputFormats = map[string]outputFormat{
+	"txt":            {ext: "txt", save: resultOnly(saveTXT)},
+	"srt":            {ext: "srt", subtitle: true, save: resultOnly(saveSRT)},
+	"lrc":            {ext: "lrc", subtitle: true, save: saveLRCOutput},
+	"ass":            {ext: "ass", subtitle: true, save: resultOnly(saveASS)},
+	"ttml":           {ext: "ttml", save: withConfig(saveTTML)},
+	"ebu-stl":        {ext: "stl", save: withConfig(saveEBUSTL)},
+	"subcap":         {ext: "subcap.txt", save: withConfig(saveSubCap)},
+	"json":           {ext: "json", save: resultOnly(saveJSON)},
+	"jsonl":          {ext: "jsonl", save: resultOnly(saveJSONL)},
+	"html":           {ext: "html", mediaTime: true, save: withInput(saveHTML)},
+	"audacity":       {ext: "labels.txt", mediaTime: true, save: resultOnly(saveAudacityLabels)},
+	"eaf":            {ext: "eaf", save: saveEAF},
+	"textgrid":       {ext: "TextGrid", save: withConfig(saveTextGrid)},
+	"ctm":            {ext: "ctm", save: saveCTM},
+	"stm":            {ext: "stm", save: saveSTM},
+	"chapters":       {ext: "chapters.txt", save: resultOnly(saveChapters)},
+	"ffmetadata":     {ext: "ffmetadata", mediaTime: true, save: resultOnly(saveFFMetadata)},
+	"tagged":         {extFor: taggedOutputExtension, mediaTime: true, save: withInput(saveTaggedAudio)},
+	"anki":           {ext: "anki.csv", mediaTime: true, save: saveAnki},
+	"qc":             {ext: "qc.txt", save: withConfig(saveQCReport)},
+	"stats":          {ext: "stats.json", mediaTime: true, save: resultOnly(saveStats)},
+	"stats-txt":      {ext: "stats.txt", mediaTime: true, save: resultOnly(saveStatsText)},
+	"minutes":        {ext: "minutes.md", save: resultOnly(saveMinutes)},
+	"minutes-json":   {ext: "minutes.json", save: resultOnly(saveMinutesJSON)},
+	"keywords":       {ext: "keywords.md", save: resultOnly(saveKeywords)},
+	"keywords-json":  {ext: "keywords.json", save: resultOnly(saveKeywordsJSON)},
+	"sentiment":      {ext: "sentiment.csv", save: resultOnly(saveSentimentCSV)},
+	"sentiment-json": {ext: "sentiment.json", save: resultOnly(saveSentimentJSON)},
+	"redactions":     {ext: "redactions.json", save: resultOnly(saveRedactionReport)},
+	"template":       {extFor: templateOutputExtension, save: saveTemplate},
+}
+
+// saveFunc outputFormat 中的保存函数
+type saveFunc = func(result *TranscriptionResult, input string, config *Config, path string) error
+
+// resultOnly 把只需要结果和输出路径的保存函数转换为 saveFunc
+func resultOnly(save func(*TranscriptionResult, string) error) saveFunc {
+	return func(result *TranscriptionResult, _ string, _ *Config, path string) error {
+		return save(result, path)
+	}
+}
+
+// withConfig 把需要配置的保存函数转换为 saveFunc
+func withConfig(save func(*TranscriptionResult, *Config, string) error) saveFunc {
+	return func(result *TranscriptionResult, _ string, config *Config, path string) error {
+		return save(result, config, path)
+	}
+}
+
+// withInput 把需要输入文件路径的保存函数转换为 saveFunc
+func withInput(save func(*TranscriptionResult, string, string) error) saveFunc {
+	return func(result *TranscriptionResult, input string, _ *Config, path string) error {
+		return save(result, input, path)
+	}
+}
+
+// saveLRCOutput 按 lrc_metadata 保存 LRC
+func saveLRCOutput(result *TranscriptionResult, _ string, config *Config, path string) error {
+	return saveLRC(result, config.LRCMetadata, path)
+}
+
+// taggedOutputExtension tagged 格式与输入文件的容器格式相同
+func taggedOutputExtension(input string, _ *Config) (string, bool) {
+	return taggedExtension(input), true
+}
+
+// templateOutputExtension template 格式的扩展名取自模板文件名，没有配置模板时无法确定
+func templateOutputExtension(_ string, config *Config) (string, bool) {
+	return templateExtension(config.Template), config.Template != ""
+}
+
+// formatExtension 输出格式的文件扩展名，tagged 格式取决于输入文件，template 格式取自模板文件名。未知格式返回 false
+func formatExtension(format, input string, config *Config) (string, bool) {
+	f, ok := outputFormats[format]
+	switch {
+	case !ok:
+		return "", false
+	case f.extFor != nil:
+		return f.extFor(input, config)
+	}
+	return f.ext, true
+}
+
+// knownExtension 文件名末尾的输出格式扩展名（取最长的匹配，避免把 .labels.txt 当作 .txt），没有时返回空字符串
+func knownExtension(path string) string {
+	var known string
+	for _, f := range outputFormats {
+		if f.ext != "" && strings.HasSuffix(path, "."+f.ext) && len(f.ext) > len(known) {
+			known = f.ext
+		}
+	}
+	return known
+}
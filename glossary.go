@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// glossaryPromptMaxLen 注入 Whisper 提示的术语总长度上限（Whisper 只使用提示的最后 224 个 token）
+const glossaryPromptMaxLen = 600
+
+// glossaryFuzzyMinLen 参与模糊匹配的术语最短字符数，过短的词容易误改
+const glossaryFuzzyMinLen = 5
+
+// glossaryWord 匹配字母数字组成的单词
+var glossaryWord = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}'’.-]*[\p{L}\p{N}]|[\p{L}\p{N}]`)
+
+// Glossary 术语表：规范写法及其常见误写
+type Glossary struct {
+	terms []glossaryTerm
+}
+
+// glossaryTerm 一个规范写法及需要替换为它的写法（含规范写法本身，用于统一大小写）
+type glossaryTerm struct {
+	canonical string
+	variants  []string
+}
+
+// loadGlossary 读取术语表。JSON 文件为 {"误写": "规范写法"}；
+// 文本文件每行一个术语，"误写1, 误写2 => 规范写法" 指定误写，# 开头为注释
+func loadGlossary(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取术语表失败: %w"), err)
+	}
+
+	index := map[string]int{}
+	g := &Glossary{}
+	add := func(canonical string, variants ...string) {
+		canonical = strings.TrimSpace(canonical)
+		if canonical == "" {
+			return
+		}
+		i, ok := index[canonical]
+		if !ok {
+			i = len(g.terms)
+			index[canonical] = i
+			g.terms = append(g.terms, glossaryTerm{canonical: canonical, variants: []string{canonical}})
+		}
+		for _, v := range variants {
+			if v = strings.TrimSpace(v); v != "" {
+				g.terms[i].variants = append(g.terms[i].variants, v)
+			}
+		}
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var m map[string]string
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf(tr("解析术语表失败: %w"), err)
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, variant := range keys {
+			add(m[variant], variant)
+		}
+		return g, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if variants, canonical, ok := strings.Cut(line, "=>"); ok {
+			add(canonical, strings.Split(variants, ",")...)
+		} else {
+			add(line)
+		}
+	}
+	return g, nil
+}
+
+// prompt 生成注入 Whisper 的提示：列出规范写法，引导模型使用这些拼写
+func (g *Glossary) prompt() string {
+	if g == nil || len(g.terms) == 0 {
+		return ""
+	}
+	var terms []string
+	size := 0
+	for _, t := range g.terms {
+		if size+len(t.canonical) > glossaryPromptMaxLen {
+			break
+		}
+		terms = append(terms, t.canonical)
+		size += len(t.canonical) + 2
+	}
+	return "Glossary: " + strings.Join(terms, ", ") + "."
+}
+
+// transcriptionPrompt 传给模型的提示：配置的 prompt 在前，术语表的提示在后
+func (c *Config) transcriptionPrompt() string {
+	var parts []string
+	for _, p := range []string{strings.TrimSpace(c.Prompt), c.glossary.prompt()} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// applyGlossary 按术语表修正转写结果中的分段和全文，返回修正次数
+func applyGlossary(result *TranscriptionResult, config *Config) int {
+	g := config.glossary
+	if g == nil || len(g.terms) == 0 {
+		return 0
+	}
+
+	total := 0
+	for i := range result.Segments {
+		text, n := g.correct(result.Segments[i].Text, config.GlossaryFuzzy)
+		result.Segments[i].Text = text
+		total += n
+	}
+	if len(result.Segments) > 0 {
+		if total > 0 {
+			rebuildResultText(result)
+		}
+	} else {
+		result.Text, total = g.correct(result.Text, config.GlossaryFuzzy)
+	}
+	if total > 0 {
+		logDebug(tr("术语修正: %d 处"), total)
+	}
+	return total
+}
+
+// correct 修正一段文本。含非拉丁文字（如中文）的写法按原文精确替换；
+// 拉丁文字按单词匹配（忽略大小写），threshold < 1 时对较长的术语进行相似度匹配
+func (g *Glossary) correct(text string, threshold float64) (string, int) {
+	count := 0
+	for _, t := range g.terms {
+		for _, v := range t.variants {
+			if v != t.canonical && !isLatinText(v) && strings.Contains(text, v) {
+				count += strings.Count(text, v)
+				text = strings.ReplaceAll(text, v, t.canonical)
+			}
+		}
+	}
+
+	words := glossaryWord.FindAllStringIndex(text, -1)
+	if len(words) == 0 {
+		return text, count
+	}
+
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(words); {
+		canonical, n := g.match(text, words[i:], threshold)
+		if n == 0 {
+			i++
+			continue
+		}
+		start, end := words[i][0], words[i+n-1][1]
+		if text[start:end] != canonical {
+			b.WriteString(text[last:start])
+			b.WriteString(canonical)
+			last = end
+			count++
+		}
+		i += n
+	}
+	b.WriteString(text[last:])
+	return b.String(), count
+}
+
+// match 查找从 words[0] 开始能匹配的术语，返回规范写法和匹配的单词数（优先匹配更长的术语）
+func (g *Glossary) match(text string, words [][]int, threshold float64) (string, int) {
+	best, bestWords := "", 0
+	for _, t := range g.terms {
+		for _, v := range t.variants {
+			if !isLatinText(v) {
+				continue
+			}
+			k := len(strings.Fields(v))
+			if k == 0 || k > len(words) || k <= bestWords {
+				continue
+			}
+			candidate := joinWords(text, words[:k])
+			if strings.EqualFold(candidate, v) ||
+				(threshold > 0 && threshold < 1 && utf8.RuneCountInString(v) >= glossaryFuzzyMinLen && similarity(strings.ToLower(candidate), strings.ToLower(v)) >= threshold) {
+				best, bestWords = t.canonical, k
+			}
+		}
+	}
+	return best, bestWords
+}
+
+// joinWords 用单个空格连接若干个单词
+func joinWords(text string, words [][]int) string {
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = text[w[0]:w[1]]
+	}
+	return strings.Join(parts, " ")
+}
+
+// isLatinText 是否只包含拉丁字母、数字、空白和标点
+func isLatinText(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) && !unicode.In(r, unicode.Latin) {
+			return false
+		}
+	}
+	return true
+}
+
+// similarity 基于编辑距离的相似度，1 为完全相同
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein 编辑距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,509 @@
+package main
+
+//go:generate protoc -I proto --go_out=proto/whisperpb --go_opt=paths=source_relative --go-grpc_out=proto/whisperpb --go-grpc_opt=paths=source_relative whisper.proto
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/whisper-client/go-whisper-go/proto/whisperpb"
+)
+
+// grpcMaxMessageSize 单条 gRPC 消息的最大长度，Transcribe 直接携带文件内容时需要放宽默认的 4MB 限制
+const grpcMaxMessageSize = 256 << 20
+
+// queuePollInterval 空闲 worker 未被唤醒时重新检查队列的间隔
+const queuePollInterval = 30 * time.Second
+
+// runGRPCServer 执行 grpc 子命令：以 gRPC 服务的形式提供转写能力
+func runGRPCServer(args []string) {
+	fs := flag.NewFlagSet("grpc", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	listen := fs.String("listen", ":50051", tr("监听地址"))
+	workers := fs.Int("workers", 1, tr("同时执行的转写任务数"))
+	allowPaths := fs.Bool("allow-paths", false, tr("允许请求直接转写服务端本地路径"))
+	metrics := fs.String("metrics", "", tr("提供 /metrics 空闲/工作状态指标的监听地址（如 :9090）"))
+	queueDB := fs.String("queue-db", "", tr("持久化任务队列的 SQLite 数据库路径，服务重启后排队中的任务继续执行（默认只保存在内存中）"))
+	web := fs.String("web", "", tr("提供网页界面的监听地址（如 :8080），可上传文件、查看进度和下载结果"))
+	fs.Parse(args)
+	// SIGTERM 或 Ctrl+C 时取消进行中的任务（任务上下文派生自 shutdownCtx）并删除临时切片
+	installShutdownHandler()
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fatalf(tr("加载配置失败: %v"), err)
+	}
+	if config.APIKey == "" && config.needsAPIKey() {
+		fatal(tr("配置文件中未设置 API Key，请先在 config.json 中配置 api_key"))
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	store, uploadRoot, err := openJobStore(*queueDB)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	defer store.close()
+	onShutdown(func() { store.close() })
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fatalf(tr("监听 %s 失败: %v"), *listen, err)
+	}
+
+	state := newDaemonState()
+	state.queueStats = store.stats
+	if *metrics != "" {
+		go func() {
+			if err := state.serveMetrics(*metrics); err != nil {
+				logError(tr("指标服务异常退出: %v"), err)
+			}
+		}()
+	}
+
+	// 启动时就提供实时字幕，叠加层可以在第一个任务开始前连接
+	if config.CaptionListen != "" {
+		if err := listenCaptions(config); err != nil {
+			logWarn(tr("启动实时字幕服务失败: %v"), err)
+		}
+	}
+
+	srv := &transcriptionServer{
+		config:     config,
+		client:     newClient(config),
+		store:      store,
+		uploadRoot: uploadRoot,
+		wake:       make(chan struct{}, 1),
+		allowPaths: *allowPaths,
+		state:      state,
+		running:    make(map[string]context.CancelFunc),
+		waiters:    make(map[string][]chan struct{}),
+		progress:   make(map[string]*whisperpb.JobProgress),
+	}
+	for i := 0; i < *workers; i++ {
+		go srv.worker()
+	}
+	srv.wakeWorker()
+
+	if *web != "" {
+		go func() {
+			if err := srv.serveWeb(*web); err != nil {
+				logError(tr("网页服务异常退出: %v"), err)
+			}
+		}()
+		logInfo(tr("网页界面已启动: %s"), *web)
+	}
+
+	server := grpc.NewServer(grpc.MaxRecvMsgSize(grpcMaxMessageSize))
+	whisperpb.RegisterTranscriptionServiceServer(server, srv)
+
+	logInfo(tr("gRPC 服务已启动: %s\n"), lis.Addr())
+	if err := server.Serve(lis); err != nil {
+		fatalf(tr("gRPC 服务异常退出: %v"), err)
+	}
+}
+
+// openJobStore 打开任务存储：path 为空时使用内存存储，上传内容放在工作目录；
+// 否则使用 SQLite 持久化，上传内容放在数据库旁的 uploads 目录，重启后仍可执行
+func openJobStore(path string) (jobStore, string, error) {
+	if path == "" {
+		return newMemoryJobStore(), workingDir(), nil
+	}
+	store, requeued, err := openSQLiteJobStore(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if requeued > 0 {
+		logInfo(tr("已将 %d 个上次中断的任务重新排队"), requeued)
+	}
+	return store, filepath.Join(filepath.Dir(path), "uploads"), nil
+}
+
+// transcriptionServer 实现 TranscriptionService
+type transcriptionServer struct {
+	whisperpb.UnimplementedTranscriptionServiceServer
+
+	config     *Config
+	client     *openai.Client
+	store      jobStore
+	uploadRoot string
+	wake       chan struct{}
+	allowPaths bool
+	state      *daemonState
+
+	// mu 保护 running、waiters 和 progress，取任务和取消任务在锁内进行，运行中的任务一定能找到取消函数
+	mu       sync.Mutex
+	running  map[string]context.CancelFunc
+	waiters  map[string][]chan struct{}
+	progress map[string]*whisperpb.JobProgress // 运行中任务的最新进度，只保存在内存中
+}
+
+// Transcribe 转写服务端本地文件或请求中携带的内容
+func (s *transcriptionServer) Transcribe(ctx context.Context, req *whisperpb.TranscribeRequest) (*whisperpb.Job, error) {
+	var inputFile, tempDir string
+
+	switch src := req.Source.(type) {
+	case *whisperpb.TranscribeRequest_Path:
+		if !s.allowPaths {
+			return nil, status.Error(codes.PermissionDenied, tr("服务端未开启本地路径转写（-allow-paths）"))
+		}
+		if _, err := os.Stat(src.Path); err != nil && !isRemoteURI(src.Path) {
+			return nil, status.Errorf(codes.NotFound, tr("输入文件不存在: %s"), src.Path)
+		}
+		inputFile = src.Path
+	case *whisperpb.TranscribeRequest_Content:
+		dir, path, err := createUploadFile(s.uploadRoot, req.Filename)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, tr("保存上传文件失败: %v"), err)
+		}
+		if err := os.WriteFile(path, src.Content, 0644); err != nil {
+			os.RemoveAll(dir)
+			return nil, status.Errorf(codes.Internal, tr("保存上传文件失败: %v"), err)
+		}
+		inputFile, tempDir = path, dir
+	default:
+		return nil, status.Error(codes.InvalidArgument, tr("请求缺少 path 或 content"))
+	}
+
+	return s.submit(ctx, inputFile, tempDir, req.Options, req.Wait)
+}
+
+// TranscribeStream 接收分块上传的内容后转写
+func (s *transcriptionServer) TranscribeStream(stream whisperpb.TranscriptionService_TranscribeStreamServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, tr("未收到任何数据"))
+	}
+	if err != nil {
+		return err
+	}
+
+	dir, path, err := createUploadFile(s.uploadRoot, first.Filename)
+	if err != nil {
+		return status.Errorf(codes.Internal, tr("保存上传文件失败: %v"), err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return status.Errorf(codes.Internal, tr("保存上传文件失败: %v"), err)
+	}
+
+	// 边接收边写入磁盘，避免在内存中缓存整个文件
+	chunk := first
+	for {
+		if _, err := f.Write(chunk.Data); err != nil {
+			f.Close()
+			os.RemoveAll(dir)
+			return status.Errorf(codes.Internal, tr("保存上传文件失败: %v"), err)
+		}
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			os.RemoveAll(dir)
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		os.RemoveAll(dir)
+		return status.Errorf(codes.Internal, tr("保存上传文件失败: %v"), err)
+	}
+
+	job, err := s.submit(stream.Context(), path, dir, first.Options, first.Wait)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(job)
+}
+
+// GetJob 查询任务状态
+func (s *transcriptionServer) GetJob(ctx context.Context, req *whisperpb.GetJobRequest) (*whisperpb.Job, error) {
+	return s.getJob(req.Id)
+}
+
+// CancelJob 取消任务：排队中的任务直接标记为已取消，运行中的任务中止进行中的请求后标记为已取消
+func (s *transcriptionServer) CancelJob(ctx context.Context, req *whisperpb.CancelJobRequest) (*whisperpb.Job, error) {
+	s.mu.Lock()
+	queued, err := s.store.cancelQueued(req.Id)
+	cancel, running := s.running[req.Id]
+	s.mu.Unlock()
+
+	switch {
+	case errors.Is(err, errJobNotFound):
+		return nil, status.Errorf(codes.NotFound, tr("任务不存在: %s"), req.Id)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, tr("取消任务失败: %v"), err)
+	case queued != nil:
+		if queued.uploadDir != "" {
+			os.RemoveAll(queued.uploadDir)
+		}
+		s.notifyDone(req.Id)
+		logInfo(tr("已取消排队中的任务: %s"), req.Id)
+	case running:
+		cancel()
+		logInfo(tr("正在取消运行中的任务: %s"), req.Id)
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, tr("任务已结束，无法取消: %s"), req.Id)
+	}
+	return s.getJob(req.Id)
+}
+
+// getJob 从任务存储读取任务，转换为 gRPC 错误
+func (s *transcriptionServer) getJob(id string) (*whisperpb.Job, error) {
+	job, err := s.store.get(id)
+	if errors.Is(err, errJobNotFound) {
+		return nil, status.Errorf(codes.NotFound, tr("任务不存在: %s"), id)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, tr("读取任务失败: %v"), err)
+	}
+	if job.State == whisperpb.JobState_JOB_STATE_RUNNING {
+		s.mu.Lock()
+		if p := s.progress[id]; p != nil {
+			job.Progress = proto.Clone(p).(*whisperpb.JobProgress)
+		}
+		s.mu.Unlock()
+	}
+	return job, nil
+}
+
+// submit 将任务加入队列，wait 为 true 时等待任务结束
+func (s *transcriptionServer) submit(ctx context.Context, inputFile, uploadDir string, options *whisperpb.TranscribeOptions, wait bool) (*whisperpb.Job, error) {
+	// 请求指定的模型按服务端的 provider 校验，无效时直接拒绝，不进入队列
+	if model := options.GetModel(); model != "" {
+		config := *s.config
+		config.Model = model
+		if err := config.validateModel(); err != nil {
+			if uploadDir != "" {
+				os.RemoveAll(uploadDir)
+			}
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	id, err := newJobID()
+	if err != nil {
+		if uploadDir != "" {
+			os.RemoveAll(uploadDir)
+		}
+		return nil, status.Errorf(codes.Internal, tr("生成任务 ID 失败: %v"), err)
+	}
+
+	j := &queuedJob{
+		job: &whisperpb.Job{
+			Id:       id,
+			State:    whisperpb.JobState_JOB_STATE_QUEUED,
+			Priority: options.GetPriority(),
+		},
+		input:     inputFile,
+		uploadDir: uploadDir,
+		options:   options,
+		created:   time.Now(),
+	}
+
+	// 先登记等待再入队，任务再快结束也不会错过通知
+	var done chan struct{}
+	if wait {
+		done = s.waitFor(id)
+	}
+	if err := s.store.add(j); err != nil {
+		if uploadDir != "" {
+			os.RemoveAll(uploadDir)
+		}
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	s.wakeWorker()
+
+	if wait {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return s.getJob(id)
+}
+
+// worker 循环从队列取出任务执行，队列为空时等待唤醒
+func (s *transcriptionServer) worker() {
+	for {
+		j, ctx, err := s.take()
+		if err != nil {
+			logError(tr("读取任务队列失败: %v"), err)
+		}
+		if j == nil {
+			select {
+			case <-s.wake:
+			case <-time.After(queuePollInterval):
+			}
+			continue
+		}
+		// 队列中可能还有任务，唤醒其他空闲的 worker
+		s.wakeWorker()
+		s.run(ctx, j)
+	}
+}
+
+// take 取出下一个任务并登记取消函数
+func (s *transcriptionServer) take() (*queuedJob, context.Context, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.store.next()
+	if err != nil || j == nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(shutdownCtx)
+	s.running[j.job.Id] = cancel
+	return j, ctx, nil
+}
+
+// wakeWorker 唤醒一个空闲的 worker，已有待处理的唤醒时不重复发送
+func (s *transcriptionServer) wakeWorker() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// waitFor 登记等待任务结束的通道
+func (s *transcriptionServer) waitFor(id string) chan struct{} {
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.waiters[id] = append(s.waiters[id], done)
+	s.mu.Unlock()
+	return done
+}
+
+// notifyDone 通知等待该任务的请求
+func (s *transcriptionServer) notifyDone(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, done := range s.waiters[id] {
+		close(done)
+	}
+	delete(s.waiters, id)
+}
+
+// run 执行转写任务并保存结果
+func (s *transcriptionServer) run(ctx context.Context, j *queuedJob) {
+	defer s.notifyDone(j.job.Id)
+	defer func() {
+		s.mu.Lock()
+		cancel := s.running[j.job.Id]
+		delete(s.running, j.job.Id)
+		delete(s.progress, j.job.Id)
+		s.mu.Unlock()
+		cancel()
+	}()
+	if j.uploadDir != "" {
+		defer os.RemoveAll(j.uploadDir)
+	}
+
+	// 按请求参数覆盖服务端配置
+	config := *s.config
+	formatList := parseFormats("txt,srt,json")
+	if options := j.options; options != nil {
+		if options.Language != "" {
+			config.Language = options.Language
+		}
+		if options.Model != "" {
+			config.Model = options.Model
+		}
+		if options.AutoDetect {
+			config.AutoDetect = true
+		}
+		if len(options.Formats) > 0 {
+			formatList = parseFormats(strings.Join(options.Formats, ","))
+		}
+	}
+
+	config.Progress = func(stage string, current, total int) {
+		s.setProgress(j.job.Id, stage, current, total)
+	}
+	// 实时字幕只推送给订阅了该任务的连接
+	config.captionScope = j.job.Id
+
+	s.state.begin()
+	result, outputFiles, err := processFile(s.client, j.input, config.withContext(ctx), formatList, false)
+	s.state.end(err)
+
+	job := j.job
+	switch {
+	case err != nil && ctx.Err() != nil:
+		job.State = whisperpb.JobState_JOB_STATE_CANCELLED
+		job.Error = tr("任务已取消")
+	case err != nil:
+		job.State = whisperpb.JobState_JOB_STATE_FAILED
+		job.Error = err.Error()
+	default:
+		job.State = whisperpb.JobState_JOB_STATE_SUCCEEDED
+		job.Language = result.Language
+		job.Text = result.Text
+		job.Duration = result.Duration
+		job.OutputFiles = outputFiles
+		for _, seg := range result.Segments {
+			job.Segments = append(job.Segments, &whisperpb.Segment{
+				Id:    int32(seg.ID),
+				Start: seg.Start,
+				End:   seg.End,
+				Text:  seg.Text,
+			})
+		}
+	}
+	if err := s.store.update(job); err != nil {
+		logError(tr("保存任务结果失败: %v"), err)
+	}
+}
+
+// setProgress 记录运行中任务的进度
+func (s *transcriptionServer) setProgress(id, stage string, current, total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.running[id]; !ok {
+		return
+	}
+	s.progress[id] = &whisperpb.JobProgress{Stage: stage, Current: int32(current), Total: int32(total)}
+}
+
+// createUploadFile 在 root 下为上传内容创建独立的目录，保留原始文件名以便识别媒体类型和命名输出
+func createUploadFile(root, filename string) (dir, path string, err error) {
+	name := filepath.Base(filename)
+	if name == "." || name == string(filepath.Separator) || name == "" {
+		name = "upload.wav"
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", "", err
+	}
+	dir, err = os.MkdirTemp(root, "whisper_upload_")
+	if err != nil {
+		return "", "", err
+	}
+	return dir, filepath.Join(dir, name), nil
+}
+
+// newJobID 生成随机任务 ID
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// HookFailure 输出文件后置命令的失败记录
+type HookFailure struct {
+	Format  string `json:"format"`
+	File    string `json:"file"`
+	Command string `json:"command"`
+	Error   string `json:"error"`
+}
+
+// runPostWriteHook 输出文件写入后执行该格式配置的命令，文件路径作为最后一个参数传入。
+// 未配置命令时返回 nil
+func runPostWriteHook(config *Config, format, path string) *HookFailure {
+	command := config.PostWriteHooks[format]
+	if len(command) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	if config.PostWriteHookTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(config.PostWriteHookTimeout*float64(time.Second)))
+		defer cancel()
+	}
+
+	args := append(append([]string{}, command[1:]...), path)
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	logDebug(tr("执行后置命令: %s %s"), strings.Join(command, " "), path)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	if ctx.Err() == context.DeadlineExceeded {
+		msg = tr("执行超时")
+	}
+	if out := strings.TrimSpace(string(output)); out != "" {
+		msg = fmt.Sprintf("%s: %s", msg, lastLine(out))
+	}
+	failure := &HookFailure{Format: format, File: path, Command: strings.Join(command, " "), Error: msg}
+	logWarn(tr("%s 的后置命令失败（%s）: %s"), path, failure.Command, msg)
+	return failure
+}
+
+// printHookFailures 在摘要中输出后置命令的失败
+func printHookFailures(result *TranscriptionResult) {
+	if len(result.HookFailures) == 0 {
+		return
+	}
+	fmt.Printf(tr("后置命令失败: %d\n"), len(result.HookFailures))
+	for _, f := range result.HookFailures {
+		fmt.Printf("  [%s] %s: %s (%s)\n", f.Format, f.File, f.Error, f.Command)
+	}
+}
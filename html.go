@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 分段置信度分级的阈值（exp(avg_logprob)，即平均 token 概率）
+const (
+	htmlConfidenceHigh = 0.75
+	htmlConfidenceLow  = 0.5
+)
+
+// htmlSegment 页面中的一个分段
+type htmlSegment struct {
+	Start      float64
+	Time       string
+	Speaker    string
+	Text       string
+	Class      string // conf-high、conf-mid、conf-low 或 conf-unknown
+	Confidence string // 悬停提示
+	Flags      string
+}
+
+// htmlPage 页面模板数据
+type htmlPage struct {
+	Lang     string
+	Title    string
+	Media    string // 相对于页面的媒体地址
+	Video    bool
+	Segments []htmlSegment
+	Text     string // 没有分段时显示全文
+	Labels   map[string]string
+}
+
+// htmlTemplate 独立的校对页面：点击分段跳转播放，播放时高亮当前分段
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; max-width: 900px; margin: 0 auto; padding: 0 16px 48px; line-height: 1.6; color: #222; }
+header { position: sticky; top: 0; background: #fff; padding: 12px 0; border-bottom: 1px solid #ddd; }
+h1 { font-size: 1.2em; margin: 0 0 8px; word-break: break-all; }
+audio, video { width: 100%; max-height: 40vh; }
+.legend span { display: inline-block; margin-right: 12px; padding: 0 6px; font-size: 0.85em; }
+.seg { margin: 4px 0; padding: 4px 8px; border-left: 4px solid transparent; cursor: pointer; border-radius: 3px; }
+.seg:hover { background: #f3f6fa; }
+.seg.active { outline: 2px solid #4a90d9; }
+.ts { color: #888; font-family: monospace; margin-right: 8px; }
+.speaker { font-weight: bold; margin-right: 6px; }
+.flags { color: #b00; font-size: 0.8em; margin-left: 6px; }
+.conf-high { border-left-color: #5cb85c; }
+.conf-mid { border-left-color: #f0ad4e; background: #fff8e6; }
+.conf-low { border-left-color: #d9534f; background: #fdecea; }
+.conf-unknown { border-left-color: #ccc; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Media}}{{if .Video}}<video id="media" src="{{.Media}}" controls preload="metadata"></video>{{else}}<audio id="media" src="{{.Media}}" controls preload="metadata"></audio>{{end}}{{end}}
+<div class="legend"><span class="conf-high">{{index .Labels "high"}}</span><span class="conf-mid">{{index .Labels "mid"}}</span><span class="conf-low">{{index .Labels "low"}}</span></div>
+</header>
+<main>
+{{range .Segments}}<p class="seg {{.Class}}" data-start="{{.Start}}" title="{{.Confidence}}"><span class="ts">{{.Time}}</span>{{if .Speaker}}<span class="speaker">{{.Speaker}}</span>{{end}}{{.Text}}{{if .Flags}}<span class="flags">[{{.Flags}}]</span>{{end}}</p>
+{{else}}<p>{{.Text}}</p>
+{{end}}</main>
+<script>
+(function () {
+  var media = document.getElementById("media");
+  var segs = Array.prototype.slice.call(document.querySelectorAll(".seg"));
+  segs.forEach(function (el) {
+    el.addEventListener("click", function () {
+      if (!media) return;
+      media.currentTime = parseFloat(el.dataset.start);
+      media.play();
+    });
+  });
+  if (!media) return;
+  var current = null;
+  media.addEventListener("timeupdate", function () {
+    var t = media.currentTime, found = null;
+    for (var i = 0; i < segs.length; i++) {
+      if (parseFloat(segs[i].dataset.start) <= t) found = segs[i]; else break;
+    }
+    if (found === current) return;
+    if (current) current.classList.remove("active");
+    if (found) found.classList.add("active");
+    current = found;
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// saveHTML 保存为独立的 HTML 校对页面，媒体文件以相对路径引用
+func saveHTML(result *TranscriptionResult, inputFile, outputPath string) error {
+	page := htmlPage{
+		Lang:  uiLang,
+		Title: filepath.Base(inputFile),
+		Media: htmlMediaURL(inputFile, filepath.Dir(outputPath)),
+		Video: isVideoFile(inputFile),
+		Text:  strings.TrimSpace(result.Text),
+		Labels: map[string]string{
+			"high": tr("高置信度"),
+			"mid":  tr("中置信度"),
+			"low":  tr("低置信度，建议核对"),
+		},
+	}
+	for _, seg := range result.Segments {
+		page.Segments = append(page.Segments, htmlSegment{
+			Start:      seg.Start,
+			Time:       formatChapterTime(seg.Start),
+			Speaker:    seg.Speaker,
+			Text:       strings.TrimSpace(seg.Text),
+			Class:      confidenceClass(seg),
+			Confidence: confidenceTitle(seg),
+			Flags:      strings.Join(seg.Flags, ", "),
+		})
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	if err := htmlTemplate.Execute(f, page); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// confidenceClass 按平均 token 概率分级，没有置信度信息的分段为 conf-unknown
+func confidenceClass(seg Segment) string {
+	if seg.AvgLogprob == 0 {
+		return "conf-unknown"
+	}
+	p := math.Exp(seg.AvgLogprob)
+	switch {
+	case p >= htmlConfidenceHigh:
+		return "conf-high"
+	case p >= htmlConfidenceLow:
+		return "conf-mid"
+	default:
+		return "conf-low"
+	}
+}
+
+// confidenceTitle 分段的置信度提示文本
+func confidenceTitle(seg Segment) string {
+	if seg.AvgLogprob == 0 {
+		return ""
+	}
+	return fmt.Sprintf(tr("置信度: %.0f%%"), math.Exp(seg.AvgLogprob)*100)
+}
+
+// htmlMediaURL 计算页面引用媒体文件的地址：优先使用相对路径，无法计算时使用 file:// 绝对地址
+func htmlMediaURL(inputFile, outputDir string) string {
+	absInput, err := filepath.Abs(inputFile)
+	if err != nil {
+		return ""
+	}
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return ""
+	}
+	if rel, err := filepath.Rel(absOutput, absInput); err == nil {
+		return (&url.URL{Path: filepath.ToSlash(rel)}).String()
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(absInput)}).String()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// 界面语言
+const (
+	uiLangZH = "zh"
+	uiLangEN = "en"
+)
+
+// uiLang 当前界面语言，由 initUILanguage 和 useUILanguage 设置
+var uiLang = uiLangZH
+
+// uiLangFromFlag 是否已通过 --lang-ui 指定界面语言（优先于配置文件）
+var uiLangFromFlag bool
+
+// messageCatalogs 各语言的消息目录，以中文原文为键
+var messageCatalogs = map[string]map[string]string{
+	uiLangEN: messagesEN,
+}
+
+// tr 返回消息在当前界面语言下的译文，没有译文时返回中文原文
+func tr(msg string) string {
+	if catalog, ok := messageCatalogs[uiLang]; ok {
+		if s, ok := catalog[msg]; ok {
+			return s
+		}
+	}
+	return msg
+}
+
+// trError 包级哨兵错误：包初始化时界面语言尚未确定，消息在 Error() 时才按当前界面语言翻译。
+// 值可比较，errors.Is 和 %w 包装与 errors.New 创建的错误用法相同
+type trError string
+
+// Error 返回当前界面语言下的错误消息
+func (e trError) Error() string {
+	return tr(string(e))
+}
+
+// initUILanguage 在解析命令行参数之前确定界面语言：--lang-ui 优先，其次为 LC_ALL、LC_MESSAGES、LANG 环境变量
+// （中文以外的语言环境使用英文）。--lang-ui 可出现在任意子命令中，处理后从 os.Args 中移除
+func initUILanguage() {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if lang, ok := parseUILanguage(value); ok {
+				uiLang = lang
+			} else if value != "C" && value != "POSIX" && !strings.HasPrefix(value, "C.") {
+				uiLang = uiLangEN
+			}
+			break
+		}
+	}
+
+	if value, ok := popGlobalFlag("lang-ui"); ok {
+		if lang, ok := parseUILanguage(value); ok {
+			uiLang = lang
+			uiLangFromFlag = true
+		}
+	}
+}
+
+// useUILanguage 按配置文件的 ui_language 设置界面语言（未通过 --lang-ui 指定时）
+func useUILanguage(config *Config) {
+	if uiLangFromFlag || config.UILanguage == "" {
+		return
+	}
+	if lang, ok := parseUILanguage(config.UILanguage); ok {
+		uiLang = lang
+	}
+}
+
+// parseUILanguage 解析 zh、en、zh_CN.UTF-8、en-US 等语言标识，C/POSIX 等无法识别的值返回 false
+func parseUILanguage(value string) (string, bool) {
+	lang := strings.ToLower(value)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case uiLangZH, uiLangEN:
+		return lang, true
+	}
+	return "", false
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonlSegments 转写结果按分段输出的内容，没有分段时整段文本作为一个分段
+func jsonlSegments(result *TranscriptionResult) []Segment {
+	if len(result.Segments) == 0 && result.Text != "" {
+		return []Segment{{Start: 0, End: result.Duration, Text: result.Text}}
+	}
+	return result.Segments
+}
+
+// encodeJSONL 每个分段编码为一行 JSON
+func encodeJSONL(segments []Segment) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, seg := range segments {
+		if err := enc.Encode(seg); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// saveJSONL 保存 JSON Lines 格式，每行一个分段，便于下游逐行处理
+func saveJSONL(result *TranscriptionResult, outputPath string) error {
+	data, err := encodeJSONL(jsonlSegments(result))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// parseJSONL 解析 JSON Lines 格式的分段，跳过空行
+func parseJSONL(data []byte) ([]Segment, error) {
+	var segments []Segment
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var seg Segment
+		if err := json.Unmarshal(line, &seg); err != nil {
+			return nil, fmt.Errorf(tr("第 %d 行: %w"), i+1, err)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
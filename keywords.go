@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// 命名实体的类型
+var entityTypes = []string{"person", "organization", "location", "product", "event", "other"}
+
+// KeywordReport 关键词和命名实体，按首次出现的时间排序
+type KeywordReport struct {
+	Keywords []KeywordMention `json:"keywords"`
+	Entities []KeywordMention `json:"entities"`
+}
+
+// KeywordMention 一个关键词或命名实体在转写中的出现情况
+type KeywordMention struct {
+	Term string `json:"term"`
+	// Type 命名实体的类型（person、organization、location、product、event、other），关键词为空
+	Type         string  `json:"type,omitempty"`
+	FirstMention float64 `json:"first_mention"`
+	// Segment 首次出现的分段序号
+	Segment  int `json:"segment"`
+	Mentions int `json:"mentions"`
+}
+
+// entityCandidate 模型返回的命名实体
+type entityCandidate struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// keywordsPrompt 提取关键词和命名实体的提示
+const keywordsPrompt = `You extract keywords and named entities from transcripts.
+Return 10 to 30 keywords (topics, technical terms, recurring concepts) and all named entities
+(people, organizations, locations, products, events). Write each term exactly as it appears in the transcript,
+without translating or normalizing it, so it can be found in the text.
+Reply with JSON only: {"keywords":["<term>"],"entities":[{"name":"<term>","type":"person|organization|location|product|event|other"}]}`
+
+// wantKeywords 是否请求了关键词格式
+func wantKeywords(formatList []string) bool {
+	for _, format := range formatList {
+		if format == "keywords" || format == "keywords-json" {
+			return true
+		}
+	}
+	return false
+}
+
+// extractKeywords 由 keywords_model 指定的对话模型挑选关键词和命名实体，首次出现的时间和次数在转写中查找，
+// 转写中找不到的词（模型改写或编造的）会被丢弃。失败时返回 nil，关键词格式不输出
+func extractKeywords(client *openai.Client, result *TranscriptionResult, config *Config) *KeywordReport {
+	if config.KeywordsModel == "" {
+		logError(tr("输出关键词需要配置 keywords_model（提取关键词用的对话模型）"))
+		return nil
+	}
+	text := result.Text
+	if text == "" {
+		text = segmentsText(result.Segments)
+	}
+	keywords, entities, err := keywordsFromModel(client, text, config.KeywordsModel)
+	if err != nil {
+		logError(tr("提取关键词失败: %v"), err)
+		return nil
+	}
+
+	report := &KeywordReport{Keywords: []KeywordMention{}, Entities: []KeywordMention{}}
+	seen := map[string]bool{}
+	locate := func(term, entityType string) (KeywordMention, bool) {
+		term = strings.TrimSpace(term)
+		key := normalizeKeyword(term)
+		if key == "" || seen[key] {
+			return KeywordMention{}, false
+		}
+		seen[key] = true
+		mention := KeywordMention{Term: term, Type: entityType}
+		for _, seg := range result.Segments {
+			n := countKeyword(normalizeKeyword(seg.Text), key)
+			if n > 0 && mention.Mentions == 0 {
+				mention.FirstMention, mention.Segment = seg.Start, seg.ID
+			}
+			mention.Mentions += n
+		}
+		return mention, mention.Mentions > 0
+	}
+	// 同一个词既是实体又是关键词时只作为实体列出
+	for _, e := range entities {
+		entityType := strings.ToLower(strings.TrimSpace(e.Type))
+		if !slices.Contains(entityTypes, entityType) {
+			entityType = "other"
+		}
+		if m, ok := locate(e.Name, entityType); ok {
+			report.Entities = append(report.Entities, m)
+		}
+	}
+	for _, k := range keywords {
+		if m, ok := locate(k, ""); ok {
+			report.Keywords = append(report.Keywords, m)
+		}
+	}
+	byTime := func(list []KeywordMention) {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].FirstMention < list[j].FirstMention })
+	}
+	byTime(report.Keywords)
+	byTime(report.Entities)
+	return report
+}
+
+// keywordsFromModel 把转写全文发给对话模型，返回模型挑选的关键词和命名实体
+func keywordsFromModel(client *openai.Client, text, model string) ([]string, []entityCandidate, error) {
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: keywordsPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: text},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, nil, errors.New(tr("模型没有返回结果"))
+	}
+
+	var reply struct {
+		Keywords []string          `json:"keywords"`
+		Entities []entityCandidate `json:"entities"`
+	}
+	if err := parseChatJSON(resp.Choices[0].Message.Content, &reply); err != nil {
+		return nil, nil, fmt.Errorf(tr("解析模型返回的关键词失败: %w"), err)
+	}
+	return reply.Keywords, reply.Entities, nil
+}
+
+// normalizeKeyword 查找关键词时忽略大小写和全半角
+func normalizeKeyword(s string) string {
+	return strings.ToLower(foldFullWidth(strings.TrimSpace(s)))
+}
+
+// countKeyword 统计 key 在 text 中出现的次数。以字母或数字开头、结尾的词要求两侧不是字母或数字，
+// 避免 AI 匹配到 said；中日韩文字没有词边界，直接按子串匹配
+func countKeyword(text, key string) int {
+	isWordRune := func(r rune) bool {
+		return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+	}
+	first, _ := utf8.DecodeRuneInString(key)
+	last, _ := utf8.DecodeLastRuneInString(key)
+
+	count := 0
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], key)
+		if i < 0 {
+			return count
+		}
+		start, end := offset+i, offset+i+len(key)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !(isWordRune(first) && start > 0 && isWordRune(before)) && !(isWordRune(last) && end < len(text) && isWordRune(after)) {
+			count++
+		}
+		offset = start + len(key)
+	}
+}
+
+// entityTypeLabel 命名实体类型的显示名称
+func entityTypeLabel(entityType string) string {
+	switch entityType {
+	case "person":
+		return tr("人物")
+	case "organization":
+		return tr("组织")
+	case "location":
+		return tr("地点")
+	case "product":
+		return tr("产品")
+	case "event":
+		return tr("事件")
+	default:
+		return tr("其他")
+	}
+}
+
+// saveKeywordsJSON 保存 JSON 格式的关键词和命名实体
+func saveKeywordsJSON(result *TranscriptionResult, outputPath string) error {
+	if result.Keywords == nil {
+		return errors.New(tr("没有关键词"))
+	}
+	data, err := json.MarshalIndent(result.Keywords, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// saveKeywords 保存 Markdown 格式的关键词和命名实体：按首次出现的时间列出，便于在长录音中定位
+func saveKeywords(result *TranscriptionResult, outputPath string) error {
+	report := result.Keywords
+	if report == nil {
+		return errors.New(tr("没有关键词"))
+	}
+	cell := func(s string) string {
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", tr("关键词"))
+	if len(report.Keywords) == 0 {
+		b.WriteString(tr("（无）") + "\n")
+	} else {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n|---|---|---|\n", tr("首次出现"), tr("关键词"), tr("次数"))
+		for _, k := range report.Keywords {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", formatChapterTime(k.FirstMention), cell(k.Term), k.Mentions)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n# %s\n\n", tr("命名实体"))
+	if len(report.Entities) == 0 {
+		b.WriteString(tr("（无）") + "\n")
+	} else {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n|---|---|---|---|\n", tr("首次出现"), tr("名称"), tr("类型"), tr("次数"))
+		for _, e := range report.Entities {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d |\n", formatChapterTime(e.FirstMention), cell(e.Term), entityTypeLabel(e.Type), e.Mentions)
+		}
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// defaultLanguageProbeSeconds 语言探测默认截取的开头时长（秒），与 Whisper 检测语言时使用的窗口一致
+const defaultLanguageProbeSeconds = 30
+
+// languageProbeMinConfidence 探测结果的平均 token 概率低于该值时不固定语言，仍按自动检测转写
+const languageProbeMinConfidence = 0.4
+
+// whisperLanguages Whisper 返回的语言名称到语言代码的映射（OpenAI 接口返回名称，其他服务商返回代码）
+var whisperLanguages = map[string]string{
+	"english": "en", "chinese": "zh", "german": "de", "spanish": "es", "russian": "ru",
+	"korean": "ko", "french": "fr", "japanese": "ja", "portuguese": "pt", "turkish": "tr",
+	"polish": "pl", "catalan": "ca", "dutch": "nl", "arabic": "ar", "swedish": "sv",
+	"italian": "it", "indonesian": "id", "hindi": "hi", "finnish": "fi", "vietnamese": "vi",
+	"hebrew": "he", "ukrainian": "uk", "greek": "el", "malay": "ms", "czech": "cs",
+	"romanian": "ro", "danish": "da", "hungarian": "hu", "tamil": "ta", "norwegian": "no",
+	"thai": "th", "urdu": "ur", "croatian": "hr", "bulgarian": "bg", "lithuanian": "lt",
+	"latin": "la", "maori": "mi", "malayalam": "ml", "welsh": "cy", "slovak": "sk",
+	"telugu": "te", "persian": "fa", "latvian": "lv", "bengali": "bn", "serbian": "sr",
+	"azerbaijani": "az", "slovenian": "sl", "kannada": "kn", "estonian": "et", "macedonian": "mk",
+	"breton": "br", "basque": "eu", "icelandic": "is", "armenian": "hy", "nepali": "ne",
+	"mongolian": "mn", "bosnian": "bs", "kazakh": "kk", "albanian": "sq", "swahili": "sw",
+	"galician": "gl", "marathi": "mr", "punjabi": "pa", "sinhala": "si", "khmer": "km",
+	"shona": "sn", "yoruba": "yo", "somali": "so", "afrikaans": "af", "occitan": "oc",
+	"georgian": "ka", "belarusian": "be", "tajik": "tg", "sindhi": "sd", "gujarati": "gu",
+	"amharic": "am", "yiddish": "yi", "lao": "lo", "uzbek": "uz", "faroese": "fo",
+	"haitian creole": "ht", "pashto": "ps", "turkmen": "tk", "nynorsk": "nn", "maltese": "mt",
+	"sanskrit": "sa", "luxembourgish": "lb", "myanmar": "my", "tibetan": "bo", "tagalog": "tl",
+	"malagasy": "mg", "assamese": "as", "tatar": "tt", "hawaiian": "haw", "lingala": "ln",
+	"hausa": "ha", "bashkir": "ba", "javanese": "jw", "sundanese": "su", "cantonese": "yue",
+}
+
+// languageCode 将服务商返回的语言名称或代码统一为语言代码，无法识别时返回空字符串
+func languageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code, ok := whisperLanguages[language]; ok {
+		return code
+	}
+	for _, code := range whisperLanguages {
+		if language == code {
+			return code
+		}
+	}
+	// 带地区的代码（如 en-US、zh_CN）只取语言部分
+	if base, _, ok := strings.Cut(strings.ReplaceAll(language, "_", "-"), "-"); ok {
+		return languageCode(base)
+	}
+	return ""
+}
+
+// pinProbedLanguage 自动检测语言时先转写开头一段探测语言，再以探测到的语言转写全部音频，
+// 避免各切片分别检测出不同的语言。探测失败或置信度过低时返回原配置
+func pinProbedLanguage(client *openai.Client, audioPath string, config *Config, verbose bool) *Config {
+	language, confidence, err := probeLanguage(client, audioPath, config, verbose)
+	if err != nil {
+		logWarn(tr("语言探测失败，改为自动检测: %v"), err)
+		return config
+	}
+	if language == "" {
+		return config
+	}
+	if confidence > 0 && confidence < languageProbeMinConfidence {
+		logWarn(tr("语言探测结果 %s 置信度过低（%.0f%%），改为自动检测"), language, confidence*100)
+		return config
+	}
+
+	if confidence > 0 {
+		logInfo(tr("探测到语言: %s（置信度 %.0f%%）"), language, confidence*100)
+	} else {
+		logInfo(tr("探测到语言: %s"), language)
+	}
+	pinned := *config
+	pinned.Language = language
+	pinned.AutoDetect = false
+	return &pinned
+}
+
+// probeLanguage 截取开头 language_probe_seconds 秒自动检测语言，返回语言代码和探测片段的平均 token 概率
+// （服务商未返回置信度时为 0）。音频不长于探测时长时返回空语言，直接按自动检测转写即可
+func probeLanguage(client *openai.Client, audioPath string, config *Config, verbose bool) (string, float64, error) {
+	duration, err := getAudioDuration(audioPath)
+	if err != nil {
+		return "", 0, err
+	}
+	if duration <= config.LanguageProbeSeconds {
+		return "", 0, nil
+	}
+
+	probePath := trackTemp(filepath.Join(workingDir(), fmt.Sprintf("whisper_probe_%d.wav", time.Now().UnixNano())))
+	defer removeTemp(probePath)
+	if err := cutAudioChunk(audioPath, fastSliceWAVInfo(audioPath), 0, config.LanguageProbeSeconds, probePath); err != nil {
+		return "", 0, fmt.Errorf(tr("截取探测片段失败: %w"), err)
+	}
+
+	logDebug(tr("正在探测语言（前 %.0f 秒）\n"), config.LanguageProbeSeconds)
+	result, err := transcribeWithBackends(client, probePath, config, verbose)
+	if err != nil {
+		return "", 0, err
+	}
+	if strings.TrimSpace(result.Text) == "" {
+		return "", 0, errors.New(tr("探测片段中没有语音"))
+	}
+	language := languageCode(result.Language)
+	if language == "" {
+		return "", 0, fmt.Errorf(tr("无法识别服务商返回的语言: %q"), result.Language)
+	}
+
+	var confidence float64
+	if logprob := averageLogprob(result); logprob != 0 {
+		confidence = math.Exp(logprob)
+	}
+	return language, confidence, nil
+}
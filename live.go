@@ -0,0 +1,221 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// livePollInterval 检查新音频片段的间隔
+const livePollInterval = 500 * time.Millisecond
+
+// runLive 执行 live 子命令：持续录制直播流或采集设备，按片段转写，
+// 输出按时长轮转为多个文件，时间戳在所有文件间连续
+func runLive(args []string) {
+	fs := flag.NewFlagSet("live", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	outputDir := fs.String("output", "", tr("输出目录"))
+	formats := fs.String("formats", "srt,txt", tr("输出格式（逗号分隔，支持 txt, srt, json, jsonl）"))
+	name := fs.String("name", "live", tr("输出文件名前缀"))
+	inputFormat := fs.String("input-format", "", tr("ffmpeg 输入格式（如 pulse、dshow、avfoundation），采集设备时需要"))
+	segment := fs.Float64("segment", 30, tr("每次转写的音频片段时长（秒）"))
+	rotate := fs.Float64("rotate", 15, tr("每隔多少分钟轮转一次输出文件（0 为不轮转）"))
+	captionListen := fs.String("captions", "", tr("实时字幕 WebSocket 服务的监听地址（如 :8765），覆盖配置中的 caption_listen"))
+	verbose := fs.Bool("verbose", false, tr("显示详细输出"))
+	fs.Parse(args)
+	useVerboseLogging(*verbose)
+	installShutdownHandler()
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go live <source> [options]"))
+		fmt.Println(tr("source 为 ffmpeg 可读取的输入，如 rtmp://、srt://、http:// 直播地址或采集设备"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	source := fs.Arg(0)
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fatalf(tr("加载配置失败: %v"), err)
+	}
+	if config.APIKey == "" && config.needsAPIKey() {
+		fatal(tr("配置文件中未设置 API Key，请先在 config.json 中配置 api_key"))
+	}
+	if *outputDir != "" {
+		config.OutputDir = *outputDir
+	}
+	if *captionListen != "" {
+		config.CaptionListen = *captionListen
+	}
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		fatalf(tr("创建输出目录失败: %v"), err)
+	}
+
+	prefix := fmt.Sprintf("%s_%s", *name, time.Now().Format("20060102_150405"))
+	writer := newRollingWriter(config.OutputDir, prefix, parseFormats(*formats), *rotate*60)
+
+	var inputArgs []string
+	if *inputFormat != "" {
+		inputArgs = []string{"-f", *inputFormat}
+	}
+	logInfo(tr("正在转写: %s（Ctrl+C 结束）\n"), source)
+	offset := runLiveSession(newClient(config), config, source, inputArgs, *segment, writer, *verbose)
+	printLiveSummary(config, writer, offset)
+}
+
+// runLiveSession 用 ffmpeg 将输入切成固定时长的片段，逐片转写并写入 writer，直到 ffmpeg 退出；返回已转写的音频时长
+func runLiveSession(client *openai.Client, config *Config, source string, inputArgs []string, segment float64, writer *rollingWriter, verbose bool) float64 {
+	sink, err := newSegmentSink(config)
+	if err != nil {
+		logWarn(tr("初始化实时字幕输出失败: %v"), err)
+	}
+	if sink != nil {
+		defer sink.Close()
+	}
+
+	workDir, err := os.MkdirTemp(workingDir(), "whisper_live_")
+	if err != nil {
+		fatalf(tr("创建临时目录失败: %v"), err)
+	}
+	defer removeTemp(trackTemp(workDir))
+
+	// ffmpeg 把输入切成固定时长的 WAV 片段，转写与录制同时进行
+	cmd := exec.Command(ffmpegTools.FFmpeg, liveFFmpegArgs(source, inputArgs, segment, workDir)...)
+	if verbose {
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Start(); err != nil {
+		fatalf(tr("启动 ffmpeg 失败: %v"), err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	defer onShutdown(func() { cmd.Process.Kill() })()
+
+	// 第一次 Ctrl+C 让 ffmpeg 写完最后一个片段，转写完剩余片段后再退出；
+	// 再按一次或收到 SIGTERM 时由 installShutdownHandler 取消请求并删除工作目录
+	interrupt := make(chan struct{})
+	setGracefulStop(func() { close(interrupt) })
+	defer setGracefulStop(nil)
+
+	var offset float64
+	running := true
+	for index := 0; ; {
+		current := liveSegmentPath(workDir, index)
+		_, nextErr := os.Stat(liveSegmentPath(workDir, index+1))
+		_, curErr := os.Stat(current)
+
+		// 下一个片段出现或 ffmpeg 已退出时，当前片段才算写完
+		if curErr == nil && (nextErr == nil || !running) {
+			duration, err := transcribeLiveSegment(client, config, current, offset, writer, sink, verbose)
+			if err != nil {
+				logError(tr("片段 %d 转写失败: %v"), index+1, err)
+			}
+			os.Remove(current)
+			offset += duration
+			index++
+			continue
+		}
+		if !running {
+			break
+		}
+
+		select {
+		case err := <-exited:
+			running = false
+			if err != nil {
+				logDebug(tr("ffmpeg 已退出: %v"), err)
+			}
+		case <-interrupt:
+			logInfo(tr("\n正在结束，转写剩余片段..."))
+			cmd.Process.Signal(os.Interrupt)
+			interrupt = nil
+		case <-time.After(livePollInterval):
+		}
+	}
+	return offset
+}
+
+// printLiveSummary 输出转写时长和所有写入的文件
+func printLiveSummary(config *Config, writer *rollingWriter, offset float64) {
+	files := writer.writtenFiles()
+	fmt.Printf(tr("\n共转写 %s，输出文件:\n"), formatSRTTime(offset))
+	for _, file := range files {
+		fmt.Printf("  - %s\n", file)
+	}
+	if len(files) > len(writer.formats) {
+		fmt.Printf(tr("\n可用 whisper-go stitch 合并分段文件，如: whisper-go stitch %s\n"), filepath.Join(config.OutputDir, writer.prefix+"_part*.srt"))
+	}
+}
+
+// liveFFmpegArgs 构建录制并按固定时长切片的 ffmpeg 参数，inputArgs 为放在 -i 之前的输入选项
+func liveFFmpegArgs(source string, inputArgs []string, segment float64, workDir string) []string {
+	args := append([]string{"-hide_banner", "-loglevel", "error"}, ffmpegTools.InputArgs...)
+	args = append(args, inputArgs...)
+	return append(args,
+		"-i", source,
+		"-vn",
+		"-acodec", "pcm_s16le",
+		"-ar", "16000",
+		"-ac", "1",
+		"-f", "segment",
+		"-segment_time", fmt.Sprintf("%.3f", segment),
+		"-reset_timestamps", "1",
+		filepath.Join(workDir, "seg_%06d.wav"),
+	)
+}
+
+// liveSegmentPath 第 index 个片段的路径（与 ffmpeg segment 的命名一致）
+func liveSegmentPath(workDir string, index int) string {
+	return filepath.Join(workDir, fmt.Sprintf("seg_%06d.wav", index))
+}
+
+// transcribeLiveSegment 转写一个片段并写入轮转输出，返回片段时长
+func transcribeLiveSegment(client *openai.Client, config *Config, path string, offset float64, writer *rollingWriter, sink SegmentSink, verbose bool) (float64, error) {
+	duration, err := getAudioDuration(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// 先检查轮转，保证同一片段的分段写入同一个文件
+	writer.maybeRotate(offset)
+
+	result, err := transcribeWithBackends(client, path, config, verbose)
+	if err != nil {
+		return duration, err
+	}
+	filterSegments(result, config)
+	applyGlossary(result, config)
+	applyReplacements(result, config)
+	convertChinese(result, config)
+	redactResult(client, result, config)
+
+	var segments []Segment
+	for _, seg := range result.Segments {
+		if seg.Text == "" {
+			continue
+		}
+		seg.Start += offset
+		seg.End += offset
+		for i := range seg.Words {
+			seg.Words[i].Start += offset
+			seg.Words[i].End += offset
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 && result.Text != "" && len(result.Segments) == 0 {
+		segments = append(segments, Segment{Start: offset, End: offset + duration, Text: result.Text})
+	}
+
+	// 分段已在上面脱敏
+	pushSegments(sink, segments)
+	for _, seg := range segments {
+		fmt.Printf("[%s] %s\n", formatSRTTime(seg.Start), seg.Text)
+	}
+	return duration, writer.add(segments, result.Language)
+}
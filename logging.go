@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// logLevel 当前日志级别，默认 info，--verbose 时为 debug
+var logLevel = new(slog.LevelVar)
+
+// logLevelFromFlag 是否已通过 --log-level 指定日志级别（优先于 --verbose）
+var logLevelFromFlag bool
+
+// logJSON 是否以 JSON 格式输出日志
+var logJSON bool
+
+// initLogging 在解析命令行参数之前按 --log-level、--log-format、--log-file 初始化日志。
+// 这些参数可出现在任意子命令中，处理后从 os.Args 中移除
+func initLogging() {
+	if value, ok := popGlobalFlag("log-level"); ok {
+		level, err := parseLogLevel(value)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		logLevel.Set(level)
+		logLevelFromFlag = true
+	}
+
+	format, _ := popGlobalFlag("log-format")
+	switch format {
+	case "", "text":
+	case "json":
+		logJSON = true
+	default:
+		fmt.Fprintf(os.Stderr, tr("无效的 --log-format: %s（可选 text, json）")+"\n", format)
+		os.Exit(2)
+	}
+
+	var out io.Writer = os.Stderr
+	if path, ok := popGlobalFlag("log-file"); ok && path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, tr("打开日志文件失败: %v")+"\n", err)
+			os.Exit(2)
+		}
+		out = f
+	}
+
+	var handler slog.Handler
+	if logJSON {
+		handler = slog.NewJSONHandler(out, &slog.HandlerOptions{Level: logLevel})
+	} else {
+		handler = &plainHandler{out: out, level: logLevel, mu: &sync.Mutex{}}
+	}
+	// 依赖库通过标准 log 包输出的内容同样进入结构化日志
+	slog.SetDefault(slog.New(handler))
+	log.SetFlags(0)
+}
+
+// useVerboseLogging --verbose 时开启 debug 日志（未通过 --log-level 指定级别时）
+func useVerboseLogging(verbose bool) {
+	if verbose && !logLevelFromFlag {
+		logLevel.Set(slog.LevelDebug)
+	}
+}
+
+// useQuietLogging --quiet 时只输出 error 日志（优先于 --log-level 和 --verbose）
+func useQuietLogging() {
+	logLevel.Set(slog.LevelError)
+	logLevelFromFlag = true
+}
+
+// parseLogLevel 解析 debug、info、warn、error
+func parseLogLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf(tr("无效的 --log-level: %s（可选 debug, info, warn, error）"), value)
+}
+
+// logDebug 输出 debug 日志（处理过程的详细信息）
+func logDebug(format string, args ...any) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// logInfo 输出 info 日志（任务开始、完成等进度）
+func logInfo(format string, args ...any) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// logWarn 输出 warn 日志（不影响结果的失败，如通知推送失败、重试）
+func logWarn(format string, args ...any) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// logError 输出 error 日志
+func logError(format string, args ...any) {
+	logf(slog.LevelError, format, args...)
+}
+
+// fatalf 输出 error 日志后退出
+func fatalf(format string, args ...any) {
+	exitWith(exitFailure, format, args...)
+}
+
+// exitWith 输出 error 日志后以指定的退出码退出
+func exitWith(code int, format string, args ...any) {
+	logf(slog.LevelError, format, args...)
+	exit(code)
+}
+
+// exit 上报完遥测数据后以指定的退出码退出，用于已经输出过结果、不需要再记录错误的情况（如部分成功）
+func exit(code int) {
+	shutdownTelemetry()
+	os.Exit(code)
+}
+
+// fatal 输出 error 日志后退出
+func fatal(msg string) {
+	logf(slog.LevelError, "%s", msg)
+	exit(1)
+}
+
+// logf 格式化消息并按级别输出。消息中用于排版的换行在 JSON 格式下去掉
+func logf(level slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	logger := slog.Default()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	msg := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	if logJSON {
+		msg = strings.TrimSpace(msg)
+	}
+	logger.Log(ctx, level, msg)
+}
+
+// plainHandler 默认的文本日志：debug/info 只输出消息本身，warn/error 带时间和级别
+type plainHandler struct {
+	out   io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+	mu    *sync.Mutex
+}
+
+// Enabled 实现 slog.Handler
+func (h *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle 实现 slog.Handler
+func (h *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	if r.Level >= slog.LevelWarn {
+		b.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+		b.WriteString(r.Level.String())
+		b.WriteString(" ")
+	}
+	b.WriteString(r.Message)
+	appendAttr := func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		appendAttr(a)
+	}
+	r.Attrs(appendAttr)
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+// WithAttrs 实现 slog.Handler
+func (h *plainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &h2
+}
+
+// WithGroup 实现 slog.Handler（文本日志不区分分组）
+func (h *plainHandler) WithGroup(string) slog.Handler {
+	return h
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 弱网预设的参数
+const (
+	lowBandwidthMaxFileSizeMB  = 1
+	lowBandwidthMaxRetries     = 10
+	lowBandwidthRequestTimeout = 600 // 秒
+	defaultUploadBitrate       = "16k"
+)
+
+// applyLowBandwidthPreset 弱网预设：上传前压缩为低码率 Opus、使用小切片、延长超时、增加重试并开启 resume。
+// 转写接口不支持续传单次上传，上传中断时整个切片重新上传，小切片让重传的代价更小；resume 只在重新运行时跳过已完成的切片。
+// 用户显式配置的上传编码、超时和关闭的重试保持不变
+func (c *Config) applyLowBandwidthPreset() {
+	if c.UploadCodec == "" {
+		c.UploadCodec = "opus"
+	}
+	if c.MaxFileSizeMB > lowBandwidthMaxFileSizeMB {
+		c.MaxFileSizeMB = lowBandwidthMaxFileSizeMB
+	}
+	// 显式配置 max_retries 为 0（不重试）时保持不变
+	if retries := c.maxRetries(); retries > 0 && retries < lowBandwidthMaxRetries {
+		retries = lowBandwidthMaxRetries
+		c.MaxRetries = &retries
+	}
+	if c.RequestTimeout == 0 {
+		c.RequestTimeout = lowBandwidthRequestTimeout
+	}
+	c.Resume = true
+}
+
+// prepareUpload 按 upload_codec 将音频压缩后再上传，返回实际上传的文件和清理函数。
+// 未配置压缩时直接返回原文件
+func prepareUpload(audioPath string, config *Config, verbose bool) (string, func(), error) {
+	switch config.UploadCodec {
+	case "":
+		return audioPath, func() {}, nil
+	case "opus":
+	default:
+		return "", nil, fmt.Errorf(tr("不支持的 upload_codec: %s（可选 opus）"), config.UploadCodec)
+	}
+
+	bitrate := config.UploadBitrate
+	if bitrate == "" {
+		bitrate = defaultUploadBitrate
+	}
+	uploadPath := trackTemp(filepath.Join(workingDir(), fmt.Sprintf("whisper_upload_%d.ogg", time.Now().UnixNano())))
+
+	args := ffmpegArgs(audioPath,
+		"-vn",
+		"-ac", "1",
+		"-ar", "16000",
+		"-c:a", "libopus",
+		"-b:a", bitrate,
+		"-application", "voip",
+		"-y",
+		uploadPath,
+	)
+	if output, err := exec.Command(ffmpegTools.FFmpeg, args...).CombinedOutput(); err != nil {
+		removeTemp(uploadPath)
+		return "", nil, fmt.Errorf(tr("压缩上传音频失败: %w: %s"), err, lastLine(string(output)))
+	}
+
+	before, _ := getFileSizeMB(audioPath)
+	after, _ := getFileSizeMB(uploadPath)
+	logDebug(tr("上传音频已压缩为 Opus %s: %.2f MB -> %.2f MB\n"), bitrate, before, after)
+	return uploadPath, func() { removeTemp(uploadPath) }, nil
+}
+
+// lastLine 返回输出的最后一个非空行（ffmpeg 的错误原因通常在最后）
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// resumeStore 断点续传：保存已完成切片的转写结果，中断后重新运行时跳过这些切片
+type resumeStore struct {
+	dir string
+}
+
+// newResumeStore 按输入文件和转写参数确定续传目录，未开启续传时返回 nil
+func newResumeStore(inputFile, localInput string, config *Config) *resumeStore {
+	if !config.Resume {
+		return nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	// 同一文件（路径、大小、修改时间）使用相同参数转写时才复用结果
+	identity := []string{inputFile, config.Model, config.Language, fmt.Sprint(config.AutoDetect), fmt.Sprint(config.MaxFileSizeMB)}
+	if info, err := os.Stat(localInput); err == nil {
+		identity = append(identity, fmt.Sprint(info.Size()))
+		if !isRemoteURI(inputFile) {
+			identity = append(identity, info.ModTime().UTC().String())
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(identity, "\x00")))
+	return &resumeStore{dir: filepath.Join(cacheDir, "whisper-go", "resume", hex.EncodeToString(sum[:8]))}
+}
+
+// chunkPath 切片结果的保存路径
+func (s *resumeStore) chunkPath(chunk AudioChunk) string {
+	return filepath.Join(s.dir, fmt.Sprintf("chunk_%.3f_%.3f.json", chunk.StartOffset, chunk.EndOffset))
+}
+
+// load 读取已保存的切片结果
+func (s *resumeStore) load(chunk AudioChunk) *TranscriptionResult {
+	if s == nil {
+		return nil
+	}
+	data, err := os.ReadFile(s.chunkPath(chunk))
+	if err != nil {
+		return nil
+	}
+	var result TranscriptionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+// save 保存切片结果，失败只影响续传不影响转写
+func (s *resumeStore) save(chunk AudioChunk, result *TranscriptionResult) {
+	if s == nil {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return
+	}
+	os.WriteFile(s.chunkPath(chunk), data, 0644)
+}
+
+// clear 任务完成后删除续传数据
+func (s *resumeStore) clear() {
+	if s == nil {
+		return
+	}
+	os.RemoveAll(s.dir)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// JSON-RPC 2.0 错误码
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcJobFailed      = -32000
+)
+
+// rpcMessage JSON-RPC 2.0 消息（请求、响应和通知共用）
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError JSON-RPC 错误
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcRequest 收到的请求
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// transcribeParams transcribe 方法的参数，未设置的字段沿用命令行和配置文件
+type transcribeParams struct {
+	Input      string   `json:"input"`
+	Language   string   `json:"language,omitempty"`
+	Model      string   `json:"model,omitempty"`
+	AutoDetect bool     `json:"auto_detect,omitempty"`
+	Output     string   `json:"output,omitempty"`
+	Formats    []string `json:"formats,omitempty"`
+}
+
+// transcribeReply transcribe 方法的返回值
+type transcribeReply struct {
+	Result      *TranscriptionResult `json:"result"`
+	OutputFiles []string             `json:"output_files"`
+}
+
+// progressParams progress 通知的参数，id 为对应请求的 id
+type progressParams struct {
+	ID      json.RawMessage `json:"id"`
+	Stage   string          `json:"stage"`
+	Current int             `json:"current"`
+	Total   int             `json:"total"`
+}
+
+// machineServer 通过标准输入输出以换行分隔的 JSON-RPC 2.0 驱动转写，
+// 供 GUI 和编辑器插件调用，标准输出只输出协议消息，日志输出到标准错误
+type machineServer struct {
+	client     *openai.Client
+	config     *Config
+	formatList []string
+
+	mu  sync.Mutex
+	out *json.Encoder
+	wg  sync.WaitGroup
+}
+
+// runMachine 执行 --machine 模式，标准输入关闭或收到 shutdown 后等待进行中的任务完成再退出
+func runMachine(client *openai.Client, config *Config, formatList []string) {
+	m := &machineServer{
+		client:     client,
+		config:     config,
+		formatList: formatList,
+		out:        json.NewEncoder(os.Stdout),
+	}
+	m.serve(os.Stdin)
+	m.wg.Wait()
+}
+
+// serve 逐行读取请求，transcribe 在后台执行，多个任务可以并行
+func (m *machineServer) serve(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			m.reply(nil, nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			m.reply(req.ID, nil, &rpcError{Code: rpcInvalidRequest, Message: "invalid request"})
+			continue
+		}
+
+		switch req.Method {
+		case "transcribe":
+			var params transcribeParams
+			if err := json.Unmarshal(req.Params, &params); err != nil || params.Input == "" {
+				m.reply(req.ID, nil, &rpcError{Code: rpcInvalidParams, Message: "params.input is required"})
+				continue
+			}
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				m.transcribe(req.ID, params)
+			}()
+		case "shutdown":
+			m.reply(req.ID, true, nil)
+			return
+		default:
+			m.reply(req.ID, nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + req.Method})
+		}
+	}
+}
+
+// transcribe 执行单个转写任务，期间发送 progress 通知，完成后返回结果
+func (m *machineServer) transcribe(id json.RawMessage, params transcribeParams) {
+	config := *m.config
+	if params.Language != "" {
+		config.Language = params.Language
+	}
+	if params.Model != "" {
+		config.Model = params.Model
+		if err := config.validateModel(); err != nil {
+			m.reply(id, nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()})
+			return
+		}
+	}
+	if params.AutoDetect {
+		config.AutoDetect = true
+	}
+	if params.Output != "" {
+		config.OutputDir = params.Output
+	}
+	formatList := m.formatList
+	if len(params.Formats) > 0 {
+		formatList = parseFormats(strings.Join(params.Formats, ","))
+	}
+	config.Progress = func(stage string, current, total int) {
+		m.notify("progress", progressParams{ID: id, Stage: stage, Current: current, Total: total})
+	}
+
+	result, outputFiles, err := processFile(m.client, params.Input, &config, formatList, false)
+	if err != nil {
+		m.reply(id, nil, &rpcError{Code: rpcJobFailed, Message: err.Error()})
+		return
+	}
+	m.reply(id, transcribeReply{Result: result, OutputFiles: outputFiles}, nil)
+}
+
+// reply 发送响应，通知（没有 id 的请求）不需要响应
+func (m *machineServer) reply(id json.RawMessage, result any, rpcErr *rpcError) {
+	if id == nil && rpcErr == nil {
+		return
+	}
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	m.write(rpcMessage{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+}
+
+// notify 发送通知
+func (m *machineServer) notify(method string, params any) {
+	m.write(rpcMessage{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// write 串行写出一行 JSON
+func (m *machineServer) write(msg rpcMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.out.Encode(msg)
+}
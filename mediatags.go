@@ -0,0 +1,524 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// taggedExtension tagged 格式的扩展名：tagged 加输入文件的扩展名，如 tagged.mp3
+func taggedExtension(inputFile string) string {
+	return "tagged" + strings.ToLower(filepath.Ext(inputFile))
+}
+
+// saveTaggedAudio 复制一份输入音频，把转写文本和章节写入标签，播客和音乐播放器可以显示文稿、同步歌词和章节。
+// MP3 写入 ID3v2 的 USLT（全文）、SYLT（按分段同步）和 CHAP/CTOC（章节）；M4A/M4B 写入 iTunes 歌词 ©lyr 和 Nero 章节 chpl。
+// 原有的其他标签保留，音频数据不重新编码
+func saveTaggedAudio(result *TranscriptionResult, inputFile, outputPath string) error {
+	switch strings.ToLower(filepath.Ext(inputFile)) {
+	case ".mp3":
+		return saveTaggedMP3(result, inputFile, outputPath)
+	case ".m4a", ".m4b":
+		return saveTaggedMP4(result, inputFile, outputPath)
+	}
+	return fmt.Errorf(tr("tagged 格式只支持 MP3、M4A 和 M4B 输入: %s"), filepath.Base(inputFile))
+}
+
+// taggedLyrics 写入歌词标签的全文，每个分段一行
+func taggedLyrics(result *TranscriptionResult) string {
+	var lines []string
+	for _, seg := range jsonlSegments(result) {
+		if text := strings.Join(strings.Fields(seg.Text), " "); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// id3Frame ID3v2 帧，Data 为帧内容（不含帧头）
+type id3Frame struct {
+	ID    string
+	Flags [2]byte
+	Data  []byte
+}
+
+// id3ReplacedFrames 重新生成、不从原标签保留的帧
+var id3ReplacedFrames = map[string]bool{"USLT": true, "SYLT": true, "CHAP": true, "CTOC": true}
+
+// syncsafe 解码 ID3v2 的 syncsafe 整数（每字节只用低 7 位）
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// putSyncsafe 编码 syncsafe 整数
+func putSyncsafe(b []byte, n int) {
+	b[0], b[1], b[2], b[3] = byte(n>>21&0x7F), byte(n>>14&0x7F), byte(n>>7&0x7F), byte(n&0x7F)
+}
+
+// readID3Tag 读取文件开头的 ID3v2 标签，返回主版本号、帧和标签总长度；没有标签时版本号为 0。
+// ID3v2.2 的帧格式不同，不保留其中的帧
+func readID3Tag(f io.Reader) (int, []id3Frame, int64, error) {
+	var header [10]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil || string(header[:3]) != "ID3" {
+		return 0, nil, 0, nil
+	}
+	version, flags := int(header[3]), header[5]
+	size := syncsafe(header[6:10])
+	tagSize := int64(10 + size)
+	if flags&0x10 != 0 {
+		tagSize += 10 // 标签尾
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return 0, nil, 0, fmt.Errorf(tr("读取 ID3 标签失败: %w"), err)
+	}
+	if version != 3 && version != 4 {
+		logWarn(tr("不支持 ID3v2.%d 标签，原有标签不会保留"), version)
+		return 0, nil, tagSize, nil
+	}
+	// v2.3 的整体反同步：去掉 0xFF 之后插入的 0x00（v2.4 的反同步按帧标记，帧原样复制即可）
+	if version == 3 && flags&0x80 != 0 {
+		data = bytes.ReplaceAll(data, []byte{0xFF, 0x00}, []byte{0xFF})
+	}
+	if flags&0x40 != 0 && len(data) >= 4 {
+		extended := int(binary.BigEndian.Uint32(data[:4])) + 4
+		if version == 4 {
+			extended = syncsafe(data[:4])
+		}
+		if extended > len(data) {
+			return 0, nil, 0, errors.New(tr("ID3 标签的扩展头无效"))
+		}
+		data = data[extended:]
+	}
+
+	var frames []id3Frame
+	for len(data) >= 10 && data[0] != 0 {
+		n := int(binary.BigEndian.Uint32(data[4:8]))
+		if version == 4 {
+			n = syncsafe(data[4:8])
+		}
+		if 10+n > len(data) {
+			return 0, nil, 0, errors.New(tr("ID3 标签中的帧长度无效"))
+		}
+		frames = append(frames, id3Frame{ID: string(data[:4]), Flags: [2]byte{data[8], data[9]}, Data: data[10 : 10+n]})
+		data = data[10+n:]
+	}
+	return version, frames, tagSize, nil
+}
+
+// encodeID3Tag 编码 ID3v2 标签（不使用反同步、扩展头和填充）
+func encodeID3Tag(version int, frames []id3Frame) []byte {
+	var body bytes.Buffer
+	for _, frame := range frames {
+		var header [10]byte
+		copy(header[:4], frame.ID)
+		if version == 4 {
+			putSyncsafe(header[4:8], len(frame.Data))
+		} else {
+			binary.BigEndian.PutUint32(header[4:8], uint32(len(frame.Data)))
+		}
+		header[8], header[9] = frame.Flags[0], frame.Flags[1]
+		body.Write(header[:])
+		body.Write(frame.Data)
+	}
+	header := []byte{'I', 'D', '3', byte(version), 0, 0, 0, 0, 0, 0}
+	putSyncsafe(header[6:10], body.Len())
+	return append(header, body.Bytes()...)
+}
+
+// id3Text 按版本编码字符串（不含结束符）：v2.4 用 UTF-8（编码 3），v2.3 用带 BOM 的 UTF-16（编码 1）
+func id3Text(version int, s string) []byte {
+	if version == 4 {
+		return []byte(s)
+	}
+	b := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+// id3String 按版本编码带结束符的字符串
+func id3String(version int, s string) []byte {
+	if version == 4 {
+		return append(id3Text(version, s), 0)
+	}
+	return append(id3Text(version, s), 0, 0)
+}
+
+// id3Encoding 文本编码字节，与 id3Text 对应
+func id3Encoding(version int) byte {
+	if version == 4 {
+		return 3
+	}
+	return 1
+}
+
+// id3Language ISO 639-2 三字母语言代码，无法确定时为 und
+func id3Language(language string) []byte {
+	code := iso6392(languageCode(language))
+	if len(code) != 3 {
+		code = "und"
+	}
+	return []byte(code)
+}
+
+// id3Millis 秒转换为 ID3 使用的 32 位毫秒数
+func id3Millis(seconds float64) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(math.Round(max(seconds, 0)*1000)))
+}
+
+// transcriptID3Frames 生成转写文本和章节对应的 ID3 帧
+func transcriptID3Frames(result *TranscriptionResult, version int) []id3Frame {
+	enc := id3Encoding(version)
+	lang := id3Language(result.Language)
+	var frames []id3Frame
+
+	if lyrics := taggedLyrics(result); lyrics != "" {
+		// USLT：编码、语言、描述、全文
+		uslt := append([]byte{enc}, lang...)
+		uslt = append(uslt, id3String(version, "")...)
+		uslt = append(uslt, id3Text(version, lyrics)...)
+		frames = append(frames, id3Frame{ID: "USLT", Data: uslt})
+
+		// SYLT：编码、语言、时间格式（2 为毫秒）、内容类型（1 为歌词）、描述，之后每个分段为文本和开始时间
+		sylt := append([]byte{enc}, lang...)
+		sylt = append(sylt, 2, 1)
+		sylt = append(sylt, id3String(version, "")...)
+		for _, seg := range jsonlSegments(result) {
+			if text := strings.Join(strings.Fields(seg.Text), " "); text != "" {
+				sylt = append(sylt, id3String(version, text)...)
+				sylt = append(sylt, id3Millis(seg.Start)...)
+			}
+		}
+		frames = append(frames, id3Frame{ID: "SYLT", Data: sylt})
+	}
+
+	// CTOC 最多引用 255 个章节
+	chapters := result.Chapters
+	if len(chapters) > 255 {
+		chapters = chapters[:255]
+	}
+	if len(chapters) > 0 {
+		// CTOC：元素 ID、标志（顶层、有序）、章节数、各章节的元素 ID
+		ctoc := append([]byte("toc\x00"), 0x03, byte(len(chapters)))
+		for i, c := range chapters {
+			id := fmt.Sprintf("chp%d", i)
+			ctoc = append(ctoc, id+"\x00"...)
+
+			// CHAP：元素 ID、开始和结束时间（毫秒）、开始和结束字节偏移（不使用）、TIT2 子帧
+			title := append([]byte{enc}, id3Text(version, c.Title)...)
+			chap := append([]byte(id+"\x00"), id3Millis(c.Start)...)
+			chap = append(chap, id3Millis(c.End)...)
+			chap = append(chap, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+			chap = append(chap, encodeID3Tag(version, []id3Frame{{ID: "TIT2", Data: title}})[10:]...)
+			frames = append(frames, id3Frame{ID: "CHAP", Data: chap})
+		}
+		frames = append(frames, id3Frame{ID: "CTOC", Data: ctoc})
+	}
+	return frames
+}
+
+// saveTaggedMP3 写入新的 ID3v2 标签（保留原有的其他帧），之后原样复制音频数据和文件尾的标签
+func saveTaggedMP3(result *TranscriptionResult, inputFile, outputPath string) error {
+	src, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	version, existing, tagSize, err := readID3Tag(src)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		version = 3 // 播客客户端对 v2.3 的支持最广
+	}
+	var frames []id3Frame
+	for _, frame := range existing {
+		if !id3ReplacedFrames[frame.ID] {
+			frames = append(frames, frame)
+		}
+	}
+	frames = append(frames, transcriptID3Frames(result, version)...)
+
+	if _, err := src.Seek(tagSize, io.SeekStart); err != nil {
+		return err
+	}
+	dst, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	if _, err := dst.Write(encodeID3Tag(version, frames)); err != nil {
+		dst.Close()
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// mp4Box MP4 box：容器 box 解析出子 box，其他 box 保留原始内容
+type mp4Box struct {
+	Type     string
+	Payload  []byte    // 非容器 box 的内容
+	Prefix   []byte    // 容器 box 在子 box 之前的内容（如 meta 的版本和标志）
+	Children []*mp4Box // 容器 box 的子 box
+}
+
+// mp4Containers 需要修改或遍历到的容器 box
+var mp4Containers = map[string]bool{"moov": true, "trak": true, "mdia": true, "minf": true, "stbl": true, "udta": true, "meta": true, "ilst": true}
+
+// parseMP4Boxes 解析一层 box
+func parseMP4Boxes(data []byte) ([]*mp4Box, error) {
+	var boxes []*mp4Box
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, errors.New(tr("MP4 box 长度无效"))
+		}
+		size, header := int64(binary.BigEndian.Uint32(data[:4])), 8
+		switch size {
+		case 0:
+			size = int64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, errors.New(tr("MP4 box 长度无效"))
+			}
+			size, header = int64(binary.BigEndian.Uint64(data[8:16])), 16
+		}
+		if size < int64(header) || size > int64(len(data)) {
+			return nil, errors.New(tr("MP4 box 长度无效"))
+		}
+		box := &mp4Box{Type: string(data[4:8])}
+		payload := data[header:size]
+		if mp4Containers[box.Type] {
+			// ISO 的 meta 是带版本和标志的 full box，QuickTime 的 meta 不是
+			if box.Type == "meta" && len(payload) >= 4 && binary.BigEndian.Uint32(payload[:4]) == 0 {
+				box.Prefix, payload = payload[:4], payload[4:]
+			}
+			children, err := parseMP4Boxes(payload)
+			if err != nil {
+				return nil, err
+			}
+			box.Children = children
+		} else {
+			box.Payload = payload
+		}
+		boxes = append(boxes, box)
+		data = data[size:]
+	}
+	return boxes, nil
+}
+
+// bytes 编码 box（含子 box）
+func (b *mp4Box) bytes() []byte {
+	body := append([]byte{}, b.Prefix...)
+	if b.Children != nil || mp4Containers[b.Type] {
+		for _, child := range b.Children {
+			body = append(body, child.bytes()...)
+		}
+	} else {
+		body = append(body, b.Payload...)
+	}
+	if size := 8 + len(body); size <= math.MaxUint32 {
+		out := binary.BigEndian.AppendUint32(nil, uint32(size))
+		return append(append(out, b.Type...), body...)
+	}
+	out := binary.BigEndian.AppendUint32(nil, 1)
+	out = append(out, b.Type...)
+	out = binary.BigEndian.AppendUint64(out, uint64(16+len(body)))
+	return append(out, body...)
+}
+
+// child 返回第一个指定类型的子 box，create 为 true 时不存在则追加一个
+func (b *mp4Box) child(boxType string, create bool) *mp4Box {
+	for _, c := range b.Children {
+		if c.Type == boxType {
+			return c
+		}
+	}
+	if !create {
+		return nil
+	}
+	c := &mp4Box{Type: boxType}
+	b.Children = append(b.Children, c)
+	return c
+}
+
+// removeChildren 删除指定类型的子 box
+func (b *mp4Box) removeChildren(boxType string) {
+	kept := b.Children[:0]
+	for _, c := range b.Children {
+		if c.Type != boxType {
+			kept = append(kept, c)
+		}
+	}
+	b.Children = kept
+}
+
+// mp4Lyrics iTunes 歌词条目 ©lyr，内容为 UTF-8 文本的 data box
+func mp4Lyrics(text string) *mp4Box {
+	data := append([]byte{0, 0, 0, 1, 0, 0, 0, 0}, text...) // 类型 1 为 UTF-8，locale 为 0
+	return &mp4Box{Type: "\xa9lyr", Payload: (&mp4Box{Type: "data", Payload: data}).bytes()}
+}
+
+// mp4Chapters Nero 章节 chpl：版本 1、保留字段、章节数，每个章节为开始时间（100 纳秒）和标题（最长 255 字节）
+func mp4Chapters(chapters []Chapter) *mp4Box {
+	if len(chapters) > 255 {
+		chapters = chapters[:255]
+	}
+	data := []byte{1, 0, 0, 0, 0, 0, 0, 0, byte(len(chapters))}
+	for _, c := range chapters {
+		data = binary.BigEndian.AppendUint64(data, uint64(math.Round(max(c.Start, 0)*1e7)))
+		title := c.Title
+		for len(title) > 255 {
+			_, size := utf8.DecodeLastRuneInString(title)
+			title = title[:len(title)-size]
+		}
+		data = append(data, byte(len(title)))
+		data = append(data, title...)
+	}
+	return &mp4Box{Type: "chpl", Payload: data}
+}
+
+// shiftChunkOffsets 把 moov 之后的数据块偏移（stco/co64）加上 delta，moov 变长或变短后音频数据随之移动
+func shiftChunkOffsets(box *mp4Box, moovEnd, delta int64) error {
+	for _, c := range box.Children {
+		if err := shiftChunkOffsets(c, moovEnd, delta); err != nil {
+			return err
+		}
+	}
+	if box.Type != "stco" && box.Type != "co64" || len(box.Payload) < 8 {
+		return nil
+	}
+	entrySize := 4
+	if box.Type == "co64" {
+		entrySize = 8
+	}
+	count := int(binary.BigEndian.Uint32(box.Payload[4:8]))
+	if 8+count*entrySize > len(box.Payload) {
+		return fmt.Errorf(tr("MP4 %s box 长度无效"), box.Type)
+	}
+	for i := 0; i < count; i++ {
+		entry := box.Payload[8+i*entrySize:]
+		if entrySize == 4 {
+			offset := int64(binary.BigEndian.Uint32(entry))
+			if offset < moovEnd {
+				continue
+			}
+			if offset+delta > math.MaxUint32 {
+				return errors.New(tr("写入标签后数据块偏移超过 4 GB，无法使用 stco"))
+			}
+			binary.BigEndian.PutUint32(entry, uint32(offset+delta))
+		} else if offset := int64(binary.BigEndian.Uint64(entry)); offset >= moovEnd {
+			binary.BigEndian.PutUint64(entry, uint64(offset+delta))
+		}
+	}
+	return nil
+}
+
+// saveTaggedMP4 修改 moov/udta：meta/ilst 中写入 ©lyr，udta 中写入 chpl；moov 位于音频数据之前时同步修正数据块偏移
+func saveTaggedMP4(result *TranscriptionResult, inputFile, outputPath string) error {
+	src, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	// 只读取顶层 box 的头，找到 moov 的位置
+	var moovOffset, moovSize int64 = -1, 0
+	for offset := int64(0); offset < info.Size(); {
+		var header [16]byte
+		if _, err := src.ReadAt(header[:8], offset); err != nil {
+			return errors.New(tr("MP4 box 长度无效"))
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		switch size {
+		case 0:
+			size = info.Size() - offset
+		case 1:
+			if _, err := src.ReadAt(header[8:16], offset+8); err != nil {
+				return errors.New(tr("MP4 box 长度无效"))
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+		}
+		if size < 8 || offset+size > info.Size() {
+			return errors.New(tr("MP4 box 长度无效"))
+		}
+		if string(header[4:8]) == "moov" {
+			moovOffset, moovSize = offset, size
+			break
+		}
+		offset += size
+	}
+	if moovOffset < 0 {
+		return errors.New(tr("MP4 文件中没有 moov box"))
+	}
+
+	raw := make([]byte, moovSize)
+	if _, err := src.ReadAt(raw, moovOffset); err != nil {
+		return err
+	}
+	boxes, err := parseMP4Boxes(raw)
+	if err != nil {
+		return err
+	}
+	moov := boxes[0]
+	if len(boxes) != 1 || moov.Type != "moov" {
+		return errors.New(tr("MP4 box 长度无效"))
+	}
+
+	udta := moov.child("udta", true)
+	udta.removeChildren("chpl")
+	if len(result.Chapters) > 0 {
+		udta.Children = append(udta.Children, mp4Chapters(result.Chapters))
+	}
+	meta := udta.child("meta", false)
+	if meta == nil {
+		meta = &mp4Box{Type: "meta", Prefix: []byte{0, 0, 0, 0}}
+		// iTunes 元数据的 hdlr：版本和标志、pre_defined、处理类型 mdir、厂商 appl、保留字段、空名称
+		hdlr := append(make([]byte, 8), "mdirappl"...)
+		meta.Children = []*mp4Box{{Type: "hdlr", Payload: append(hdlr, make([]byte, 9)...)}}
+		udta.Children = append(udta.Children, meta)
+	}
+	ilst := meta.child("ilst", true)
+	ilst.removeChildren("\xa9lyr")
+	if lyrics := taggedLyrics(result); lyrics != "" {
+		ilst.Children = append(ilst.Children, mp4Lyrics(lyrics))
+	}
+
+	delta := int64(len(moov.bytes())) - moovSize
+	if err := shiftChunkOffsets(moov, moovOffset+moovSize, delta); err != nil {
+		return err
+	}
+
+	dst, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	for _, part := range []io.Reader{
+		io.NewSectionReader(src, 0, moovOffset),
+		bytes.NewReader(moov.bytes()),
+		io.NewSectionReader(src, moovOffset+moovSize, info.Size()-moovOffset-moovSize),
+	} {
+		if _, err := io.Copy(dst, part); err != nil {
+			dst.Close()
+			return err
+		}
+	}
+	return dst.Close()
+}
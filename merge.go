@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// mergedPart 合并文档中的一个输入文件
+type mergedPart struct {
+	Input    string    `json:"input"`
+	Offset   float64   `json:"offset"` // 在合并文档中的起始时间（之前所有文件的累计时长）
+	Duration float64   `json:"duration"`
+	Language string    `json:"language,omitempty"`
+	Text     string    `json:"text"`
+	Segments []Segment `json:"segments"` // 时间已加上 Offset，序号在整个文档中连续
+}
+
+// mergedDocument 多个连续录音合并后的文档
+type mergedDocument struct {
+	Duration float64      `json:"duration"`
+	Text     string       `json:"text"`
+	Files    []mergedPart `json:"files"`
+}
+
+// expandInputs 展开输入参数：目录替换为其中的音视频文件（按文件名中的数字自然排序），文件保持参数顺序
+func expandInputs(args []string) ([]string, error) {
+	var inputs []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			if isRemoteURI(arg) {
+				inputs = append(inputs, arg)
+				continue
+			}
+			return nil, fmt.Errorf(tr("输入文件不存在: %s"), arg)
+		}
+		if !info.IsDir() {
+			inputs = append(inputs, arg)
+			continue
+		}
+		var files []string
+		for path := range scanMediaFiles(arg) {
+			files = append(files, path)
+		}
+		sort.Slice(files, func(i, j int) bool { return naturalLess(filepath.Base(files[i]), filepath.Base(files[j])) })
+		inputs = append(inputs, files...)
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf(tr("没有找到音视频文件: %s"), strings.Join(args, " "))
+	}
+	return inputs, nil
+}
+
+// naturalLess 按自然顺序比较文件名，数字部分按数值比较（part2 排在 part10 之前）
+func naturalLess(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si := i
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			sj := j
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			na, _ := strconv.ParseInt(string(ra[si:i]), 10, 64)
+			nb, _ := strconv.ParseInt(string(rb[sj:j]), 10, 64)
+			if na != nb {
+				return na < nb
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			return ra[i] < rb[j]
+		}
+		i++
+		j++
+	}
+	return len(ra)-i < len(rb)-j
+}
+
+// runMergedTranscription 依次转写多个连续录音，并将结果合并为一份文档（时间按累计时长连续）
+func runMergedTranscription(client *openai.Client, args []string, config *Config, formatList, mergeFormats []string, alert jobAlert, quiet, verbose bool) {
+	inputs, err := expandInputs(args)
+	if err != nil {
+		exitWith(exitBadInput, "%v", err)
+	}
+	alert.start(fmt.Sprintf(tr("正在转写 %d 个文件"), len(inputs)))
+
+	doc := &mergedDocument{}
+	var outputFiles []string
+	partial := false
+	for i, input := range inputs {
+		logInfo(tr("[%d/%d] 正在转写: %s\n"), i+1, len(inputs), input)
+		result, files, err := processFile(client, input, config, formatList, verbose)
+		if err != nil {
+			alert.done(tr("whisper-go 批量转写失败"), fmt.Sprintf("%s: %v", filepath.Base(input), err))
+			exitWith(exitCodeOf(err), tr("转写 %s 失败: %v"), input, err)
+		}
+		outputFiles = append(outputFiles, files...)
+		doc.add(input, result)
+		partial = partial || result.partial()
+	}
+
+	files, err := saveMergedOutputs(doc, inputs, args, config, mergeFormats)
+	if err != nil {
+		alert.done(tr("whisper-go 批量转写失败"), err.Error())
+		fatalf("%v", err)
+	}
+
+	if quiet {
+		// 安静模式只输出结果文件路径
+		for _, file := range append(outputFiles, files...) {
+			fmt.Println(file)
+		}
+	} else {
+		fmt.Println(tr("\n=== 合并完成 ==="))
+		fmt.Printf(tr("文件数: %d\n"), len(doc.Files))
+		fmt.Printf(tr("总时长: %s\n"), formatChapterTime(doc.Duration))
+		fmt.Print(tr("\n输出文件:\n"))
+		for _, file := range outputFiles {
+			fmt.Printf("  - %s\n", file)
+		}
+		fmt.Print(tr("\n合并文档:\n"))
+		for _, file := range files {
+			fmt.Printf("  - %s\n", file)
+		}
+	}
+
+	alert.done(tr("whisper-go 批量转写完成"), fmt.Sprintf(tr("%d 个文件，总时长 %s"), len(doc.Files), formatChapterTime(doc.Duration)))
+
+	// 有合并文档格式保存失败时同样视为部分成功
+	if partial || len(files) < len(mergeFormats) {
+		exit(exitPartial)
+	}
+}
+
+// add 追加一个文件的转写结果，时间偏移为之前所有文件的累计时长
+func (d *mergedDocument) add(input string, result *TranscriptionResult) {
+	part := mergedPart{
+		Input:    filepath.Base(input),
+		Offset:   d.Duration,
+		Duration: result.Duration,
+		Language: result.Language,
+		Text:     result.Text,
+		Segments: []Segment{},
+	}
+	// 没有时长信息时以最后一个分段的结束时间代替
+	if n := len(result.Segments); part.Duration == 0 && n > 0 {
+		part.Duration = result.Segments[n-1].End
+	}
+
+	id := 0
+	for _, p := range d.Files {
+		id += len(p.Segments)
+	}
+	for _, seg := range result.Segments {
+		id++
+		seg.ID = id
+		seg.Start += part.Offset
+		seg.End += part.Offset
+		if len(seg.Words) > 0 {
+			words := make([]Word, len(seg.Words))
+			for i, w := range seg.Words {
+				w.Start += part.Offset
+				w.End += part.Offset
+				words[i] = w
+			}
+			seg.Words = words
+		}
+		part.Segments = append(part.Segments, seg)
+	}
+
+	d.Files = append(d.Files, part)
+	d.Duration += part.Duration
+	d.Text = joinSegmentText(d.Text, result.Text)
+}
+
+// saveMergedOutputs 保存合并文档，文件名取自输入目录（只有一个目录参数时）或第一个输入文件
+func saveMergedOutputs(doc *mergedDocument, inputs, args []string, config *Config, formats []string) ([]string, error) {
+	name := inputs[0]
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+			abs, _ := filepath.Abs(args[0])
+			name = abs
+		}
+	}
+
+	// 输出目录为对象存储时先写入本地临时目录，完成后再上传
+	outputDir := config.OutputDir
+	var remoteOutput string
+	if isRemoteURI(outputDir) {
+		tempDir, err := os.MkdirTemp(workingDir(), "whisper_output_")
+		if err != nil {
+			return nil, fmt.Errorf(tr("创建临时输出目录失败: %w"), err)
+		}
+		defer removeTemp(trackTemp(tempDir))
+		remoteOutput, outputDir = outputDir, tempDir
+	}
+	dir := organizedOutputDir(outputDir, name, config.Organize, time.Now())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf(tr("创建输出目录失败: %w"), err)
+	}
+
+	var files []string
+	for _, format := range formats {
+		var save func(*mergedDocument, string) error
+		switch format {
+		case "txt":
+			save = saveMergedTXT
+		case "md":
+			save = saveMergedMarkdown
+		case "json":
+			save = saveMergedJSON
+		default:
+			logError(tr("合并文档不支持的格式: %s（可选 txt, md, json）"), format)
+			continue
+		}
+		path := generateOutputPath(name, dir, "merged."+format)
+		if err := save(doc, path); err != nil {
+			logError(tr("保存合并文档失败: %v"), err)
+			continue
+		}
+		files = append(files, path)
+	}
+
+	if remoteOutput != "" {
+		return uploadOutputs(context.Background(), outputDir, remoteOutput, files, false)
+	}
+	return files, nil
+}
+
+// saveMergedTXT 保存为纯文本：每个文件一个标题，分段前标注在合并文档中的时间
+func saveMergedTXT(doc *mergedDocument, outputPath string) error {
+	var b strings.Builder
+	for i, part := range doc.Files {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "=== %s [%s] ===\n", part.Input, formatChapterTime(part.Offset))
+		if len(part.Segments) == 0 {
+			b.WriteString(strings.TrimSpace(part.Text))
+			b.WriteString("\n")
+			continue
+		}
+		for _, seg := range part.Segments {
+			fmt.Fprintf(&b, "[%s] %s\n", formatChapterTime(seg.Start), strings.TrimSpace(seg.Text))
+		}
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
+
+// saveMergedMarkdown 保存为 Markdown：每个文件一个二级标题
+func saveMergedMarkdown(doc *mergedDocument, outputPath string) error {
+	var b strings.Builder
+	for i, part := range doc.Files {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s (%s)\n\n", part.Input, formatChapterTime(part.Offset))
+		if len(part.Segments) == 0 {
+			b.WriteString(strings.TrimSpace(part.Text))
+			b.WriteString("\n")
+			continue
+		}
+		for _, seg := range part.Segments {
+			fmt.Fprintf(&b, "**[%s]** %s\n\n", formatChapterTime(seg.Start), strings.TrimSpace(seg.Text))
+		}
+	}
+	return os.WriteFile(outputPath, []byte(strings.TrimRight(b.String(), "\n")+"\n"), 0644)
+}
+
+// saveMergedJSON 保存为 JSON
+func saveMergedJSON(doc *mergedDocument, outputPath string) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
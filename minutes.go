@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Minutes 会议纪要
+type Minutes struct {
+	Title         string            `json:"title"`
+	Summary       string            `json:"summary"`
+	Attendees     []MinutesAttendee `json:"attendees"`
+	Decisions     []string          `json:"decisions"`
+	ActionItems   []ActionItem      `json:"action_items"`
+	OpenQuestions []string          `json:"open_questions"`
+}
+
+// MinutesAttendee 参会人：Label 为转写结果中的说话人标签，Name 为模型从对话中识别出的姓名（可能为空）
+type MinutesAttendee struct {
+	Label string `json:"label"`
+	Name  string `json:"name,omitempty"`
+}
+
+// ActionItem 待办事项
+type ActionItem struct {
+	Task  string `json:"task"`
+	Owner string `json:"owner,omitempty"`
+	Due   string `json:"due,omitempty"`
+}
+
+// minutesPrompt 生成会议纪要的提示
+const minutesPrompt = `You write meeting minutes from transcripts. Each line of the transcript is "mm:ss SPEAKER: text"
+(the speaker is omitted when unknown). The speakers in the transcript are: %s.
+Write everything in the language of the transcript. Only include what was actually said; leave lists empty rather than guessing.
+- title: a short title for the meeting
+- summary: two to four sentences
+- speakers: for each speaker label whose real name can be inferred from the conversation, its name
+- decisions: decisions that were agreed on
+- action_items: tasks someone committed to, with the owner (name or speaker label) and due date if mentioned
+- open_questions: questions raised but not resolved
+Reply with JSON only: {"title":"","summary":"","speakers":{"<label>":"<name>"},"decisions":[""],"action_items":[{"task":"","owner":"","due":""}],"open_questions":[""]}`
+
+// wantMinutes 是否请求了会议纪要格式
+func wantMinutes(formatList []string) bool {
+	for _, format := range formatList {
+		if format == "minutes" || format == "minutes-json" {
+			return true
+		}
+	}
+	return false
+}
+
+// speakerLabels 按首次出现的顺序列出转写结果中的说话人标签
+func speakerLabels(segments []Segment) []string {
+	var labels []string
+	seen := map[string]bool{}
+	for _, seg := range segments {
+		if seg.Speaker != "" && !seen[seg.Speaker] {
+			seen[seg.Speaker] = true
+			labels = append(labels, seg.Speaker)
+		}
+	}
+	return labels
+}
+
+// generateMinutes 由 minutes_model 指定的对话模型从转写结果中整理会议纪要，参会人取自说话人标签。
+// 失败时返回 nil，会议纪要格式不输出
+func generateMinutes(client *openai.Client, result *TranscriptionResult, config *Config) *Minutes {
+	if config.MinutesModel == "" {
+		logError(tr("输出会议纪要需要配置 minutes_model（整理纪要用的对话模型）"))
+		return nil
+	}
+	minutes, err := minutesFromModel(client, result.Segments, config.MinutesModel)
+	if err != nil {
+		logError(tr("生成会议纪要失败: %v"), err)
+		return nil
+	}
+	return minutes
+}
+
+// minutesFromModel 把带时间和说话人的转写发给对话模型，解析返回的纪要
+func minutesFromModel(client *openai.Client, segments []Segment, model string) (*Minutes, error) {
+	labels := speakerLabels(segments)
+	var transcript strings.Builder
+	for _, seg := range segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		if seg.Speaker != "" {
+			fmt.Fprintf(&transcript, "%s %s: %s\n", formatChapterTime(seg.Start), seg.Speaker, text)
+		} else {
+			fmt.Fprintf(&transcript, "%s %s\n", formatChapterTime(seg.Start), text)
+		}
+	}
+	speakers := "unknown"
+	if len(labels) > 0 {
+		speakers = strings.Join(labels, ", ")
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: fmt.Sprintf(minutesPrompt, speakers)},
+			{Role: openai.ChatMessageRoleUser, Content: transcript.String()},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New(tr("模型没有返回结果"))
+	}
+
+	var reply struct {
+		Title         string            `json:"title"`
+		Summary       string            `json:"summary"`
+		Speakers      map[string]string `json:"speakers"`
+		Decisions     []string          `json:"decisions"`
+		ActionItems   []ActionItem      `json:"action_items"`
+		OpenQuestions []string          `json:"open_questions"`
+	}
+	if err := parseChatJSON(resp.Choices[0].Message.Content, &reply); err != nil {
+		return nil, fmt.Errorf(tr("解析模型返回的会议纪要失败: %w"), err)
+	}
+
+	minutes := &Minutes{
+		Title:         strings.TrimSpace(reply.Title),
+		Summary:       strings.TrimSpace(reply.Summary),
+		Attendees:     []MinutesAttendee{},
+		Decisions:     nonEmptyLines(reply.Decisions),
+		ActionItems:   []ActionItem{},
+		OpenQuestions: nonEmptyLines(reply.OpenQuestions),
+	}
+	// 参会人以说话人标签为准，模型只补充姓名
+	for _, label := range labels {
+		minutes.Attendees = append(minutes.Attendees, MinutesAttendee{Label: label, Name: strings.TrimSpace(reply.Speakers[label])})
+	}
+	for _, item := range reply.ActionItems {
+		item.Task, item.Owner, item.Due = strings.TrimSpace(item.Task), strings.TrimSpace(item.Owner), strings.TrimSpace(item.Due)
+		if item.Task != "" {
+			minutes.ActionItems = append(minutes.ActionItems, item)
+		}
+	}
+	return minutes, nil
+}
+
+// nonEmptyLines 去掉空白项，返回非 nil 的切片（JSON 中输出 [] 而不是 null）
+func nonEmptyLines(lines []string) []string {
+	out := []string{}
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// saveMinutesJSON 保存 JSON 格式的会议纪要
+func saveMinutesJSON(result *TranscriptionResult, outputPath string) error {
+	if result.Minutes == nil {
+		return errors.New(tr("没有会议纪要"))
+	}
+	data, err := json.MarshalIndent(result.Minutes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// saveMinutes 保存 Markdown 格式的会议纪要，待办事项为表格
+func saveMinutes(result *TranscriptionResult, outputPath string) error {
+	m := result.Minutes
+	if m == nil {
+		return errors.New(tr("没有会议纪要"))
+	}
+	// 表格单元格中的 | 和换行会破坏表格
+	cell := func(s string) string {
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+	}
+	list := func(b *strings.Builder, heading string, items []string, empty string) {
+		fmt.Fprintf(b, "\n## %s\n\n", heading)
+		if len(items) == 0 {
+			b.WriteString(empty + "\n")
+		}
+		for _, item := range items {
+			fmt.Fprintf(b, "- %s\n", item)
+		}
+	}
+
+	var b strings.Builder
+	title := m.Title
+	if title == "" {
+		title = tr("会议纪要")
+	}
+	fmt.Fprintf(&b, "# %s\n", title)
+	if m.Summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", m.Summary)
+	}
+
+	var attendees []string
+	for _, a := range m.Attendees {
+		if a.Name != "" {
+			attendees = append(attendees, fmt.Sprintf(tr("%s（%s）"), a.Name, a.Label))
+		} else {
+			attendees = append(attendees, a.Label)
+		}
+	}
+	list(&b, tr("参会人"), attendees, tr("（转写结果没有说话人标签）"))
+	list(&b, tr("决定"), m.Decisions, tr("（无）"))
+
+	fmt.Fprintf(&b, "\n## %s\n\n", tr("待办事项"))
+	if len(m.ActionItems) == 0 {
+		b.WriteString(tr("（无）") + "\n")
+	} else {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n|---|---|---|\n", tr("事项"), tr("负责人"), tr("截止"))
+		for _, item := range m.ActionItems {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", cell(item.Task), cell(item.Owner), cell(item.Due))
+		}
+	}
+
+	list(&b, tr("待定问题"), m.OpenQuestions, tr("（无）"))
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errNotMP3 输入不是可解析帧头的 MP3 文件
+var errNotMP3 error = trError("不是 MP3 文件")
+
+// mp3Bitrates 各 MPEG 版本和层的比特率表（kbps），下标为帧头中的比特率代码，0 为自由格式
+var mp3Bitrates = map[[2]int][16]int{
+	{1, 1}: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+	{1, 2}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+	{1, 3}: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{2, 1}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+	{2, 2}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	{2, 3}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+// mp3Frame MPEG 音频帧头中与时长相关的信息
+type mp3Frame struct {
+	Version    int // 1 为 MPEG-1，2 为 MPEG-2，25 为 MPEG-2.5
+	Layer      int
+	SampleRate int
+	Samples    int // 每帧每声道的采样数
+	Size       int // 整帧字节数（含帧头）
+	Mono       bool
+}
+
+// parseMP3Frame 解析 4 字节帧头，不是有效帧头时返回 false（自由格式比特率也视为无效）
+func parseMP3Frame(h []byte) (mp3Frame, bool) {
+	if h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	var f mp3Frame
+	switch h[1] >> 3 & 0x3 {
+	case 0:
+		f.Version = 25
+	case 2:
+		f.Version = 2
+	case 3:
+		f.Version = 1
+	default:
+		return mp3Frame{}, false
+	}
+	f.Layer = 4 - int(h[1]>>1&0x3)
+	bitrateCode := int(h[2] >> 4)
+	rateCode := int(h[2] >> 2 & 0x3)
+	if f.Layer == 4 || bitrateCode == 0 || bitrateCode == 15 || rateCode == 3 {
+		return mp3Frame{}, false
+	}
+	padding := int(h[2] >> 1 & 0x1)
+	f.Mono = h[3]>>6 == 3
+
+	f.SampleRate = [3]int{44100, 48000, 32000}[rateCode]
+	table := 1
+	switch f.Version {
+	case 2:
+		f.SampleRate /= 2
+		table = 2
+	case 25:
+		f.SampleRate /= 4
+		table = 2
+	}
+	bitrate := mp3Bitrates[[2]int{table, f.Layer}][bitrateCode] * 1000
+
+	switch {
+	case f.Layer == 1:
+		f.Samples = 384
+		f.Size = (12*bitrate/f.SampleRate + padding) * 4
+	case f.Layer == 3 && f.Version != 1:
+		f.Samples = 576
+		f.Size = 72*bitrate/f.SampleRate + padding
+	default:
+		f.Samples = 1152
+		f.Size = 144*bitrate/f.SampleRate + padding
+	}
+	return f, true
+}
+
+// mp3Duration 不解码音频，按帧头计算 .mp3 文件的时长：有 Xing/Info 或 VBRI 头时直接读取总帧数，否则逐帧累加
+func mp3Duration(audioPath string) (float64, error) {
+	if strings.ToLower(filepath.Ext(audioPath)) != ".mp3" {
+		return 0, errNotMP3
+	}
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, 1<<16)
+
+	// 跳过 ID3v2 标签，长度为 4 个 7 位的 syncsafe 整数
+	if head, err := r.Peek(10); err == nil && string(head[:3]) == "ID3" {
+		size := int(head[6])<<21 | int(head[7])<<14 | int(head[8])<<7 | int(head[9])
+		if head[5]&0x10 != 0 {
+			size += 10 // 标签尾
+		}
+		if _, err := r.Discard(10 + size); err != nil {
+			return 0, errNotMP3
+		}
+	}
+
+	var samples int64
+	var frames, sampleRate int
+	for {
+		head, err := r.Peek(4)
+		if err != nil {
+			break
+		}
+		frame, ok := parseMP3Frame(head)
+		if !ok {
+			if string(head[:3]) == "TAG" {
+				break
+			}
+			// 帧之间的垃圾数据：逐字节寻找下一个帧头
+			r.Discard(1)
+			continue
+		}
+		if frames == 0 {
+			sampleRate = frame.SampleRate
+			if total, ok := mp3HeaderFrames(r, frame); ok {
+				return float64(total) * float64(frame.Samples) / float64(sampleRate), nil
+			}
+		}
+		if _, err := r.Discard(frame.Size); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		frames++
+		samples += int64(frame.Samples)
+	}
+	if frames == 0 {
+		return 0, errNotMP3
+	}
+	return float64(samples) / float64(sampleRate), nil
+}
+
+// mp3HeaderFrames 读取第一帧中 Xing/Info 或 VBRI 头记录的总帧数（不含该帧本身）
+func mp3HeaderFrames(r *bufio.Reader, frame mp3Frame) (int64, bool) {
+	data, err := r.Peek(frame.Size)
+	if err != nil && len(data) < 4 {
+		return 0, false
+	}
+
+	// Xing/Info 位于边信息之后，边信息长度取决于版本和声道数
+	side := 32
+	switch {
+	case frame.Version == 1 && frame.Mono, frame.Version != 1 && !frame.Mono:
+		side = 17
+	case frame.Version != 1 && frame.Mono:
+		side = 9
+	}
+	if x := 4 + side; len(data) >= x+12 {
+		tag := string(data[x : x+4])
+		if (tag == "Xing" || tag == "Info") && data[x+7]&0x1 != 0 {
+			return int64(binary.BigEndian.Uint32(data[x+8 : x+12])), true
+		}
+	}
+	// VBRI 固定位于帧头之后 32 字节
+	if len(data) >= 36+18 && string(data[36:40]) == "VBRI" {
+		return int64(binary.BigEndian.Uint32(data[36+14 : 36+18])), true
+	}
+	return 0, false
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// mqttTimeout MQTT 连接和发布的超时时间
+const mqttTimeout = 10 * time.Second
+
+// 任务状态，发布到 <mqtt_topic>/status
+const (
+	mqttStatusRunning   = "running"
+	mqttStatusCompleted = "completed"
+	mqttStatusFailed    = "failed"
+)
+
+// MQTTResult 发布到 <mqtt_topic>/result 的转写结果，在 Webhook 内容的基础上附带全文
+type MQTTResult struct {
+	WebhookPayload
+	Text string `json:"text"`
+}
+
+// mqttPublisher MQTT 消息发布
+type mqttPublisher struct {
+	client mqtt.Client
+	topic  string
+}
+
+// newMQTTPublisher 连接 MQTT Broker，开启 Home Assistant 自动发现时同时发布传感器配置
+func newMQTTPublisher(config *Config) (*mqttPublisher, error) {
+	opts := mqtt.NewClientOptions().
+		AddBroker(config.MQTTBroker).
+		SetClientID(fmt.Sprintf("whisper-go-%d", time.Now().UnixNano())).
+		SetUsername(config.MQTTUsername).
+		SetPassword(config.MQTTPassword).
+		SetConnectTimeout(mqttTimeout)
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(mqttTimeout) {
+		return nil, fmt.Errorf(tr("连接 MQTT Broker 超时: %s"), config.MQTTBroker)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf(tr("连接 MQTT Broker 失败: %w"), err)
+	}
+
+	p := &mqttPublisher{client: client, topic: config.MQTTTopic}
+	if config.MQTTDiscovery {
+		if err := p.publishDiscovery(); err != nil {
+			client.Disconnect(250)
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// publish 以 QoS 1 发布消息
+func (p *mqttPublisher) publish(topic string, retained bool, payload []byte) error {
+	token := p.client.Publish(topic, 1, retained, payload)
+	if !token.WaitTimeout(mqttTimeout) {
+		return fmt.Errorf(tr("发布 MQTT 消息超时: %s"), topic)
+	}
+	return token.Error()
+}
+
+// publishStatus 发布任务状态（保留消息，新订阅者可立即拿到最新状态）
+func (p *mqttPublisher) publishStatus(status string) error {
+	return p.publish(p.topic+"/status", true, []byte(status))
+}
+
+// publishResult 发布任务结果和最终状态
+func (p *mqttPublisher) publishResult(payload WebhookPayload, result *TranscriptionResult) error {
+	msg := MQTTResult{WebhookPayload: payload}
+	if result != nil {
+		msg.Text = result.Text
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := p.publish(p.topic+"/result", false, data); err != nil {
+		return err
+	}
+
+	status := mqttStatusCompleted
+	if payload.Event == "job.failed" {
+		status = mqttStatusFailed
+	}
+	return p.publishStatus(status)
+}
+
+// publishDiscovery 发布 Home Assistant MQTT 自动发现配置：
+// 传感器状态为任务状态，属性为最近一次的转写结果
+func (p *mqttPublisher) publishDiscovery() error {
+	objectID := "whisper_go_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, p.topic)
+	data, err := json.Marshal(map[string]any{
+		"name":                  "Whisper Go",
+		"unique_id":             objectID,
+		"state_topic":           p.topic + "/status",
+		"json_attributes_topic": p.topic + "/result",
+		"icon":                  "mdi:text-to-speech",
+	})
+	if err != nil {
+		return err
+	}
+	return p.publish("homeassistant/sensor/"+objectID+"/config", true, data)
+}
+
+// Close 断开连接
+func (p *mqttPublisher) Close() {
+	p.client.Disconnect(250)
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/mewkiz/flac"
+)
+
+// errNotNativeAudio 输入不是可在 Go 中直接解码的音频（PCM WAV、FLAC 或 MP3）
+var errNotNativeAudio error = trError("不是可直接解码的音频文件")
+
+// pcm16kInfo 切片和 whisper.cpp 使用的 16kHz 单声道 16 位 PCM 格式
+var pcm16kInfo = &wavInfo{Channels: 1, SampleRate: 16000, BitsPerSample: 16}
+
+// nativeAudio 在 Go 中直接解码的音频流（整数 PCM WAV、FLAC 或 MP3），按帧读出各声道交错的 [-1, 1] 浮点采样。
+// FLAC 和 MP3 分别由 mewkiz/flac 和 hajimehoshi/go-mp3 解码
+type nativeAudio struct {
+	Channels   int
+	SampleRate int
+	Frames     int64 // 每声道的采样数，0 表示未知
+
+	file *os.File
+
+	// PCM WAV 的数据块，或 MP3 解码后的 16 位 PCM
+	pcm    *wavInfo
+	reader *bufio.Reader
+	raw    []byte
+	wav    bool
+	mp3    *mp3.Decoder
+
+	// FLAC
+	flac      *flac.Stream
+	flacScale float64
+	pending   []float64 // 已解码但尚未读出的采样
+}
+
+// openNativeAudio 打开 PCM WAV、FLAC 或 MP3 文件，其他格式返回 errNotNativeAudio
+func openNativeAudio(audioPath string) (*nativeAudio, error) {
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := readWAVInfo(f)
+	if err == nil {
+		if _, err := f.Seek(info.DataOffset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &nativeAudio{
+			Channels:   info.Channels,
+			SampleRate: info.SampleRate,
+			Frames:     info.DataSize / int64(info.frameSize()),
+			file:       f,
+			pcm:        info,
+			reader:     bufio.NewReaderSize(io.LimitReader(f, info.DataSize), 1<<16),
+			wav:        true,
+		}, nil
+	}
+	if !errors.Is(err, errNotPCMWAV) {
+		f.Close()
+		return nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err == nil && string(magic[:]) == "fLaC" {
+		stream, err := flac.New(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf(tr("读取 FLAC 元数据失败: %w"), err)
+		}
+		return &nativeAudio{
+			Channels:   int(stream.Info.NChannels),
+			SampleRate: int(stream.Info.SampleRate),
+			Frames:     int64(stream.Info.NSamples),
+			file:       f,
+			flac:       stream,
+			flacScale:  1 / float64(int64(1)<<(stream.Info.BitsPerSample-1)),
+		}, nil
+	}
+
+	// MP3 只按扩展名识别，其他 MPEG 容器（如 .m4a）中的帧同步码可能是误判；
+	// go-mp3 不支持的 Layer I/II 和 MPEG-2.5 仍交给 ffmpeg
+	duration, err := mp3Duration(audioPath)
+	if err != nil {
+		f.Close()
+		return nil, errNotNativeAudio
+	}
+	dec, err := mp3.NewDecoder(f)
+	if err != nil {
+		f.Close()
+		return nil, errNotNativeAudio
+	}
+	// 解码输出固定为 16 位双声道（单声道 MP3 两个声道相同）
+	frames := dec.Length() / 4
+	if frames < 0 {
+		frames = int64(math.Round(duration * float64(dec.SampleRate())))
+	}
+	return &nativeAudio{
+		Channels:   2,
+		SampleRate: dec.SampleRate(),
+		Frames:     frames,
+		file:       f,
+		pcm:        &wavInfo{Channels: 2, SampleRate: dec.SampleRate(), BitsPerSample: 16},
+		reader:     bufio.NewReaderSize(dec, 1<<16),
+		mp3:        dec,
+	}, nil
+}
+
+// Duration 音频时长（秒），采样数未知时返回 0
+func (a *nativeAudio) Duration() float64 {
+	return float64(a.Frames) / float64(a.SampleRate)
+}
+
+// Close 关闭音频文件
+func (a *nativeAudio) Close() error {
+	return a.file.Close()
+}
+
+// read 读取整数帧的交错采样到 dst，返回读到的采样数，没有更多数据时返回 io.EOF
+func (a *nativeAudio) read(dst []float64) (int, error) {
+	want := len(dst) - len(dst)%a.Channels
+	if a.pcm != nil {
+		sampleBytes := a.pcm.BitsPerSample / 8
+		if need := want * sampleBytes; len(a.raw) < need {
+			a.raw = make([]byte, need)
+		}
+		n, err := io.ReadFull(a.reader, a.raw[:want*sampleBytes])
+		n -= n % a.pcm.frameSize()
+		for i := 0; i < n/sampleBytes; i++ {
+			dst[i] = sampleValue(a.raw[i*sampleBytes:], a.pcm.BitsPerSample)
+		}
+		if n == 0 {
+			if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		return n / sampleBytes, nil
+	}
+
+	if len(a.pending) == 0 {
+		frame, err := a.flac.ParseNext()
+		if err != nil {
+			return 0, err
+		}
+		if len(frame.Subframes) != a.Channels {
+			return 0, errors.New(tr("FLAC 帧的声道数与 STREAMINFO 不一致"))
+		}
+		a.pending = a.pending[:0]
+		for i := range frame.Subframes[0].Samples {
+			for _, sub := range frame.Subframes {
+				a.pending = append(a.pending, float64(sub.Samples[i])*a.flacScale)
+			}
+		}
+	}
+	n := copy(dst[:want], a.pending)
+	a.pending = a.pending[n:]
+	return n, nil
+}
+
+// readFull 读满 dst（文件结尾除外），返回读到的采样数
+func (a *nativeAudio) readFull(dst []float64) (int, error) {
+	total := 0
+	for len(dst)-total >= a.Channels {
+		n, err := a.read(dst[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// skip 跳过 frames 帧：WAV 和 MP3 直接定位，FLAC 解码后丢弃
+func (a *nativeAudio) skip(frames int64) error {
+	if frames <= 0 {
+		return nil
+	}
+	if a.wav {
+		offset := min(frames*int64(a.pcm.frameSize()), a.pcm.DataSize)
+		if _, err := a.file.Seek(a.pcm.DataOffset+offset, io.SeekStart); err != nil {
+			return err
+		}
+		a.reader.Reset(io.LimitReader(a.file, a.pcm.DataSize-offset))
+		return nil
+	}
+	if a.mp3 != nil && a.mp3.Length() >= 0 {
+		if _, err := a.mp3.Seek(min(frames*4, a.mp3.Length()), io.SeekStart); err != nil {
+			return err
+		}
+		a.reader.Reset(a.mp3)
+		return nil
+	}
+
+	buf := make([]float64, 4096*a.Channels)
+	for frames > 0 {
+		n, err := a.read(buf[:min(int64(len(buf)), frames*int64(a.Channels))])
+		frames -= int64(n / a.Channels)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodePCM16k 把 [start, end) 区间（end 为 0 表示到结尾）混为单声道并重采样为 16kHz 16 位 PCM 写入 w，返回写入的字节数。
+// 降采样取每个输出采样对应输入区间的平均值，升采样使用线性插值，对语音识别足够
+func decodePCM16k(a *nativeAudio, start, end float64, w io.Writer) (int64, error) {
+	rate := int64(a.SampleRate)
+	outRate := int64(pcm16kInfo.SampleRate)
+	startFrame := int64(math.Round(start * float64(rate)))
+	endFrame := int64(math.MaxInt64)
+	if end > 0 {
+		endFrame = int64(math.Round(end * float64(rate)))
+	}
+	if err := a.skip(startFrame); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	out := bufio.NewWriterSize(w, 1<<16)
+	var written int64
+	var sample [2]byte
+	emit := func(v float64) error {
+		s := int16(math.Round(max(-1, min(v, 32767.0/32768)) * 32768))
+		binary.LittleEndian.PutUint16(sample[:], uint16(s))
+		written += 2
+		_, err := out.Write(sample[:])
+		return err
+	}
+
+	buf := make([]float64, 4096*a.Channels)
+	var rel int64  // 当前帧相对 startFrame 的序号
+	var next int64 // 下一个输出采样的序号
+	var sum, prev float64
+	var count int
+	for rel < endFrame-startFrame {
+		n, err := a.read(buf)
+		for i := 0; i+a.Channels <= n && rel < endFrame-startFrame; i += a.Channels {
+			var v float64
+			for _, s := range buf[i : i+a.Channels] {
+				v += s
+			}
+			v /= float64(a.Channels)
+
+			if rate >= outRate {
+				// 输出采样 next 覆盖输入帧 [next*rate/outRate, (next+1)*rate/outRate)
+				if k := rel * outRate / rate; k != next && count > 0 {
+					if err := emit(sum / float64(count)); err != nil {
+						return written, err
+					}
+					next, sum, count = k, 0, 0
+				}
+				sum += v
+				count++
+			} else {
+				// 输出采样 next 位于输入帧 next*rate/outRate，在前一帧和当前帧之间插值
+				for ; next*rate <= rel*outRate; next++ {
+					t := 1.0
+					if rel > 0 {
+						t = float64(next*rate-(rel-1)*outRate) / float64(outRate)
+					}
+					if err := emit(prev + t*(v-prev)); err != nil {
+						return written, err
+					}
+				}
+				prev = v
+			}
+			rel++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+
+	if count > 0 {
+		if err := emit(sum / float64(count)); err != nil {
+			return written, err
+		}
+	}
+	// 升采样时最后一帧之后还有不足一帧的输出采样
+	for ; rate < outRate && rel > 0 && next*rate < rel*outRate; next++ {
+		if err := emit(prev); err != nil {
+			return written, err
+		}
+	}
+	return written, out.Flush()
+}
+
+// decodeToWAV16k 在 Go 中把 PCM WAV、FLAC 或 MP3 的 [start, end) 区间转换为 16kHz 单声道 16 位 WAV，
+// end 为 0 表示到结尾；其他格式返回 errNotNativeAudio
+func decodeToWAV16k(audioPath string, start, end float64, outputPath string) error {
+	a, err := openNativeAudio(audioPath)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	if end > 0 && end <= start || start < 0 {
+		return fmt.Errorf(tr("无效的切片区间: %.3f - %.3f 秒"), start, end)
+	}
+
+	dst, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	// 先写入占位文件头，数据写完后回填实际长度
+	if err := writeWAVHeader(dst, pcm16kInfo, 0); err != nil {
+		dst.Close()
+		return err
+	}
+	dataSize, err := decodePCM16k(a, start, end, dst)
+	if err != nil {
+		dst.Close()
+		return fmt.Errorf(tr("解码音频失败: %w"), err)
+	}
+	if dataSize == 0 {
+		dst.Close()
+		return fmt.Errorf(tr("无效的切片区间: %.3f - %.3f 秒"), start, end)
+	}
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := writeWAVHeader(dst, pcm16kInfo, dataSize); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// nativeWAV16k 在 Go 中把整个 PCM WAV、FLAC 或 MP3 文件转换为工作目录中的 16kHz 单声道 WAV 临时文件，
+// 调用方负责用 removeTemp 删除；其他格式返回 errNotNativeAudio
+func nativeWAV16k(audioPath string) (string, error) {
+	outputPath := trackTemp(filepath.Join(workingDir(), fmt.Sprintf("whisper_%d.wav", time.Now().UnixNano())))
+	if err := decodeToWAV16k(audioPath, 0, 0, outputPath); err != nil {
+		removeTemp(outputPath)
+		return "", err
+	}
+	return outputPath, nil
+}
+
+// openNativePCM16k 以 16kHz 单声道 16 位 PCM 流读取 PCM WAV、FLAC 或 MP3，在后台协程中解码
+func openNativePCM16k(audioPath string) (io.ReadCloser, error) {
+	a, err := openNativeAudio(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	r, w := io.Pipe()
+	go func() {
+		_, err := decodePCM16k(a, 0, 0, w)
+		a.Close()
+		w.CloseWithError(err)
+	}()
+	return r, nil
+}
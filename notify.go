@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sendNotification 发送桌面通知，失败时静默忽略（通知只是辅助提示）
+func sendNotification(title, message string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(message), appleScriptQuote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(5000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, powerShellQuote(title), powerShellQuote(message))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=whisper-go", title, message)
+	}
+
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return
+	}
+	cmd.Start()
+	go cmd.Wait()
+}
+
+// appleScriptQuote 生成 AppleScript 字符串字面量
+func appleScriptQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// powerShellQuote 生成 PowerShell 单引号字符串字面量
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// jobAlert 任务或批量任务结束（完成或失败）时的提醒方式
+type jobAlert struct {
+	Notify bool // 桌面通知
+	Bell   bool // 终端响铃
+}
+
+// start 发送任务开始的桌面通知
+func (a jobAlert) start(message string) {
+	if a.Notify {
+		sendNotification("whisper-go", message)
+	}
+}
+
+// done 发送任务结束的提醒
+func (a jobAlert) done(title, message string) {
+	if a.Notify {
+		sendNotification(title, message)
+	}
+	if a.Bell {
+		ringBell()
+	}
+}
+
+// ringBell 在终端响铃。写到标准错误，避免混入标准输出中的结果
+func ringBell() {
+	fmt.Fprint(os.Stderr, "\a")
+}
+
+// completionMessage 生成转写完成通知的正文
+func completionMessage(inputFile string, result *TranscriptionResult) string {
+	if result.NoSpeech {
+		return fmt.Sprintf(tr("%s: 未检测到语音"), filepath.Base(inputFile))
+	}
+	return fmt.Sprintf(tr("%s: %d 个分段，%d 字符"), filepath.Base(inputFile), len(result.Segments), len([]rune(result.Text)))
+}
+
+// openFolder 在系统文件管理器中打开目录
+func openFolder(dir string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", dir)
+	case "windows":
+		cmd = exec.Command("explorer", dir)
+	default:
+		cmd = exec.Command("xdg-open", dir)
+	}
+	return cmd.Start()
+}
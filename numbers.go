@@ -0,0 +1,644 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 数字规范化规则（normalize_numbers），all 为全部启用
+const (
+	numberRuleNumbers = "numbers" // 多位数和小数：twenty four → 24，三点五 → 3.5
+	numberRuleYears   = "years"   // 年份：twenty twenty four → 2024，二零二四年 → 2024年
+	numberRulePercent = "percent" // 百分数：five percent → 5%，百分之五 → 5%
+	numberRuleUnits   = "units"   // 带单位的数：five kilometers → 5 km，三公斤 → 3公斤
+)
+
+// numberRules 可选的数字规范化规则
+var numberRules = []string{numberRuleNumbers, numberRuleYears, numberRulePercent, numberRuleUnits}
+
+// numberOptions 启用的数字规范化规则
+type numberOptions struct {
+	numbers, years, percent, units bool
+}
+
+// validateNumberRules 检查 normalize_numbers 中的规则名称
+func (c *Config) validateNumberRules() error {
+	for _, rule := range c.NormalizeNumbers {
+		if rule != "all" && !slices.Contains(numberRules, rule) {
+			return fmt.Errorf(tr("无效的 normalize_numbers 规则: %s（可选 numbers, years, percent, units, all）"), rule)
+		}
+	}
+	return nil
+}
+
+// numberOptions 按 normalize_numbers 返回启用的规则
+func (c *Config) numberOptions() numberOptions {
+	var o numberOptions
+	for _, rule := range c.NormalizeNumbers {
+		all := rule == "all"
+		o.numbers = o.numbers || all || rule == numberRuleNumbers
+		o.years = o.years || all || rule == numberRuleYears
+		o.percent = o.percent || all || rule == numberRulePercent
+		o.units = o.units || all || rule == numberRuleUnits
+	}
+	return o
+}
+
+// normalizeNumbers 按 normalize_numbers 把分段中读出来的数字改写为阿拉伯数字，规则按结果的语言选择（英文、中文），
+// 其他语言不处理。只改写分段文本和全文，词级时间戳保持原样
+func normalizeNumbers(result *TranscriptionResult, config *Config) {
+	if len(config.NormalizeNumbers) == 0 || result.NoSpeech {
+		return
+	}
+	language := result.Language
+	if language == "" {
+		language = config.Language
+	}
+	var normalize func(string, numberOptions) string
+	switch languageCode(language) {
+	case "en":
+		normalize = normalizeEnglishNumbers
+	case "zh", "yue":
+		normalize = normalizeChineseNumbers
+	default:
+		logDebug(tr("数字规范化不支持语言 %s，跳过"), language)
+		return
+	}
+	opts := config.numberOptions()
+
+	if len(result.Segments) == 0 {
+		result.Text = normalize(result.Text, opts)
+		return
+	}
+	changed := 0
+	for i := range result.Segments {
+		if text := normalize(result.Segments[i].Text, opts); text != result.Segments[i].Text {
+			result.Segments[i].Text = text
+			changed++
+		}
+	}
+	if changed > 0 {
+		rebuildResultText(result)
+		logDebug(tr("数字规范化: %d 个分段"), changed)
+	}
+}
+
+// ---- 英文 ----
+
+var (
+	// englishNumberToken 英文单词（连字符连接的如 twenty-four 作为一个词）
+	englishNumberToken = regexp.MustCompile(`[A-Za-z]+(?:-[A-Za-z]+)*`)
+
+	englishSmallNumbers = map[string]int64{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+		"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+		"seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	englishTens = map[string]int64{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+	}
+	englishScales = map[string]int64{
+		"hundred": 100, "thousand": 1e3, "million": 1e6, "billion": 1e9, "trillion": 1e12,
+	}
+
+	// englishUnits 单位名称对应的符号
+	englishUnits = map[string]string{
+		"kilometer": "km", "kilometers": "km", "kilometre": "km", "kilometres": "km",
+		"meter": "m", "meters": "m", "metre": "m", "metres": "m",
+		"centimeter": "cm", "centimeters": "cm", "centimetre": "cm", "centimetres": "cm",
+		"millimeter": "mm", "millimeters": "mm", "millimetre": "mm", "millimetres": "mm",
+		"kilogram": "kg", "kilograms": "kg", "kilo": "kg", "kilos": "kg",
+		"gram": "g", "grams": "g", "milligram": "mg", "milligrams": "mg",
+		"liter": "L", "liters": "L", "litre": "L", "litres": "L",
+		"milliliter": "mL", "milliliters": "mL", "millilitre": "mL", "millilitres": "mL",
+		"mile": "mi", "miles": "mi", "foot": "ft", "feet": "ft",
+		"kilobyte": "KB", "kilobytes": "KB", "megabyte": "MB", "megabytes": "MB",
+		"gigabyte": "GB", "gigabytes": "GB", "terabyte": "TB", "terabytes": "TB",
+		"hertz": "Hz", "kilohertz": "kHz", "megahertz": "MHz", "gigahertz": "GHz",
+		"watt": "W", "watts": "W", "kilowatt": "kW", "kilowatts": "kW", "volt": "V", "volts": "V",
+		"degree": "°", "degrees": "°",
+	}
+)
+
+// isEnglishNumberWord 是否为可以出现在读出来的数字中的词
+func isEnglishNumberWord(word string) bool {
+	_, small := englishSmallNumbers[word]
+	_, tens := englishTens[word]
+	_, scale := englishScales[word]
+	return small || tens || scale || word == "and" || word == "point" || word == "oh"
+}
+
+// normalizeEnglishNumbers 把英文文本中读出来的数字改写为阿拉伯数字。相邻的数字词（只隔空格）作为一个整体解析，
+// 整体解析不出数值时在 and 处拆开再试（nineteen oh five and nineteen hundred）；仍然不行的（如 twenty twenty four
+// 在未启用 years 时）保持原样。单独的个位数（one、five）只在带百分号或单位时改写
+func normalizeEnglishNumbers(text string, opts numberOptions) string {
+	matches := englishNumberToken.FindAllStringIndex(text, -1)
+	word := func(k int) string {
+		return strings.ToLower(text[matches[k][0]:matches[k][1]])
+	}
+	numeric := func(k int) bool {
+		for _, part := range strings.Split(word(k), "-") {
+			if !isEnglishNumberWord(part) {
+				return false
+			}
+		}
+		return true
+	}
+	adjacent := func(k int) bool {
+		return k+1 < len(matches) && strings.TrimSpace(text[matches[k][1]:matches[k+1][0]]) == ""
+	}
+
+	var b strings.Builder
+	last := 0
+	// convert 尝试改写 matches[start..end]，成功时写入并返回改写覆盖到的最后一个词
+	var convert func(start, end int) (int, bool)
+	convert = func(start, end int) (int, bool) {
+		// 去掉首尾的 and、oh 和结尾的 point
+		for start <= end && (word(start) == "and" || word(start) == "oh") {
+			start++
+		}
+		for start <= end && (word(end) == "and" || word(end) == "point") {
+			end--
+		}
+		if start > end {
+			return 0, false
+		}
+		var words []string
+		for m := start; m <= end; m++ {
+			words = append(words, strings.Split(word(m), "-")...)
+		}
+
+		digits, value, decimal, ok := parseEnglishNumber(words)
+		year := false
+		if !ok && opts.years {
+			var y int64
+			if y, ok = parseEnglishYear(words); ok {
+				digits, value, year = strconv.FormatInt(y, 10), y, true
+			}
+		}
+		if !ok {
+			// 在 and 处拆开分别改写
+			for m := start + 1; m < end; m++ {
+				if word(m) == "and" {
+					lastLeft, okLeft := convert(start, m-1)
+					lastRight, okRight := convert(m+1, end)
+					return max(lastLeft, lastRight), okLeft || okRight
+				}
+			}
+			return 0, false
+		}
+
+		// 后面紧跟的 percent 或单位
+		covered := end
+		var next, after string
+		if adjacent(end) {
+			next = word(end + 1)
+			if adjacent(end + 1) {
+				after = word(end + 2)
+			}
+		}
+		wanted := false
+		switch unit, isUnit := englishUnits[next]; {
+		case opts.percent && next == "percent" && !year:
+			digits += "%"
+			covered = end + 1
+			wanted = true
+		case opts.units && isUnit && !year:
+			covered = end + 1
+			switch {
+			case unit == "°" && after == "celsius":
+				digits += "°C"
+				covered = end + 2
+			case unit == "°" && after == "fahrenheit":
+				digits += "°F"
+				covered = end + 2
+			case unit == "°":
+				digits += unit
+			default:
+				digits += " " + unit
+			}
+			wanted = true
+		case year:
+			wanted = true
+		case opts.numbers:
+			wanted = len(words) >= 2 || value >= 10 || decimal
+		}
+		if !wanted {
+			return 0, false
+		}
+		b.WriteString(text[last:matches[start][0]])
+		b.WriteString(digits)
+		last = matches[covered][1]
+		return covered, true
+	}
+
+	for k := 0; k < len(matches); k++ {
+		if !numeric(k) {
+			continue
+		}
+		end := k
+		for adjacent(end) && numeric(end+1) {
+			end++
+		}
+		if covered, ok := convert(k, end); ok {
+			end = max(end, covered)
+		}
+		k = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// parseEnglishNumber 解析读出来的数字，如 one hundred and five、two point five、three million。
+// 以 million、billion、trillion 结尾且前面小于 1000 时保留单位词（3 million、2.5 billion）
+func parseEnglishNumber(words []string) (digits string, value int64, decimal, ok bool) {
+	if n := len(words); n >= 2 && englishScales[words[n-1]] >= 1e6 {
+		prefix, scale := words[:n-1], words[n-1]
+		if d, v, dec, pok := parseEnglishNumber(prefix); pok && v < 1000 && !strings.Contains(d, " ") {
+			return d + " " + scale, v * englishScales[scale], dec, true
+		}
+	}
+
+	intWords, fracWords := words, []string(nil)
+	if i := slices.Index(words, "point"); i >= 0 {
+		intWords, fracWords = words[:i], words[i+1:]
+		if len(fracWords) == 0 {
+			return "", 0, false, false
+		}
+	}
+	if len(intWords) > 0 {
+		if value, ok = parseEnglishInt(intWords); !ok {
+			return "", 0, false, false
+		}
+	}
+	digits = strconv.FormatInt(value, 10)
+	if fracWords == nil {
+		return digits, value, false, true
+	}
+	var frac strings.Builder
+	for _, w := range fracWords {
+		d, isSmall := englishSmallNumbers[w]
+		if w == "oh" {
+			d, isSmall = 0, true
+		}
+		if !isSmall || d > 9 {
+			return "", 0, false, false
+		}
+		frac.WriteString(strconv.FormatInt(d, 10))
+	}
+	return digits + "." + frac.String(), value, true, true
+}
+
+// parseEnglishInt 解析读出来的整数。相邻的两个数（如 twenty twenty、three four）不是合法的整数，返回 false
+func parseEnglishInt(words []string) (int64, bool) {
+	var total, current, lastScale int64
+	prev := ""
+	for i, w := range words {
+		kind := ""
+		switch {
+		case w == "and":
+			// and 只出现在 hundred、thousand 等之后，后面接十位或个位
+			if (prev != "hundred" && prev != "scale") || i+1 >= len(words) {
+				return 0, false
+			}
+			if _, ok := englishScales[words[i+1]]; ok || words[i+1] == "and" {
+				return 0, false
+			}
+			prev = "and"
+			continue
+		case w == "oh" || w == "point":
+			return 0, false
+		case englishSmallNumbers[w] > 0 || w == "zero":
+			v := englishSmallNumbers[w]
+			if prev == "small" || (prev == "tens" && v >= 10) || (v == 0 && len(words) > 1) {
+				return 0, false
+			}
+			current += v
+			kind = "small"
+		case englishTens[w] > 0:
+			if prev == "small" || prev == "tens" {
+				return 0, false
+			}
+			current += englishTens[w]
+			kind = "tens"
+		case w == "hundred":
+			if current == 0 || current >= 100 {
+				return 0, false
+			}
+			current *= 100
+			kind = "hundred"
+		default:
+			scale := englishScales[w]
+			if current == 0 || (lastScale != 0 && scale >= lastScale) {
+				return 0, false
+			}
+			total += current * scale
+			current, lastScale = 0, scale
+			kind = "scale"
+		}
+		prev = kind
+	}
+	return total + current, true
+}
+
+// parseEnglishYear 解析按两位一组读出的年份：nineteen ninety-nine、twenty twenty four、nineteen oh five、nineteen hundred
+func parseEnglishYear(words []string) (int64, bool) {
+	if len(words) < 2 {
+		return 0, false
+	}
+	var century int64
+	switch v := englishSmallNumbers[words[0]]; {
+	case v >= 11:
+		century = v
+	case words[0] == "twenty":
+		century = 20
+	default:
+		return 0, false
+	}
+	rest := words[1:]
+	switch {
+	case len(rest) == 1 && rest[0] == "hundred":
+		return century * 100, true
+	case len(rest) == 2 && rest[0] == "oh":
+		if d := englishSmallNumbers[rest[1]]; d >= 1 && d <= 9 {
+			return century*100 + d, true
+		}
+	case len(rest) == 1 && englishSmallNumbers[rest[0]] >= 10:
+		return century*100 + englishSmallNumbers[rest[0]], true
+	case len(rest) == 1 && englishTens[rest[0]] > 0:
+		return century*100 + englishTens[rest[0]], true
+	case len(rest) == 2 && englishTens[rest[0]] > 0:
+		if d := englishSmallNumbers[rest[1]]; d >= 1 && d <= 9 {
+			return century*100 + englishTens[rest[0]] + d, true
+		}
+	}
+	return 0, false
+}
+
+// ---- 中文 ----
+
+var (
+	chineseDigitValues = map[rune]int64{
+		'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '兩': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+	}
+	chineseSmallUnits = map[rune]int64{'十': 10, '百': 100, '千': 1000}
+	chineseLargeUnits = map[rune]int64{'万': 1e4, '萬': 1e4, '亿': 1e8, '億': 1e8}
+
+	// chineseMeasureUnits 数字后的计量单位，个位数后接这些单位时也改写（units 规则）。较长的在前
+	chineseMeasureUnits = []string{
+		"公里", "千米", "厘米", "毫米", "米", "公斤", "千克", "毫克", "克", "吨", "噸",
+		"毫升", "升", "美元", "元", "岁", "歲",
+	}
+
+	// chineseNumberIdioms 含数字但不表示数量的词
+	chineseNumberIdioms = []string{"十分", "十足", "十全十美", "十字", "十之八九", "十万火急", "十万八千里", "五十步笑百步"}
+)
+
+// isChineseNumeral 是否为中文数字或数位
+func isChineseNumeral(r rune) bool {
+	_, digit := chineseDigitValues[r]
+	_, small := chineseSmallUnits[r]
+	_, large := chineseLargeUnits[r]
+	return digit || small || large
+}
+
+// normalizeChineseNumbers 把中文文本中的中文数字改写为阿拉伯数字。数位写法（二十五、三百零五、一万五）解析为整数，
+// 三点五 解析为小数，百分之五 改为 5%；单独的个位数（一个、两天）和约数（三四个、十几个）保持原样
+func normalizeChineseNumbers(text string, opts numberOptions) string {
+	runes := []rune(text)
+	// 成语和固定搭配中的数字不改写；前面是数字时（五十分）不算
+	idiom := make([]bool, len(runes))
+	for _, word := range chineseNumberIdioms {
+		w := []rune(word)
+		for i := 0; i+len(w) <= len(runes); i++ {
+			if string(runes[i:i+len(w)]) == word && (i == 0 || !isChineseNumeral(runes[i-1])) {
+				for j := range w {
+					idiom[i+j] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		if opts.percent && strings.HasPrefix(string(runes[i:]), "百分之百") {
+			b.WriteString("100%")
+			i += 4
+			continue
+		}
+		if opts.percent && strings.HasPrefix(string(runes[i:min(i+3, len(runes))]), "百分之") {
+			if digits, n, ok := parseChineseNumberAt(runes, i+3, idiom); ok && n > 0 {
+				b.WriteString(digits + "%")
+				i += 3 + n
+				continue
+			}
+		}
+		if !isChineseNumeral(runes[i]) || idiom[i] {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isChineseNumeral(runes[j]) && !idiom[j] {
+			j++
+		}
+		digits, n, ok := parseChineseNumberAt(runes, i, idiom)
+		if ok && n > 0 && chineseNumberWanted(runes, i, i+n, opts) {
+			b.WriteString(digits)
+			i += n
+			continue
+		}
+		// 不改写时整段跳过，避免从中间重新开始（五六十 不会变成 五60）；
+		// 不是小数的 X点Y（三点五十分）是时间，连同后面的数字一起保持原样
+		if j+1 < len(runes) && (runes[j] == '点' || runes[j] == '點') && isChineseNumeral(runes[j+1]) {
+			for j++; j < len(runes) && isChineseNumeral(runes[j]); j++ {
+			}
+		}
+		b.WriteString(string(runes[i:j]))
+		i = j
+	}
+	return b.String()
+}
+
+// parseChineseNumberAt 从 runes[i] 开始解析中文数字（整数、小数、数字串），返回阿拉伯数字写法和消耗的字数
+func parseChineseNumberAt(runes []rune, i int, idiom []bool) (string, int, bool) {
+	j := i
+	for j < len(runes) && isChineseNumeral(runes[j]) && !idiom[j] {
+		j++
+	}
+	if j == i {
+		return "", 0, false
+	}
+	run := runes[i:j]
+
+	// 小数：整数部分 + 点 + 逐位读出的数字；后面接十、百或分时是时间（三点五十、三点五分），不是小数
+	if j+1 < len(runes) && (runes[j] == '点' || runes[j] == '點') {
+		if _, isDigit := chineseDigitValues[runes[j+1]]; isDigit {
+			var frac strings.Builder
+			m := j + 1
+			for m < len(runes) {
+				d, isDigit := chineseDigitValues[runes[m]]
+				if !isDigit {
+					break
+				}
+				frac.WriteString(strconv.FormatInt(d, 10))
+				m++
+			}
+			next := rune(0)
+			if m < len(runes) {
+				next = runes[m]
+			}
+			_, small := chineseSmallUnits[next]
+			if !small && next != '分' && next != '刻' {
+				if whole, ok := parseChineseInt(run); ok {
+					digits := strconv.FormatInt(whole, 10) + "." + frac.String()
+					// 一点五亿 → 1.5亿
+					if _, large := chineseLargeUnits[next]; large {
+						return digits + string(next), m + 1 - i, true
+					}
+					return digits, m - i, true
+				}
+			}
+		}
+	}
+
+	// 数字串：二零二四、一九九九
+	hasUnit := false
+	for _, r := range run {
+		if _, digit := chineseDigitValues[r]; !digit {
+			hasUnit = true
+		}
+	}
+	if !hasUnit {
+		if len(run) == 1 {
+			return strconv.FormatInt(chineseDigitValues[run[0]], 10), 1, true
+		}
+		var seq strings.Builder
+		for _, r := range run {
+			seq.WriteString(strconv.FormatInt(chineseDigitValues[r], 10))
+		}
+		return seq.String(), len(run), true
+	}
+
+	// 以万、亿结尾且只有这一个大数位时保留该字（三万 → 3万，两千万 → 2000万）
+	last := run[len(run)-1]
+	if _, large := chineseLargeUnits[last]; large {
+		prefix := run[:len(run)-1]
+		single := true
+		for _, r := range prefix {
+			if _, l := chineseLargeUnits[r]; l {
+				single = false
+			}
+		}
+		if single && len(prefix) > 0 {
+			if v, ok := parseChineseInt(prefix); ok {
+				return strconv.FormatInt(v, 10) + string(last), len(run), true
+			}
+		}
+	}
+	v, ok := parseChineseInt(run)
+	if !ok {
+		return "", 0, false
+	}
+	return strconv.FormatInt(v, 10), len(run), true
+}
+
+// parseChineseInt 解析数位写法的中文整数（十二、三百零五、两千三、一万五、一亿二千万）。
+// 相邻的数字（三四）、数位顺序错误（十百）等不是合法的整数，返回 false
+func parseChineseInt(run []rune) (int64, bool) {
+	var total, section, number, lastSmall, lastLarge int64
+	pending := false // 上一个字是数字，还没有乘数位
+	var after int64  // 紧挨着的上一个数位（用于 三百五、一万五 这样省略末位数位的说法）
+	zero := false    // 上一个数位之后出现过零
+	for k, r := range run {
+		if d, ok := chineseDigitValues[r]; ok {
+			if d == 0 {
+				if pending || k == 0 {
+					return 0, false
+				}
+				zero, after = true, 0
+				continue
+			}
+			if pending {
+				return 0, false
+			}
+			number, pending = d, true
+			continue
+		}
+		if u, ok := chineseSmallUnits[r]; ok {
+			if !pending {
+				// 开头的 十 表示 一十
+				if u != 10 || k != 0 {
+					return 0, false
+				}
+				number = 1
+			}
+			if lastSmall != 0 && u >= lastSmall {
+				return 0, false
+			}
+			section += number * u
+			number, pending, lastSmall, after, zero = 0, false, u, u, false
+			continue
+		}
+		u := chineseLargeUnits[r]
+		if pending {
+			section += number
+		}
+		if section == 0 || (lastLarge != 0 && u >= lastLarge && u != 1e8) {
+			return 0, false
+		}
+		if u == 1e8 {
+			total = (total + section) * u
+		} else {
+			total += section * u
+		}
+		section, number, pending, lastSmall, lastLarge, after, zero = 0, 0, false, 0, u, u, false
+	}
+	if pending {
+		// 三百五 = 350，一万五 = 15000；中间有零时（三百零五）就是个位
+		if after >= 100 && !zero {
+			number *= after / 10
+		}
+		section += number
+	}
+	return total + section, true
+}
+
+// chineseNumberWanted 按启用的规则判断 runes[i:end] 处的数字是否改写
+func chineseNumberWanted(runes []rune, i, end int, opts numberOptions) bool {
+	// 约数（几十、数百、十几个）
+	if (i > 0 && (runes[i-1] == '几' || runes[i-1] == '幾' || runes[i-1] == '数' || runes[i-1] == '數')) ||
+		(end < len(runes) && (runes[end] == '几' || runes[end] == '幾')) {
+		return false
+	}
+	run := runes[i:end]
+	hasUnit, hasZero, decimal := false, false, false
+	for _, r := range run {
+		switch _, digit := chineseDigitValues[r]; {
+		case r == '点' || r == '點':
+			decimal = true
+		case r == '零' || r == '〇':
+			hasZero = true
+		case !digit:
+			hasUnit = true
+		}
+	}
+	rest := string(runes[end:])
+
+	switch {
+	case opts.units && slices.ContainsFunc(chineseMeasureUnits, func(u string) bool { return strings.HasPrefix(rest, u) }):
+		return true
+	case decimal:
+		return opts.numbers
+	case !hasUnit && len(run) >= 2:
+		// 数字串：年份（二零二四年）或含零的编号（一零一），三四个 这样的约数不改写
+		return (opts.years && strings.HasPrefix(rest, "年")) || (opts.numbers && hasZero && len(run) >= 3)
+	case hasUnit:
+		return opts.numbers
+	}
+	return false
+}
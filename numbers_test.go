@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestNormalizeChineseNumbers(t *testing.T) {
+	all := numberOptions{numbers: true, years: true, percent: true, units: true}
+	tests := []struct {
+		name string
+		in   string
+		opts numberOptions
+		want string
+	}{
+		{"整数", "二十五个人", all, "25个人"},
+		{"零", "三百零五页", all, "305页"},
+		{"省略末位数位", "三百五", all, "350"},
+		{"万", "一万五", all, "15000"},
+		{"保留单个大数位", "两千万", all, "2000万"},
+		{"亿", "一亿二千万", all, "120000000"},
+		{"小数", "三点五", all, "3.5"},
+		{"小数加大数位", "一点五亿", all, "1.5亿"},
+		{"时间不是小数", "三点五十分", all, "三点五十分"},
+		{"百分比", "百分之五十", all, "50%"},
+		{"百分之百", "百分之百", all, "100%"},
+		{"年份", "二零二四年", all, "2024年"},
+		{"年份未启用", "二零二四年", numberOptions{percent: true}, "二零二四年"},
+		{"不含零的数字串", "一九年", numberOptions{numbers: true}, "一九年"},
+		{"单位", "五公里", all, "5公里"},
+		{"单位未启用", "五公里", numberOptions{numbers: true}, "五公里"},
+		{"单独的个位数", "一个人", all, "一个人"},
+		{"约数", "三四个", all, "三四个"},
+		{"几", "十几个", all, "十几个"},
+		{"成语", "十全十美", all, "十全十美"},
+		{"十分", "十分重要", all, "十分重要"},
+		{"前面是数字时不是成语", "五十分", all, "50分"},
+		{"十万火急", "十万火急", all, "十万火急"},
+		{"十万八千里", "差了十万八千里", all, "差了十万八千里"},
+		{"五十步笑百步", "五十步笑百步", all, "五十步笑百步"},
+		{"数位顺序错误", "十百", all, "十百"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeChineseNumbers(tt.in, tt.opts); got != tt.want {
+				t.Errorf("normalizeChineseNumbers(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEnglishNumbers(t *testing.T) {
+	all := numberOptions{numbers: true, years: true, percent: true, units: true}
+	tests := []struct {
+		name string
+		in   string
+		opts numberOptions
+		want string
+	}{
+		{"integer", "twenty four people", all, "24 people"},
+		{"hyphenated", "twenty-four people", all, "24 people"},
+		{"hundreds", "three hundred and five pages", all, "305 pages"},
+		{"thousands", "two thousand five hundred", all, "2500"},
+		{"decimal", "three point five", all, "3.5"},
+		{"percent", "fifty percent", all, "50%"},
+		{"single digit alone", "one of them", all, "one of them"},
+		{"single digit with unit", "five kilometers", all, "5 km"},
+		{"year", "twenty twenty four", all, "2024"},
+		{"year disabled", "twenty twenty four", numberOptions{numbers: true}, "twenty twenty four"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeEnglishNumbers(tt.in, tt.opts); got != tt.want {
+				t.Errorf("normalizeEnglishNumbers(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
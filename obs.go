@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// obs-websocket v5 协议的消息类型
+const (
+	obsOpHello           = 0
+	obsOpIdentify        = 1
+	obsOpIdentified      = 2
+	obsOpRequest         = 6
+	obsOpRequestResponse = 7
+)
+
+// obsMessage obs-websocket 消息
+type obsMessage struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d"`
+}
+
+// obsSink 通过 obs-websocket 直接更新 OBS 文本源的内容
+type obsSink struct {
+	conn       *websocket.Conn
+	textSource string
+	lines      int
+	caption    rollingCaption
+
+	mu        sync.Mutex
+	requestID int
+}
+
+// newOBSSink 连接 obs-websocket（v5 协议）并完成鉴权
+func newOBSSink(url, password, textSource string, lines int) (*obsSink, error) {
+	if textSource == "" {
+		return nil, errors.New(tr("未配置 obs_text_source（OBS 中文本源的名称）"))
+	}
+
+	dialer := websocket.Dialer{HandshakeTimeout: 5 * time.Second}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		return nil, fmt.Errorf(tr("连接 OBS WebSocket 失败: %w"), err)
+	}
+
+	if err := obsIdentify(conn, password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	sink := &obsSink{conn: conn, textSource: textSource, lines: lines}
+	// 读取并丢弃响应和事件，保持连接正常
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return sink, nil
+}
+
+// obsIdentify 完成 Hello/Identify 握手
+func obsIdentify(conn *websocket.Conn, password string) error {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var hello obsMessage
+	if err := conn.ReadJSON(&hello); err != nil || hello.Op != obsOpHello {
+		return fmt.Errorf(tr("OBS WebSocket 握手失败: %v"), err)
+	}
+	var helloData struct {
+		Authentication *struct {
+			Challenge string `json:"challenge"`
+			Salt      string `json:"salt"`
+		} `json:"authentication"`
+	}
+	json.Unmarshal(hello.D, &helloData)
+
+	identify := map[string]any{"rpcVersion": 1, "eventSubscriptions": 0}
+	if auth := helloData.Authentication; auth != nil {
+		if password == "" {
+			return errors.New(tr("OBS WebSocket 需要密码，请配置 obs_password"))
+		}
+		// secret = base64(sha256(password + salt))，auth = base64(sha256(secret + challenge))
+		secret := sha256.Sum256([]byte(password + auth.Salt))
+		secretB64 := base64.StdEncoding.EncodeToString(secret[:])
+		sum := sha256.Sum256([]byte(secretB64 + auth.Challenge))
+		identify["authentication"] = base64.StdEncoding.EncodeToString(sum[:])
+	}
+	data, _ := json.Marshal(identify)
+	if err := conn.WriteJSON(obsMessage{Op: obsOpIdentify, D: data}); err != nil {
+		return fmt.Errorf(tr("OBS WebSocket 鉴权失败: %w"), err)
+	}
+
+	var identified obsMessage
+	if err := conn.ReadJSON(&identified); err != nil || identified.Op != obsOpIdentified {
+		return errors.New(tr("OBS WebSocket 鉴权失败，请检查 obs_password"))
+	}
+	return nil
+}
+
+func (o *obsSink) WriteSegments(segments []Segment) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.caption.max = o.lines
+	text := o.caption.add(segments)
+
+	o.requestID++
+	data, _ := json.Marshal(map[string]any{
+		"requestType": "SetInputSettings",
+		"requestId":   fmt.Sprintf("whisper-go-%d", o.requestID),
+		"requestData": map[string]any{
+			"inputName":     o.textSource,
+			"inputSettings": map[string]any{"text": text},
+		},
+	})
+	return o.conn.WriteJSON(obsMessage{Op: obsOpRequest, D: data})
+}
+
+func (o *obsSink) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return o.conn.Close()
+}
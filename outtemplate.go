@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateSuffixes 模板文件的扩展名，输出文件名中去掉
+var templateSuffixes = []string{".tmpl", ".gotmpl", ".tpl"}
+
+// templateData 传给自定义模板的数据：嵌入转写结果，模板中可直接使用 .Text、.Segments、.Chapters 等字段
+type templateData struct {
+	*TranscriptionResult
+	// Input 输入文件路径，InputName 为文件名
+	Input     string
+	InputName string
+	Model     string
+	// Speakers 按首次出现顺序列出的说话人标签
+	Speakers  []string
+	Generated time.Time
+}
+
+// templateFuncs 模板中可用的函数
+var templateFuncs = template.FuncMap{
+	"srtTime": formatSRTTime,
+	"assTime": formatASSTime,
+	"lrcTime": formatLRCTime,
+	"clock":   formatChapterTime,
+	"trim":    strings.TrimSpace,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"join":    strings.Join,
+	"replace": strings.ReplaceAll,
+	"add":     func(a, b int) int { return a + b },
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// loadOutputTemplate 解析自定义输出模板，模板名为文件名
+func loadOutputTemplate(path string) (*template.Template, error) {
+	if path == "" {
+		return nil, errors.New(tr("template 格式需要通过 --template 或配置 template 指定模板文件"))
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf(tr("解析模板失败: %w"), err)
+	}
+	return tmpl, nil
+}
+
+// templateExtension 模板输出的扩展名：模板文件名去掉 .tmpl 等后缀，如 notes.md.tmpl 为 notes.md；
+// 去掉后没有扩展名时加上 .txt
+func templateExtension(path string) string {
+	name := filepath.Base(path)
+	for _, suffix := range templateSuffixes {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			name = strings.TrimSuffix(name, suffix)
+			break
+		}
+	}
+	if filepath.Ext(name) == "" {
+		name += ".txt"
+	}
+	return name
+}
+
+// saveTemplate 用自定义模板生成输出文件，模板执行失败时不留下不完整的文件
+func saveTemplate(result *TranscriptionResult, inputFile string, config *Config, outputPath string) error {
+	tmpl, err := loadOutputTemplate(config.Template)
+	if err != nil {
+		return err
+	}
+	data := templateData{
+		TranscriptionResult: result,
+		Input:               inputFile,
+		InputName:           filepath.Base(inputFile),
+		Model:               config.Model,
+		Speakers:            speakerLabels(result.Segments),
+		Generated:           time.Now(),
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf(tr("执行模板失败: %w"), err)
+	}
+	return os.WriteFile(outputPath, buf.Bytes(), 0644)
+}
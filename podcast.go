@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// podcastTitleMaxLen 输出文件名中节目标题的最大字符数
+const podcastTitleMaxLen = 80
+
+// rssFeed RSS 订阅中用到的字段
+type rssFeed struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// rssItem 单集节目
+type rssItem struct {
+	Title     string `xml:"title"`
+	GUID      string `xml:"guid"`
+	PubDate   string `xml:"pubDate"`
+	Enclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+// id 返回单集的唯一标识，没有 guid 时使用音频地址
+func (item rssItem) id() string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	return item.Enclosure.URL
+}
+
+// published 解析发布时间，RSS 中的日期格式并不统一
+func (item rssItem) published() time.Time {
+	layouts := []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2 Jan 2006 15:04:05 -0700", time.RFC3339}
+	s := strings.TrimSpace(item.PubDate)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// podcastState 已处理节目的记录，避免重复下载和转写
+type podcastState struct {
+	Episodes map[string]podcastEpisode `json:"episodes"`
+}
+
+// podcastEpisode 已转写的单集
+type podcastEpisode struct {
+	Title         string    `json:"title"`
+	Published     time.Time `json:"published,omitempty"`
+	OutputFiles   []string  `json:"output_files"`
+	TranscribedAt time.Time `json:"transcribed_at"`
+}
+
+// loadPodcastState 读取状态文件，不存在时返回空状态
+func loadPodcastState(path string) (*podcastState, error) {
+	state := &podcastState{Episodes: map[string]podcastEpisode{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf(tr("解析状态文件失败: %w"), err)
+	}
+	if state.Episodes == nil {
+		state.Episodes = map[string]podcastEpisode{}
+	}
+	return state, nil
+}
+
+// save 写入状态文件（先写临时文件再重命名，中途退出不会损坏已有记录）
+func (s *podcastState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// runPodcast 执行 podcast 子命令：读取 RSS 订阅，下载并转写尚未处理的节目
+func runPodcast(args []string) {
+	fs := flag.NewFlagSet("podcast", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	outputDir := fs.String("output", "", tr("输出目录"))
+	formats := fs.String("formats", "txt,srt,json", tr("输出格式（逗号分隔）"))
+	statePath := fs.String("state", "", tr("记录已处理节目的状态文件（默认为输出目录下的 podcast_state.json）"))
+	limit := fs.Int("limit", 0, tr("本次最多处理的新节目数（从最新的开始，0 为不限制）"))
+	verbose := fs.Bool("verbose", false, tr("显示详细输出"))
+	fs.Parse(args)
+	useVerboseLogging(*verbose)
+	installShutdownHandler()
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go podcast <rss-url> [options]"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	feedURL := fs.Arg(0)
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fatalf(tr("加载配置失败: %v"), err)
+	}
+	if config.APIKey == "" && config.needsAPIKey() {
+		fatal(tr("配置文件中未设置 API Key，请先在 config.json 中配置 api_key"))
+	}
+	if *outputDir != "" {
+		config.OutputDir = *outputDir
+	}
+
+	if *statePath == "" {
+		*statePath = "podcast_state.json"
+		if !isRemoteURI(config.OutputDir) {
+			if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+				fatalf(tr("创建输出目录失败: %v"), err)
+			}
+			*statePath = filepath.Join(config.OutputDir, "podcast_state.json")
+		}
+	}
+	state, err := loadPodcastState(*statePath)
+	if err != nil {
+		fatalf(tr("读取状态文件失败: %v"), err)
+	}
+
+	feed, err := fetchFeed(feedURL)
+	if err != nil {
+		fatalf(tr("读取 RSS 订阅失败: %v"), err)
+	}
+
+	// 筛选未处理的节目，从最新的开始截取，再按发布时间从旧到新处理
+	var pending []rssItem
+	for _, item := range feed.Channel.Items {
+		if item.Enclosure.URL == "" {
+			continue
+		}
+		if _, done := state.Episodes[item.id()]; !done {
+			pending = append(pending, item)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].published().After(pending[j].published())
+	})
+	if *limit > 0 && len(pending) > *limit {
+		pending = pending[:*limit]
+	}
+	for i, j := 0, len(pending)-1; i < j; i, j = i+1, j-1 {
+		pending[i], pending[j] = pending[j], pending[i]
+	}
+
+	logInfo(tr("%s: %d 个新节目\n"), feed.Channel.Title, len(pending))
+	if len(pending) == 0 {
+		return
+	}
+
+	client := newClient(config)
+	formatList := parseFormats(*formats)
+	failed := 0
+
+	for i, item := range pending {
+		logInfo("\n[%d/%d] %s", i+1, len(pending), item.Title)
+
+		outputFiles, err := transcribeEpisode(client, item, config, formatList, *verbose)
+		if err != nil {
+			logError(tr("处理失败: %v"), err)
+			failed++
+			continue
+		}
+		for _, file := range outputFiles {
+			logInfo("  - %s", file)
+		}
+
+		state.Episodes[item.id()] = podcastEpisode{
+			Title:         item.Title,
+			Published:     item.published(),
+			OutputFiles:   outputFiles,
+			TranscribedAt: time.Now(),
+		}
+		if err := state.save(*statePath); err != nil {
+			fatalf(tr("保存状态文件失败: %v"), err)
+		}
+	}
+
+	logInfo(tr("\n完成: %d 成功, %d 失败\n"), len(pending)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// fetchFeed 下载并解析 RSS 订阅
+func fetchFeed(feedURL string) (*rssFeed, error) {
+	resp, err := http.Get(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(tr("服务器返回 %s"), resp.Status)
+	}
+
+	var feed rssFeed
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf(tr("解析 RSS 失败: %w"), err)
+	}
+	return &feed, nil
+}
+
+// transcribeEpisode 下载单集音频并转写，输出文件以发布日期和节目标题命名
+func transcribeEpisode(client *openai.Client, item rssItem, config *Config, formatList []string, verbose bool) ([]string, error) {
+	tempDir, err := os.MkdirTemp(workingDir(), "whisper_podcast_")
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemp(trackTemp(tempDir))
+
+	audioPath := filepath.Join(tempDir, episodeFilename(item))
+	logDebug(tr("正在下载: %s\n"), item.Enclosure.URL)
+	if err := downloadFile(item.Enclosure.URL, audioPath); err != nil {
+		return nil, fmt.Errorf(tr("下载失败: %w"), err)
+	}
+
+	_, outputFiles, err := processFile(client, audioPath, config, formatList, verbose)
+	return outputFiles, err
+}
+
+// episodeFilename 生成 <发布日期>_<标题>.<扩展名> 形式的文件名
+func episodeFilename(item rssItem) string {
+	name := sanitizeFilename(item.Title)
+	if name == "" {
+		name = "episode"
+	}
+	if t := item.published(); !t.IsZero() {
+		name = t.Format("2006-01-02") + "_" + name
+	}
+	return name + episodeExt(item)
+}
+
+// episodeExt 根据音频地址或 MIME 类型确定扩展名
+func episodeExt(item rssItem) string {
+	if u, err := url.Parse(item.Enclosure.URL); err == nil {
+		if ext := strings.ToLower(path.Ext(u.Path)); ext != "" && len(ext) <= 5 {
+			return ext
+		}
+	}
+	switch item.Enclosure.Type {
+	case "audio/mp4", "audio/x-m4a", "audio/m4a":
+		return ".m4a"
+	case "audio/aac":
+		return ".aac"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	case "audio/flac":
+		return ".flac"
+	case "video/mp4":
+		return ".mp4"
+	}
+	return ".mp3"
+}
+
+// sanitizeFilename 去掉文件名中不允许的字符并限制长度
+func sanitizeFilename(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if runes := []rune(s); len(runes) > podcastTitleMaxLen {
+		s = string(runes[:podcastTitleMaxLen])
+	}
+	return strings.Trim(s, " .")
+}
+
+// downloadFile 下载文件到本地路径
+func downloadFile(fileURL, dest string) error {
+	resp, err := http.Get(fileURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(tr("服务器返回 %s"), resp.Status)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,241 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: whisper.proto
+
+package whisperpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TranscriptionService_Transcribe_FullMethodName       = "/whisper.v1.TranscriptionService/Transcribe"
+	TranscriptionService_TranscribeStream_FullMethodName = "/whisper.v1.TranscriptionService/TranscribeStream"
+	TranscriptionService_GetJob_FullMethodName           = "/whisper.v1.TranscriptionService/GetJob"
+	TranscriptionService_CancelJob_FullMethodName        = "/whisper.v1.TranscriptionService/CancelJob"
+)
+
+// TranscriptionServiceClient is the client API for TranscriptionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TranscriptionService 转写服务
+type TranscriptionServiceClient interface {
+	// Transcribe 转写服务端本地文件或请求中携带的音视频内容
+	Transcribe(ctx context.Context, in *TranscribeRequest, opts ...grpc.CallOption) (*Job, error)
+	// TranscribeStream 分块上传音视频内容后转写，第一条消息需携带文件名和选项
+	TranscribeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[TranscribeChunk, Job], error)
+	// GetJob 查询任务状态和结果
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// CancelJob 取消排队中或运行中的任务
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error)
+}
+
+type transcriptionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTranscriptionServiceClient(cc grpc.ClientConnInterface) TranscriptionServiceClient {
+	return &transcriptionServiceClient{cc}
+}
+
+func (c *transcriptionServiceClient) Transcribe(ctx context.Context, in *TranscribeRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, TranscriptionService_Transcribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transcriptionServiceClient) TranscribeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[TranscribeChunk, Job], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TranscriptionService_ServiceDesc.Streams[0], TranscriptionService_TranscribeStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TranscribeChunk, Job]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TranscriptionService_TranscribeStreamClient = grpc.ClientStreamingClient[TranscribeChunk, Job]
+
+func (c *transcriptionServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, TranscriptionService_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transcriptionServiceClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, TranscriptionService_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TranscriptionServiceServer is the server API for TranscriptionService service.
+// All implementations must embed UnimplementedTranscriptionServiceServer
+// for forward compatibility.
+//
+// TranscriptionService 转写服务
+type TranscriptionServiceServer interface {
+	// Transcribe 转写服务端本地文件或请求中携带的音视频内容
+	Transcribe(context.Context, *TranscribeRequest) (*Job, error)
+	// TranscribeStream 分块上传音视频内容后转写，第一条消息需携带文件名和选项
+	TranscribeStream(grpc.ClientStreamingServer[TranscribeChunk, Job]) error
+	// GetJob 查询任务状态和结果
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// CancelJob 取消排队中或运行中的任务
+	CancelJob(context.Context, *CancelJobRequest) (*Job, error)
+	mustEmbedUnimplementedTranscriptionServiceServer()
+}
+
+// UnimplementedTranscriptionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTranscriptionServiceServer struct{}
+
+func (UnimplementedTranscriptionServiceServer) Transcribe(context.Context, *TranscribeRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transcribe not implemented")
+}
+func (UnimplementedTranscriptionServiceServer) TranscribeStream(grpc.ClientStreamingServer[TranscribeChunk, Job]) error {
+	return status.Errorf(codes.Unimplemented, "method TranscribeStream not implemented")
+}
+func (UnimplementedTranscriptionServiceServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedTranscriptionServiceServer) CancelJob(context.Context, *CancelJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedTranscriptionServiceServer) mustEmbedUnimplementedTranscriptionServiceServer() {}
+func (UnimplementedTranscriptionServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeTranscriptionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TranscriptionServiceServer will
+// result in compilation errors.
+type UnsafeTranscriptionServiceServer interface {
+	mustEmbedUnimplementedTranscriptionServiceServer()
+}
+
+func RegisterTranscriptionServiceServer(s grpc.ServiceRegistrar, srv TranscriptionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTranscriptionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TranscriptionService_ServiceDesc, srv)
+}
+
+func _TranscriptionService_Transcribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranscribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscriptionServiceServer).Transcribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscriptionService_Transcribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscriptionServiceServer).Transcribe(ctx, req.(*TranscribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranscriptionService_TranscribeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TranscriptionServiceServer).TranscribeStream(&grpc.GenericServerStream[TranscribeChunk, Job]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TranscriptionService_TranscribeStreamServer = grpc.ClientStreamingServer[TranscribeChunk, Job]
+
+func _TranscriptionService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscriptionServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscriptionService_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscriptionServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranscriptionService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscriptionServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscriptionService_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscriptionServiceServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TranscriptionService_ServiceDesc is the grpc.ServiceDesc for TranscriptionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TranscriptionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "whisper.v1.TranscriptionService",
+	HandlerType: (*TranscriptionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Transcribe",
+			Handler:    _TranscriptionService_Transcribe_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _TranscriptionService_GetJob_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _TranscriptionService_CancelJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TranscribeStream",
+			Handler:       _TranscriptionService_TranscribeStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "whisper.proto",
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 字幕合成方式
+const (
+	muxModeSoft = "soft" // 作为可切换的字幕轨道封装，不重新编码
+	muxModeBurn = "burn" // 烧录进画面，需要重新编码视频
+)
+
+// publishProject publish 子命令的项目文件，描述一期节目从转写到发布的全部步骤。
+// 相对路径均相对于项目文件所在目录
+type publishProject struct {
+	Input     string            `json:"input"`
+	Config    string            `json:"config,omitempty"`
+	OutputDir string            `json:"output_dir,omitempty"`
+	Language  string            `json:"language,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Formats   []string          `json:"formats,omitempty"`
+	Glossary  string            `json:"glossary,omitempty"`
+	Chapters  bool              `json:"chapters,omitempty"`
+	Translate *publishTranslate `json:"translate,omitempty"`
+	Mux       *publishMux       `json:"mux,omitempty"`
+	Upload    *publishUpload    `json:"upload,omitempty"`
+	Notify    *publishNotify    `json:"notify,omitempty"`
+}
+
+// publishTranslate 字幕翻译步骤
+type publishTranslate struct {
+	Model     string   `json:"model"`
+	Languages []string `json:"languages"`
+}
+
+// publishMux 字幕合成步骤
+type publishMux struct {
+	Mode      string   `json:"mode"`                // soft 或 burn
+	Output    string   `json:"output,omitempty"`    // 默认为输出目录下的 <文件名>_subtitled.<扩展名>
+	Languages []string `json:"languages,omitempty"` // 要合成的字幕语言，默认全部；burn 只使用第一个
+}
+
+// publishUpload 上传步骤
+type publishUpload struct {
+	Destination  string `json:"destination"`             // 对象存储目录，如 s3://bucket/episodes/42/
+	IncludeMedia bool   `json:"include_media,omitempty"` // 同时上传合成后的视频
+}
+
+// publishNotify 通知步骤
+type publishNotify struct {
+	WebhookURL string `json:"webhook_url,omitempty"`
+	Desktop    bool   `json:"desktop,omitempty"`
+}
+
+// subtitleTrack 一条字幕及其语言
+type subtitleTrack struct {
+	Language string
+	Path     string
+}
+
+// loadPublishProject 读取项目文件，将相对路径转换为相对于项目文件所在目录的路径
+func loadPublishProject(path string) (*publishProject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取项目文件失败: %w"), err)
+	}
+	var project publishProject
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf(tr("解析项目文件失败: %w"), err)
+	}
+
+	if project.Input == "" {
+		return nil, errors.New(tr("项目文件缺少 input"))
+	}
+	if project.Config == "" {
+		project.Config = "config.json"
+	}
+	if project.Translate != nil && len(project.Translate.Languages) > 0 && project.Translate.Model == "" {
+		return nil, errors.New(tr("translate 缺少 model"))
+	}
+	if project.Mux != nil {
+		switch project.Mux.Mode {
+		case "":
+			project.Mux.Mode = muxModeSoft
+		case muxModeSoft, muxModeBurn:
+		default:
+			return nil, fmt.Errorf(tr("无效的 mux.mode: %s（可选 soft, burn）"), project.Mux.Mode)
+		}
+	}
+	if project.Upload != nil && !isRemoteURI(project.Upload.Destination) {
+		return nil, fmt.Errorf(tr("upload.destination 必须是对象存储地址: %s"), project.Upload.Destination)
+	}
+
+	base := filepath.Dir(path)
+	resolve := func(p *string) {
+		if *p != "" && !filepath.IsAbs(*p) && !isRemoteURI(*p) {
+			*p = filepath.Join(base, *p)
+		}
+	}
+	resolve(&project.Input)
+	resolve(&project.Config)
+	resolve(&project.OutputDir)
+	resolve(&project.Glossary)
+	if project.Mux != nil {
+		resolve(&project.Mux.Output)
+	}
+	return &project, nil
+}
+
+// runPublish 执行 publish 子命令：按项目文件依次完成转写、后处理、翻译、字幕合成、上传和通知
+func runPublish(args []string) {
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	verbose := fs.Bool("verbose", false, tr("显示详细输出"))
+	fs.Parse(args)
+	useVerboseLogging(*verbose)
+	installShutdownHandler()
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go publish <project.json> [options]"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	project, err := loadPublishProject(fs.Arg(0))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if _, err := os.Stat(project.Input); os.IsNotExist(err) && !isRemoteURI(project.Input) {
+		fatalf(tr("输入文件不存在: %s"), project.Input)
+	}
+	if project.Mux != nil && !isVideoFile(project.Input) {
+		fatal(tr("mux 需要视频输入"))
+	}
+
+	config, err := loadConfig(project.Config)
+	if err != nil {
+		fatalf(tr("加载配置失败: %v"), err)
+	}
+	if config.APIKey == "" && config.needsAPIKey() {
+		fatal(tr("配置文件中未设置 API Key，请先在 config.json 中配置 api_key"))
+	}
+	if project.OutputDir != "" {
+		config.OutputDir = project.OutputDir
+	}
+	if isRemoteURI(config.OutputDir) {
+		fatal(tr("publish 的输出目录必须是本地目录，上传到对象存储请使用 upload"))
+	}
+	if project.Language != "" {
+		config.Language = project.Language
+	}
+	if project.Model != "" {
+		config.Model = project.Model
+		if err := config.validateModel(); err != nil {
+			exitWith(exitConfig, "%v", err)
+		}
+	}
+	if project.Glossary != "" {
+		config.GlossaryFile = project.Glossary
+		if config.glossary, err = loadGlossary(project.Glossary); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	formatList := []string{"txt", "srt", "json"}
+	if len(project.Formats) > 0 {
+		formatList = parseFormats(strings.Join(project.Formats, ","))
+	}
+	if project.Mux != nil || project.Translate != nil {
+		formatList = appendFormats(formatList, "srt")
+	}
+	if project.Chapters {
+		config.Chapters = true
+		formatList = appendFormats(formatList, "chapters", "ffmetadata")
+	}
+
+	steps := []string{tr("转写")}
+	if project.Translate != nil && len(project.Translate.Languages) > 0 {
+		steps = append(steps, tr("翻译"))
+	}
+	if project.Mux != nil {
+		steps = append(steps, tr("合成字幕"))
+	}
+	if project.Upload != nil {
+		steps = append(steps, tr("上传"))
+	}
+	step := 0
+	nextStep := func() {
+		step++
+		logInfo("[%d/%d] %s\n", step, len(steps), steps[step-1])
+	}
+
+	start := time.Now()
+	client := newClient(config)
+	var result *TranscriptionResult
+	var outputFiles, uploaded []string
+	var media string
+
+	// 任一步骤失败时也发送通知，再退出
+	fail := func(err error) {
+		notifyPublish(project, result, outputFiles, err, time.Since(start))
+		fatalf(tr("发布失败: %v"), err)
+	}
+
+	// 转写和后处理（过滤、术语修正、章节）
+	nextStep()
+	result, outputFiles, err = processFile(client, project.Input, config, formatList, *verbose)
+	if err != nil {
+		fail(err)
+	}
+
+	tracks := []subtitleTrack{}
+	if srt := findOutputFile(outputFiles, ".srt"); srt != "" {
+		language := config.Language
+		if language == "" {
+			language = result.Language
+		}
+		tracks = append(tracks, subtitleTrack{Language: language, Path: srt})
+	}
+
+	// 翻译
+	if project.Translate != nil && len(project.Translate.Languages) > 0 {
+		nextStep()
+		if len(tracks) == 0 {
+			fail(errors.New(tr("未生成 SRT 字幕，无法翻译")))
+		}
+		base := strings.TrimSuffix(tracks[0].Path, ".srt")
+		for _, language := range project.Translate.Languages {
+			logDebug(tr("正在翻译为 %s\n"), language)
+			translated, err := translateResult(client, result, project.Translate.Model, language)
+			if err != nil {
+				fail(fmt.Errorf(tr("翻译为 %s 失败: %w"), language, err))
+			}
+			path := base + "." + language + ".srt"
+			if err := saveSRT(translated, path); err != nil {
+				fail(fmt.Errorf(tr("保存 SRT 失败: %w"), err))
+			}
+			logDebug(tr("已保存: %s\n"), path)
+			if failure := runPostWriteHook(config, "srt", path); failure != nil {
+				result.HookFailures = append(result.HookFailures, *failure)
+			}
+			outputFiles = append(outputFiles, path)
+			tracks = append(tracks, subtitleTrack{Language: language, Path: path})
+		}
+	}
+
+	// 合成字幕
+	if project.Mux != nil {
+		nextStep()
+		selected := selectSubtitleTracks(tracks, project.Mux.Languages)
+		if len(selected) == 0 {
+			fail(errors.New(tr("没有可合成的字幕")))
+		}
+		media = project.Mux.Output
+		if media == "" {
+			name := filepath.Base(project.Input)
+			ext := filepath.Ext(name)
+			media = filepath.Join(filepath.Dir(selected[0].Path), strings.TrimSuffix(name, ext)+"_subtitled"+ext)
+		}
+		chapters := findOutputFile(outputFiles, ".ffmetadata")
+		if err := muxSubtitles(project.Input, media, project.Mux.Mode, selected, chapters); err != nil {
+			fail(err)
+		}
+		logDebug(tr("已保存: %s\n"), media)
+	}
+
+	// 上传字幕和文稿
+	if project.Upload != nil {
+		nextStep()
+		files := outputFiles
+		if project.Upload.IncludeMedia && media != "" {
+			files = append(append([]string{}, outputFiles...), media)
+		}
+		uploaded, err = uploadFiles(context.Background(), files, project.Upload.Destination)
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	// 通知
+	notifyPublish(project, result, outputFiles, nil, time.Since(start))
+
+	printSummary(result, outputFiles)
+	if media != "" {
+		fmt.Printf(tr("合成视频: %s\n"), media)
+	}
+	if len(uploaded) > 0 {
+		fmt.Println(tr("已上传:"))
+		for _, uri := range uploaded {
+			fmt.Printf("  - %s\n", uri)
+		}
+	}
+}
+
+// findOutputFile 返回输出文件中第一个指定扩展名的文件
+func findOutputFile(outputFiles []string, ext string) string {
+	for _, file := range outputFiles {
+		if strings.EqualFold(filepath.Ext(file), ext) {
+			return file
+		}
+	}
+	return ""
+}
+
+// selectSubtitleTracks 按语言列表挑选字幕，列表为空时返回全部
+func selectSubtitleTracks(tracks []subtitleTrack, languages []string) []subtitleTrack {
+	if len(languages) == 0 {
+		return tracks
+	}
+	var selected []subtitleTrack
+	for _, language := range languages {
+		for _, track := range tracks {
+			if strings.EqualFold(track.Language, language) {
+				selected = append(selected, track)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// muxSubtitles 用 ffmpeg 将字幕合成到视频：soft 封装为字幕轨道（不重新编码），
+// burn 将第一条字幕烧录进画面。chapters 不为空时同时写入章节
+func muxSubtitles(input, output, mode string, tracks []subtitleTrack, chapters string) error {
+	if _, err := exec.LookPath(ffmpegTools.FFmpeg); err != nil {
+		return fmt.Errorf(tr("未找到 ffmpeg（%s），请先安装 ffmpeg 或在配置中设置 ffmpeg_path"), ffmpegTools.FFmpeg)
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fmt.Errorf(tr("创建输出目录失败: %w"), err)
+	}
+
+	args := append([]string{}, ffmpegTools.InputArgs...)
+	args = append(args, "-i", input)
+	if mode == muxModeBurn {
+		tracks = tracks[:1]
+	} else {
+		for _, track := range tracks {
+			args = append(args, "-i", track.Path)
+		}
+	}
+	// 章节文件是最后一个输入：烧录时为 1，封装时排在所有字幕之后
+	chapterInput := -1
+	if chapters != "" {
+		chapterInput = 1
+		if mode != muxModeBurn {
+			chapterInput += len(tracks)
+		}
+		args = append(args, "-i", chapters)
+	}
+
+	switch mode {
+	case muxModeBurn:
+		args = append(args, "-map", "0:v", "-map", "0:a?",
+			"-vf", "subtitles="+escapeFilterPath(tracks[0].Path),
+			"-c:a", "copy")
+	default:
+		args = append(args, "-map", "0:v", "-map", "0:a?")
+		for i := range tracks {
+			args = append(args, "-map", fmt.Sprintf("%d:s", i+1))
+		}
+		args = append(args, "-c", "copy", "-c:s", subtitleCodec(output))
+		for i, track := range tracks {
+			args = append(args,
+				fmt.Sprintf("-metadata:s:s:%d", i), "language="+iso6392(track.Language),
+				fmt.Sprintf("-metadata:s:s:%d", i), "title="+track.Language)
+		}
+	}
+	if chapterInput >= 0 {
+		args = append(args, "-map_chapters", fmt.Sprint(chapterInput))
+	}
+	args = append(args, "-y", output)
+
+	logDebug(tr("执行: %s %s\n"), ffmpegTools.FFmpeg, strings.Join(args, " "))
+	if out, err := exec.Command(ffmpegTools.FFmpeg, args...).CombinedOutput(); err != nil {
+		os.Remove(output)
+		return fmt.Errorf(tr("ffmpeg 合成字幕失败: %w: %s"), err, lastLine(string(out)))
+	}
+	return nil
+}
+
+// subtitleCodec 按容器选择字幕编码：MP4/MOV 只支持 mov_text，WebM 只支持 WebVTT
+func subtitleCodec(output string) string {
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".mp4", ".m4v", ".mov":
+		return "mov_text"
+	case ".webm":
+		return "webvtt"
+	default:
+		return "srt"
+	}
+}
+
+// iso6392 将常见的两字母语言代码转换为容器元数据使用的三字母代码，其他值原样返回
+func iso6392(language string) string {
+	codes := map[string]string{
+		"zh": "chi", "en": "eng", "ja": "jpn", "ko": "kor", "fr": "fre", "de": "ger",
+		"es": "spa", "pt": "por", "it": "ita", "ru": "rus", "ar": "ara", "hi": "hin",
+	}
+	if code, ok := codes[strings.ToLower(language)]; ok {
+		return code
+	}
+	return language
+}
+
+// escapeFilterPath 转义 subtitles 滤镜中的文件路径（冒号和引号在滤镜参数中有特殊含义）
+func escapeFilterPath(path string) string {
+	path = filepath.ToSlash(path)
+	path = strings.NewReplacer(`'`, `\'`, ":", `\:`).Replace(path)
+	return "'" + path + "'"
+}
+
+// uploadFiles 将文件上传到对象存储目录（按文件名平铺），返回远程地址
+func uploadFiles(ctx context.Context, files []string, destination string) ([]string, error) {
+	base, err := parseRemoteURI(destination)
+	if err != nil {
+		return nil, err
+	}
+	uploaded := make([]string, 0, len(files))
+	for _, file := range files {
+		dst := base.join(filepath.Base(file))
+		logDebug(tr("正在上传: %s\n"), dst)
+		if err := remotePutFile(ctx, file, dst); err != nil {
+			return uploaded, fmt.Errorf(tr("上传 %s 失败: %w"), dst, err)
+		}
+		uploaded = append(uploaded, dst.String())
+	}
+	return uploaded, nil
+}
+
+// notifyPublish 按项目配置推送 Webhook 和桌面通知，失败只记录警告
+func notifyPublish(project *publishProject, result *TranscriptionResult, outputFiles []string, publishErr error, elapsed time.Duration) {
+	if project.Notify == nil {
+		return
+	}
+	if project.Notify.WebhookURL != "" {
+		payload := newWebhookPayload(project.Input, result, outputFiles, publishErr, elapsed)
+		payload.Event = "publish.completed"
+		if publishErr != nil {
+			payload.Event = "publish.failed"
+		}
+		if err := sendWebhook(project.Notify.WebhookURL, payload); err != nil {
+			logWarn(tr("推送 Webhook 失败: %v"), err)
+		}
+	}
+	if project.Notify.Desktop {
+		if publishErr != nil {
+			sendNotification(tr("发布失败"), fmt.Sprintf("%s: %v", filepath.Base(project.Input), publishErr))
+		} else {
+			sendNotification(tr("发布完成"), completionMessage(project.Input, result))
+		}
+	}
+}
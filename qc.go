@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// 字幕质检的默认限制，中日韩文字为主的字幕使用更严格的阅读速度和行长
+const (
+	defaultQCMaxCPS           = 20
+	defaultQCMaxCPSCJK        = 9
+	defaultQCMaxLineLength    = 42
+	defaultQCMaxLineLengthCJK = 16
+	defaultQCMaxLines         = 2
+	// qcMinRepeats 同一个词或短语连续出现的次数达到此值时视为可疑重复
+	qcMinRepeats = 3
+)
+
+// 字幕质检规则
+const (
+	qcRuleReadingSpeed = "reading_speed"
+	qcRuleOverlap      = "overlap"
+	qcRuleZeroDuration = "zero_duration"
+	qcRuleLineLength   = "line_length"
+	qcRuleLineCount    = "line_count"
+	qcRuleRepetition   = "repetition"
+)
+
+// qcRuleOrder 报告中各规则的顺序
+var qcRuleOrder = []string{qcRuleReadingSpeed, qcRuleOverlap, qcRuleZeroDuration, qcRuleLineLength, qcRuleLineCount, qcRuleRepetition}
+
+// qcIssue 一条字幕质检问题
+type qcIssue struct {
+	Cue    int
+	Start  float64
+	End    float64
+	Rule   string
+	Detail string
+	Text   string
+}
+
+// qcLimits 本次质检使用的限制
+type qcLimits struct {
+	MaxCPS        float64
+	MaxLineLength int
+	MaxLines      int
+}
+
+// validateQC 校验 qc_max_cps、qc_max_line_length 和 qc_max_lines
+func (c *Config) validateQC() error {
+	if c.QCMaxCPS < 0 || c.QCMaxLineLength < 0 || c.QCMaxLines < 0 {
+		return errors.New(tr("qc_max_cps、qc_max_line_length 和 qc_max_lines 不能为负数"))
+	}
+	return nil
+}
+
+// qcLimitsFor 按配置确定质检限制，未配置的项按字幕是否以中日韩文字为主选择默认值
+func qcLimitsFor(result *TranscriptionResult, config *Config) qcLimits {
+	var cjk, other int
+	for _, seg := range result.Segments {
+		for _, r := range seg.Text {
+			switch {
+			case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+				cjk++
+			case unicode.IsLetter(r):
+				other++
+			}
+		}
+	}
+	limits := qcLimits{MaxCPS: defaultQCMaxCPS, MaxLineLength: defaultQCMaxLineLength, MaxLines: defaultQCMaxLines}
+	// 英文按字母计数，一个汉字约相当于一个单词，汉字数超过字母数的 1/4 即视为中日韩字幕
+	if cjk*4 > other {
+		limits.MaxCPS, limits.MaxLineLength = defaultQCMaxCPSCJK, defaultQCMaxLineLengthCJK
+	}
+	if config.QCMaxCPS > 0 {
+		limits.MaxCPS = config.QCMaxCPS
+	}
+	if config.QCMaxLineLength > 0 {
+		limits.MaxLineLength = config.QCMaxLineLength
+	}
+	if config.QCMaxLines > 0 {
+		limits.MaxLines = config.QCMaxLines
+	}
+	return limits
+}
+
+// checkSubtitles 检查每条字幕的阅读速度、时间重叠、零时长、行长、行数和可疑重复
+func checkSubtitles(result *TranscriptionResult, limits qcLimits) []qcIssue {
+	var issues []qcIssue
+	for i, seg := range result.Segments {
+		text := strings.TrimSpace(seg.Text)
+		add := func(rule, detail string) {
+			issues = append(issues, qcIssue{Cue: seg.ID, Start: seg.Start, End: seg.End, Rule: rule, Detail: detail, Text: text})
+		}
+		duration := seg.End - seg.Start
+
+		if duration <= 0 {
+			add(qcRuleZeroDuration, fmt.Sprintf(tr("时长 %.3f 秒"), duration))
+		} else if chars := len([]rune(strings.ReplaceAll(text, "\n", ""))); float64(chars)/duration > limits.MaxCPS {
+			add(qcRuleReadingSpeed, fmt.Sprintf(tr("%.1f 字符/秒（上限 %g）"), float64(chars)/duration, limits.MaxCPS))
+		}
+		// 允许 1 毫秒的误差
+		if i > 0 && seg.Start < result.Segments[i-1].End-0.001 {
+			add(qcRuleOverlap, fmt.Sprintf(tr("与第 %d 条重叠 %.3f 秒"), result.Segments[i-1].ID, result.Segments[i-1].End-seg.Start))
+		}
+
+		lines := strings.Split(text, "\n")
+		if len(lines) > limits.MaxLines {
+			add(qcRuleLineCount, fmt.Sprintf(tr("%d 行（上限 %d）"), len(lines), limits.MaxLines))
+		}
+		for n, line := range lines {
+			if length := len([]rune(strings.TrimSpace(line))); length > limits.MaxLineLength {
+				add(qcRuleLineLength, fmt.Sprintf(tr("第 %d 行 %d 字符（上限 %d）"), n+1, length, limits.MaxLineLength))
+			}
+		}
+
+		if phrase, count := repeatedPhrase(text); count > 0 {
+			add(qcRuleRepetition, fmt.Sprintf(tr("「%s」连续重复 %d 次"), phrase, count))
+		} else if i > 0 && text != "" && normalizePhrase(text) == normalizePhrase(result.Segments[i-1].Text) {
+			add(qcRuleRepetition, fmt.Sprintf(tr("与第 %d 条内容相同"), result.Segments[i-1].ID))
+		}
+	}
+	return issues
+}
+
+// repeatedPhrase 查找连续重复至少 qcMinRepeats 次的词或短语（最长 4 个词），如 "the the the"，没有时 count 为 0
+func repeatedPhrase(text string) (string, int) {
+	words, _ := evalTokens(text, evalOptions{})
+	for size := 1; size <= 4; size++ {
+		for start := 0; start+size*qcMinRepeats <= len(words); start++ {
+			count := 1
+			for next := start + size; next+size <= len(words) && equalWords(words[start:start+size], words[next:next+size]); next += size {
+				count++
+			}
+			if count >= qcMinRepeats {
+				phrase := words[start]
+				for _, w := range words[start+1 : start+size] {
+					phrase = joinSegmentText(phrase, w)
+				}
+				return phrase, count
+			}
+		}
+	}
+	return "", 0
+}
+
+// equalWords 两组词是否相同
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// saveQCReport 保存字幕质检报告：各规则的问题数，以及每个问题的字幕序号（与 SRT 一致）、时间和内容
+func saveQCReport(result *TranscriptionResult, config *Config, outputPath string) error {
+	limits := qcLimitsFor(result, config)
+	issues := checkSubtitles(result, limits)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, tr("字幕质检：%d 条字幕，%d 个问题\n"), len(result.Segments), len(issues))
+	fmt.Fprintf(&b, tr("限制：阅读速度 %g 字符/秒，每行 %d 字符，最多 %d 行\n\n"), limits.MaxCPS, limits.MaxLineLength, limits.MaxLines)
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		counts[issue.Rule]++
+	}
+	for _, rule := range qcRuleOrder {
+		fmt.Fprintf(&b, "  %-14s %d\n", rule, counts[rule])
+	}
+	if len(issues) > 0 {
+		b.WriteString("\n")
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "#%d %s --> %s [%s] %s\n    %s\n", issue.Cue, formatSRTTime(issue.Start), formatSRTTime(issue.End),
+			issue.Rule, issue.Detail, strings.ReplaceAll(issue.Text, "\n", " / "))
+	}
+	if len(issues) > 0 {
+		logWarn(tr("字幕质检发现 %d 个问题，详见 %s"), len(issues), outputPath)
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
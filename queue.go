@@ -0,0 +1,212 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/whisper-client/go-whisper-go/proto/whisperpb"
+)
+
+// errJobNotFound 任务不存在
+var errJobNotFound error = trError("任务不存在")
+
+// queuedJob 任务记录及执行所需的输入
+type queuedJob struct {
+	job       *whisperpb.Job
+	input     string
+	uploadDir string // 上传内容所在目录，任务结束后删除
+	options   *whisperpb.TranscribeOptions
+	created   time.Time
+	finished  time.Time // 任务结束（成功、失败或取消）的时间，内存存储按它淘汰旧任务
+	seq       int64     // 提交顺序，相同优先级时先提交的先执行
+}
+
+// queueStats 队列中各状态的任务数
+type queueStats struct {
+	counts map[whisperpb.JobState]int
+	// oldestQueued 最早提交的排队任务已等待的时长，没有排队任务时为 0
+	oldestQueued time.Duration
+}
+
+// jobStore 任务存储：gRPC 服务的任务队列和任务结果，内存实现重启后丢失，SQLite 实现持久化
+type jobStore interface {
+	// add 保存新提交的排队任务
+	add(j *queuedJob) error
+	// next 取出优先级最高、提交最早的排队任务并标记为运行中，没有排队任务时返回 nil
+	next() (*queuedJob, error)
+	// get 查询任务，排队中的任务填入排队位置
+	get(id string) (*whisperpb.Job, error)
+	// update 保存任务的状态和结果
+	update(job *whisperpb.Job) error
+	// cancelQueued 将排队中的任务标记为已取消并返回；任务不在排队中时返回 nil
+	cancelQueued(id string) (*queuedJob, error)
+	// stats 统计各状态的任务数
+	stats() (queueStats, error)
+	// close 关闭存储
+	close() error
+}
+
+// betterQueued a 是否应先于 b 执行：优先级高的先执行，相同时先提交的先执行
+func betterQueued(a, b *queuedJob) bool {
+	if a.job.Priority != b.job.Priority {
+		return a.job.Priority > b.job.Priority
+	}
+	return a.seq < b.seq
+}
+
+// 内存存储中已结束任务的保留期限和数量上限，超出后在提交新任务时删除最早结束的任务，避免长期运行的服务内存持续增长
+const (
+	memoryJobTTL   = 24 * time.Hour
+	memoryJobLimit = 1000
+)
+
+// memoryJobStore 内存中的任务存储（未指定 -queue-db 时使用）
+type memoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*queuedJob
+	seq  int64
+}
+
+// newMemoryJobStore 创建内存任务存储
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: make(map[string]*queuedJob)}
+}
+
+// add 保存新任务并分配提交顺序，同时淘汰过期的已结束任务
+func (s *memoryJobStore) add(j *queuedJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(time.Now())
+	s.seq++
+	j.seq = s.seq
+	s.jobs[j.job.Id] = j
+	return nil
+}
+
+// prune 删除结束超过 memoryJobTTL 的任务，剩余的已结束任务超过 memoryJobLimit 个时再删除最早结束的，调用方持有锁
+func (s *memoryJobStore) prune(now time.Time) {
+	var finished []*queuedJob
+	for id, j := range s.jobs {
+		if j.finished.IsZero() {
+			continue
+		}
+		if now.Sub(j.finished) > memoryJobTTL {
+			delete(s.jobs, id)
+			continue
+		}
+		finished = append(finished, j)
+	}
+	if len(finished) <= memoryJobLimit {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool { return finished[a].finished.Before(finished[b].finished) })
+	for _, j := range finished[:len(finished)-memoryJobLimit] {
+		delete(s.jobs, j.job.Id)
+	}
+}
+
+// next 遍历排队任务取出最先执行的一个并标记为运行中
+func (s *memoryJobStore) next() (*queuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var best *queuedJob
+	for _, j := range s.jobs {
+		if j.job.State == whisperpb.JobState_JOB_STATE_QUEUED && (best == nil || betterQueued(j, best)) {
+			best = j
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	best.job.State = whisperpb.JobState_JOB_STATE_RUNNING
+	return &queuedJob{
+		job:       proto.Clone(best.job).(*whisperpb.Job),
+		input:     best.input,
+		uploadDir: best.uploadDir,
+		options:   best.options,
+		created:   best.created,
+		seq:       best.seq,
+	}, nil
+}
+
+// get 返回任务副本，排队中的任务计算排在它前面的任务数
+func (s *memoryJobStore) get(id string) (*whisperpb.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, errJobNotFound
+	}
+	job := proto.Clone(j.job).(*whisperpb.Job)
+	if job.State == whisperpb.JobState_JOB_STATE_QUEUED {
+		for _, other := range s.jobs {
+			if other.job.State == whisperpb.JobState_JOB_STATE_QUEUED && betterQueued(other, j) {
+				job.QueuePosition++
+			}
+		}
+	}
+	return job, nil
+}
+
+// update 替换任务记录
+func (s *memoryJobStore) update(job *whisperpb.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[job.Id]
+	if !ok {
+		return errJobNotFound
+	}
+	j.job = proto.Clone(job).(*whisperpb.Job)
+	if j.finished.IsZero() && jobFinished(job.State) {
+		j.finished = time.Now()
+	}
+	return nil
+}
+
+// jobFinished 任务是否已结束（成功、失败或取消）
+func jobFinished(state whisperpb.JobState) bool {
+	switch state {
+	case whisperpb.JobState_JOB_STATE_SUCCEEDED, whisperpb.JobState_JOB_STATE_FAILED, whisperpb.JobState_JOB_STATE_CANCELLED:
+		return true
+	}
+	return false
+}
+
+// cancelQueued 排队中的任务直接标记为已取消
+func (s *memoryJobStore) cancelQueued(id string) (*queuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, errJobNotFound
+	}
+	if j.job.State != whisperpb.JobState_JOB_STATE_QUEUED {
+		return nil, nil
+	}
+	j.job.State = whisperpb.JobState_JOB_STATE_CANCELLED
+	j.finished = time.Now()
+	return j, nil
+}
+
+// stats 统计各状态的任务数和最早排队任务的等待时长
+func (s *memoryJobStore) stats() (queueStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := queueStats{counts: make(map[whisperpb.JobState]int)}
+	now := time.Now()
+	for _, j := range s.jobs {
+		stats.counts[j.job.State]++
+		if j.job.State == whisperpb.JobState_JOB_STATE_QUEUED {
+			stats.oldestQueued = max(stats.oldestQueued, now.Sub(j.created))
+		}
+	}
+	return stats, nil
+}
+
+// close 内存存储无需关闭
+func (s *memoryJobStore) close() error {
+	return nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/whisper-client/go-whisper-go/proto/whisperpb"
+)
+
+// sqliteJobSchema 任务表：state 和 priority 单独成列用于排队查询，任务记录和选项以 protobuf 编码保存
+const sqliteJobSchema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	id         TEXT    NOT NULL UNIQUE,
+	state      INTEGER NOT NULL,
+	priority   INTEGER NOT NULL,
+	input      TEXT    NOT NULL,
+	upload_dir TEXT    NOT NULL,
+	options    BLOB,
+	job        BLOB    NOT NULL,
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS jobs_queue ON jobs (state, priority DESC, seq);
+`
+
+// sqliteJobStore 以 SQLite 持久化的任务存储，服务重启后排队中的任务继续执行
+type sqliteJobStore struct {
+	db *sql.DB
+}
+
+// openSQLiteJobStore 打开（不存在时创建）任务数据库。上次退出时仍在运行的任务重新排队，返回重新排队的任务数
+func openSQLiteJobStore(path string) (*sqliteJobStore, int, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, 0, fmt.Errorf(tr("打开任务数据库失败: %w"), err)
+	}
+	// 单连接串行访问，取任务时不会与其他写入冲突
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteJobSchema); err != nil {
+		db.Close()
+		return nil, 0, fmt.Errorf(tr("初始化任务数据库失败: %w"), err)
+	}
+
+	res, err := db.Exec(`UPDATE jobs SET state = ? WHERE state = ?`,
+		whisperpb.JobState_JOB_STATE_QUEUED, whisperpb.JobState_JOB_STATE_RUNNING)
+	if err != nil {
+		db.Close()
+		return nil, 0, fmt.Errorf(tr("恢复中断的任务失败: %w"), err)
+	}
+	requeued, _ := res.RowsAffected()
+	return &sqliteJobStore{db: db}, int(requeued), nil
+}
+
+// add 插入新任务，提交顺序由自增主键决定
+func (s *sqliteJobStore) add(j *queuedJob) error {
+	job, err := proto.Marshal(j.job)
+	if err != nil {
+		return err
+	}
+	var options []byte
+	if j.options != nil {
+		if options, err = proto.Marshal(j.options); err != nil {
+			return err
+		}
+	}
+	res, err := s.db.Exec(`INSERT INTO jobs (id, state, priority, input, upload_dir, options, job, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		j.job.Id, j.job.State, j.job.Priority, j.input, j.uploadDir, options, job, j.created.UnixNano())
+	if err != nil {
+		return fmt.Errorf(tr("保存任务失败: %w"), err)
+	}
+	j.seq, _ = res.LastInsertId()
+	return nil
+}
+
+// next 在事务中取出优先级最高、提交最早的排队任务并标记为运行中
+func (s *sqliteJobStore) next() (*queuedJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow(`SELECT seq, input, upload_dir, options, job, created_at FROM jobs WHERE state = ? ORDER BY priority DESC, seq LIMIT 1`,
+		whisperpb.JobState_JOB_STATE_QUEUED)
+	j, err := scanQueuedJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	j.job.State = whisperpb.JobState_JOB_STATE_RUNNING
+	if _, err := tx.Exec(`UPDATE jobs SET state = ? WHERE seq = ?`, j.job.State, j.seq); err != nil {
+		return nil, err
+	}
+	return j, tx.Commit()
+}
+
+// scanQueuedJob 读取 next 查询的一行
+func scanQueuedJob(row *sql.Row) (*queuedJob, error) {
+	var j queuedJob
+	var options, job []byte
+	var created int64
+	if err := row.Scan(&j.seq, &j.input, &j.uploadDir, &options, &job, &created); err != nil {
+		return nil, err
+	}
+	j.job = &whisperpb.Job{}
+	if err := proto.Unmarshal(job, j.job); err != nil {
+		return nil, fmt.Errorf(tr("解析任务记录失败: %w"), err)
+	}
+	if len(options) > 0 {
+		j.options = &whisperpb.TranscribeOptions{}
+		if err := proto.Unmarshal(options, j.options); err != nil {
+			return nil, fmt.Errorf(tr("解析任务记录失败: %w"), err)
+		}
+	}
+	j.created = time.Unix(0, created)
+	return &j, nil
+}
+
+// get 读取任务记录，状态以 state 列为准；排队中的任务统计排在它前面的任务数
+func (s *sqliteJobStore) get(id string) (*whisperpb.Job, error) {
+	var seq int64
+	var state, priority int32
+	var data []byte
+	err := s.db.QueryRow(`SELECT seq, state, priority, job FROM jobs WHERE id = ?`, id).Scan(&seq, &state, &priority, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job := &whisperpb.Job{}
+	if err := proto.Unmarshal(data, job); err != nil {
+		return nil, fmt.Errorf(tr("解析任务记录失败: %w"), err)
+	}
+	job.State = whisperpb.JobState(state)
+	if job.State == whisperpb.JobState_JOB_STATE_QUEUED {
+		err := s.db.QueryRow(`SELECT COUNT(*) FROM jobs WHERE state = ? AND (priority > ? OR (priority = ? AND seq < ?))`,
+			whisperpb.JobState_JOB_STATE_QUEUED, priority, priority, seq).Scan(&job.QueuePosition)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+// update 保存任务的状态和结果
+func (s *sqliteJobStore) update(job *whisperpb.Job) error {
+	data, err := proto.Marshal(job)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE jobs SET state = ?, job = ? WHERE id = ?`, job.State, data, job.Id)
+	if err != nil {
+		return fmt.Errorf(tr("保存任务失败: %w"), err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errJobNotFound
+	}
+	return nil
+}
+
+// cancelQueued 在事务中检查任务仍在排队并标记为已取消
+func (s *sqliteJobStore) cancelQueued(id string) (*queuedJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var state int32
+	var uploadDir string
+	var data []byte
+	err = tx.QueryRow(`SELECT state, upload_dir, job FROM jobs WHERE id = ?`, id).Scan(&state, &uploadDir, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if whisperpb.JobState(state) != whisperpb.JobState_JOB_STATE_QUEUED {
+		return nil, nil
+	}
+
+	job := &whisperpb.Job{}
+	if err := proto.Unmarshal(data, job); err != nil {
+		return nil, fmt.Errorf(tr("解析任务记录失败: %w"), err)
+	}
+	job.State = whisperpb.JobState_JOB_STATE_CANCELLED
+	if data, err = proto.Marshal(job); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE jobs SET state = ?, job = ? WHERE id = ?`, job.State, data, id); err != nil {
+		return nil, err
+	}
+	return &queuedJob{job: job, uploadDir: uploadDir}, tx.Commit()
+}
+
+// stats 按状态分组统计任务数，并计算最早排队任务的等待时长
+func (s *sqliteJobStore) stats() (queueStats, error) {
+	stats := queueStats{counts: make(map[whisperpb.JobState]int)}
+	rows, err := s.db.Query(`SELECT state, COUNT(*) FROM jobs GROUP BY state`)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var state int32
+		var count int
+		if err := rows.Scan(&state, &count); err != nil {
+			return stats, err
+		}
+		stats.counts[whisperpb.JobState(state)] = count
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	var oldest sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MIN(created_at) FROM jobs WHERE state = ?`, whisperpb.JobState_JOB_STATE_QUEUED).Scan(&oldest); err != nil {
+		return stats, err
+	}
+	if oldest.Valid {
+		stats.oldestQueued = time.Since(time.Unix(0, oldest.Int64))
+	}
+	return stats, nil
+}
+
+// close 关闭数据库
+func (s *sqliteJobStore) close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findConfigPath 查找默认配置文件：当前目录、可执行文件所在目录、用户配置目录
+// 通过 Finder/资源管理器右键菜单调用时工作目录不确定，因此需要依次查找
+func findConfigPath() string {
+	candidates := []string{"./config.json"}
+	if exe, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), "config.json"))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "whisper-go", "config.json"))
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return candidates[0]
+}
+
+// runQuick 执行 quick 子命令：使用全部默认值转写单个文件，
+// 通过通知提示进度，结束后复制文本并打开输出目录。适合绑定到 macOS 快速操作或 Windows 右键菜单
+func runQuick(args []string) {
+	fs := flag.NewFlagSet("quick", flag.ExitOnError)
+	configPath := fs.String("config", "", tr("配置文件路径（默认自动查找）"))
+	fs.Parse(args)
+	installShutdownHandler()
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go quick <input-file>"))
+		os.Exit(1)
+	}
+	inputFile := fs.Arg(0)
+
+	// fail 通过通知报告错误，非技术用户通常看不到终端输出
+	fail := func(err error) {
+		sendNotification(tr("whisper-go 转写失败"), err.Error())
+		fatal(err.Error())
+	}
+
+	if _, err := os.Stat(inputFile); err != nil {
+		fail(fmt.Errorf(tr("输入文件不存在: %s"), inputFile))
+	}
+
+	path := *configPath
+	if path == "" {
+		path = findConfigPath()
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		fail(fmt.Errorf(tr("加载配置失败: %w"), err))
+	}
+	if config.APIKey == "" && config.needsAPIKey() {
+		fail(fmt.Errorf(tr("配置文件中未设置 API Key，请先在 %s 中配置 api_key"), path))
+	}
+
+	// 相对输出目录放在输入文件旁边，而不是不确定的工作目录下
+	if !filepath.IsAbs(config.OutputDir) {
+		config.OutputDir = filepath.Join(filepath.Dir(inputFile), config.OutputDir)
+	}
+
+	sendNotification("whisper-go", fmt.Sprintf(tr("正在转写: %s"), filepath.Base(inputFile)))
+
+	result, outputFiles, err := processFile(newClient(config), inputFile, config, parseFormats("txt,srt,json"), false)
+	if err != nil {
+		fail(err)
+	}
+
+	for _, file := range outputFiles {
+		fmt.Println(file)
+	}
+
+	if !result.NoSpeech {
+		if err := copyToClipboard(strings.TrimSpace(result.Text)); err != nil {
+			logWarn(tr("复制到剪贴板失败: %v"), err)
+		}
+	}
+
+	sendNotification(tr("whisper-go 转写完成"), completionMessage(inputFile, result))
+
+	// 按 organize 配置输出文件可能位于子目录中
+	folder := config.OutputDir
+	if len(outputFiles) > 0 {
+		folder = filepath.Dir(outputFiles[0])
+	}
+	if err := openFolder(folder); err != nil {
+		logWarn(tr("打开输出目录失败: %v"), err)
+	}
+}
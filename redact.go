@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// 脱敏类型
+const (
+	redactProfanity = "profanity"
+	redactEmail     = "email"
+	redactPhone     = "phone"
+	redactIDNumber  = "id_number"
+)
+
+// redactBatchSize 每次请求模型识别个人信息的分段数
+const redactBatchSize = 40
+
+// redactPatterns 内置的个人信息规则，按优先级排列（身份证号先于电话号码匹配）
+var redactPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{redactEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	// 居民身份证号、美国社会安全号
+	{redactIDNumber, regexp.MustCompile(`\b\d{17}[\dXx]\b|\b\d{3}-\d{2}-\d{4}\b`)},
+	// 中国大陆手机号、带区号的固定电话、北美格式号码
+	{redactPhone, regexp.MustCompile(`(?:\+?86[\s-]?)?\b1[3-9]\d[\s-]?\d{4}[\s-]?\d{4}\b|\b0\d{2,3}-\d{7,8}\b|(?:\+1[\s.-]?)?\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b`)},
+}
+
+// defaultProfanity 内置脏话词表，可通过 profanity_words 补充
+var defaultProfanity = []string{
+	"fuck", "fucking", "fucked", "fucker", "motherfucker", "shit", "shitty", "bullshit",
+	"bitch", "bastard", "asshole", "dick", "cunt", "wanker", "bollocks",
+	"他妈的", "妈的", "操你妈", "傻逼", "牛逼", "装逼", "卧槽", "草泥马", "王八蛋", "狗日的", "贱人", "婊子",
+}
+
+// Redaction 一处脱敏记录，只记录类型和位置，不包含原文
+type Redaction struct {
+	Segment int     `json:"segment"` // 分段序号，没有分段时为 -1
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Type    string  `json:"type"`
+	Offset  int     `json:"offset"` // 在分段文本中的字符位置
+	Length  int     `json:"length"` // 被遮盖的字符数
+}
+
+// redactSpan 文本中待遮盖的区间（字节位置）
+type redactSpan struct {
+	start, end int
+	kind       string
+}
+
+// redactor 按配置查找并遮盖敏感内容
+type redactor struct {
+	types     map[string]bool
+	profanity *regexp.Regexp
+	mask      string
+}
+
+// newRedactor 根据配置创建脱敏器
+func newRedactor(config *Config) *redactor {
+	r := &redactor{types: map[string]bool{}, mask: config.RedactMask}
+	for _, t := range config.RedactTypes {
+		r.types[t] = true
+	}
+	if r.types[redactProfanity] {
+		r.profanity = profanityPattern(append(append([]string{}, defaultProfanity...), config.ProfanityWords...))
+	}
+	return r
+}
+
+// profanityPattern 将词表编译为正则：拉丁文字按整词匹配（忽略大小写），中文按原文匹配并包含繁体写法
+func profanityPattern(words []string) *regexp.Regexp {
+	seen := map[string]bool{}
+	var latin, other []string
+	add := func(w string) {
+		if w == "" || seen[w] {
+			return
+		}
+		seen[w] = true
+		if isLatinWord(w) {
+			latin = append(latin, regexp.QuoteMeta(w))
+		} else {
+			other = append(other, regexp.QuoteMeta(w))
+		}
+	}
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		add(strings.ToLower(w))
+		if !isLatinWord(w) {
+			add(getChineseConverter(chineseTraditional).convert(w))
+		}
+	}
+
+	// 长词优先，避免只遮盖到较短的前缀
+	byLength := func(list []string) {
+		sort.Slice(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+	}
+	byLength(latin)
+	byLength(other)
+
+	var parts []string
+	if len(latin) > 0 {
+		parts = append(parts, `(?i:\b(?:`+strings.Join(latin, "|")+`)\b)`)
+	}
+	if len(other) > 0 {
+		parts = append(parts, `(?:`+strings.Join(other, "|")+`)`)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(strings.Join(parts, "|"))
+}
+
+// isLatinWord 是否只包含 ASCII 字符
+func isLatinWord(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// find 查找文本中的敏感内容
+func (r *redactor) find(text string) []redactSpan {
+	var spans []redactSpan
+	for _, p := range redactPatterns {
+		if !r.types[p.kind] {
+			continue
+		}
+		for _, m := range p.re.FindAllStringIndex(text, -1) {
+			spans = append(spans, redactSpan{m[0], m[1], p.kind})
+		}
+	}
+	if r.profanity != nil {
+		for _, m := range r.profanity.FindAllStringIndex(text, -1) {
+			spans = append(spans, redactSpan{m[0], m[1], redactProfanity})
+		}
+	}
+	return spans
+}
+
+// apply 遮盖区间（重叠时保留先开始、较长的区间），返回遮盖后的文本和实际遮盖的区间
+func (r *redactor) apply(text string, spans []redactSpan) (string, []redactSpan) {
+	if len(spans) == 0 {
+		return text, nil
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var b strings.Builder
+	var applied []redactSpan
+	pos := 0
+	for _, s := range spans {
+		if s.start < pos {
+			continue
+		}
+		b.WriteString(text[pos:s.start])
+		b.WriteString(r.maskFor(s.kind))
+		applied = append(applied, s)
+		pos = s.end
+	}
+	b.WriteString(text[pos:])
+	return b.String(), applied
+}
+
+// maskFor 替换 kind 类型敏感内容的遮盖文本
+func (r *redactor) maskFor(kind string) string {
+	return strings.ReplaceAll(r.mask, "{type}", kind)
+}
+
+// redactWords 把分段文本中实际遮盖的区间映射到词级时间戳：与区间重叠的词被遮盖，同一区间内的多个词合并为一个。
+// 词无法在文本中依次找到（文本已被术语修正、数字规范化等改写）时返回 false，调用方应丢弃该分段的词级时间戳
+func (r *redactor) redactWords(words []Word, text string, applied []redactSpan) ([]Word, bool) {
+	out := make([]Word, 0, len(words))
+	pos := 0
+	last := -1 // 上一个词所在区间的下标
+	for _, w := range words {
+		token := strings.TrimSpace(w.Word)
+		if token == "" {
+			out = append(out, w)
+			continue
+		}
+		i := strings.Index(text[pos:], token)
+		if i < 0 {
+			return nil, false
+		}
+		start, end := pos+i, pos+i+len(token)
+		pos = end
+
+		hit := -1
+		for k, s := range applied {
+			if start < s.end && s.start < end {
+				hit = k
+				break
+			}
+		}
+		if hit < 0 {
+			out = append(out, w)
+			last = hit
+			continue
+		}
+		// 保留词中区间之外的部分（如末尾的标点）
+		s := applied[hit]
+		suffix := text[min(end, s.end):end]
+		if hit == last {
+			out[len(out)-1].Word += suffix
+			out[len(out)-1].End = w.End
+		} else {
+			lead := w.Word[:len(w.Word)-len(strings.TrimLeft(w.Word, " "))]
+			word := lead + text[start:max(start, s.start)] + r.maskFor(s.kind) + suffix
+			out = append(out, Word{Word: word, Start: w.Start, End: w.End})
+		}
+		last = hit
+	}
+	return out, true
+}
+
+// redactResult 遮盖转写结果中的脏话和个人信息，记录每处遮盖的类型和位置。
+// 配置了 redact_model 时还会让对话模型识别规则无法覆盖的个人信息（如姓名、地址），失败时只使用规则
+func redactResult(client *openai.Client, result *TranscriptionResult, config *Config) {
+	if !config.Redact {
+		return
+	}
+	r := newRedactor(config)
+
+	var modelSpans map[int][]redactSpan
+	if config.RedactModel != "" && len(result.Segments) > 0 {
+		var err error
+		modelSpans, err = findPIIWithModel(client, result.Segments, config.RedactModel)
+		if err != nil {
+			logWarn(tr("模型识别个人信息失败，只使用内置规则: %v"), err)
+		}
+	}
+
+	for i := range result.Segments {
+		seg := &result.Segments[i]
+		spans := append(r.find(seg.Text), modelSpans[i]...)
+		text, applied := r.apply(seg.Text, spans)
+		for _, s := range applied {
+			result.Redactions = append(result.Redactions, Redaction{
+				Segment: seg.ID,
+				Start:   seg.Start,
+				End:     seg.End,
+				Type:    s.kind,
+				Offset:  len([]rune(seg.Text[:s.start])),
+				Length:  len([]rune(seg.Text[s.start:s.end])),
+			})
+		}
+		if len(applied) > 0 && len(seg.Words) > 0 {
+			words, ok := r.redactWords(seg.Words, seg.Text, applied)
+			if !ok {
+				// 无法确定哪些词被遮盖，宁可不输出该分段的词级时间戳
+				logDebug(tr("分段 %d 的词与文本不一致，已丢弃其词级时间戳"), seg.ID)
+			}
+			seg.Words = words
+		}
+		seg.Text = text
+		for j := range seg.Words {
+			seg.Words[j].Word, _ = r.apply(seg.Words[j].Word, r.find(seg.Words[j].Word))
+		}
+	}
+
+	if len(result.Segments) > 0 {
+		if len(result.Redactions) > 0 {
+			rebuildResultText(result)
+		}
+	} else {
+		text, applied := r.apply(result.Text, r.find(result.Text))
+		for _, s := range applied {
+			result.Redactions = append(result.Redactions, Redaction{
+				Segment: -1,
+				Type:    s.kind,
+				Offset:  len([]rune(result.Text[:s.start])),
+				Length:  len([]rune(result.Text[s.start:s.end])),
+			})
+		}
+		result.Text = text
+	}
+
+	// 被过滤的分段和章节标题也会写入输出
+	for i := range result.Filtered {
+		result.Filtered[i].Text, _ = r.apply(result.Filtered[i].Text, r.find(result.Filtered[i].Text))
+	}
+	for i := range result.Chapters {
+		result.Chapters[i].Title, _ = r.apply(result.Chapters[i].Title, r.find(result.Chapters[i].Title))
+	}
+
+	if len(result.Redactions) > 0 {
+		logDebug(tr("脱敏: %d 处"), len(result.Redactions))
+	}
+}
+
+// redactPrompt 让对话模型识别个人信息的提示
+const redactPrompt = `You find personal information in transcript segments: personal names, street addresses,
+phone numbers, email addresses, ID, passport, bank account and card numbers, license plates.
+Each line is "[index] text". Return every occurrence as the exact substring from the text.
+Reply with JSON only: {"items":[{"index":<index>,"text":"<exact substring>","type":"<name|address|phone|email|id_number|account|other>"}]}`
+
+// findPIIWithModel 由对话模型逐批识别个人信息，返回每个分段（按下标）中的区间
+func findPIIWithModel(client *openai.Client, segments []Segment, model string) (map[int][]redactSpan, error) {
+	spans := map[int][]redactSpan{}
+	for start := 0; start < len(segments); start += redactBatchSize {
+		end := min(start+redactBatchSize, len(segments))
+		var input strings.Builder
+		for i := start; i < end; i++ {
+			fmt.Fprintf(&input, "[%d] %s\n", i, strings.TrimSpace(segments[i].Text))
+		}
+
+		resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: redactPrompt},
+				{Role: openai.ChatMessageRoleUser, Content: input.String()},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Choices) == 0 {
+			return nil, errors.New(tr("模型没有返回结果"))
+		}
+
+		var reply struct {
+			Items []struct {
+				Index int    `json:"index"`
+				Text  string `json:"text"`
+				Type  string `json:"type"`
+			} `json:"items"`
+		}
+		if err := parseChatJSON(resp.Choices[0].Message.Content, &reply); err != nil {
+			return nil, fmt.Errorf(tr("解析模型返回的个人信息失败: %w"), err)
+		}
+
+		for _, item := range reply.Items {
+			if item.Index < start || item.Index >= end || strings.TrimSpace(item.Text) == "" {
+				continue
+			}
+			kind := item.Type
+			if kind == "" {
+				kind = "other"
+			}
+			text := segments[item.Index].Text
+			for offset := 0; ; {
+				i := strings.Index(text[offset:], item.Text)
+				if i < 0 {
+					break
+				}
+				spans[item.Index] = append(spans[item.Index], redactSpan{offset + i, offset + i + len(item.Text), kind})
+				offset += i + len(item.Text)
+			}
+		}
+	}
+	return spans, nil
+}
+
+// saveRedactionReport 保存脱敏报告：总数、各类型数量和每处遮盖的位置
+func saveRedactionReport(result *TranscriptionResult, outputPath string) error {
+	report := struct {
+		Total      int            `json:"total"`
+		ByType     map[string]int `json:"by_type"`
+		Redactions []Redaction    `json:"redactions"`
+	}{
+		Total:      len(result.Redactions),
+		ByType:     redactionCounts(result),
+		Redactions: result.Redactions,
+	}
+	if report.Redactions == nil {
+		report.Redactions = []Redaction{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// redactionCounts 按类型统计脱敏次数
+func redactionCounts(result *TranscriptionResult) map[string]int {
+	counts := map[string]int{}
+	for _, r := range result.Redactions {
+		counts[r.Type]++
+	}
+	return counts
+}
+
+// printRedactionReport 在摘要中输出脱敏统计
+func printRedactionReport(result *TranscriptionResult) {
+	if len(result.Redactions) == 0 {
+		return
+	}
+	counts := redactionCounts(result)
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%s %d", t, counts[t]))
+	}
+	fmt.Printf(tr("已脱敏: %d（%s）\n"), len(result.Redactions), strings.Join(parts, ", "))
+}
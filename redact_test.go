@@ -0,0 +1,119 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRedactorApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		types     []string
+		words     []string
+		in        string
+		want      string
+		wantKinds []string
+	}{
+		{"邮箱", []string{redactEmail}, nil, "mail me at bob@example.com now", "mail me at [email] now", []string{redactEmail}},
+		{"手机号", []string{redactPhone}, nil, "电话 138 1234 5678 谢谢", "电话 [phone] 谢谢", []string{redactPhone}},
+		{"身份证号优先于电话", []string{redactIDNumber, redactPhone}, nil, "号码 11010519491231002X 结束", "号码 [id_number] 结束", []string{redactIDNumber}},
+		{"未启用的类型不遮盖", []string{redactEmail}, nil, "call 555-123-4567", "call 555-123-4567", nil},
+		{"脏话整词匹配", []string{redactProfanity}, nil, "Shit, that's a shitty classic", "[profanity], that's a [profanity] classic", []string{redactProfanity, redactProfanity}},
+		{"中文脏话含繁体", []string{redactProfanity}, nil, "他媽的真是", "[profanity]真是", []string{redactProfanity}},
+		{"自定义词表", []string{redactProfanity}, []string{"darn"}, "darn it", "[profanity] it", []string{redactProfanity}},
+		{"多种类型", []string{redactEmail, redactPhone}, nil, "a@b.co 或 010-12345678", "[email] 或 [phone]", []string{redactEmail, redactPhone}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRedactor(&Config{RedactTypes: tt.types, ProfanityWords: tt.words, RedactMask: "[{type}]"})
+			got, applied := r.apply(tt.in, r.find(tt.in))
+			if got != tt.want {
+				t.Errorf("apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			var kinds []string
+			for _, s := range applied {
+				kinds = append(kinds, s.kind)
+			}
+			if !slices.Equal(kinds, tt.wantKinds) {
+				t.Errorf("applied kinds = %q, want %q", kinds, tt.wantKinds)
+			}
+		})
+	}
+}
+
+func TestRedactorApplyOverlap(t *testing.T) {
+	r := &redactor{mask: "*"}
+	// 重叠时保留先开始、较长的区间
+	spans := []redactSpan{{4, 6, "b"}, {0, 5, "a"}, {0, 2, "c"}, {8, 10, "d"}}
+	got, applied := r.apply("0123456789", spans)
+	if got != "*567*" {
+		t.Errorf("apply() = %q, want %q", got, "*567*")
+	}
+	if len(applied) != 2 || applied[0].kind != "a" || applied[1].kind != "d" {
+		t.Errorf("applied = %+v, want spans a and d", applied)
+	}
+}
+
+func TestRedactWords(t *testing.T) {
+	r := &redactor{mask: "[{type}]"}
+	tests := []struct {
+		name    string
+		words   []Word
+		text    string
+		applied []redactSpan
+		want    []Word
+		wantOK  bool
+	}{
+		{
+			name:    "没有遮盖",
+			words:   []Word{{Word: " hello", Start: 0, End: 1}, {Word: " world", Start: 1, End: 2}},
+			text:    "hello world",
+			applied: nil,
+			want:    []Word{{Word: " hello", Start: 0, End: 1}, {Word: " world", Start: 1, End: 2}},
+			wantOK:  true,
+		},
+		{
+			name:    "保留词末尾的标点",
+			words:   []Word{{Word: " oh", Start: 0, End: 1}, {Word: " shit!", Start: 1, End: 2}},
+			text:    "oh shit!",
+			applied: []redactSpan{{3, 7, redactProfanity}},
+			want:    []Word{{Word: " oh", Start: 0, End: 1}, {Word: " [profanity]!", Start: 1, End: 2}},
+			wantOK:  true,
+		},
+		{
+			name: "同一区间内的词合并",
+			words: []Word{
+				{Word: " call", Start: 0, End: 1}, {Word: " 555", Start: 1, End: 2}, {Word: " 123", Start: 2, End: 3},
+				{Word: " 4567.", Start: 3, End: 4},
+			},
+			text:    "call 555 123 4567.",
+			applied: []redactSpan{{5, 17, redactPhone}},
+			want:    []Word{{Word: " call", Start: 0, End: 1}, {Word: " [phone].", Start: 1, End: 4}},
+			wantOK:  true,
+		},
+		{
+			name:    "中文词",
+			words:   []Word{{Word: "电话", Start: 0, End: 1}, {Word: "13812345678", Start: 1, End: 3}},
+			text:    "电话13812345678",
+			applied: []redactSpan{{6, 17, redactPhone}},
+			want:    []Word{{Word: "电话", Start: 0, End: 1}, {Word: "[phone]", Start: 1, End: 3}},
+			wantOK:  true,
+		},
+		{
+			name:    "文本已被改写",
+			words:   []Word{{Word: " twenty", Start: 0, End: 1}},
+			text:    "20",
+			applied: nil,
+			want:    nil,
+			wantOK:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.redactWords(tt.words, tt.text, tt.applied)
+			if ok != tt.wantOK || !slices.Equal(got, tt.want) {
+				t.Errorf("redactWords() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/oauth2/google"
+)
+
+// 支持的对象存储 URI 前缀
+const (
+	remoteS3    = "s3"
+	remoteGCS   = "gs"
+	remoteAzure = "az"
+)
+
+// remoteURI 对象存储地址，如 s3://bucket/path/to/key
+type remoteURI struct {
+	Scheme string
+	Bucket string // S3/GCS 的存储桶，Azure 的容器
+	Key    string
+}
+
+func (u remoteURI) String() string {
+	return u.Scheme + "://" + path.Join(u.Bucket, u.Key)
+}
+
+// join 在当前地址下拼接相对路径
+func (u remoteURI) join(rel string) remoteURI {
+	u.Key = strings.TrimPrefix(path.Join(u.Key, filepath.ToSlash(rel)), "/")
+	return u
+}
+
+// isRemoteURI 判断路径是否为 s3://、gs:// 或 az:// 对象存储地址
+func isRemoteURI(s string) bool {
+	_, err := parseRemoteURI(s)
+	return err == nil
+}
+
+// parseRemoteURI 解析对象存储地址
+func parseRemoteURI(s string) (remoteURI, error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok {
+		return remoteURI{}, fmt.Errorf(tr("不是对象存储地址: %s"), s)
+	}
+	switch scheme {
+	case remoteS3, remoteGCS, remoteAzure:
+	default:
+		return remoteURI{}, fmt.Errorf(tr("不支持的对象存储类型: %s"), scheme)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return remoteURI{}, fmt.Errorf(tr("对象存储地址缺少存储桶: %s"), s)
+	}
+	return remoteURI{Scheme: scheme, Bucket: bucket, Key: strings.Trim(key, "/")}, nil
+}
+
+// downloadRemote 将对象存储中的输入文件下载到临时目录，保留原始文件名以便识别媒体类型和命名输出
+func downloadRemote(ctx context.Context, uri string, verbose bool) (localPath, tempDir string, err error) {
+	u, err := parseRemoteURI(uri)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Key == "" {
+		return "", "", fmt.Errorf(tr("对象存储地址缺少对象路径: %s"), uri)
+	}
+
+	tempDir, err = os.MkdirTemp(workingDir(), "whisper_remote_")
+	if err != nil {
+		return "", "", err
+	}
+	trackTemp(tempDir)
+	localPath = filepath.Join(tempDir, path.Base(u.Key))
+
+	logDebug(tr("正在下载: %s\n"), uri)
+
+	f, err := os.Create(localPath)
+	if err != nil {
+		removeTemp(tempDir)
+		return "", "", err
+	}
+	err = remoteGet(ctx, u, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		removeTemp(tempDir)
+		return "", "", fmt.Errorf(tr("下载 %s 失败: %w"), uri, err)
+	}
+	return localPath, tempDir, nil
+}
+
+// uploadOutputs 将本地输出目录中的文件上传到对象存储，保持相对路径不变，返回输出文件对应的远程地址
+func uploadOutputs(ctx context.Context, localDir, remoteDir string, outputFiles []string, verbose bool) ([]string, error) {
+	base, err := parseRemoteURI(remoteDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 遍历整个目录，一并上传 latest 链接等附带文件
+	err = filepath.Walk(localDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		dst := base.join(rel)
+		logDebug(tr("正在上传: %s\n"), dst)
+		if err := remotePutFile(ctx, p, dst); err != nil {
+			return fmt.Errorf(tr("上传 %s 失败: %w"), dst, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	remoteFiles := make([]string, 0, len(outputFiles))
+	for _, file := range outputFiles {
+		rel, err := filepath.Rel(localDir, file)
+		if err != nil {
+			return nil, err
+		}
+		remoteFiles = append(remoteFiles, base.join(rel).String())
+	}
+	return remoteFiles, nil
+}
+
+// remoteGet 下载对象内容
+func remoteGet(ctx context.Context, u remoteURI, w io.Writer) error {
+	switch u.Scheme {
+	case remoteS3:
+		client, err := newS3Client(ctx)
+		if err != nil {
+			return err
+		}
+		out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(u.Bucket), Key: aws.String(u.Key)})
+		if err != nil {
+			return err
+		}
+		defer out.Body.Close()
+		_, err = io.Copy(w, out.Body)
+		return err
+	case remoteGCS:
+		client, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/devstorage.read_write")
+		if err != nil {
+			return fmt.Errorf(tr("获取 Google Cloud 凭据失败: %w"), err)
+		}
+		endpoint := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s?alt=media",
+			url.PathEscape(u.Bucket), url.PathEscape(u.Key))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return gcsError(resp)
+		}
+		_, err = io.Copy(w, resp.Body)
+		return err
+	case remoteAzure:
+		client, err := newAzureClient()
+		if err != nil {
+			return err
+		}
+		resp, err := client.DownloadStream(ctx, u.Bucket, u.Key, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+	return fmt.Errorf(tr("不支持的对象存储类型: %s"), u.Scheme)
+}
+
+// remotePutFile 上传本地文件
+func remotePutFile(ctx context.Context, localPath string, u remoteURI) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case remoteS3:
+		client, err := newS3Client(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        aws.String(u.Bucket),
+			Key:           aws.String(u.Key),
+			Body:          f,
+			ContentLength: aws.Int64(info.Size()),
+		})
+		return err
+	case remoteGCS:
+		client, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/devstorage.read_write")
+		if err != nil {
+			return fmt.Errorf(tr("获取 Google Cloud 凭据失败: %w"), err)
+		}
+		endpoint := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+			url.PathEscape(u.Bucket), url.QueryEscape(u.Key))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, f)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = info.Size()
+		req.Header.Set("Content-Type", "application/octet-stream")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return gcsError(resp)
+		}
+		return nil
+	case remoteAzure:
+		client, err := newAzureClient()
+		if err != nil {
+			return err
+		}
+		_, err = client.UploadFile(ctx, u.Bucket, u.Key, f, nil)
+		return err
+	}
+	return fmt.Errorf(tr("不支持的对象存储类型: %s"), u.Scheme)
+}
+
+// newS3Client 按 AWS SDK 的标准方式（环境变量、~/.aws 配置、实例角色等）加载凭据
+func newS3Client(ctx context.Context) (*s3.Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(tr("加载 AWS 配置失败: %w"), err)
+	}
+	return s3.NewFromConfig(cfg), nil
+}
+
+// newAzureClient 优先使用 AZURE_STORAGE_CONNECTION_STRING，
+// 否则使用 AZURE_STORAGE_ACCOUNT 和 DefaultAzureCredential（环境变量、托管标识、Azure CLI 登录等）
+func newAzureClient() (*azblob.Client, error) {
+	if conn := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); conn != "" {
+		return azblob.NewClientFromConnectionString(conn, nil)
+	}
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	if account == "" {
+		return nil, errors.New(tr("未设置 AZURE_STORAGE_ACCOUNT 或 AZURE_STORAGE_CONNECTION_STRING"))
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf(tr("获取 Azure 凭据失败: %w"), err)
+	}
+	return azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", account), cred, nil)
+}
+
+// gcsError 读取 GCS JSON API 的错误信息
+func gcsError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf(tr("GCS 返回 %s: %s"), resp.Status, strings.TrimSpace(string(body)))
+}
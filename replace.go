@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Replacement 正则替换规则，用于修正固定的误识别和格式问题
+type Replacement struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`           // 支持 $1、${name} 引用分组
+	IgnoreCase  bool   `json:"ignore_case,omitempty"` // 忽略大小写，默认区分
+	re          *regexp.Regexp
+}
+
+// compileReplacements 编译配置中的替换规则，规则无效时返回错误
+func (c *Config) compileReplacements() error {
+	for i := range c.Replacements {
+		r := &c.Replacements[i]
+		pattern := r.Pattern
+		if r.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf(tr("无效的替换规则 %q: %w"), r.Pattern, err)
+		}
+		r.re = re
+	}
+	return nil
+}
+
+// applyReplacements 按顺序对每个分段应用替换规则并重建全文，返回替换次数
+func applyReplacements(result *TranscriptionResult, config *Config) int {
+	if len(config.Replacements) == 0 {
+		return 0
+	}
+
+	replace := func(text string) (string, int) {
+		count := 0
+		for _, r := range config.Replacements {
+			if r.re == nil || r.Pattern == "" {
+				continue
+			}
+			count += len(r.re.FindAllStringIndex(text, -1))
+			text = r.re.ReplaceAllString(text, r.Replacement)
+		}
+		return text, count
+	}
+
+	total := 0
+	for i := range result.Segments {
+		text, n := replace(result.Segments[i].Text)
+		result.Segments[i].Text = text
+		total += n
+	}
+	if len(result.Segments) > 0 {
+		if total > 0 {
+			rebuildResultText(result)
+		}
+	} else {
+		result.Text, total = replace(result.Text)
+	}
+	if total > 0 {
+		logDebug(tr("替换规则: %d 处"), total)
+	}
+	return total
+}
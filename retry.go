@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// retryBaseDelay 重试的初始等待时间，之后每次翻倍
+const retryBaseDelay = 2 * time.Second
+
+// retryMaxDelay 单次重试的最长等待时间
+const retryMaxDelay = 30 * time.Second
+
+// httpStatusCode 提取 API 错误中的 HTTP 状态码，非 HTTP 错误返回 0
+func httpStatusCode(err error) int {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+	var backendErr *backendHTTPError
+	if errors.As(err, &backendErr) {
+		return backendErr.StatusCode
+	}
+	return 0
+}
+
+// isRetryableError 判断错误是否值得重试：限流、服务端错误和网络中断
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if code := httpStatusCode(err); code != 0 {
+		return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// retryDelay 第 attempt 次重试前的等待时间（指数退避）
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// retryCounterKey 上下文中重试计数器的键
+type retryCounterKey struct{}
+
+// withRetryCounter 返回带重试计数器的上下文，用于统计一个文件处理过程中接口调用的重试次数（批量报告）
+func withRetryCounter(ctx context.Context) (context.Context, *atomic.Int64) {
+	counter := new(atomic.Int64)
+	return context.WithValue(ctx, retryCounterKey{}, counter), counter
+}
+
+// countRetry 上下文中有重试计数器时加一
+func countRetry(ctx context.Context) {
+	if counter, ok := ctx.Value(retryCounterKey{}).(*atomic.Int64); ok {
+		counter.Add(1)
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// reviewPageSize 每页显示的分段数
+const reviewPageSize = 15
+
+// outputTimestampSuffix 匹配输出文件名末尾的时间戳（见 generateOutputPath）
+var outputTimestampSuffix = regexp.MustCompile(`_\d{8}_\d{6}$`)
+
+// runReview 执行 review 子命令：交互式校对转写分段并重新导出。终端中使用全屏界面，
+// 标准输入输出不是终端（如脚本通过管道输入命令）或指定 --plain 时使用逐行命令界面
+func runReview(args []string) {
+	fs := flag.NewFlagSet("review", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	outputDir := fs.String("output", "", tr("输出目录（默认与 JSON 文件相同）"))
+	formats := fs.String("formats", "txt,srt,json", tr("导出格式（逗号分隔）"))
+	plain := fs.Bool("plain", false, tr("使用逐行命令界面（标准输入输出不是终端时自动使用）"))
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go review <result.json> [options]"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	jsonPath := fs.Arg(0)
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		fatalf(tr("读取转写结果失败: %v"), err)
+	}
+	var result TranscriptionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		fatalf(tr("解析转写结果失败: %v"), err)
+	}
+
+	// 配置文件仅用于导出参数（如 LRC 元数据），不存在时使用默认值
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		config = &Config{}
+	}
+	config.OutputDir = filepath.Dir(jsonPath)
+	config.Organize = organizeFlat
+	if *outputDir != "" {
+		config.OutputDir = *outputDir
+	}
+
+	// 导出文件沿用原始输入的文件名（去掉时间戳后缀）
+	name := strings.TrimSuffix(filepath.Base(jsonPath), filepath.Ext(jsonPath))
+	sourceName := outputTimestampSuffix.ReplaceAllString(name, "") + filepath.Ext(jsonPath)
+
+	r := &reviewSession{
+		result:     &result,
+		config:     config,
+		formatList: parseFormats(*formats),
+		sourceName: sourceName,
+		in:         bufio.NewReader(os.Stdin),
+		out:        os.Stdout,
+	}
+	if !*plain && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) {
+		if err := r.runTUI(); err != nil {
+			fatalf(tr("启动全屏界面失败: %v（可使用 --plain）"), err)
+		}
+		return
+	}
+	r.run()
+}
+
+// reviewSession 交互式校对会话状态
+type reviewSession struct {
+	result     *TranscriptionResult
+	config     *Config
+	formatList []string
+	sourceName string
+	page       int
+	dirty      bool
+	in         *bufio.Reader
+	out        io.Writer
+}
+
+// run 进入命令循环
+func (r *reviewSession) run() {
+	r.list()
+	r.help()
+	for {
+		fmt.Fprint(r.out, "\nreview> ")
+		line, err := r.in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(r.out)
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var cmdErr error
+		switch fields[0] {
+		case "l", "list":
+			if len(fields) > 1 {
+				if p, err := strconv.Atoi(fields[1]); err == nil {
+					r.page = p - 1
+				}
+			}
+			r.list()
+		case "n", "next":
+			r.page++
+			r.list()
+		case "p", "prev":
+			r.page--
+			r.list()
+		case "e", "edit", "m", "merge", "s", "split", "t", "nudge":
+			if cmdErr = r.modify(fields[0][:1], fields[1:]); cmdErr == nil {
+				r.list()
+			}
+		case "w", "write":
+			files, err := r.export()
+			if err != nil {
+				cmdErr = err
+				break
+			}
+			fmt.Fprintln(r.out, tr("已导出:"))
+			for _, file := range files {
+				fmt.Fprintf(r.out, "  - %s\n", file)
+			}
+		case "q", "quit":
+			if r.dirty {
+				fmt.Fprint(r.out, tr("有未导出的修改，确认退出？(y/N) "))
+				answer, _ := r.in.ReadString('\n')
+				if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+					continue
+				}
+			}
+			return
+		case "h", "help", "?":
+			r.help()
+		default:
+			cmdErr = fmt.Errorf(tr("未知命令: %s（输入 h 查看帮助）"), fields[0])
+		}
+
+		if cmdErr != nil {
+			fmt.Fprintf(r.out, tr("错误: %v\n"), cmdErr)
+		}
+	}
+}
+
+// help 打印命令说明
+func (r *reviewSession) help() {
+	fmt.Fprintln(r.out, tr(`
+命令:
+  l [页码]                 列出分段（n 下一页, p 上一页）
+  e <序号>                 编辑分段文本
+  m <序号>                 将分段与下一分段合并
+  s <序号> <字符位置>       在指定字符位置拆分分段，时间按字数比例分配
+  t <序号> <start|end|both> <±秒>  微调时间，如: t 3 start -0.25
+  w                        按导出格式重新导出所有文件
+  q                        退出`))
+}
+
+// list 显示当前页的分段
+func (r *reviewSession) list() {
+	segments := r.result.Segments
+	pages := (len(segments) + reviewPageSize - 1) / reviewPageSize
+	if pages == 0 {
+		fmt.Fprintln(r.out, tr("没有分段信息"))
+		return
+	}
+	if r.page < 0 {
+		r.page = 0
+	}
+	if r.page >= pages {
+		r.page = pages - 1
+	}
+
+	start := r.page * reviewPageSize
+	end := start + reviewPageSize
+	if end > len(segments) {
+		end = len(segments)
+	}
+
+	fmt.Fprintf(r.out, tr("\n--- 第 %d/%d 页（共 %d 个分段）---\n"), r.page+1, pages, len(segments))
+	for _, seg := range segments[start:end] {
+		fmt.Fprintf(r.out, "%4d  %s --> %s  %s\n", seg.ID, formatSRTTime(seg.Start), formatSRTTime(seg.End), strings.TrimSpace(seg.Text))
+	}
+}
+
+// segmentIndex 解析分段序号并返回切片下标
+func (r *reviewSession) segmentIndex(args []string) (int, error) {
+	if len(args) < 1 {
+		return 0, errors.New(tr("缺少分段序号"))
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id < 1 || id > len(r.result.Segments) {
+		return 0, fmt.Errorf(tr("无效的分段序号: %s"), args[0])
+	}
+	return id - 1, nil
+}
+
+// modify 执行逐行界面中修改分段的命令：e 编辑、m 合并、s 拆分、t 微调时间
+func (r *reviewSession) modify(cmd string, args []string) error {
+	i, err := r.segmentIndex(args)
+	if err != nil {
+		return err
+	}
+	switch cmd {
+	case "e":
+		fmt.Fprintf(r.out, tr("当前: %s\n新文本（留空保持不变）: "), strings.TrimSpace(r.result.Segments[i].Text))
+		line, _ := r.in.ReadString('\n')
+		r.setText(i, line)
+		return nil
+	case "m":
+		return r.mergeNext(i)
+	case "s":
+		if len(args) < 2 {
+			return errors.New(tr("缺少拆分位置"))
+		}
+		pos, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf(tr("拆分位置应在 1 到 %d 之间"), len([]rune(strings.TrimSpace(r.result.Segments[i].Text)))-1)
+		}
+		return r.splitAt(i, pos)
+	default:
+		if len(args) < 3 {
+			return errors.New(tr("用法: t <序号> <start|end|both> <±秒>"))
+		}
+		delta, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return fmt.Errorf(tr("无效的时间偏移: %s"), args[2])
+		}
+		return r.shift(i, args[1], delta)
+	}
+}
+
+// setText 修改第 i 个分段的文本，空文本保持不变
+func (r *reviewSession) setText(i int, text string) {
+	text = strings.TrimSpace(text)
+	seg := &r.result.Segments[i]
+	if text == "" || text == strings.TrimSpace(seg.Text) {
+		return
+	}
+	seg.Text = text
+	// 文本被手动修改后词级时间戳不再可靠
+	seg.Words = nil
+	r.changed()
+}
+
+// mergeNext 将第 i 个分段与下一分段合并
+func (r *reviewSession) mergeNext(i int) error {
+	segments := r.result.Segments
+	if i+1 >= len(segments) {
+		return fmt.Errorf(tr("分段 %d 已是最后一个分段"), i+1)
+	}
+
+	cur, next := segments[i], segments[i+1]
+	cur.End = next.End
+	cur.Text = joinSegmentText(cur.Text, next.Text)
+	cur.Words = append(cur.Words, next.Words...)
+
+	segments[i] = cur
+	r.result.Segments = append(segments[:i+1], segments[i+2:]...)
+	r.changed()
+	return nil
+}
+
+// splitAt 在第 i 个分段文本（去掉首尾空白）的第 pos 个字符处拆分分段
+func (r *reviewSession) splitAt(i, pos int) error {
+	seg := r.result.Segments[i]
+	runes := []rune(strings.TrimSpace(seg.Text))
+	if pos <= 0 || pos >= len(runes) {
+		return fmt.Errorf(tr("拆分位置应在 1 到 %d 之间"), len(runes)-1)
+	}
+
+	// 按字数比例分配时间
+	splitTime := seg.Start + (seg.End-seg.Start)*float64(pos)/float64(len(runes))
+
+	first := Segment{Start: seg.Start, End: splitTime, Text: strings.TrimSpace(string(runes[:pos]))}
+	second := Segment{Start: splitTime, End: seg.End, Text: strings.TrimSpace(string(runes[pos:]))}
+	for _, w := range seg.Words {
+		if w.Start < splitTime {
+			first.Words = append(first.Words, w)
+		} else {
+			second.Words = append(second.Words, w)
+		}
+	}
+
+	segments := append([]Segment{}, r.result.Segments[:i]...)
+	segments = append(segments, first, second)
+	segments = append(segments, r.result.Segments[i+1:]...)
+	r.result.Segments = segments
+	r.changed()
+	return nil
+}
+
+// shift 微调第 i 个分段的起止时间，target 为 start、end 或 both
+func (r *reviewSession) shift(i int, target string, delta float64) error {
+	seg := r.result.Segments[i]
+	switch target {
+	case "start":
+		seg.Start += delta
+	case "end":
+		seg.End += delta
+	case "both":
+		seg.Start += delta
+		seg.End += delta
+	default:
+		return fmt.Errorf(tr("无效的调整目标: %s"), target)
+	}
+	if seg.Start < 0 {
+		seg.Start = 0
+	}
+	if seg.End <= seg.Start {
+		return errors.New(tr("调整后结束时间早于开始时间"))
+	}
+
+	r.result.Segments[i] = seg
+	r.changed()
+	return nil
+}
+
+// changed 标记修改并重建序号、全文和时长
+func (r *reviewSession) changed() {
+	r.dirty = true
+	var text strings.Builder
+	for i := range r.result.Segments {
+		r.result.Segments[i].ID = i + 1
+		text.WriteString(strings.TrimSpace(r.result.Segments[i].Text))
+		text.WriteString("\n")
+	}
+	r.result.Text = text.String()
+	if n := len(r.result.Segments); n > 0 {
+		r.result.Duration = r.result.Segments[n-1].End
+	}
+}
+
+// export 按导出格式重新导出所有文件，返回写入的文件
+func (r *reviewSession) export() ([]string, error) {
+	if err := os.MkdirAll(r.config.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf(tr("创建输出目录失败: %w"), err)
+	}
+	files := saveOutputs(r.result, r.sourceName, r.config, r.formatList, false)
+	r.dirty = false
+	return files, nil
+}
+
+// segmentsText 按顺序拼接各分段的文本
+func segmentsText(segments []Segment) string {
+	var text string
+	for _, seg := range segments {
+		text = joinSegmentText(text, seg.Text)
+	}
+	return text
+}
+
+// rebuildResultText 分段文本被修改或增删后，按分段重建全文
+func rebuildResultText(result *TranscriptionResult) {
+	result.Text = segmentsText(result.Segments)
+}
+
+// joinSegmentText 拼接两段文本，英文等以空格分词的语言保留空格
+func joinSegmentText(a, b string) string {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == "" || b == "" {
+		return a + b
+	}
+	last := []rune(a)[len([]rune(a))-1]
+	if last < 0x80 {
+		return a + " " + b
+	}
+	return a + b
+}
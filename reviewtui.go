@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// reviewNudgeStep 全屏界面中每次微调时间的秒数
+const reviewNudgeStep = 0.1
+
+// 全屏界面的按键：方向键和翻页键解析为以下名称，其他按键为对应的字符
+const (
+	keyUp       = "up"
+	keyDown     = "down"
+	keyLeft     = "left"
+	keyRight    = "right"
+	keyHome     = "home"
+	keyEnd      = "end"
+	keyPageUp   = "pgup"
+	keyPageDown = "pgdn"
+	keyDelete   = "delete"
+	keyEscape   = "esc"
+)
+
+// reviewTUI review 子命令的全屏界面：上下选择分段，按键编辑、合并、拆分、微调时间和导出
+type reviewTUI struct {
+	*reviewSession
+	keys   *bufio.Reader
+	screen *bufio.Writer
+	cursor int    // 选中的分段下标
+	top    int    // 列表第一行的分段下标
+	status string // 底部状态栏的提示
+}
+
+// runTUI 切换到终端的备用屏幕和原始模式运行全屏界面，退出时恢复终端
+func (r *reviewSession) runTUI() error {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	t := &reviewTUI{reviewSession: r, keys: bufio.NewReader(os.Stdin), screen: bufio.NewWriter(os.Stdout)}
+	t.screen.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		t.screen.WriteString("\x1b[?25h\x1b[?1049l")
+		t.screen.Flush()
+		term.Restore(fd, state)
+	}()
+	t.loop()
+	return nil
+}
+
+// loop 读取按键并执行，直到退出
+func (t *reviewTUI) loop() {
+	for {
+		t.render()
+		key, ok := t.readKey()
+		if !ok {
+			return
+		}
+		t.status = ""
+		n := len(t.result.Segments)
+		var err error
+		switch key {
+		case keyUp, "k":
+			t.cursor--
+		case keyDown, "j":
+			t.cursor++
+		case keyPageUp:
+			t.cursor -= t.listHeight()
+		case keyPageDown, " ":
+			t.cursor += t.listHeight()
+		case keyHome, "g":
+			t.cursor = 0
+		case keyEnd, "G":
+			t.cursor = n - 1
+		case "\r", "e":
+			if n > 0 {
+				if text, ok := t.prompt(tr("编辑: "), strings.TrimSpace(t.result.Segments[t.cursor].Text), false); ok {
+					t.setText(t.cursor, text)
+				}
+			}
+		case "m":
+			if n > 0 {
+				err = t.mergeNext(t.cursor)
+			}
+		case "s":
+			if n > 0 {
+				text := strings.TrimSpace(t.result.Segments[t.cursor].Text)
+				if pos, ok := t.promptPosition(tr("拆分（移动光标到拆分处后回车）: "), text); ok {
+					err = t.splitAt(t.cursor, pos)
+				}
+			}
+		case "[", "]", "{", "}", "<", ">":
+			if n > 0 {
+				target := map[string]string{"[": "start", "]": "start", "{": "end", "}": "end", "<": "both", ">": "both"}[key]
+				delta := reviewNudgeStep
+				if strings.Contains("[{<", key) {
+					delta = -delta
+				}
+				err = t.shift(t.cursor, target, delta)
+			}
+		case "w":
+			var files []string
+			if files, err = t.export(); err == nil {
+				t.status = fmt.Sprintf(tr("已导出 %d 个文件到 %s"), len(files), t.config.OutputDir)
+			}
+		case "q", "\x03", "\x04":
+			if !t.dirty || t.confirm(tr("有未导出的修改，确认退出？(y/N) ")) {
+				return
+			}
+		}
+		if err != nil {
+			t.status = fmt.Sprintf(tr("错误: %v"), err)
+		}
+	}
+}
+
+// listHeight 列表区域的行数：去掉标题栏、状态栏和按键说明
+func (t *reviewTUI) listHeight() int {
+	_, height := t.size()
+	return max(height-3, 1)
+}
+
+// size 终端的列数和行数，获取失败时按 80x24 处理
+func (t *reviewTUI) size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// render 重绘整个屏幕：标题栏、分段列表（选中行反色）、状态栏和按键说明
+func (t *reviewTUI) render() {
+	width, height := t.size()
+	rows := t.listHeight()
+	segments := t.result.Segments
+	t.cursor = max(min(t.cursor, len(segments)-1), 0)
+	if t.cursor < t.top {
+		t.top = t.cursor
+	}
+	if t.cursor >= t.top+rows {
+		t.top = t.cursor - rows + 1
+	}
+
+	t.screen.WriteString("\x1b[H")
+	title := fmt.Sprintf(tr(" %s — 第 %d/%d 个分段"), t.sourceName, min(t.cursor+1, len(segments)), len(segments))
+	if t.dirty {
+		title += tr("（未导出）")
+	}
+	t.line("\x1b[1m", title, width)
+	for row := 0; row < rows; row++ {
+		i := t.top + row
+		if i >= len(segments) {
+			t.line("", "", width)
+			continue
+		}
+		seg := segments[i]
+		text := fmt.Sprintf("%4d  %s --> %s  %s", i+1, formatSRTTime(seg.Start), formatSRTTime(seg.End), strings.Join(strings.Fields(seg.Text), " "))
+		style := ""
+		if i == t.cursor {
+			style = "\x1b[7m"
+		}
+		t.line(style, text, width)
+	}
+	t.line("\x1b[33m", t.status, width)
+	fmt.Fprintf(t.screen, "\x1b[%d;1H", height)
+	t.screen.WriteString("\x1b[2m" + truncateWidth(tr("↑↓ 选择  Enter 编辑  m 合并  s 拆分  [ ] 开始  { } 结束  < > 平移 ±0.1s  w 导出  q 退出"), width) + "\x1b[0m\x1b[K")
+	t.screen.Flush()
+}
+
+// line 输出一行（超出宽度的部分截断）并清除行尾
+func (t *reviewTUI) line(style, text string, width int) {
+	t.screen.WriteString(style + truncateWidth(text, width) + "\x1b[0m\x1b[K\r\n")
+}
+
+// prompt 在状态栏中编辑一行文本，回车确认，Esc 或 Ctrl+C 取消
+func (t *reviewTUI) prompt(label, text string, readOnly bool) (string, bool) {
+	runes := []rune(text)
+	pos := len(runes)
+	if readOnly {
+		pos = 0
+	}
+	t.screen.WriteString("\x1b[?25h")
+	defer t.screen.WriteString("\x1b[?25l")
+	for {
+		t.renderPrompt(label, runes, pos)
+		key, ok := t.readKey()
+		if !ok {
+			return "", false
+		}
+		switch key {
+		case "\r":
+			if readOnly {
+				return string(runes[:pos]), true
+			}
+			return string(runes), true
+		case keyEscape, "\x03":
+			return "", false
+		case keyLeft:
+			pos = max(pos-1, 0)
+		case keyRight:
+			pos = min(pos+1, len(runes))
+		case keyHome, "\x01":
+			pos = 0
+		case keyEnd, "\x05":
+			pos = len(runes)
+		case "\x7f", "\x08":
+			if !readOnly && pos > 0 {
+				runes = append(runes[:pos-1], runes[pos:]...)
+				pos--
+			}
+		case keyDelete:
+			if !readOnly && pos < len(runes) {
+				runes = append(runes[:pos], runes[pos+1:]...)
+			}
+		case "\x15":
+			if !readOnly {
+				runes, pos = runes[pos:], 0
+			}
+		default:
+			r := []rune(key)
+			if !readOnly && len(r) == 1 && unicode.IsPrint(r[0]) {
+				runes = append(runes[:pos], append([]rune{r[0]}, runes[pos:]...)...)
+				pos++
+			}
+		}
+	}
+}
+
+// promptPosition 在状态栏中显示文本并移动光标选择位置，返回光标前的字符数
+func (t *reviewTUI) promptPosition(label, text string) (int, bool) {
+	before, ok := t.prompt(label, text, true)
+	return len([]rune(before)), ok
+}
+
+// renderPrompt 在状态栏绘制编辑中的文本并把终端光标放到编辑位置；文本超出宽度时横向滚动，保证光标可见
+func (t *reviewTUI) renderPrompt(label string, runes []rune, pos int) {
+	width, height := t.size()
+	avail := max(width-displayWidth(label)-1, 1)
+	start := 0
+	for displayWidth(string(runes[start:pos])) > avail-1 {
+		start++
+	}
+	visible := truncateWidth(string(runes[start:]), avail)
+	fmt.Fprintf(t.screen, "\x1b[%d;1H\x1b[33m%s\x1b[0m%s\x1b[K", height-1, label, visible)
+	fmt.Fprintf(t.screen, "\x1b[%d;%dH", height-1, displayWidth(label)+displayWidth(string(runes[start:pos]))+1)
+	t.screen.Flush()
+}
+
+// confirm 在状态栏询问是否继续，按 y 确认
+func (t *reviewTUI) confirm(question string) bool {
+	t.status = question
+	t.render()
+	key, ok := t.readKey()
+	t.status = ""
+	return ok && strings.EqualFold(key, "y")
+}
+
+// readKey 读取一个按键：方向键等转义序列转换为按键名称，其他按键返回对应的字符。输入结束时返回 false
+func (t *reviewTUI) readKey() (string, bool) {
+	r, _, err := t.keys.ReadRune()
+	if err != nil {
+		return "", false
+	}
+	// 单独的 Esc 后面不会紧跟其他字节，转义序列则由终端一次写入
+	if r != 0x1b || t.keys.Buffered() == 0 {
+		if r == 0x1b {
+			return keyEscape, true
+		}
+		return string(r), true
+	}
+	prefix, _ := t.keys.ReadByte()
+	if prefix != '[' && prefix != 'O' {
+		return keyEscape, true
+	}
+	var seq []byte
+	for {
+		b, err := t.keys.ReadByte()
+		if err != nil {
+			return "", false
+		}
+		seq = append(seq, b)
+		// 参数为数字和分号，以字母或 ~ 结束
+		if b >= 0x40 && b <= 0x7e {
+			break
+		}
+	}
+	switch string(seq) {
+	case "A":
+		return keyUp, true
+	case "B":
+		return keyDown, true
+	case "C":
+		return keyRight, true
+	case "D":
+		return keyLeft, true
+	case "H", "1~", "7~":
+		return keyHome, true
+	case "F", "4~", "8~":
+		return keyEnd, true
+	case "5~":
+		return keyPageUp, true
+	case "6~":
+		return keyPageDown, true
+	case "3~":
+		return keyDelete, true
+	}
+	return "", true
+}
+
+// runeWidth 字符在终端中占用的列数：中日韩文字和全角字符为 2，组合符号为 0
+func runeWidth(r rune) int {
+	switch {
+	case unicode.Is(unicode.Mn, r):
+		return 0
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul),
+		r >= 0x3000 && r <= 0x303f, r >= 0xff00 && r <= 0xff60, r >= 0xffe0 && r <= 0xffe6:
+		return 2
+	}
+	return 1
+}
+
+// displayWidth 文本在终端中占用的列数
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// truncateWidth 截断文本使其不超过 width 列
+func truncateWidth(s string, width int) string {
+	used := 0
+	for i, r := range s {
+		if used += runeWidth(r); used > width {
+			return s[:i]
+		}
+	}
+	return s
+}
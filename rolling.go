@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// rollingWriter 长时间转写的轮转输出：每隔 rotate 秒（音频时间）开始一个新的分段文件，
+// 每个文件写入的都是已完成的分段，中途中断时已写入的内容不会丢失。不轮转时 json 文件随会话变长，每次重写的开销也随之增加
+type rollingWriter struct {
+	dir     string
+	prefix  string
+	formats []string
+	rotate  float64 // 秒，0 为不轮转
+
+	part      int
+	partStart float64
+	segments  []Segment // 当前分段文件中的分段
+	language  string
+	nextID    int
+	files     []string // 所有已写入的文件
+}
+
+// newRollingWriter 创建轮转输出，仅支持 txt、srt、json、jsonl
+func newRollingWriter(dir, prefix string, formats []string, rotate float64) *rollingWriter {
+	w := &rollingWriter{dir: dir, prefix: prefix, rotate: rotate}
+	for _, format := range formats {
+		switch format {
+		case "txt", "srt", "json", "jsonl":
+			w.formats = append(w.formats, format)
+		default:
+			logWarn(tr("轮转输出不支持的格式: %s"), format)
+		}
+	}
+	return w
+}
+
+// maybeRotate 当前分段文件达到轮转时长时开始新文件
+func (w *rollingWriter) maybeRotate(offset float64) {
+	if w.part == 0 {
+		w.part = 1
+		w.partStart = offset
+		return
+	}
+	if w.rotate <= 0 || offset-w.partStart < w.rotate {
+		return
+	}
+
+	if len(w.segments) > 0 {
+		for _, file := range w.partFiles() {
+			logInfo(tr("已完成: %s\n"), file)
+		}
+	}
+	w.part++
+	w.partStart = offset
+	w.segments = nil
+}
+
+// add 追加分段（时间戳为相对整个会话的绝对时间）并写入当前分段文件：txt、srt、jsonl 没有文件头和文件尾，
+// 只在末尾追加新分段；json 是一个完整的对象，每次按当前分段文件中的全部分段重写
+func (w *rollingWriter) add(segments []Segment, language string) error {
+	if w.part == 0 {
+		w.maybeRotate(0)
+	}
+	if w.language == "" {
+		w.language = language
+	}
+	added := len(w.segments)
+	for _, seg := range segments {
+		w.nextID++
+		seg.ID = w.nextID
+		w.segments = append(w.segments, seg)
+	}
+	if added == len(w.segments) {
+		return nil
+	}
+	for _, path := range w.partFiles() {
+		var err error
+		if filepath.Ext(path) == ".json" {
+			err = w.rewrite(path)
+		} else {
+			err = w.appendTo(path, w.segments[added:])
+		}
+		if err != nil {
+			return fmt.Errorf(tr("写入 %s 失败: %w"), path, err)
+		}
+		w.track(path)
+	}
+	return nil
+}
+
+// appendTo 把新分段追加到 txt、srt 或 jsonl 文件末尾。本次会话第一次写入时清空文件，不接在上次运行留下的内容后面
+func (w *rollingWriter) appendTo(path string, segments []Segment) error {
+	var data []byte
+	switch filepath.Ext(path) {
+	case ".txt":
+		for _, seg := range segments {
+			data = append(data, seg.Text+"\n"...)
+		}
+	case ".srt":
+		data = []byte(srtCues(segments))
+	case ".jsonl":
+		var err error
+		if data, err = encodeJSONL(segments); err != nil {
+			return err
+		}
+	}
+
+	flag := os.O_WRONLY | os.O_APPEND | os.O_CREATE
+	if !slices.Contains(w.files, path) {
+		flag |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite 按当前分段文件中的全部分段重写 json 文件
+func (w *rollingWriter) rewrite(path string) error {
+	result := &TranscriptionResult{Language: w.language, Segments: w.segments}
+	rebuildResultText(result)
+	if n := len(w.segments); n > 0 {
+		result.Duration = w.segments[n-1].End
+	}
+	return saveJSON(result, path)
+}
+
+// partFiles 当前分段文件的路径，不轮转时只有一个文件，不加 _part 编号
+func (w *rollingWriter) partFiles() []string {
+	var files []string
+	for _, format := range w.formats {
+		if w.rotate <= 0 {
+			files = append(files, filepath.Join(w.dir, fmt.Sprintf("%s.%s", w.prefix, format)))
+			continue
+		}
+		files = append(files, filepath.Join(w.dir, fmt.Sprintf("%s_part%03d.%s", w.prefix, w.part, format)))
+	}
+	return files
+}
+
+// track 记录写入过的文件
+func (w *rollingWriter) track(path string) {
+	if !slices.Contains(w.files, path) {
+		w.files = append(w.files, path)
+	}
+}
+
+// writtenFiles 返回所有写入过的分段文件
+func (w *rollingWriter) writtenFiles() []string {
+	return w.files
+}
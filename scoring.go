@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scoringFileID CTM/STM 中的录音标识：输入文件名去掉扩展名，空白替换为下划线（字段以空白分隔）
+func scoringFileID(inputFile string) string {
+	name := filepath.Base(inputFile)
+	return scoringToken(strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+// scoringToken 把字段中的空白替换为下划线
+func scoringToken(s string) string {
+	return strings.Join(strings.Fields(s), "_")
+}
+
+// scoringChannel 分段所在的声道：按声道区分说话人时右声道为 2，其余为 1
+func scoringChannel(seg Segment, config *Config) string {
+	if config.ChannelSpeakers && len(config.ChannelSpeakerNames) == 2 && seg.Speaker == config.ChannelSpeakerNames[1] {
+		return "2"
+	}
+	return "1"
+}
+
+// saveCTM 保存为词级 CTM（<录音> <声道> <开始> <时长> <词>），需要词级时间戳，供 sclite 等 ASR 评分工具使用。
+// 词与分段文本不一致的分段不输出
+func saveCTM(result *TranscriptionResult, inputFile string, config *Config, outputPath string) error {
+	fileID := scoringFileID(inputFile)
+	var b strings.Builder
+	words := 0
+	for _, seg := range result.Segments {
+		channel := scoringChannel(seg, config)
+		for _, w := range alignedWords(seg) {
+			word := scoringToken(w.Word)
+			if word == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "%s %s %.3f %.3f %s\n", fileID, channel, w.Start, max(w.End-w.Start, 0), word)
+			words++
+		}
+	}
+	if words == 0 {
+		return errors.New(tr("CTM 需要与分段文本一致的词级时间戳，当前后端没有返回词级时间，或分段文本已被改写"))
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
+
+// saveSTM 保存为分段级 STM（<录音> <声道> <说话人> <开始> <结束> <文本>），作为评分的参考或对照。
+// 没有说话人标签时说话人为录音标识
+func saveSTM(result *TranscriptionResult, inputFile string, config *Config, outputPath string) error {
+	fileID := scoringFileID(inputFile)
+	var b strings.Builder
+	for _, seg := range jsonlSegments(result) {
+		text := strings.Join(strings.Fields(seg.Text), " ")
+		if text == "" {
+			continue
+		}
+		speaker := scoringToken(seg.Speaker)
+		if speaker == "" {
+			speaker = fileID
+		}
+		fmt.Fprintf(&b, "%s %s %s %.3f %.3f %s\n", fileID, scoringChannel(seg, config), speaker, seg.Start, seg.End, text)
+	}
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
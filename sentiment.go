@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// sentimentBatchSize 每次请求评分的分段数
+const sentimentBatchSize = 40
+
+// escalationThreshold 情绪分数不高于该值视为负面，从非负面转为负面的分段标记为升级点
+const escalationThreshold = -0.5
+
+// SentimentTimeline 按分段的情绪和语气时间线
+type SentimentTimeline struct {
+	// Average 所有分段的平均分数
+	Average float64 `json:"average"`
+	// Escalations 升级点的分段序号
+	Escalations []int              `json:"escalations"`
+	Segments    []SegmentSentiment `json:"segments"`
+}
+
+// SegmentSentiment 一个分段的情绪分数（-1 最负面，1 最正面）和语气
+type SegmentSentiment struct {
+	Segment    int     `json:"segment"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Speaker    string  `json:"speaker,omitempty"`
+	Score      float64 `json:"score"`
+	Tone       string  `json:"tone"`
+	Escalation bool    `json:"escalation,omitempty"`
+	Text       string  `json:"text"`
+}
+
+// sentimentPrompt 情绪评分的系统提示词
+const sentimentPrompt = `You score the sentiment of customer call transcript lines.
+The user sends a JSON array of lines in conversation order, each prefixed with the speaker when known.
+For each line, give a sentiment score from -1 (very negative, e.g. angry or threatening to cancel) to 1 (very positive),
+0 for neutral or purely factual lines, and a one-word English tone such as calm, polite, confused, frustrated, angry, apologetic, satisfied.
+Judge each line in the context of the surrounding lines. Reply with a JSON array of exactly the same length as the input,
+in the same order: [{"score":0,"tone":"calm"}]. Reply with JSON only.`
+
+// wantSentiment 是否请求了情绪时间线格式
+func wantSentiment(formatList []string) bool {
+	for _, format := range formatList {
+		if format == "sentiment" || format == "sentiment-json" {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeSentiment 由 sentiment_model 指定的对话模型按 sentimentBatchSize 个分段一组为每个分段评分，标记升级点。
+// 失败时返回 nil，情绪时间线格式不输出
+func analyzeSentiment(client *openai.Client, result *TranscriptionResult, config *Config) *SentimentTimeline {
+	if config.SentimentModel == "" {
+		logError(tr("输出情绪时间线需要配置 sentiment_model（情绪评分用的对话模型）"))
+		return nil
+	}
+	logInfo(tr("正在分析情绪（%d 个分段）\n"), len(result.Segments))
+
+	timeline := &SentimentTimeline{Escalations: []int{}, Segments: []SegmentSentiment{}}
+	for start := 0; start < len(result.Segments); start += sentimentBatchSize {
+		end := min(start+sentimentBatchSize, len(result.Segments))
+		lines := make([]string, 0, end-start)
+		for _, seg := range result.Segments[start:end] {
+			line := strings.TrimSpace(seg.Text)
+			if seg.Speaker != "" {
+				line = seg.Speaker + ": " + line
+			}
+			lines = append(lines, line)
+		}
+		scores, err := sentimentFromModel(client, config.SentimentModel, lines)
+		if err != nil {
+			logError(tr("分析第 %d-%d 个分段的情绪失败: %v"), start+1, end, err)
+			return nil
+		}
+		for i, seg := range result.Segments[start:end] {
+			timeline.Segments = append(timeline.Segments, SegmentSentiment{
+				Segment: seg.ID,
+				Start:   seg.Start,
+				End:     seg.End,
+				Speaker: seg.Speaker,
+				Score:   scores[i].Score,
+				Tone:    scores[i].Tone,
+				Text:    strings.TrimSpace(seg.Text),
+			})
+		}
+	}
+
+	// 升级点：分数从高于阈值降到阈值以下的分段，持续负面的后续分段不重复标记
+	negative := false
+	var total float64
+	for i := range timeline.Segments {
+		s := &timeline.Segments[i]
+		total += s.Score
+		if s.Score <= escalationThreshold {
+			if !negative {
+				s.Escalation = true
+				timeline.Escalations = append(timeline.Escalations, s.Segment)
+			}
+			negative = true
+		} else {
+			negative = false
+		}
+	}
+	if len(timeline.Segments) > 0 {
+		timeline.Average = total / float64(len(timeline.Segments))
+	}
+	return timeline
+}
+
+// sentimentScore 模型返回的单个分段评分
+type sentimentScore struct {
+	Score float64 `json:"score"`
+	Tone  string  `json:"tone"`
+}
+
+// sentimentFromModel 一次请求为一批分段评分，分数限制在 [-1, 1]
+func sentimentFromModel(client *openai.Client, model string, lines []string) ([]sentimentScore, error) {
+	input, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: sentimentPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: string(input)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New(tr("模型没有返回结果"))
+	}
+
+	var out []sentimentScore
+	if err := parseChatJSON(resp.Choices[0].Message.Content, &out); err != nil {
+		return nil, fmt.Errorf(tr("解析模型返回的情绪评分失败: %w"), err)
+	}
+	if len(out) != len(lines) {
+		return nil, fmt.Errorf(tr("模型返回 %d 个评分，应为 %d 个"), len(out), len(lines))
+	}
+	for i := range out {
+		out[i].Score = max(-1, min(1, out[i].Score))
+		out[i].Tone = strings.ToLower(strings.TrimSpace(out[i].Tone))
+	}
+	return out, nil
+}
+
+// saveSentimentJSON 保存 JSON 格式的情绪时间线
+func saveSentimentJSON(result *TranscriptionResult, outputPath string) error {
+	if result.Sentiment == nil {
+		return errors.New(tr("没有情绪时间线"))
+	}
+	data, err := json.MarshalIndent(result.Sentiment, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// saveSentimentCSV 保存 CSV 格式的情绪时间线，每个分段一行，便于在表格软件中排序和画图
+func saveSentimentCSV(result *TranscriptionResult, outputPath string) error {
+	timeline := result.Sentiment
+	if timeline == nil {
+		return errors.New(tr("没有情绪时间线"))
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"segment", "start", "end", "time", "speaker", "score", "tone", "escalation", "text"}); err != nil {
+		return err
+	}
+	for _, s := range timeline.Segments {
+		record := []string{
+			strconv.Itoa(s.Segment),
+			strconv.FormatFloat(s.Start, 'f', 3, 64),
+			strconv.FormatFloat(s.End, 'f', 3, 64),
+			formatChapterTime(s.Start),
+			s.Speaker,
+			strconv.FormatFloat(s.Score, 'f', 2, 64),
+			s.Tone,
+			strconv.FormatBool(s.Escalation),
+			s.Text,
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, buf.Bytes(), 0644)
+}
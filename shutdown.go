@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// 收到中断信号后的退出码（128 + 信号值，与 shell 的约定一致）
+const (
+	exitInterrupted = 130 // SIGINT（Ctrl+C）
+	exitTerminated  = 143 // SIGTERM
+)
+
+// shutdownCtx 收到中断信号时取消，进行中的 API 请求随之中止
+var shutdownCtx, cancelShutdown = context.WithCancel(context.Background())
+
+// tempRegistry 进程创建的临时文件和目录，中断退出前统一删除
+var tempRegistry = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: map[string]bool{}}
+
+// trackTemp 登记临时文件或目录并原样返回路径，中断时删除
+func trackTemp(path string) string {
+	tempRegistry.Lock()
+	tempRegistry.paths[path] = true
+	tempRegistry.Unlock()
+	return path
+}
+
+// removeTemp 删除临时文件或目录并取消登记
+func removeTemp(path string) {
+	os.RemoveAll(path)
+	tempRegistry.Lock()
+	delete(tempRegistry.paths, path)
+	tempRegistry.Unlock()
+}
+
+// cleanupTemps 删除所有登记的临时文件和目录，返回删除的数量
+func cleanupTemps() int {
+	tempRegistry.Lock()
+	defer tempRegistry.Unlock()
+	removed := 0
+	for path := range tempRegistry.paths {
+		if _, err := os.Lstat(path); err == nil && os.RemoveAll(path) == nil {
+			removed++
+		}
+		delete(tempRegistry.paths, path)
+	}
+	return removed
+}
+
+// shutdownHooks 中断退出前调用的清理函数，如结束仍在录制的 ffmpeg 子进程
+var shutdownHooks = struct {
+	sync.Mutex
+	next int
+	fns  map[int]func()
+}{fns: map[int]func(){}}
+
+// onShutdown 登记中断退出前调用的清理函数，返回取消登记的函数
+func onShutdown(fn func()) func() {
+	shutdownHooks.Lock()
+	id := shutdownHooks.next
+	shutdownHooks.next++
+	shutdownHooks.fns[id] = fn
+	shutdownHooks.Unlock()
+	return func() {
+		shutdownHooks.Lock()
+		delete(shutdownHooks.fns, id)
+		shutdownHooks.Unlock()
+	}
+}
+
+// runShutdownHooks 调用所有登记的清理函数
+func runShutdownHooks() {
+	shutdownHooks.Lock()
+	defer shutdownHooks.Unlock()
+	for _, fn := range shutdownHooks.fns {
+		fn()
+	}
+}
+
+// gracefulStop 长时间运行的命令（watch、live、follow）注册的停止函数
+var gracefulStop struct {
+	sync.Mutex
+	fn func()
+}
+
+// setGracefulStop 注册第一次按 Ctrl+C 时调用的停止函数，命令结束当前工作后自行正常退出；
+// 再次按 Ctrl+C 或收到 SIGTERM 时仍按 installShutdownHandler 立即取消并退出。fn 为 nil 时取消注册
+func setGracefulStop(fn func()) {
+	gracefulStop.Lock()
+	gracefulStop.fn = fn
+	gracefulStop.Unlock()
+}
+
+// takeGracefulStop 取出并清除已注册的停止函数，只调用一次
+func takeGracefulStop() func() {
+	gracefulStop.Lock()
+	defer gracefulStop.Unlock()
+	fn := gracefulStop.fn
+	gracefulStop.fn = nil
+	return fn
+}
+
+// installShutdownHandler 捕获 Ctrl+C 和 SIGTERM：取消进行中的请求、删除临时的提取音频和切片文件后以 130/143 退出。
+// 注册了 setGracefulStop 时第一次 Ctrl+C 只调用停止函数。
+// 断点续传和缓存数据在每个切片完成时已经写入，不会删除，重新运行时可以继续
+func installShutdownHandler() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range signals {
+			if sig == os.Interrupt {
+				if stop := takeGracefulStop(); stop != nil {
+					logWarn(tr("正在停止，再按一次 Ctrl+C 立即退出"))
+					stop()
+					continue
+				}
+			}
+			logWarn(tr("收到信号 %v，正在取消任务并清理临时文件"), sig)
+			cancelShutdown()
+			runShutdownHooks()
+			removed := cleanupTemps()
+			logDebug(tr("已删除 %d 个临时文件"), removed)
+
+			code := exitInterrupted
+			if sig == syscall.SIGTERM {
+				code = exitTerminated
+			}
+			exit(code)
+		}
+	}()
+}
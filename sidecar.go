@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sidecarSuffix 单文件配置的后缀：talk.mp3 旁的 talk.mp3.whisper.json 或 talk.whisper.json
+const sidecarSuffix = ".whisper.json"
+
+// sidecarConfig 单文件配置，只覆盖设置了的字段
+type sidecarConfig struct {
+	Language   *string  `json:"language"`
+	AutoDetect *bool    `json:"auto_detect"`
+	Prompt     *string  `json:"prompt"`
+	Model      *string  `json:"model"`
+	Formats    []string `json:"formats"`
+}
+
+// sidecarPath 查找输入文件旁的单文件配置，优先使用带原扩展名的文件名，不存在时返回空字符串
+func sidecarPath(inputFile string) string {
+	if isRemoteURI(inputFile) {
+		return ""
+	}
+	candidates := []string{
+		inputFile + sidecarSuffix,
+		strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + sidecarSuffix,
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// applySidecar 读取输入文件的单文件配置，返回覆盖后的配置副本和输出格式；没有单文件配置时原样返回
+func applySidecar(inputFile string, config *Config, formatList []string) (*Config, []string, error) {
+	path := sidecarPath(inputFile)
+	if path == "" {
+		return config, formatList, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf(tr("读取单文件配置失败: %w"), err)
+	}
+	var sidecar sidecarConfig
+	if err := decodeConfig(data, &sidecar); err != nil {
+		return nil, nil, withExitCode(exitConfig, fmt.Errorf(tr("解析单文件配置 %s 失败: %w"), path, err))
+	}
+
+	overridden := *config
+	if sidecar.Language != nil {
+		overridden.Language = *sidecar.Language
+		// 指定了语言而未指定 auto_detect 时不再自动检测
+		overridden.AutoDetect = false
+	}
+	if sidecar.AutoDetect != nil {
+		overridden.AutoDetect = *sidecar.AutoDetect
+	}
+	if sidecar.Prompt != nil {
+		overridden.Prompt = *sidecar.Prompt
+	}
+	if sidecar.Model != nil {
+		overridden.Model = *sidecar.Model
+		if err := overridden.validateModel(); err != nil {
+			return nil, nil, withExitCode(exitConfig, fmt.Errorf(tr("单文件配置 %s 无效: %w"), path, err))
+		}
+	}
+	if len(sidecar.Formats) > 0 {
+		formatList = parseFormats(strings.Join(sidecar.Formats, ","))
+	}
+
+	logInfo(tr("使用单文件配置: %s"), path)
+	return &overridden, formatList, nil
+}
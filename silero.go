@@ -0,0 +1,118 @@
+//go:build silero
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// sileroStateShape Silero VAD v5 循环状态张量的形状
+var sileroStateShape = ort.NewShape(2, 1, 128)
+
+// onnxRuntime onnxruntime 环境在进程内只初始化一次
+var onnxRuntime struct {
+	once sync.Once
+	err  error
+}
+
+// initONNXRuntime 加载 onnxruntime 动态库并初始化环境，lib 为空时按系统库搜索路径查找
+func initONNXRuntime(lib string) error {
+	onnxRuntime.once.Do(func() {
+		if lib != "" {
+			ort.SetSharedLibraryPath(lib)
+		}
+		if err := ort.InitializeEnvironment(); err != nil {
+			onnxRuntime.err = fmt.Errorf(tr("初始化 onnxruntime 失败（可在 onnxruntime_lib 中配置动态库路径）: %w"), err)
+		}
+	})
+	return onnxRuntime.err
+}
+
+// sileroDetector 通过 onnxruntime 运行的 Silero VAD v5 模型，输入输出张量在各窗口间复用
+type sileroDetector struct {
+	session *ort.AdvancedSession
+	input   *ort.Tensor[float32] // [1, 上下文 + 窗口]
+	state   *ort.Tensor[float32]
+	sr      *ort.Tensor[int64]
+	output  *ort.Tensor[float32] // [1, 1] 语音概率
+	stateN  *ort.Tensor[float32]
+	context []float32 // 上一窗口末尾的采样
+}
+
+// newSileroDetector 加载 silero_model 配置的模型文件
+func newSileroDetector(config *Config) (vadDetector, error) {
+	if err := initONNXRuntime(config.ONNXRuntimeLib); err != nil {
+		return nil, err
+	}
+
+	d := &sileroDetector{context: make([]float32, sileroContext)}
+	fail := func(format string, args ...any) (vadDetector, error) {
+		d.Close()
+		return nil, fmt.Errorf(format, args...)
+	}
+	var err error
+	if d.input, err = ort.NewEmptyTensor[float32](ort.NewShape(1, sileroContext+sileroWindow)); err != nil {
+		return fail(tr("创建 Silero VAD 张量失败: %w"), err)
+	}
+	if d.state, err = ort.NewEmptyTensor[float32](sileroStateShape); err != nil {
+		return fail(tr("创建 Silero VAD 张量失败: %w"), err)
+	}
+	if d.sr, err = ort.NewTensor(ort.NewShape(1), []int64{sileroSampleRate}); err != nil {
+		return fail(tr("创建 Silero VAD 张量失败: %w"), err)
+	}
+	if d.output, err = ort.NewEmptyTensor[float32](ort.NewShape(1, 1)); err != nil {
+		return fail(tr("创建 Silero VAD 张量失败: %w"), err)
+	}
+	if d.stateN, err = ort.NewEmptyTensor[float32](sileroStateShape); err != nil {
+		return fail(tr("创建 Silero VAD 张量失败: %w"), err)
+	}
+
+	// 模型很小，单线程推理最快，也不与并行的切片任务争抢 CPU
+	options, err := ort.NewSessionOptions()
+	if err != nil {
+		return fail(tr("加载 Silero VAD 模型 %s 失败: %w"), config.SileroModel, err)
+	}
+	defer options.Destroy()
+	options.SetIntraOpNumThreads(1)
+	options.SetInterOpNumThreads(1)
+
+	d.session, err = ort.NewAdvancedSession(config.SileroModel,
+		[]string{"input", "state", "sr"}, []string{"output", "stateN"},
+		[]ort.Value{d.input, d.state, d.sr}, []ort.Value{d.output, d.stateN}, options)
+	if err != nil {
+		return fail(tr("加载 Silero VAD 模型 %s 失败: %w"), config.SileroModel, err)
+	}
+	return d, nil
+}
+
+// Probability 运行一个窗口，并保存循环状态和上下文供下一个窗口使用
+func (d *sileroDetector) Probability(window []float32) (float32, error) {
+	input := d.input.GetData()
+	copy(input, d.context)
+	copy(input[sileroContext:], window)
+	copy(d.context, input[len(input)-sileroContext:])
+
+	if err := d.session.Run(); err != nil {
+		return 0, err
+	}
+	copy(d.state.GetData(), d.stateN.GetData())
+	return d.output.GetData()[0], nil
+}
+
+// Close 释放会话和已创建的张量
+func (d *sileroDetector) Close() {
+	if d.session != nil {
+		d.session.Destroy()
+	}
+	for _, t := range []*ort.Tensor[float32]{d.input, d.state, d.output, d.stateN} {
+		if t != nil {
+			t.Destroy()
+		}
+	}
+	if d.sr != nil {
+		d.sr.Destroy()
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !silero
+
+package main
+
+import "errors"
+
+// newSileroDetector 未使用 silero 构建标签编译时不包含 onnxruntime，无法使用 Silero VAD
+func newSileroDetector(config *Config) (vadDetector, error) {
+	return nil, errors.New(tr("当前版本未包含 Silero VAD，请使用 -tags silero 重新编译（需要 github.com/yalue/onnxruntime_go）"))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// SegmentSink 接收转写过程中实时产生的分段（时间戳已对齐原始音频），用于驱动直播字幕等实时输出
+type SegmentSink interface {
+	WriteSegments(segments []Segment) error
+	Close() error
+}
+
+// multiSink 将分段同时写入多个输出
+type multiSink []SegmentSink
+
+func (m multiSink) WriteSegments(segments []Segment) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.WriteSegments(segments); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiSink) Close() error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newSegmentSink 根据配置创建实时分段输出，未配置任何输出时返回 nil
+func newSegmentSink(config *Config) (SegmentSink, error) {
+	var sinks multiSink
+
+	if config.CaptionFile != "" {
+		sinks = append(sinks, &captionFileSink{path: config.CaptionFile, lines: config.CaptionLines})
+	}
+	if config.OBSWebSocketURL != "" {
+		obs, err := newOBSSink(config.OBSWebSocketURL, config.OBSPassword, config.OBSTextSource, config.CaptionLines)
+		if err != nil {
+			sinks.Close()
+			return nil, err
+		}
+		sinks = append(sinks, obs)
+	}
+
+	if config.CaptionListen != "" {
+		if err := listenCaptions(config); err != nil {
+			sinks.Close()
+			return nil, fmt.Errorf(tr("启动实时字幕服务失败: %w"), err)
+		}
+	}
+	if hub := activeCaptions(); hub != nil {
+		sinks = append(sinks, captionHubSink{hub: hub, scope: config.captionScope})
+	}
+
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return sinks, nil
+}
+
+// rollingCaption 保留最近若干行字幕文本
+type rollingCaption struct {
+	lines []string
+	max   int
+}
+
+// add 追加分段文本并返回当前应显示的字幕
+func (r *rollingCaption) add(segments []Segment) string {
+	for _, seg := range segments {
+		if text := strings.TrimSpace(seg.Text); text != "" {
+			r.lines = append(r.lines, text)
+		}
+	}
+	max := r.max
+	if max <= 0 {
+		max = 2
+	}
+	if len(r.lines) > max {
+		r.lines = r.lines[len(r.lines)-max:]
+	}
+	return strings.Join(r.lines, "\n")
+}
+
+// captionFileSink 将最近几行字幕写入文本文件，供 OBS 文本源的"从文件读取"使用
+type captionFileSink struct {
+	path    string
+	lines   int
+	caption rollingCaption
+}
+
+func (c *captionFileSink) WriteSegments(segments []Segment) error {
+	c.caption.max = c.lines
+	text := c.caption.add(segments)
+
+	// 写入临时文件后重命名，避免 OBS 读到写了一半的内容
+	tmp := filepath.Join(filepath.Dir(c.path), "."+filepath.Base(c.path)+".tmp")
+	if err := os.WriteFile(tmp, []byte(text), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+func (c *captionFileSink) Close() error {
+	return nil
+}
+
+// emitSegments 将分段按偏移对齐后推送到实时输出，filter_action 为 drop 时跳过会被过滤的分段；
+// 开启 redact 时先遮盖敏感内容再推送（原分段不变，完整结果稍后另行脱敏）。推送失败只记录日志，不影响转写
+func emitSegments(client *openai.Client, sink SegmentSink, segments []Segment, offset float64, config *Config) {
+	if sink == nil {
+		return
+	}
+	var out []Segment
+	for _, seg := range segments {
+		if strings.TrimSpace(seg.Text) == "" {
+			continue
+		}
+		if config.FilterAction == "drop" && len(segmentFilterReasons(seg, config)) > 0 {
+			continue
+		}
+		seg.Start += offset
+		seg.End += offset
+		seg.Words = slices.Clone(seg.Words)
+		out = append(out, seg)
+	}
+	if config.Redact && len(out) > 0 {
+		redacted := &TranscriptionResult{Segments: out}
+		redactResult(client, redacted, config)
+		out = redacted.Segments
+	}
+	pushSegments(sink, out)
+}
+
+// pushSegments 推送已经过滤和脱敏的分段，推送失败只记录日志
+func pushSegments(sink SegmentSink, out []Segment) {
+	if sink == nil || len(out) == 0 {
+		return
+	}
+	if err := sink.WriteSegments(out); err != nil {
+		logWarn(tr("推送实时字幕失败: %v"), err)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// sizeGuardBitrates 上传超过服务商大小限制时依次尝试的 Opus 码率（同时重采样为 16kHz 单声道）
+var sizeGuardBitrates = []string{"32k", "16k", "8k"}
+
+// sizeGuardMaxDepth 压缩后仍然过大时继续对半切分的最大层数
+const sizeGuardMaxDepth = 3
+
+// isTooLargeError 判断错误是否因上传文件超过服务商大小限制：HTTP 413，或错误信息提示文件过大
+func isTooLargeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if httpStatusCode(err) == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, hint := range []string{"too large", "maximum content size", "size limit"} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// transcribeWithSizeGuard 转写音频，上传过大被拒绝时先降低码率重新压缩，仍然过大则对半切分后分别转写再合并，
+// 避免单个切片导致整个任务失败
+func transcribeWithSizeGuard(client *openai.Client, audioPath string, config *Config, verbose bool) (*TranscriptionResult, error) {
+	return sizeGuardTranscribe(client, audioPath, config, verbose, 0)
+}
+
+// sizeGuardTranscribe transcribeWithSizeGuard 的递归实现，depth 为已切分的层数
+func sizeGuardTranscribe(client *openai.Client, audioPath string, config *Config, verbose bool, depth int) (*TranscriptionResult, error) {
+	result, err := transcribeWithBackends(client, audioPath, config, verbose)
+	if config.Provider == providerWhisperCpp || !isTooLargeError(err) {
+		return result, err
+	}
+
+	for _, bitrate := range lowerUploadBitrates(config) {
+		logWarn(tr("上传文件超过服务商大小限制，压缩为 Opus %s 后重试: %s\n"), bitrate, audioPath)
+		downsampled := *config
+		downsampled.UploadCodec = "opus"
+		downsampled.UploadBitrate = bitrate
+		result, cerr := transcribeWithBackends(client, audioPath, &downsampled, verbose)
+		if cerr == nil {
+			return result, nil
+		}
+		if !isTooLargeError(cerr) {
+			// 压缩失败（如没有 ffmpeg）时改为切分
+			logWarn(tr("降低码率重试失败: %v\n"), cerr)
+			break
+		}
+		config = &downsampled
+	}
+
+	if depth >= sizeGuardMaxDepth {
+		return nil, err
+	}
+	return splitTooLarge(client, audioPath, config, verbose, depth+1)
+}
+
+// lowerUploadBitrates 比当前上传码率更低的重试码率，未压缩上传时返回全部
+func lowerUploadBitrates(config *Config) []string {
+	if config.UploadCodec == "" {
+		return sizeGuardBitrates
+	}
+	current := config.UploadBitrate
+	if current == "" {
+		current = defaultUploadBitrate
+	}
+	currentBits := parseBitrate(current)
+	var lower []string
+	for _, bitrate := range sizeGuardBitrates {
+		if currentBits == 0 || parseBitrate(bitrate) < currentBits {
+			lower = append(lower, bitrate)
+		}
+	}
+	return lower
+}
+
+// parseBitrate 解析 ffmpeg 码率（如 16k、32000），无法解析时返回 0
+func parseBitrate(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	multiplier := 1
+	if strings.HasSuffix(s, "k") {
+		multiplier = 1000
+		s = strings.TrimSuffix(s, "k")
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n * multiplier
+}
+
+// splitTooLarge 在中点附近的静音处将音频切为两半，分别转写后合并
+func splitTooLarge(client *openai.Client, audioPath string, config *Config, verbose bool, depth int) (*TranscriptionResult, error) {
+	duration, err := getAudioDuration(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf(tr("获取音频时长失败: %w"), err)
+	}
+	silencePoints, err := detectSilence(audioPath, config, verbose)
+	if err != nil {
+		return nil, err
+	}
+	splitTimes := calculateSplitTimes(duration, duration/2, silencePoints)
+	if len(splitTimes) == 0 {
+		return nil, fmt.Errorf(tr("音频过短，无法继续切分: %s"), audioPath)
+	}
+	logWarn(tr("仍超过服务商大小限制，切分为 %d 段重试: %s\n"), len(splitTimes)+1, audioPath)
+
+	prefix := fmt.Sprintf("whisper_split_%d", time.Now().UnixNano())
+	chunks := startAudioChunks(audioPath, splitTimes, workingDir(), prefix, len(splitTimes)+1, verbose)
+	for _, chunk := range chunks {
+		trackTemp(chunk.Path)
+	}
+	defer cleanupChunks(chunks)
+
+	results := make([]*TranscriptionResult, len(chunks))
+	for i, chunk := range chunks {
+		if err := chunk.Wait(); err != nil {
+			return nil, fmt.Errorf(tr("创建切片 %d 失败: %w"), i+1, err)
+		}
+		results[i], err = sizeGuardTranscribe(client, chunk.Path, config, verbose, depth)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mergeResults(results, chunks), nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SplitManifest split 子命令输出的切片清单
+type SplitManifest struct {
+	Source   string          `json:"source"`
+	Duration float64         `json:"duration"`
+	Chunks   []ManifestChunk `json:"chunks"`
+}
+
+// ManifestChunk 单个切片及其在原始音频中的位置
+type ManifestChunk struct {
+	Index int     `json:"index"`
+	File  string  `json:"file"` // 相对于清单文件所在目录
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// runSplit 执行 split 子命令：只按静音点（或切点文件）切片并输出切片清单，不调用转写 API
+func runSplit(args []string) {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径（可选，用于读取切片参数）"))
+	outputDir := fs.String("output", "", tr("切片输出目录（默认为 <输出目录>/<文件名>_chunks）"))
+	maxSize := fs.Float64("max-size", 0, tr("单个切片的最大大小（MB，默认读取配置）"))
+	chunkWorkers := fs.Int("chunk-workers", 0, tr("并行切割切片的进程数（默认读取配置）"))
+	vad := fs.String("vad", "", tr("切点检测方式：energy（按能量阈值）或 silero（Silero VAD 模型，适合嘈杂的录音）（覆盖配置中的 vad）"))
+	splits := fs.String("splits", "", tr("手动切点文件：每行一个时间点，代替按静音点计算的切点（默认使用输入文件旁的 <文件名>.splits.txt）"))
+	verbose := fs.Bool("verbose", false, tr("显示详细输出"))
+	fs.Parse(args)
+	useVerboseLogging(*verbose)
+	installShutdownHandler()
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go split <input-file> [options]"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	inputFile := fs.Arg(0)
+	if _, err := os.Stat(inputFile); err != nil {
+		fatalf(tr("输入文件不存在: %s"), inputFile)
+	}
+
+	// 切片不需要 API Key，配置文件不存在时使用默认切片参数
+	config, err := loadConfig(*configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		config = &Config{}
+		err = config.applyDefaults()
+	}
+	if err != nil {
+		fatalf(tr("加载配置失败: %v"), err)
+	}
+	if *maxSize > 0 {
+		config.MaxFileSizeMB = *maxSize
+	}
+	if *chunkWorkers > 0 {
+		config.ChunkWorkers = *chunkWorkers
+	}
+	if *vad != "" {
+		if err := config.setVAD(*vad); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	config.SplitsFile = *splits
+
+	// 提取的音频在 splitAudio 返回时删除，出错也只在这里退出，避免 os.Exit 跳过清理
+	if err := splitAudio(inputFile, config, *outputDir, *verbose); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+// splitAudio 按静音点（或切点文件）切分输入文件，切片和清单写入 dir（为空时为 <输出目录>/<文件名>_chunks）
+func splitAudio(inputFile string, config *Config, dir string, verbose bool) error {
+	name := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+	if dir == "" {
+		dir = filepath.Join(config.OutputDir, name+"_chunks")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf(tr("创建输出目录失败: %w"), err)
+	}
+
+	audioPath := inputFile
+	if isVideoFile(inputFile) {
+		var err error
+		audioPath, err = extractAudio(inputFile, verbose)
+		if err != nil {
+			return fmt.Errorf(tr("提取音频失败: %w"), err)
+		}
+		defer removeTemp(audioPath)
+	}
+
+	duration, err := getAudioDuration(audioPath)
+	if err != nil {
+		return fmt.Errorf(tr("获取音频时长失败: %w"), err)
+	}
+	var splitTimes []float64
+	if splitsFile := manualSplitsFile(inputFile, config); splitsFile != "" {
+		splitTimes, err = loadSplitTimes(splitsFile, duration)
+	} else {
+		splitTimes, err = planSplitTimes(audioPath, config, verbose)
+	}
+	if err != nil {
+		return fmt.Errorf(tr("音频切片失败: %w"), err)
+	}
+	chunks := startAudioChunks(audioPath, splitTimes, dir, name, config.ChunkWorkers, verbose)
+
+	manifest := SplitManifest{Source: inputFile, Duration: duration}
+	if abs, err := filepath.Abs(inputFile); err == nil {
+		manifest.Source = abs
+	}
+	for i, chunk := range chunks {
+		if err := chunk.Wait(); err != nil {
+			return fmt.Errorf(tr("创建切片 %d 失败: %w"), i+1, err)
+		}
+		end := chunk.EndOffset
+		if end == 0 {
+			end = duration
+		}
+		manifest.Chunks = append(manifest.Chunks, ManifestChunk{
+			Index: i + 1,
+			File:  filepath.Base(chunk.Path),
+			Start: chunk.StartOffset,
+			End:   end,
+		})
+		fmt.Printf("%s  %s --> %s\n", chunk.Path, formatSRTTime(chunk.StartOffset), formatSRTTime(end))
+	}
+
+	manifestPath := filepath.Join(dir, name+"_chunks.json")
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf(tr("生成切片清单失败: %w"), err)
+	}
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		return fmt.Errorf(tr("保存切片清单失败: %w"), err)
+	}
+	fmt.Printf(tr("\n共 %d 个切片，清单: %s\n"), len(chunks), manifestPath)
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// splitsSuffix 手动切点文件的后缀：talk.mp3 旁的 talk.mp3.splits.txt 或 talk.splits.txt
+const splitsSuffix = ".splits.txt"
+
+// manualSplitsFile 输入文件使用的手动切点文件：优先使用 --splits 指定的文件，其次查找输入文件旁的
+// .splits.txt，都没有时返回空字符串（按静音点计算切点）
+func manualSplitsFile(inputFile string, config *Config) string {
+	if config.SplitsFile != "" {
+		return config.SplitsFile
+	}
+	if isRemoteURI(inputFile) {
+		return ""
+	}
+	candidates := []string{
+		inputFile + splitsSuffix,
+		strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + splitsSuffix,
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadSplitTimes 读取手动切点文件：每行一个时间点（秒数或 HH:MM:SS.fff、MM:SS.fff），
+// # 之后为注释，空行忽略。时间点必须递增且在音频时长之内
+func loadSplitTimes(path string, duration float64) ([]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取切点文件失败: %w"), err)
+	}
+	defer f.Close()
+
+	var times []float64
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		t, err := parseTimeOffset(text)
+		if err != nil || strings.HasPrefix(text, "-") {
+			return nil, fmt.Errorf(tr("%s 第 %d 行: 无效的时间点 %q（格式如 754.2、12:34.2、01:02:03）"), path, line, text)
+		}
+		switch {
+		case t <= 0 || (duration > 0 && t >= duration):
+			return nil, fmt.Errorf(tr("%s 第 %d 行: 时间点 %s 不在音频时长 %s 之内"), path, line, text, formatChapterTime(duration))
+		case len(times) > 0 && t <= times[len(times)-1]:
+			return nil, fmt.Errorf(tr("%s 第 %d 行: 时间点 %s 应晚于上一个时间点"), path, line, text)
+		}
+		times = append(times, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(tr("读取切点文件失败: %w"), err)
+	}
+	if len(times) == 0 {
+		return nil, fmt.Errorf(tr("切点文件 %s 中没有时间点"), path)
+	}
+	return times, nil
+}
+
+// splitAudioManually 按手动切点文件分割音频，切片超过 maxSizeMB 时只警告（接口可能拒绝过大的切片）
+func splitAudioManually(audioPath, splitsFile string, maxSizeMB float64, workers int, verbose bool) ([]AudioChunk, error) {
+	duration, err := getAudioDuration(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf(tr("获取音频时长失败: %w"), err)
+	}
+	splitTimes, err := loadSplitTimes(splitsFile, duration)
+	if err != nil {
+		return nil, err
+	}
+	logInfo(tr("使用切点文件 %s，共 %d 个切片\n"), splitsFile, len(splitTimes)+1)
+
+	for i, chunk := range chunkPlans(splitTimes, duration) {
+		// 切片为 16kHz 单声道 PCM
+		if sizeMB := (chunk.End - chunk.Start) * extractedBytesPerSecond / (1024 * 1024); sizeMB > maxSizeMB {
+			logWarn(tr("切片 %d（%s - %s）约 %.1f MB，超过阈值 %.0f MB，可能被接口拒绝"), i+1, formatChapterTime(chunk.Start), formatChapterTime(chunk.End), sizeMB, maxSizeMB)
+		}
+	}
+	return cutAudioAt(audioPath, splitTimes, workers, verbose)
+}
+
+// chunkPlans 把切点转换为切片区间
+func chunkPlans(splitTimes []float64, duration float64) []chunkPlan {
+	var chunks []chunkPlan
+	start := 0.0
+	for _, end := range append(splitTimes, duration) {
+		if end <= start {
+			continue
+		}
+		chunks = append(chunks, chunkPlan{Start: start, End: end})
+		start = end
+	}
+	return chunks
+}
+
+// formatSplitTime 切点的显示格式 HH:MM:SS.fff，可直接写入切点文件
+func formatSplitTime(seconds float64) string {
+	return strings.Replace(formatSRTTime(seconds), ",", ".", 1)
+}
+
+// runPlanSplits 执行 --plan-splits：输出按静音点计算的切点（不切割、不调用 API），
+// 格式与切点文件相同，可重定向到 .splits.txt 后手动调整
+func runPlanSplits(inputs []string, config *Config) {
+	for i, input := range inputs {
+		plan, err := planDryRun(input, config, nil)
+		if err != nil {
+			fatalf(tr("分析 %s 失败: %v"), input, err)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s\n", input)
+		if plan.Duration == 0 {
+			for _, note := range plan.Notes {
+				fmt.Printf("# %s\n", note)
+			}
+			continue
+		}
+		if plan.SplitsFile != "" {
+			fmt.Printf(tr("# 已有切点文件 %s，转写时以该文件为准\n"), plan.SplitsFile)
+		}
+		if !plan.NeedsSplit {
+			fmt.Printf(tr("# %s，%.2f MB 未超过阈值 %.0f MB，不需要切片\n"), formatChapterTime(plan.Duration), plan.AudioSizeMB, config.MaxFileSizeMB)
+			continue
+		}
+
+		// 没有切点文件时预演计划中已是按静音点计算的切片
+		var splitTimes []float64
+		if plan.SplitsFile != "" {
+			if splitTimes, err = silenceSplitTimes(input, plan, config); err != nil {
+				fmt.Printf("# %s\n", fmt.Sprintf(tr("静音检测失败，切片边界按等长估算: %v"), err))
+			}
+		} else {
+			for _, note := range plan.Notes {
+				fmt.Printf("# %s\n", note)
+			}
+			for _, chunk := range plan.Chunks[1:] {
+				splitTimes = append(splitTimes, chunk.Start)
+			}
+		}
+		fmt.Printf(tr("# %s，%.2f MB 超过阈值 %.0f MB，按静音点计算 %d 个切片\n"), formatChapterTime(plan.Duration), plan.AudioSizeMB, config.MaxFileSizeMB, len(splitTimes)+1)
+		for _, t := range splitTimes {
+			fmt.Println(formatSplitTime(t))
+		}
+	}
+}
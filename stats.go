@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 统计报告参数
+const (
+	// statsRateWindow 语速随时间变化的统计窗口（秒）
+	statsRateWindow = 60.0
+	// monologueMaxGap 同一说话人的相邻分段间隔不超过此值（秒）时视为同一段连续发言
+	monologueMaxGap = 2.0
+)
+
+// TranscriptStats stats 格式输出的转写统计
+type TranscriptStats struct {
+	Language         string         `json:"language,omitempty"`
+	Duration         float64        `json:"duration"`
+	SpeechTime       float64        `json:"speech_time"`
+	SilencePercent   float64        `json:"silence_percent"`
+	Segments         int            `json:"segments"`
+	Words            int            `json:"words"`
+	Characters       int            `json:"characters"`
+	WordsPerMinute   float64        `json:"words_per_minute"` // 按说话时间计算
+	RateOverTime     []RateWindow   `json:"rate_over_time"`
+	LongestMonologue *Monologue     `json:"longest_monologue,omitempty"`
+	Speakers         []SpeakerStats `json:"speakers,omitempty"`
+}
+
+// RateWindow 一个时间窗口内的语速
+type RateWindow struct {
+	Start          float64 `json:"start"`
+	End            float64 `json:"end"`
+	Words          int     `json:"words"`
+	WordsPerMinute float64 `json:"words_per_minute"` // 按窗口时长计算，包含静音
+}
+
+// Monologue 一段连续发言：同一说话人（未区分说话人时为任何人）的相邻分段，间隔不超过 monologueMaxGap
+type Monologue struct {
+	Speaker  string  `json:"speaker,omitempty"`
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Duration float64 `json:"duration"`
+}
+
+// SpeakerStats 单个说话人的统计（开启说话人区分时）
+type SpeakerStats struct {
+	Speaker        string  `json:"speaker"`
+	TalkTime       float64 `json:"talk_time"`
+	TalkPercent    float64 `json:"talk_percent"` // 占全部说话时间的百分比
+	Segments       int     `json:"segments"`
+	Words          int     `json:"words"`
+	WordsPerMinute float64 `json:"words_per_minute"`
+}
+
+// countWords 统计词数和字符数：中日韩文字每个字计为一个词，其他语言按连续的字母数字计为一个词；字符数不含空白和标点
+func countWords(text string) (words, chars int) {
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			words++
+			chars++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || (inWord && (r == '\'' || r == '’')):
+			if !inWord {
+				words++
+			}
+			chars++
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return words, chars
+}
+
+// computeStats 根据分段计算转写统计
+func computeStats(result *TranscriptionResult) *TranscriptStats {
+	stats := &TranscriptStats{Language: result.Language, Duration: result.Duration, Segments: len(result.Segments), RateOverTime: []RateWindow{}}
+	if n := len(result.Segments); n > 0 && result.Segments[n-1].End > stats.Duration {
+		stats.Duration = result.Segments[n-1].End
+	}
+
+	windows := int(math.Ceil(stats.Duration / statsRateWindow))
+	for i := 0; i < windows; i++ {
+		stats.RateOverTime = append(stats.RateOverTime, RateWindow{Start: float64(i) * statsRateWindow, End: min(float64(i+1)*statsRateWindow, stats.Duration)})
+	}
+
+	speakers := make(map[string]*SpeakerStats)
+	var speechEnd float64
+	var current *Monologue
+	for _, seg := range result.Segments {
+		words, chars := countWords(seg.Text)
+		stats.Words += words
+		stats.Characters += chars
+
+		// 说话时间合并重叠的分段
+		start := max(seg.Start, speechEnd)
+		if seg.End > start {
+			stats.SpeechTime += seg.End - start
+		}
+		speechEnd = max(speechEnd, seg.End)
+
+		addRateWords(stats.RateOverTime, seg, words)
+
+		if seg.Speaker != "" {
+			sp := speakers[seg.Speaker]
+			if sp == nil {
+				sp = &SpeakerStats{Speaker: seg.Speaker}
+				speakers[seg.Speaker] = sp
+			}
+			sp.TalkTime += seg.End - seg.Start
+			sp.Segments++
+			sp.Words += words
+		}
+
+		if current != nil && current.Speaker == seg.Speaker && seg.Start-current.End <= monologueMaxGap {
+			current.End = max(current.End, seg.End)
+		} else {
+			current = &Monologue{Speaker: seg.Speaker, Start: seg.Start, End: seg.End}
+		}
+		current.Duration = current.End - current.Start
+		if stats.LongestMonologue == nil || current.Duration > stats.LongestMonologue.Duration {
+			longest := *current
+			stats.LongestMonologue = &longest
+		}
+	}
+
+	if stats.Duration > 0 {
+		stats.SilencePercent = round2(max(100*(1-stats.SpeechTime/stats.Duration), 0))
+	}
+	stats.SpeechTime = round2(stats.SpeechTime)
+	stats.WordsPerMinute = perMinute(stats.Words, stats.SpeechTime)
+	for i := range stats.RateOverTime {
+		w := &stats.RateOverTime[i]
+		w.WordsPerMinute = perMinute(w.Words, w.End-w.Start)
+	}
+
+	var talkTotal float64
+	for _, sp := range speakers {
+		talkTotal += sp.TalkTime
+	}
+	for _, sp := range speakers {
+		sp.WordsPerMinute = perMinute(sp.Words, sp.TalkTime)
+		if talkTotal > 0 {
+			sp.TalkPercent = round2(100 * sp.TalkTime / talkTotal)
+		}
+		sp.TalkTime = round2(sp.TalkTime)
+		stats.Speakers = append(stats.Speakers, *sp)
+	}
+	sort.Slice(stats.Speakers, func(i, j int) bool { return stats.Speakers[i].TalkTime > stats.Speakers[j].TalkTime })
+	return stats
+}
+
+// addRateWords 把分段的词数计入语速窗口：有词级时间戳时按每个词的开始时间，否则按分段与窗口重叠的时长比例分配
+func addRateWords(windows []RateWindow, seg Segment, words int) {
+	if len(windows) == 0 {
+		return
+	}
+	window := func(t float64) int {
+		return min(max(int(t/statsRateWindow), 0), len(windows)-1)
+	}
+	if len(seg.Words) > 0 {
+		for _, w := range seg.Words {
+			n, _ := countWords(w.Word)
+			windows[window(w.Start)].Words += n
+		}
+		return
+	}
+	duration := seg.End - seg.Start
+	if duration <= 0 {
+		windows[window(seg.Start)].Words += words
+		return
+	}
+	// 按比例分配后四舍五入，余数计入分段开始所在的窗口，保证总数不变
+	assigned := 0
+	for i := window(seg.Start); i <= window(seg.End); i++ {
+		overlap := min(seg.End, windows[i].End) - max(seg.Start, windows[i].Start)
+		if overlap <= 0 {
+			continue
+		}
+		n := int(math.Round(float64(words) * overlap / duration))
+		windows[i].Words += n
+		assigned += n
+	}
+	windows[window(seg.Start)].Words += words - assigned
+}
+
+// perMinute 每分钟的词数，时长为 0 时返回 0
+func perMinute(words int, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return round2(float64(words) / seconds * 60)
+}
+
+// round2 保留两位小数，避免 JSON 中出现浮点误差的长尾
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// saveStats 保存为 JSON 格式的转写统计
+func saveStats(result *TranscriptionResult, outputPath string) error {
+	data, err := json.MarshalIndent(computeStats(result), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// saveStatsText 保存为便于阅读的统计摘要
+func saveStatsText(result *TranscriptionResult, outputPath string) error {
+	return os.WriteFile(outputPath, []byte(formatStats(computeStats(result))), 0644)
+}
+
+// formatStats 生成统计摘要文本
+func formatStats(stats *TranscriptStats) string {
+	var b strings.Builder
+	if stats.Language != "" {
+		fmt.Fprintf(&b, tr("语言: %s\n"), stats.Language)
+	}
+	fmt.Fprintf(&b, tr("时长: %s，说话 %s，静音 %.1f%%\n"), formatChapterTime(stats.Duration), formatChapterTime(stats.SpeechTime), stats.SilencePercent)
+	fmt.Fprintf(&b, tr("分段: %d，词数: %d，字符数: %d\n"), stats.Segments, stats.Words, stats.Characters)
+	fmt.Fprintf(&b, tr("语速: %.0f 词/分钟（按说话时间）\n"), stats.WordsPerMinute)
+	if m := stats.LongestMonologue; m != nil {
+		speaker := ""
+		if m.Speaker != "" {
+			speaker = m.Speaker + " "
+		}
+		fmt.Fprintf(&b, tr("最长连续发言: %s%s - %s（%s）\n"), speaker, formatChapterTime(m.Start), formatChapterTime(m.End), formatChapterTime(m.Duration))
+	}
+
+	if len(stats.Speakers) > 0 {
+		fmt.Fprint(&b, tr("\n说话人:\n"))
+		for _, sp := range stats.Speakers {
+			fmt.Fprintf(&b, tr("  %-10s %s（%.1f%%），%d 个分段，%d 词，%.0f 词/分钟\n"), sp.Speaker, formatChapterTime(sp.TalkTime), sp.TalkPercent, sp.Segments, sp.Words, sp.WordsPerMinute)
+		}
+	}
+
+	if len(stats.RateOverTime) > 0 {
+		fmt.Fprint(&b, tr("\n语速变化（每分钟）:\n"))
+		peak := 0.0
+		for _, w := range stats.RateOverTime {
+			peak = max(peak, w.WordsPerMinute)
+		}
+		for _, w := range stats.RateOverTime {
+			bar := ""
+			if peak > 0 {
+				bar = strings.Repeat("#", int(math.Round(w.WordsPerMinute/peak*30)))
+			}
+			fmt.Fprintf(&b, "  %8s %5.0f %s\n", formatChapterTime(w.Start), w.WordsPerMinute, bar)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// partSuffix 匹配轮转输出文件名中的 _part001 后缀
+var partSuffix = regexp.MustCompile(`_part\d+$`)
+
+// runStitch 执行 stitch 子命令：把 live 模式轮转生成的分段文件合并为一个文件
+func runStitch(args []string) {
+	fs := flag.NewFlagSet("stitch", flag.ExitOnError)
+	output := fs.String("output", "", tr("合并后的文件路径（默认为去掉 _partNNN 后缀的文件名）"))
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go stitch [options] <part files...>"))
+		fmt.Println(tr("示例: whisper-go stitch outputs/live_20240222_153020_part*.srt"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	// 支持未经 shell 展开的通配符（如 Windows）
+	var parts []string
+	for _, arg := range fs.Args() {
+		matches, err := filepath.Glob(arg)
+		if err != nil || len(matches) == 0 {
+			matches = []string{arg}
+		}
+		parts = append(parts, matches...)
+	}
+	sort.Strings(parts)
+
+	ext := strings.ToLower(filepath.Ext(parts[0]))
+	for _, part := range parts {
+		if strings.ToLower(filepath.Ext(part)) != ext {
+			fatalf(tr("只能合并同一格式的文件: %s"), part)
+		}
+	}
+
+	result := &TranscriptionResult{}
+	for _, part := range parts {
+		r, err := readPartFile(part)
+		if err != nil {
+			fatalf(tr("读取 %s 失败: %v"), part, err)
+		}
+		if result.Language == "" {
+			result.Language = r.Language
+		}
+		result.Segments = append(result.Segments, r.Segments...)
+		result.Text = joinSegmentText(result.Text, r.Text)
+	}
+	for i := range result.Segments {
+		result.Segments[i].ID = i + 1
+	}
+	if n := len(result.Segments); n > 0 {
+		result.Duration = result.Segments[n-1].End
+	}
+
+	path := *output
+	if path == "" {
+		base := strings.TrimSuffix(parts[0], filepath.Ext(parts[0]))
+		path = partSuffix.ReplaceAllString(base, "") + ext
+	}
+
+	var err error
+	switch ext {
+	case ".srt":
+		err = saveSRT(result, path)
+	case ".txt":
+		err = saveTXT(result, path)
+	case ".json":
+		err = saveJSON(result, path)
+	case ".jsonl":
+		err = saveJSONL(result, path)
+	default:
+		fatalf(tr("不支持的格式: %s（支持 .srt, .txt, .json, .jsonl）"), ext)
+	}
+	if err != nil {
+		fatalf(tr("保存失败: %v"), err)
+	}
+	fmt.Printf(tr("已合并 %d 个文件: %s\n"), len(parts), path)
+}
+
+// readPartFile 读取轮转输出的分段文件
+func readPartFile(path string) (*TranscriptionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var result TranscriptionResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	case ".jsonl":
+		segments, err := parseJSONL(data)
+		if err != nil {
+			return nil, err
+		}
+		result := &TranscriptionResult{Segments: segments}
+		rebuildResultText(result)
+		return result, nil
+	case ".srt":
+		segments, err := parseSRT(string(data))
+		if err != nil {
+			return nil, err
+		}
+		result := &TranscriptionResult{Segments: segments}
+		rebuildResultText(result)
+		return result, nil
+	default:
+		// TXT 每行一个分段，没有时间信息
+		var segments []Segment
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			segments = append(segments, Segment{Text: line})
+		}
+		return &TranscriptionResult{Text: string(data), Segments: segments}, nil
+	}
+}
+
+// parseSRT 解析 SRT 字幕
+func parseSRT(data string) ([]Segment, error) {
+	data = strings.ReplaceAll(strings.TrimPrefix(data, "\ufeff"), "\r\n", "\n")
+
+	var segments []Segment
+	for _, block := range strings.Split(strings.TrimSpace(data), "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		// 序号行可省略
+		if !strings.Contains(lines[0], "-->") {
+			lines = lines[1:]
+		}
+		start, end, ok := strings.Cut(lines[0], "-->")
+		if !ok {
+			return nil, fmt.Errorf(tr("无效的时间行: %s"), lines[0])
+		}
+		s, err := parseSRTTime(start)
+		if err != nil {
+			return nil, err
+		}
+		e, err := parseSRTTime(end)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, Segment{
+			ID:    len(segments) + 1,
+			Start: s,
+			End:   e,
+			Text:  strings.Join(lines[1:], "\n"),
+		})
+	}
+	return segments, nil
+}
+
+// parseSRTTime 解析 SRT 时间 00:01:02,345
+func parseSRTTime(s string) (float64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf(tr("无效的时间: %s"), s)
+	}
+	var total float64
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, fmt.Errorf(tr("无效的时间: %s"), s)
+		}
+		total = total*60 + v
+	}
+	return total, nil
+}
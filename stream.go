@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// streamSearchSeconds 流式切片时在切片末尾多长的范围内寻找最安静的位置作为切分点
+const streamSearchSeconds = 30
+
+// streamMaxChunkSeconds 流式切片的最大时长（16kHz 单声道约 18 MB），max_file_size_mb 设得很大时内存占用也不会随之增长
+const streamMaxChunkSeconds = 600
+
+// streamReadStep 读取缓冲区每次增长的大小，短音频不会按切片上限分配内存
+const streamReadStep = 1 << 20
+
+// streamFormat 流式提取的 PCM 格式：16kHz 单声道 16 位，与 extractAudio 一致
+var streamFormat = &wavInfo{Channels: 1, SampleRate: 16000, BitsPerSample: 16}
+
+// streamChunk 流式切出的切片，err 不为空时表示提取失败
+type streamChunk struct {
+	chunk AudioChunk
+	err   error
+}
+
+// streamChunks 启动 ffmpeg 将输入解码为 PCM 并通过管道读取，每攒够一个切片（不超过 max_file_size_mb 和 streamMaxChunkSeconds）
+// 就在末尾最安静处切出一个切片写入工作目录。
+// 不生成完整的中间 WAV，磁盘上同时最多只有两三个切片。取消 ctx 会终止 ffmpeg，未交付的切片会被删除
+func streamChunks(ctx context.Context, inputFile string, config *Config) <-chan streamChunk {
+	out := make(chan streamChunk, 1)
+	go func() {
+		defer close(out)
+		send := func(sc streamChunk) bool {
+			select {
+			case out <- sc:
+				return true
+			case <-ctx.Done():
+				if sc.chunk.Path != "" {
+					removeTemp(sc.chunk.Path)
+				}
+				return false
+			}
+		}
+
+		args := ffmpegArgs(inputFile,
+			"-vn",
+			"-acodec", "pcm_s16le",
+			"-ar", "16000",
+			"-ac", "1",
+		)
+		args = append(args, ffmpegTools.ExtractArgs...)
+		args = append(args, "-f", "s16le", "pipe:1")
+		cmd := exec.CommandContext(ctx, ffmpegTools.FFmpeg, args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			send(streamChunk{err: err})
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			send(streamChunk{err: fmt.Errorf(tr("启动 ffmpeg 失败: %w"), err)})
+			return
+		}
+
+		bytesPerSecond := streamFormat.SampleRate * streamFormat.frameSize()
+		maxBytes := min(int(config.MaxFileSizeMB*1024*1024)-44, streamMaxChunkSeconds*bytesPerSecond)
+		maxBytes -= maxBytes % streamFormat.frameSize()
+		searchBytes := min(streamSearchSeconds*bytesPerSecond, maxBytes/4)
+
+		reader := bufio.NewReaderSize(stdout, 1<<16)
+		var buf []byte
+		prefix := fmt.Sprintf("whisper_stream_%d", time.Now().UnixNano())
+		offset := 0.0
+		for index := 1; ; index++ {
+			var rerr error
+			buf, rerr = readStreamChunk(reader, buf, maxBytes)
+			eof := rerr == io.EOF
+			if rerr != nil && !eof {
+				cmd.Wait()
+				send(streamChunk{err: fmt.Errorf(tr("读取 ffmpeg 输出失败: %w"), rerr)})
+				return
+			}
+			if len(buf) < streamFormat.frameSize() {
+				break
+			}
+
+			cut := len(buf) - len(buf)%streamFormat.frameSize()
+			if !eof {
+				cut = quietestCut(buf, searchBytes)
+			}
+			path := trackTemp(filepath.Join(workingDir(), fmt.Sprintf("%s_%03d.wav", prefix, index)))
+			if err := writePCMChunk(path, buf[:cut]); err != nil {
+				removeTemp(path)
+				cmd.Wait()
+				send(streamChunk{err: err})
+				return
+			}
+			end := offset + float64(cut)/float64(bytesPerSecond)
+			logDebug(tr("流式切片 %d: %.2f - %.2f 秒\n"), index, offset, end)
+			if !send(streamChunk{chunk: AudioChunk{Path: path, StartOffset: offset, EndOffset: end}}) {
+				cmd.Wait()
+				return
+			}
+			offset = end
+			buf = append(buf[:0], buf[cut:]...)
+			if eof {
+				break
+			}
+		}
+
+		if err := cmd.Wait(); err != nil {
+			send(streamChunk{err: withExitCode(exitBadInput, fmt.Errorf(tr("ffmpeg 提取音频失败: %w: %s"), err, lastLine(stderr.String())))})
+		}
+	}()
+	return out
+}
+
+// readStreamChunk 从 r 读取数据追加到 buf，直到 buf 达到 limit 字节或读取出错（输入结束时返回 io.EOF）。
+// 缓冲区按 streamReadStep 逐步增长，最多为 limit
+func readStreamChunk(r io.Reader, buf []byte, limit int) ([]byte, error) {
+	for len(buf) < limit {
+		if len(buf) == cap(buf) {
+			buf = slices.Grow(buf, min(streamReadStep, limit-len(buf)))
+		}
+		n, err := r.Read(buf[len(buf):min(cap(buf), limit)])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+// quietestCut 在缓冲区末尾 searchBytes 范围内按 silenceWindowSeconds 窗口计算 RMS 能量，返回最安静窗口的起始位置
+func quietestCut(buf []byte, searchBytes int) int {
+	frameSize := streamFormat.frameSize()
+	window := int(float64(streamFormat.SampleRate)*silenceWindowSeconds) * frameSize
+	start := len(buf) - searchBytes
+	start -= start % frameSize
+
+	best, bestEnergy := len(buf)-len(buf)%frameSize, math.Inf(1)
+	for pos := start; pos+window <= len(buf); pos += window {
+		var sum float64
+		for i := pos; i < pos+window; i += frameSize {
+			v := sampleValue(buf[i:i+frameSize], streamFormat.BitsPerSample)
+			sum += v * v
+		}
+		if sum <= bestEnergy {
+			best, bestEnergy = pos, sum
+		}
+	}
+	return best
+}
+
+// writePCMChunk 将 PCM 数据写为 WAV 文件
+func writePCMChunk(path string, pcm []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeWAVHeader(f, streamFormat, int64(len(pcm))); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(pcm); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// transcribeStream 流式提取并转写：切片一边生成一边转写，转写完成后立即删除，返回各切片结果和切片信息
+func transcribeStream(client *openai.Client, inputFile string, config *Config, sink SegmentSink, resume *resumeStore, verbose bool) ([]*TranscriptionResult, []AudioChunk, error) {
+	ctx, cancel := context.WithCancel(config.jobContext())
+	defer cancel()
+	stream := streamChunks(ctx, inputFile, config)
+
+	var results []*TranscriptionResult
+	var chunks []AudioChunk
+	fail := func(err error) ([]*TranscriptionResult, []AudioChunk, error) {
+		// 终止 ffmpeg 并删除已切出但未转写的切片
+		cancel()
+		for sc := range stream {
+			if sc.chunk.Path != "" {
+				removeTemp(sc.chunk.Path)
+			}
+		}
+		return nil, nil, err
+	}
+
+	for sc := range stream {
+		if sc.err != nil {
+			return fail(sc.err)
+		}
+		chunk := sc.chunk
+		i := len(chunks)
+		logDebug(tr("\n转写进度: 第 %d 片\n"), i+1)
+		config.reportProgress(stageTranscribe, i, 0)
+
+		result := resume.load(chunk)
+		if result != nil {
+			logDebug(tr("使用上次保存的切片结果"))
+		} else {
+			var err error
+			result, err = transcribeWithSizeGuard(client, chunk.Path, config, verbose)
+			if err != nil {
+				removeTemp(chunk.Path)
+				return fail(fmt.Errorf(tr("切片 %d 转写失败: %w"), i+1, err))
+			}
+			resume.save(chunk, result)
+		}
+		removeTemp(chunk.Path)
+
+		results = append(results, result)
+		chunks = append(chunks, chunk)
+		emitSegments(client, sink, result.Segments, chunk.StartOffset, config)
+	}
+	if len(chunks) == 0 {
+		return nil, nil, withExitCode(exitBadInput, fmt.Errorf(tr("未能从 %s 提取到音频"), inputFile))
+	}
+	config.reportProgress(stageTranscribe, len(chunks), len(chunks))
+	return results, chunks, nil
+}
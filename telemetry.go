@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// telemetryServiceName 未设置 OTEL_SERVICE_NAME 时上报的服务名
+const telemetryServiceName = "whisper-go"
+
+// telemetryFlushTimeout 退出时导出剩余 span 和指标的最长等待时间
+const telemetryFlushTimeout = 5 * time.Second
+
+// 失败计数的操作类型
+const (
+	operationExtract = "extract"
+	operationSplit   = "split"
+	operationAPI     = "api"
+	operationFile    = "file"
+)
+
+// telemetry 追踪和指标。未启用 OTLP 导出时使用 OpenTelemetry 默认的空实现，调用开销可以忽略
+var telemetry struct {
+	tracer     trace.Tracer
+	minutes    metric.Float64Counter
+	failures   metric.Int64Counter
+	apiLatency metric.Float64Histogram
+	shutdown   []func(context.Context) error
+	once       sync.Once
+}
+
+// initTelemetry 按标准 OTEL 环境变量启用 OTLP 导出：设置了 OTEL_EXPORTER_OTLP_ENDPOINT（或 TRACES/METRICS 专用地址）时启用，
+// OTEL_SDK_DISABLED=true 时禁用，OTEL_TRACES_EXPORTER/OTEL_METRICS_EXPORTER=none 时只关闭对应部分；
+// 协议由 OTEL_EXPORTER_OTLP_PROTOCOL 选择 grpc 或 http/protobuf（默认）。请求头、超时和导出间隔等由 SDK 读取对应环境变量
+func initTelemetry() {
+	defer initInstruments()
+	if !telemetryEnabled() {
+		return
+	}
+
+	ctx := context.Background()
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(telemetryServiceName), semconv.ServiceVersion(currentVersion())),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		logWarn(tr("OpenTelemetry 资源信息不完整: %v"), err)
+	}
+
+	if otelExporterEnabled("OTEL_TRACES_EXPORTER") {
+		exporter, err := newTraceExporter(ctx)
+		if err != nil {
+			logWarn(tr("创建 OTLP 追踪导出器失败: %v"), err)
+		} else {
+			provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+			otel.SetTracerProvider(provider)
+			telemetry.shutdown = append(telemetry.shutdown, provider.Shutdown)
+		}
+	}
+
+	if otelExporterEnabled("OTEL_METRICS_EXPORTER") {
+		exporter, err := newMetricExporter(ctx)
+		if err != nil {
+			logWarn(tr("创建 OTLP 指标导出器失败: %v"), err)
+		} else {
+			provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)), sdkmetric.WithResource(res))
+			otel.SetMeterProvider(provider)
+			telemetry.shutdown = append(telemetry.shutdown, provider.Shutdown)
+		}
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logDebug(tr("OpenTelemetry 导出失败: %v"), err)
+	}))
+	logDebug(tr("已启用 OpenTelemetry 导出"))
+}
+
+// telemetryEnabled 是否设置了 OTLP 导出地址且未通过 OTEL_SDK_DISABLED 禁用
+func telemetryEnabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	for _, key := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"} {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// otelExporterEnabled OTEL_TRACES_EXPORTER/OTEL_METRICS_EXPORTER 未设置或为 otlp 时启用，为 none 时关闭
+func otelExporterEnabled(key string) bool {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+	return value == "" || strings.Contains(value, "otlp")
+}
+
+// otlpProtocol 读取追踪或指标的 OTLP 协议，专用变量优先，默认为 http/protobuf
+func otlpProtocol(signal string) string {
+	if protocol := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_PROTOCOL"); protocol != "" {
+		return protocol
+	}
+	if protocol := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); protocol != "" {
+		return protocol
+	}
+	return "http/protobuf"
+}
+
+// newTraceExporter 按协议创建追踪导出器，地址、请求头等由导出器从环境变量读取
+func newTraceExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	if otlpProtocol("TRACES") == "grpc" {
+		return otlptracegrpc.New(ctx)
+	}
+	return otlptracehttp.New(ctx)
+}
+
+// newMetricExporter 按协议创建指标导出器，地址、请求头等由导出器从环境变量读取
+func newMetricExporter(ctx context.Context) (sdkmetric.Exporter, error) {
+	if otlpProtocol("METRICS") == "grpc" {
+		return otlpmetricgrpc.New(ctx)
+	}
+	return otlpmetrichttp.New(ctx)
+}
+
+// initInstruments 创建 tracer 和指标，未启用导出时得到的是空实现。指标说明是上报给后端的元数据，不随界面语言变化
+func initInstruments() {
+	telemetry.tracer = otel.Tracer(telemetryServiceName)
+	meter := otel.Meter(telemetryServiceName)
+	telemetry.minutes, _ = meter.Float64Counter("whisper.audio.minutes",
+		metric.WithDescription("Audio minutes transcribed"), metric.WithUnit("min"))
+	telemetry.failures, _ = meter.Int64Counter("whisper.failures",
+		metric.WithDescription("Failures by operation (extract, split, api, file)"))
+	telemetry.apiLatency, _ = meter.Float64Histogram("whisper.api.latency",
+		metric.WithDescription("Transcription API call latency, including retries"), metric.WithUnit("s"))
+}
+
+// shutdownTelemetry 导出剩余的 span 和指标，可重复调用；os.Exit 前需要显式调用
+func shutdownTelemetry() {
+	telemetry.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryFlushTimeout)
+		defer cancel()
+		for _, shutdown := range telemetry.shutdown {
+			if err := shutdown(ctx); err != nil {
+				logDebug(tr("OpenTelemetry 导出失败: %v"), err)
+			}
+		}
+	})
+}
+
+// startSpan 以配置中的任务上下文为父开始一个 span，返回的上下文同样随任务取消
+func startSpan(config *Config, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return telemetry.tracer.Start(config.jobContext(), name, trace.WithAttributes(attrs...))
+}
+
+// endSpan 结束 span；err 不为空时记录错误并按操作类型累计失败次数
+func endSpan(ctx context.Context, span trace.Span, operation string, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		telemetry.failures.Add(ctx, 1, metric.WithAttributes(attribute.String("operation", operation)))
+	}
+	span.End()
+}
+
+// recordAPICall 记录一次转写接口调用的耗时
+func recordAPICall(ctx context.Context, config *Config, elapsed time.Duration, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	telemetry.apiLatency.Record(ctx, elapsed.Seconds(), metric.WithAttributes(
+		attribute.String("provider", config.Provider),
+		attribute.String("model", config.Model),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// recordTranscribedMinutes 累计已转写的音频时长
+func recordTranscribedMinutes(ctx context.Context, config *Config, seconds float64) {
+	if seconds <= 0 {
+		return
+	}
+	telemetry.minutes.Add(ctx, seconds/60, metric.WithAttributes(
+		attribute.String("provider", config.Provider),
+		attribute.String("model", config.Model),
+	))
+}
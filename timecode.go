@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultTimecodeFPS 未设置 timecode_fps 时 subcap 输出使用的帧率
+const defaultTimecodeFPS = "25"
+
+// frameRate SMPTE 时间码帧率：num/den 为实际帧率，nominal 为时间码中每秒的帧号数，drop 为丢帧时间码
+type frameRate struct {
+	num, den int
+	nominal  int
+	drop     bool
+}
+
+// frameRates timecode_fps 可选的帧率，29.97df、59.94df 为丢帧时间码（帧号与秒之间用分号分隔）
+var frameRates = map[string]frameRate{
+	"23.976":  {24000, 1001, 24, false},
+	"24":      {24, 1, 24, false},
+	"25":      {25, 1, 25, false},
+	"29.97":   {30000, 1001, 30, false},
+	"29.97df": {30000, 1001, 30, true},
+	"30":      {30, 1, 30, false},
+	"50":      {50, 1, 50, false},
+	"59.94":   {60000, 1001, 60, false},
+	"59.94df": {60000, 1001, 60, true},
+	"60":      {60, 1, 60, false},
+}
+
+// offsetPart 时间偏移中的一项（时、分或秒）
+var offsetPart = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// validateTimecode 校验 time_offset 和 timecode_fps，在 applyDefaults 和命令行参数覆盖后调用
+func (c *Config) validateTimecode() error {
+	if _, err := parseTimeOffset(c.TimeOffset); err != nil {
+		return err
+	}
+	if _, ok := frameRates[c.TimecodeFPS]; c.TimecodeFPS != "" && !ok {
+		return fmt.Errorf(tr("无效的 timecode_fps 配置: %s（可选 23.976, 24, 25, 29.97, 29.97df, 30, 50, 59.94, 59.94df, 60）"), c.TimecodeFPS)
+	}
+	return nil
+}
+
+// parseTimeOffset 解析时间偏移：秒数（如 5.5、-2）或 [+|-]HH:MM:SS(.fff)、MM:SS(.fff)，空字符串为 0
+func parseTimeOffset(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	invalid := fmt.Errorf(tr("无效的时间偏移: %s（格式如 +00:00:05.5、-2.5）"), s)
+	sign := 1.0
+	value := s
+	switch value[0] {
+	case '-':
+		sign = -1
+		value = value[1:]
+	case '+':
+		value = value[1:]
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, invalid
+	}
+	var seconds float64
+	for i, part := range parts {
+		// 只有最后一项（秒）可以带小数，分和秒不超过 59
+		if !offsetPart.MatchString(part) || (i < len(parts)-1 && strings.Contains(part, ".")) {
+			return 0, invalid
+		}
+		n, _ := strconv.ParseFloat(part, 64)
+		if i > 0 && n >= 60 {
+			return 0, invalid
+		}
+		seconds = seconds*60 + n
+	}
+	return sign * seconds, nil
+}
+
+// shiftedResult 返回按 time_offset 平移时间戳（小于 0 的截为 0）、设置了 timecode_fps 时再对齐到最近帧的副本，
+// 供字幕和时间码类输出使用；没有偏移和帧对齐时直接返回 result。result 本身不变，与原始媒体对应的输出仍使用原始时间
+func shiftedResult(result *TranscriptionResult, config *Config) *TranscriptionResult {
+	offset, _ := parseTimeOffset(config.TimeOffset)
+	rate, snap := frameRates[config.TimecodeFPS]
+	if offset == 0 && !snap {
+		return result
+	}
+	adjust := func(t float64) float64 {
+		t = max(t+offset, 0)
+		if snap {
+			t = rate.seconds(rate.frames(t))
+		}
+		return t
+	}
+
+	shifted := *result
+	if result.Duration > 0 {
+		shifted.Duration = adjust(result.Duration)
+	}
+	shifted.Segments = make([]Segment, len(result.Segments))
+	for i, seg := range result.Segments {
+		seg.Start, seg.End = adjust(seg.Start), adjust(seg.End)
+		seg.Words = slices.Clone(seg.Words)
+		for j := range seg.Words {
+			seg.Words[j].Start, seg.Words[j].End = adjust(seg.Words[j].Start), adjust(seg.Words[j].End)
+		}
+		shifted.Segments[i] = seg
+	}
+	shifted.Filtered = slices.Clone(result.Filtered)
+	for i := range shifted.Filtered {
+		shifted.Filtered[i].Start, shifted.Filtered[i].End = adjust(shifted.Filtered[i].Start), adjust(shifted.Filtered[i].End)
+	}
+	shifted.Chapters = slices.Clone(result.Chapters)
+	for i := range shifted.Chapters {
+		shifted.Chapters[i].Start, shifted.Chapters[i].End = adjust(shifted.Chapters[i].Start), adjust(shifted.Chapters[i].End)
+	}
+	shifted.Backends = slices.Clone(result.Backends)
+	for i := range shifted.Backends {
+		shifted.Backends[i].Start, shifted.Backends[i].End = adjust(shifted.Backends[i].Start), adjust(shifted.Backends[i].End)
+	}
+	shifted.Redactions = slices.Clone(result.Redactions)
+	for i := range shifted.Redactions {
+		if shifted.Redactions[i].Segment >= 0 {
+			shifted.Redactions[i].Start, shifted.Redactions[i].End = adjust(shifted.Redactions[i].Start), adjust(shifted.Redactions[i].End)
+		}
+	}
+	if result.Keywords != nil {
+		keywords := *result.Keywords
+		for _, list := range []*[]KeywordMention{&keywords.Keywords, &keywords.Entities} {
+			*list = slices.Clone(*list)
+			for i := range *list {
+				(*list)[i].FirstMention = adjust((*list)[i].FirstMention)
+			}
+		}
+		shifted.Keywords = &keywords
+	}
+	if result.Sentiment != nil {
+		sentiment := *result.Sentiment
+		sentiment.Segments = slices.Clone(sentiment.Segments)
+		for i := range sentiment.Segments {
+			sentiment.Segments[i].Start, sentiment.Segments[i].End = adjust(sentiment.Segments[i].Start), adjust(sentiment.Segments[i].End)
+		}
+		shifted.Sentiment = &sentiment
+	}
+	return &shifted
+}
+
+// frames 时间对应的帧序号（四舍五入到最近的帧）
+func (f frameRate) frames(seconds float64) int64 {
+	return int64(math.Round(seconds * float64(f.num) / float64(f.den)))
+}
+
+// seconds 帧序号对应的时间
+func (f frameRate) seconds(frames int64) float64 {
+	return float64(frames) * float64(f.den) / float64(f.num)
+}
+
+// timecode 格式化为 SMPTE 时间码 HH:MM:SS:FF。丢帧时间码除每 10 分钟的整分钟外，
+// 每分钟开始时跳过 2 个（59.94df 为 4 个）帧号，使时间码与实际时间保持一致
+func (f frameRate) timecode(seconds float64) string {
+	n := f.frames(max(seconds, 0))
+	sep := ":"
+	if f.drop {
+		dropped := int64(f.nominal / 15)
+		perMinute := int64(f.nominal*60) - dropped
+		perTenMinutes := int64(f.nominal*600) - 9*dropped
+		tens, rest := n/perTenMinutes, n%perTenMinutes
+		n += 9 * dropped * tens
+		if rest > dropped {
+			n += dropped * ((rest - dropped) / perMinute)
+		}
+		sep = ";"
+	}
+	nominal := int64(f.nominal)
+	secs := n / nominal
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", secs/3600, secs/60%60, secs%60, sep, n%nominal)
+}
+
+// saveSubCap 保存为 Avid SubCap 字幕（按 timecode_fps 输出 SMPTE 时间码，默认 25 帧），可导入 Avid Media Composer 等广电剪辑系统
+func saveSubCap(result *TranscriptionResult, config *Config, outputPath string) error {
+	rate, ok := frameRates[config.TimecodeFPS]
+	if !ok {
+		rate = frameRates[defaultTimecodeFPS]
+	}
+	var b strings.Builder
+	b.WriteString("<begin subtitles>\n\n")
+	for _, seg := range result.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s\n%s\n\n", rate.timecode(seg.Start), rate.timecode(seg.End), text)
+	}
+	b.WriteString("<end subtitles>\n")
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
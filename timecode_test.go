@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFrameRateTimecode(t *testing.T) {
+	tests := []struct {
+		fps    string
+		frames int64
+		want   string
+	}{
+		{"25", 0, "00:00:00:00"},
+		{"25", 24, "00:00:00:24"},
+		{"25", 25, "00:00:01:00"},
+		{"25", 25 * 3661, "01:01:01:00"},
+		{"24", 24*60 + 5, "00:01:00:05"},
+		{"23.976", 24 * 60, "00:01:00:00"},
+		{"29.97", 30 * 60, "00:01:00:00"},
+		// 丢帧：每分钟开始时跳过 ;00 和 ;01，整 10 分钟不跳
+		{"29.97df", 1799, "00:00:59;29"},
+		{"29.97df", 1800, "00:01:00;02"},
+		{"29.97df", 3597, "00:01:59;29"},
+		{"29.97df", 3598, "00:02:00;02"},
+		{"29.97df", 17981, "00:09:59;29"},
+		{"29.97df", 17982, "00:10:00;00"},
+		{"29.97df", 107892, "01:00:00;00"},
+		{"59.94df", 3600, "00:01:00;04"},
+		{"59.94df", 35964, "00:10:00;00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fps+"/"+tt.want, func(t *testing.T) {
+			rate := frameRates[tt.fps]
+			if got := rate.timecode(rate.seconds(tt.frames)); got != tt.want {
+				t.Errorf("timecode(frame %d) = %s, want %s", tt.frames, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeOffset(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"5.5", 5.5, false},
+		{"-2", -2, false},
+		{"+00:00:05.5", 5.5, false},
+		{"01:30", 90, false},
+		{"-01:00:00", -3600, false},
+		{"00:60", 0, true},
+		{"1.5:00", 0, true},
+		{"1:2:3:4", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseTimeOffset(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimeOffset(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseTimeOffset(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftedResult(t *testing.T) {
+	result := &TranscriptionResult{
+		Duration: 10,
+		Segments: []Segment{
+			{Start: 0.5, End: 2, Text: "a", Words: []Word{{Word: "a", Start: 0.5, End: 2}}},
+			{Start: 3.01, End: 4.02, Text: "b"},
+		},
+		Redactions: []Redaction{{Segment: 0, Start: 0.5, End: 2}, {Segment: -1}},
+	}
+	tests := []struct {
+		name      string
+		config    Config
+		wantSame  bool
+		wantStart []float64
+		wantEnd   []float64
+	}{
+		{"没有偏移和帧对齐时返回原结果", Config{}, true, []float64{0.5, 3.01}, []float64{2, 4.02}},
+		{"正偏移", Config{TimeOffset: "+00:00:10"}, false, []float64{10.5, 13.01}, []float64{12, 14.02}},
+		{"负偏移截为 0", Config{TimeOffset: "-1"}, false, []float64{0, 2.01}, []float64{1, 3.02}},
+		{"对齐到 25 帧", Config{TimecodeFPS: "25"}, false, []float64{0.52, 3.0}, []float64{2, 4.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shiftedResult(result, &tt.config)
+			if (got == result) != tt.wantSame {
+				t.Fatalf("shiftedResult returned the original result: %v, want %v", got == result, tt.wantSame)
+			}
+			for i, seg := range got.Segments {
+				if math.Abs(seg.Start-tt.wantStart[i]) > 1e-9 || math.Abs(seg.End-tt.wantEnd[i]) > 1e-9 {
+					t.Errorf("segment %d = %v-%v, want %v-%v", i, seg.Start, seg.End, tt.wantStart[i], tt.wantEnd[i])
+				}
+			}
+			if w := got.Segments[0].Words[0]; w.Start != got.Segments[0].Start || w.End != got.Segments[0].End {
+				t.Errorf("word = %v-%v, want the segment times", w.Start, w.End)
+			}
+			if r := got.Redactions[0]; r.Start != got.Segments[0].Start || r.End != got.Segments[0].End {
+				t.Errorf("redaction = %v-%v, want the segment times", r.Start, r.End)
+			}
+		})
+	}
+	// 原结果不变
+	if result.Segments[0].Start != 0.5 || result.Segments[0].Words[0].Start != 0.5 || result.Redactions[0].Start != 0.5 {
+		t.Errorf("shiftedResult modified the original result: %+v", result.Segments[0])
+	}
+}
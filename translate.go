@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// translateBatchSize 每次请求翻译的分段数，过大时模型容易合并或漏掉行
+const translateBatchSize = 40
+
+// translatePrompt 字幕翻译的系统提示词，%s 为目标语言
+const translatePrompt = `You translate subtitle lines into the language with code %q.
+The user sends a JSON array of strings. Reply with a JSON array of exactly the same length,
+containing the translation of each line in the same order. Do not merge, split or drop lines,
+and keep names and technical terms consistent. Reply with JSON only.`
+
+// translateResult 用对话模型将分段逐批翻译为目标语言，返回时间轴不变的新结果
+func translateResult(client *openai.Client, result *TranscriptionResult, model, language string) (*TranscriptionResult, error) {
+	lines := make([]string, len(result.Segments))
+	for start := 0; start < len(lines); start += translateBatchSize {
+		end := min(start+translateBatchSize, len(lines))
+		batch := make([]string, 0, end-start)
+		for _, seg := range result.Segments[start:end] {
+			batch = append(batch, strings.TrimSpace(seg.Text))
+		}
+
+		out, err := translateLines(client, model, language, batch)
+		if err != nil {
+			return nil, fmt.Errorf(tr("翻译第 %d-%d 个分段失败: %w"), start+1, end, err)
+		}
+		copy(lines[start:], out)
+	}
+	return withTranslation(result, language, lines), nil
+}
+
+// withTranslation 用译文替换各分段文本，返回时间轴不变的新结果。译文分段不保留原文的词级时间戳，
+// 否则 ass 卡拉 OK、增强 LRC 等词级输出会显示原文
+func withTranslation(result *TranscriptionResult, language string, lines []string) *TranscriptionResult {
+	translated := *result
+	translated.Language = language
+	translated.Segments = make([]Segment, len(result.Segments))
+	translated.Filtered = nil
+	translated.HookFailures = nil
+	copy(translated.Segments, result.Segments)
+
+	var text string
+	for i, line := range lines {
+		translated.Segments[i].Text = line
+		translated.Segments[i].Words = nil
+		text = joinSegmentText(text, line)
+	}
+	translated.Text = text
+	return &translated
+}
+
+// translateLines 翻译一批字幕行，返回的行数与输入一致
+func translateLines(client *openai.Client, model, language string, lines []string) ([]string, error) {
+	input, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: fmt.Sprintf(translatePrompt, language)},
+			{Role: openai.ChatMessageRoleUser, Content: string(input)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New(tr("模型没有返回结果"))
+	}
+
+	var out []string
+	if err := parseChatJSON(resp.Choices[0].Message.Content, &out); err != nil {
+		return nil, fmt.Errorf(tr("解析模型返回的译文失败: %w"), err)
+	}
+	if len(out) != len(lines) {
+		return nil, fmt.Errorf(tr("模型返回 %d 行译文，应为 %d 行"), len(out), len(lines))
+	}
+	return out, nil
+}
+
+// parseChatJSON 解析对话模型返回的 JSON 到 v。部分模型会在 JSON 外包裹说明文字或代码块，
+// 解析前按 v 的类型截取最外层的数组（[...]）或对象（{...}）
+func parseChatJSON(content string, v any) error {
+	start, end := "{", "}"
+	if t := reflect.TypeOf(v); t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Slice {
+		start, end = "[", "]"
+	}
+	if i, j := strings.Index(content, start), strings.LastIndex(content, end); i >= 0 && j > i {
+		content = content[i : j+1]
+	}
+	return json.Unmarshal([]byte(content), v)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// translateMultiPrompt 一次翻译为多种语言的系统提示词，%s 为目标语言代码的 JSON 数组
+const translateMultiPrompt = `You translate subtitle lines into each of the languages with codes %s.
+The user sends a JSON array of strings. Reply with a JSON object that has one key per language code,
+each mapping to an array of exactly the same length as the input, containing the translation of each
+line in the same order. Do not merge, split or drop lines, and keep names and technical terms
+consistent across lines and languages. Reply with JSON only.`
+
+// translateLanguagePattern 目标语言代码（用于文件名），如 en、zh-TW、pt_BR
+var translateLanguagePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// setTranslateTo 设置 translate_to：去掉空白和重复的语言，检查语言代码和翻译模型
+func (c *Config) setTranslateTo(languages []string) error {
+	var list []string
+	for _, language := range languages {
+		language = strings.TrimSpace(language)
+		if language == "" || slices.Contains(list, language) {
+			continue
+		}
+		if !translateLanguagePattern.MatchString(language) {
+			return fmt.Errorf(tr("无效的目标语言: %q（应为 en、zh-TW 这样的语言代码）"), language)
+		}
+		list = append(list, language)
+	}
+	if len(list) > 0 && c.TranslateModel == "" {
+		return errors.New(tr("设置 translate_to 时需要同时设置 translate_model（翻译用的对话模型）"))
+	}
+	c.TranslateTo = list
+	return nil
+}
+
+// translationFormats 译文输出的格式：输出格式中可以输出译文的字幕格式（srt、ass、lrc），都没有时输出 SRT
+func translationFormats(formatList []string) []string {
+	var formats []string
+	for _, format := range formatList {
+		if outputFormats[format].subtitle && !slices.Contains(formats, format) {
+			formats = append(formats, format)
+		}
+	}
+	if len(formats) == 0 {
+		formats = []string{"srt"}
+	}
+	return formats
+}
+
+// translationExtension 译文文件的扩展名，如 en.srt
+func translationExtension(language, format string) string {
+	return language + "." + outputFormats[format].ext
+}
+
+// translationPath 译文文件路径：与已保存的输出文件同名（时间戳相同），如 talk_20240101_120000.en.srt；
+// 没有其他输出文件时按输入文件生成
+func translationPath(inputFile, outputDir string, outputFiles []string, ext string) string {
+	if len(outputFiles) > 0 {
+		if known := knownExtension(outputFiles[0]); known != "" {
+			return strings.TrimSuffix(outputFiles[0], known) + ext
+		}
+	}
+	return generateOutputPath(inputFile, outputDir, ext)
+}
+
+// saveTranslations 开启 translate_to 时把结果翻译为各目标语言，每种语言按 translationFormats 各保存一个字幕文件。
+// 目标语言与转写语言相同时直接保存原文；某种语言翻译失败时只跳过该语言，计入失败的输出数
+func saveTranslations(client *openai.Client, result *TranscriptionResult, inputFile string, config *Config, formatList, outputFiles []string) []string {
+	if len(config.TranslateTo) == 0 || result.NoSpeech || len(result.Segments) == 0 {
+		return nil
+	}
+	formats := translationFormats(formatList)
+
+	translations := map[string]*TranscriptionResult{}
+	var pending []string
+	for _, language := range config.TranslateTo {
+		if !config.AutoDetect && strings.EqualFold(language, config.Language) {
+			translations[language] = result
+			continue
+		}
+		pending = append(pending, language)
+	}
+	if len(pending) > 0 {
+		logInfo(tr("正在翻译为 %s（%d 个分段）\n"), strings.Join(pending, ", "), len(result.Segments))
+		translated, errs := translateMulti(client, result, config.TranslateModel, pending, config.TranslateBatch)
+		for language, t := range translated {
+			translations[language] = t
+		}
+		for _, language := range pending {
+			if err := errs[language]; err != nil {
+				logError(tr("翻译为 %s 失败: %v"), language, err)
+				result.FailedOutputs += len(formats)
+			}
+		}
+	}
+
+	outputDir := organizedOutputDir(config.OutputDir, inputFile, config.Organize, time.Now())
+	var saved []string
+	for _, language := range config.TranslateTo {
+		translated := translations[language]
+		if translated == nil {
+			continue
+		}
+		translated = shiftedResult(translated, config)
+		for _, format := range formats {
+			outputPath := translationPath(inputFile, outputDir, outputFiles, translationExtension(language, format))
+			if err := outputFormats[format].save(translated, inputFile, config, outputPath); err != nil {
+				logError(tr("保存 %s 译文失败: %v"), language, err)
+				result.FailedOutputs++
+				continue
+			}
+			saved = append(saved, outputPath)
+			logDebug(tr("已保存: %s\n"), outputPath)
+			if failure := runPostWriteHook(config, format, outputPath); failure != nil {
+				result.HookFailures = append(result.HookFailures, *failure)
+			}
+		}
+	}
+	return saved
+}
+
+// translateMulti 按 translate_batch 个分段一组翻译为全部目标语言：每组只发一次请求，同时返回各语言的译文；
+// 某种语言的译文缺失或行数不对时，该组单独为这种语言重新请求。返回翻译成功的结果和各语言的错误
+func translateMulti(client *openai.Client, result *TranscriptionResult, model string, languages []string, batchSize int) (map[string]*TranscriptionResult, map[string]error) {
+	lines := map[string][]string{}
+	errs := map[string]error{}
+	for _, language := range languages {
+		lines[language] = make([]string, len(result.Segments))
+	}
+
+	for start := 0; start < len(result.Segments); start += batchSize {
+		end := min(start+batchSize, len(result.Segments))
+		batch := make([]string, 0, end-start)
+		for _, seg := range result.Segments[start:end] {
+			batch = append(batch, strings.TrimSpace(seg.Text))
+		}
+
+		var active []string
+		for _, language := range languages {
+			if errs[language] == nil {
+				active = append(active, language)
+			}
+		}
+		if len(active) == 0 {
+			break
+		}
+		var multi map[string][]string
+		if len(active) > 1 {
+			var err error
+			if multi, err = translateLinesMulti(client, model, active, batch); err != nil {
+				logDebug(tr("第 %d-%d 个分段的多语言翻译失败，改为逐个语言翻译: %v\n"), start+1, end, err)
+			}
+		}
+		for _, language := range active {
+			out, ok := multi[language]
+			if !ok || len(out) != len(batch) {
+				var err error
+				if out, err = translateLines(client, model, language, batch); err != nil {
+					errs[language] = fmt.Errorf(tr("翻译第 %d-%d 个分段失败: %w"), start+1, end, err)
+					continue
+				}
+			}
+			copy(lines[language][start:], out)
+		}
+	}
+
+	translations := map[string]*TranscriptionResult{}
+	for _, language := range languages {
+		if errs[language] == nil {
+			translations[language] = withTranslation(result, language, lines[language])
+		}
+	}
+	return translations, errs
+}
+
+// translateLinesMulti 一次请求把一批字幕行翻译为多种语言，返回各语言的译文（行数由调用方检查）
+func translateLinesMulti(client *openai.Client, model string, languages, lines []string) (map[string][]string, error) {
+	input, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := json.Marshal(languages)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: fmt.Sprintf(translateMultiPrompt, codes)},
+			{Role: openai.ChatMessageRoleUser, Content: string(input)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New(tr("模型没有返回结果"))
+	}
+
+	var out map[string][]string
+	if err := parseChatJSON(resp.Choices[0].Message.Content, &out); err != nil {
+		return nil, fmt.Errorf(tr("解析模型返回的译文失败: %w"), err)
+	}
+	return out, nil
+}
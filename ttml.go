@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// TTML 样式和区域的默认值：白色文字、半透明黑底，位于画面底部居中（安全区内）
+const (
+	defaultTTMLFontFamily      = "proportionalSansSerif"
+	defaultTTMLFontSize        = "100%"
+	defaultTTMLColor           = "white"
+	defaultTTMLBackgroundColor = "#000000b2"
+	defaultTTMLTextAlign       = "center"
+	defaultTTMLOrigin          = "10% 80%"
+	defaultTTMLExtent          = "80% 15%"
+)
+
+// ttmlLengthPair TTML 的 origin/extent 取值：两个百分比或像素长度，如 10% 80%
+var ttmlLengthPair = regexp.MustCompile(`^\d+(\.\d+)?(%|px) \d+(\.\d+)?(%|px)$`)
+
+// applyTTMLDefaults 填充 ttml 格式的样式和区域默认值并检查取值
+func (c *Config) applyTTMLDefaults() error {
+	defaults := []struct {
+		value *string
+		def   string
+	}{
+		{&c.TTMLFontFamily, defaultTTMLFontFamily},
+		{&c.TTMLFontSize, defaultTTMLFontSize},
+		{&c.TTMLColor, defaultTTMLColor},
+		{&c.TTMLBackgroundColor, defaultTTMLBackgroundColor},
+		{&c.TTMLTextAlign, defaultTTMLTextAlign},
+		{&c.TTMLOrigin, defaultTTMLOrigin},
+		{&c.TTMLExtent, defaultTTMLExtent},
+	}
+	for _, d := range defaults {
+		if *d.value == "" {
+			*d.value = d.def
+		}
+	}
+	switch c.TTMLTextAlign {
+	case "left", "center", "right", "start", "end":
+	default:
+		return fmt.Errorf(tr("无效的 ttml_text_align 配置: %s（可选 left, center, right, start, end）"), c.TTMLTextAlign)
+	}
+	if !ttmlLengthPair.MatchString(c.TTMLOrigin) {
+		return fmt.Errorf(tr("无效的 ttml_origin 配置: %s（应为两个百分比或像素长度，如 10%% 80%%）"), c.TTMLOrigin)
+	}
+	if !ttmlLengthPair.MatchString(c.TTMLExtent) {
+		return fmt.Errorf(tr("无效的 ttml_extent 配置: %s（应为两个百分比或像素长度，如 80%% 15%%）"), c.TTMLExtent)
+	}
+	return nil
+}
+
+// formatTTMLTime 格式化 TTML 时钟时间表达式（HH:MM:SS.mmm，timeBase 为 media）
+func formatTTMLTime(seconds float64) string {
+	ms := int64(max(seconds, 0)*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// ttmlEscape 转义 XML 文本和属性值
+func ttmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// saveTTML 保存为 TTML（DFXP）字幕：一个样式和一个区域，每个分段一个 <p>，分段内换行写为 <br/>
+func saveTTML(result *TranscriptionResult, config *Config, outputPath string) error {
+	lang := languageCode(result.Language)
+	if lang == "" {
+		lang = "und"
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, `<tt xmlns="http://www.w3.org/ns/ttml" xmlns:tts="http://www.w3.org/ns/ttml#styling" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" ttp:timeBase="media" xml:lang="%s">`+"\n", ttmlEscape(lang))
+	b.WriteString("  <head>\n    <styling>\n")
+	fmt.Fprintf(&b, `      <style xml:id="s1" tts:fontFamily="%s" tts:fontSize="%s" tts:color="%s" tts:backgroundColor="%s" tts:textAlign="%s"/>`+"\n",
+		ttmlEscape(config.TTMLFontFamily), ttmlEscape(config.TTMLFontSize), ttmlEscape(config.TTMLColor), ttmlEscape(config.TTMLBackgroundColor), config.TTMLTextAlign)
+	b.WriteString("    </styling>\n    <layout>\n")
+	fmt.Fprintf(&b, `      <region xml:id="r1" tts:origin="%s" tts:extent="%s" tts:displayAlign="after"/>`+"\n", config.TTMLOrigin, config.TTMLExtent)
+	b.WriteString("    </layout>\n  </head>\n")
+	b.WriteString(`  <body style="s1" region="r1">` + "\n    <div>\n")
+	for _, seg := range jsonlSegments(result) {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" || seg.End <= seg.Start {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, ttmlEscape(strings.TrimSpace(line)))
+		}
+		fmt.Fprintf(&b, `      <p begin="%s" end="%s">%s</p>`+"\n", formatTTMLTime(seg.Start), formatTTMLTime(seg.End), strings.Join(lines, "<br/>"))
+	}
+	b.WriteString("    </div>\n  </body>\n</tt>\n")
+	return os.WriteFile(outputPath, []byte(b.String()), 0644)
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 以下变量可在发布构建时通过 -ldflags "-X main.updatePublicKey=..." 设置（见 .github/workflows/release.yml）
+var (
+	// updateRepo 检查更新的 GitHub 仓库，fork 发布时可覆盖
+	updateRepo = "JiawenXiong/Go-Whisper-Client"
+	// updatePublicKey 校验 checksums.txt 签名的 Ed25519 公钥（base64），为空时只能检查更新，不能安装
+	updatePublicKey = ""
+)
+
+// 发布附件的命名约定：每个平台一个可执行文件，另有 SHA-256 校验文件及其签名
+const (
+	updateChecksumsAsset = "checksums.txt"
+	updateSignatureAsset = "checksums.txt.sig"
+	// updateTimeout 检查和下载更新的总超时
+	updateTimeout = 10 * time.Minute
+)
+
+// githubRelease GitHub Releases API 返回的发布信息
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// runUpdate 执行 update 子命令：检查 GitHub 上的最新发布，校验签名和 SHA-256 后替换当前可执行文件
+func runUpdate(args []string) {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径（可选，用于读取 proxy_url 和 ca_bundle）"))
+	checkOnly := fs.Bool("check", false, tr("只检查是否有新版本，不下载"))
+	force := fs.Bool("force", false, tr("开发版本或已是最新版本时也重新安装最新发布"))
+	fs.Parse(args)
+
+	// 配置是可选的，只用于通过代理或私有 CA 访问 GitHub
+	client := &http.Client{}
+	if config, err := loadConfig(*configPath); err == nil {
+		client.Transport = config.baseTransport()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+
+	current := currentVersion()
+	release, err := fetchLatestRelease(ctx, client)
+	if err != nil {
+		fatalf(tr("检查更新失败: %v"), err)
+	}
+	fmt.Printf(tr("当前版本: %s\n最新版本: %s\n"), current, release.TagName)
+
+	newer := compareVersions(release.TagName, current) > 0
+	switch {
+	case current == "dev" && !*force:
+		fmt.Println(tr("当前为开发版本（未设置版本号），使用 --force 安装最新发布"))
+		return
+	case !newer && !*force:
+		fmt.Println(tr("已是最新版本"))
+		return
+	case *checkOnly:
+		if newer {
+			fmt.Printf(tr("有新版本可用: %s\n运行 whisper-go update 更新\n"), release.HTMLURL)
+		}
+		return
+	}
+
+	// 没有公钥就无法确认 checksums.txt 出自发布者，SHA-256 只能防止传输损坏
+	if updatePublicKey == "" {
+		fatalf(tr("此版本未内置签名公钥，无法校验发布文件，请从 %s 手动下载"), release.HTMLURL)
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fatalf(tr("无法确定当前可执行文件的位置: %v"), err)
+	}
+	if err := installRelease(ctx, client, release, exe); err != nil {
+		fatalf(tr("更新失败: %v"), err)
+	}
+	fmt.Printf(tr("已更新到 %s: %s\n"), release.TagName, exe)
+}
+
+// fetchLatestRelease 获取 updateRepo 的最新正式发布
+func fetchLatestRelease(ctx context.Context, client *http.Client) (*githubRelease, error) {
+	return fetchRelease(ctx, client, "latest")
+}
+
+// fetchRelease 获取 updateRepo 的发布，which 为 latest 或 tags/<标签>（设置了 GITHUB_TOKEN 时带上认证，避免匿名请求的频率限制）
+func fetchRelease(ctx context.Context, client *http.Client, which string) (*githubRelease, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/repos/"+updateRepo+"/releases/"+which, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound && which == "latest":
+		return nil, fmt.Errorf(tr("%s 还没有正式发布"), updateRepo)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf(tr("%s 中没有发布 %s"), updateRepo, strings.TrimPrefix(which, "tags/"))
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf(tr("GitHub 返回 %s"), resp.Status)
+	}
+	var release githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf(tr("解析发布信息失败: %w"), err)
+	}
+	return &release, nil
+}
+
+// updateAssetName 当前平台的发布附件名，如 whisper-go_linux_amd64、whisper-go_windows_amd64.exe
+func updateAssetName() string {
+	name := "whisper-go_" + runtime.GOOS + "_" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// installRelease 下载当前平台的可执行文件，校验 checksums.txt 的签名和文件的 SHA-256，全部通过后试运行新文件，再替换 exe
+func installRelease(ctx context.Context, client *http.Client, release *githubRelease, exe string) error {
+	assetName := updateAssetName()
+	assetURL, want, err := releaseAssetChecksum(ctx, client, release, assetName)
+	if err != nil {
+		return err
+	}
+
+	// 下载到同一目录下的临时文件，保证最后的重命名是原子操作
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".whisper-go-update-*")
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf(tr("没有写入 %s 的权限，请以管理员身份运行，或从 %s 手动下载"), filepath.Dir(exe), release.HTMLURL)
+		}
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	logInfo(tr("下载 %s ..."), assetName)
+	hash := sha256.New()
+	err = downloadUpdateAsset(ctx, client, assetURL, io.MultiWriter(tmp, hash))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf(tr("%s 的 SHA-256 不匹配（应为 %s，实际为 %s）"), assetName, want, got)
+	}
+
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(exe); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	// 签名和 SHA-256 都已通过，此时运行的是发布者签署的文件
+	if out, err := exec.CommandContext(ctx, tmp.Name(), "version").Output(); err != nil {
+		return fmt.Errorf(tr("下载的文件无法运行: %v"), err)
+	} else if got := strings.TrimSpace(string(out)); got != release.TagName {
+		logWarn(tr("下载的文件报告的版本为 %s，与发布 %s 不一致"), got, release.TagName)
+	}
+	return replaceExecutable(exe, tmp.Name())
+}
+
+// releaseAssetChecksum 查找发布中的附件，校验 checksums.txt 的签名后返回附件的下载地址和 SHA-256
+func releaseAssetChecksum(ctx context.Context, client *http.Client, release *githubRelease, assetName string) (string, string, error) {
+	assets := make(map[string]string)
+	for _, a := range release.Assets {
+		assets[a.Name] = a.URL
+	}
+	if assets[assetName] == "" {
+		return "", "", fmt.Errorf(tr("发布 %s 中没有当前平台的文件 %s"), release.TagName, assetName)
+	}
+	if assets[updateChecksumsAsset] == "" {
+		return "", "", fmt.Errorf(tr("发布 %s 中没有 %s，无法校验下载的文件"), release.TagName, updateChecksumsAsset)
+	}
+	if assets[updateSignatureAsset] == "" {
+		return "", "", fmt.Errorf(tr("发布 %s 中没有签名文件 %s"), release.TagName, updateSignatureAsset)
+	}
+
+	checksums, err := fetchUpdateAsset(ctx, client, assets[updateChecksumsAsset])
+	if err != nil {
+		return "", "", err
+	}
+	signature, err := fetchUpdateAsset(ctx, client, assets[updateSignatureAsset])
+	if err != nil {
+		return "", "", err
+	}
+	if err := verifyUpdateSignature(checksums, signature); err != nil {
+		return "", "", err
+	}
+	want, err := lookupChecksum(checksums, assetName)
+	if err != nil {
+		return "", "", err
+	}
+	return assets[assetName], want, nil
+}
+
+// fetchUpdateAsset 下载较小的发布附件（校验文件和签名）到内存
+func fetchUpdateAsset(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := downloadUpdateAsset(ctx, client, url, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// downloadUpdateAsset 下载发布附件写入 w
+func downloadUpdateAsset(ctx context.Context, client *http.Client, url string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(tr("下载 %s 失败: 服务器返回 %s"), url, resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// verifyUpdateSignature 用 updatePublicKey 校验 checksums.txt 的 Ed25519 签名（签名文件为 base64 或原始 64 字节）
+func verifyUpdateSignature(checksums, signature []byte) error {
+	key, err := base64.StdEncoding.DecodeString(updatePublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New(tr("内置的签名公钥无效"))
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), checksums, signature) {
+		return fmt.Errorf(tr("%s 的签名校验失败，文件可能被篡改"), updateChecksumsAsset)
+	}
+	return nil
+}
+
+// lookupChecksum 在 sha256sum 格式（<哈希>  <文件名>）的校验文件中查找文件的哈希
+func lookupChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum 的二进制模式在文件名前加 *
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf(tr("%s 中没有 %s 的校验值"), updateChecksumsAsset, name)
+}
+
+// replaceExecutable 用新文件替换 exe。Windows 不能覆盖正在运行的可执行文件，但可以重命名，
+// 因此先把旧文件改名为 .old（下次更新时删除）
+func replaceExecutable(exe, newPath string) error {
+	if runtime.GOOS != "windows" {
+		return os.Rename(newPath, exe)
+	}
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(newPath, exe); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+	return nil
+}
+
+// compareVersions 比较 v1.2.3 形式的版本号，a 较新时返回正数；预发布版本（如 v1.2.0-rc1）早于对应的正式版本，
+// 无法解析的版本（如 dev）视为最旧
+func compareVersions(a, b string) int {
+	parse := func(v string) ([]int, string, bool) {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		core, pre, _ := strings.Cut(v, "-")
+		core, _, _ = strings.Cut(core, "+")
+		var nums []int
+		for _, part := range strings.Split(core, ".") {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, "", false
+			}
+			nums = append(nums, n)
+		}
+		return nums, pre, true
+	}
+	na, preA, okA := parse(a)
+	nb, preB, okB := parse(b)
+	if !okA || !okB {
+		switch {
+		case okA == okB:
+			return 0
+		case okA:
+			return 1
+		}
+		return -1
+	}
+	for i := 0; i < max(len(na), len(nb)); i++ {
+		var x, y int
+		if i < len(na) {
+			x = na[i]
+		}
+		if i < len(nb) {
+			y = nb[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
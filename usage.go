@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 超出月度预算时的处理方式
+const (
+	budgetActionRefuse = "refuse" // 拒绝开始新任务（默认）
+	budgetActionWarn   = "warn"   // 只记录警告
+)
+
+// usageMonthLayout 用量按自然月（本地时间）统计
+const usageMonthLayout = "2006-01"
+
+// usageEntry 用量账本中的一条记录：一次成功的转写请求
+type usageEntry struct {
+	Time     time.Time `json:"time"`
+	KeyID    string    `json:"key_id"` // key 的 SHA-256 前 8 字节，账本中不保存 key 本身
+	Key      string    `json:"key"`    // 只保留最后 4 个字符，便于辨认
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Seconds  float64   `json:"seconds"`
+}
+
+// usageLedgerMu 同一进程内追加账本时串行写入
+var usageLedgerMu sync.Mutex
+
+// usedKeyContextKey 请求上下文中记录实际使用的 API key（key 池轮换时由 keyRotatingTransport 写入）
+type usedKeyContextKey struct{}
+
+// withUsedKey 返回可记录实际使用 key 的上下文
+func withUsedKey(ctx context.Context) (context.Context, *string) {
+	used := new(string)
+	return context.WithValue(ctx, usedKeyContextKey{}, used), used
+}
+
+// recordUsedKey 在请求上下文中记录实际使用的 key
+func recordUsedKey(ctx context.Context, key string) {
+	if used, ok := ctx.Value(usedKeyContextKey{}).(*string); ok {
+		*used = key
+	}
+}
+
+// usageLedgerPath 账本文件路径，usage_ledger 为 off 或无法确定用户配置目录时返回空字符串
+func (c *Config) usageLedgerPath() string {
+	switch c.UsageLedger {
+	case "off":
+		return ""
+	case "":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(dir, "whisper-go", "usage.jsonl")
+	default:
+		return c.UsageLedger
+	}
+}
+
+// recordUsage 把一次成功转写的音频时长记入账本。本地 whisper.cpp 不产生费用，不记录；写入失败只记录警告
+func recordUsage(config *Config, key string, seconds float64) {
+	path := config.usageLedgerPath()
+	if path == "" || !config.needsAPIKey() || seconds <= 0 {
+		return
+	}
+	if key == "" {
+		key = config.APIKey
+	}
+	sum := sha256.Sum256([]byte(key))
+	entry := usageEntry{
+		Time:     time.Now(),
+		KeyID:    hex.EncodeToString(sum[:8]),
+		Key:      maskAPIKey(key),
+		Provider: config.Provider,
+		Model:    config.Model,
+		Seconds:  seconds,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	usageLedgerMu.Lock()
+	defer usageLedgerMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logWarn(tr("写入用量账本失败: %v"), err)
+		return
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		logWarn(tr("写入用量账本失败: %v"), err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logWarn(tr("写入用量账本失败: %v"), err)
+	}
+}
+
+// readUsage 读取账本中 month（YYYY-MM）的记录，账本不存在时返回空
+func readUsage(path, month string) ([]usageEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []usageEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry usageEntry
+		// 跳过写入中断等原因损坏的行
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		if entry.Time.Local().Format(usageMonthLayout) == month {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// monthMinutes 本月已转写的分钟数
+func monthMinutes(path string) (float64, error) {
+	entries, err := readUsage(path, time.Now().Format(usageMonthLayout))
+	if err != nil {
+		return 0, err
+	}
+	var seconds float64
+	for _, entry := range entries {
+		seconds += entry.Seconds
+	}
+	return seconds / 60, nil
+}
+
+// checkBudget 开始转写前检查本月用量加上该文件的时长是否超出 monthly_budget_minutes：
+// budget_action 为 refuse 时返回错误，为 warn 时只记录警告。无法获取时长时只检查已用量
+func checkBudget(config *Config, inputFile string) error {
+	path := config.usageLedgerPath()
+	if config.MonthlyBudgetMinutes <= 0 || path == "" || !config.needsAPIKey() {
+		return nil
+	}
+	used, err := monthMinutes(path)
+	if err != nil {
+		logWarn(tr("读取用量账本失败，跳过预算检查: %v"), err)
+		return nil
+	}
+	var needed float64
+	if duration, err := getAudioDuration(inputFile); err == nil {
+		needed = duration / 60
+	}
+	if used+needed <= config.MonthlyBudgetMinutes {
+		return nil
+	}
+
+	err = fmt.Errorf(tr("超出月度预算：本月已用 %.1f 分钟，该文件约 %.1f 分钟，预算 %.1f 分钟"), used, needed, config.MonthlyBudgetMinutes)
+	if config.BudgetAction == budgetActionWarn {
+		logWarn("%v", err)
+		return nil
+	}
+	return withExitCode(exitBudget, err)
+}
+
+// usageSummary usage 子命令按 key 汇总的一行
+type usageSummary struct {
+	KeyID    string  `json:"key_id"`
+	Key      string  `json:"key"`
+	Requests int     `json:"requests"`
+	Minutes  float64 `json:"minutes"`
+	Cost     float64 `json:"cost"`
+}
+
+// usageReport usage 子命令的 JSON 输出
+type usageReport struct {
+	Month         string         `json:"month"`
+	Ledger        string         `json:"ledger"`
+	Keys          []usageSummary `json:"keys"`
+	Minutes       float64        `json:"minutes"`
+	Cost          float64        `json:"cost"`
+	BudgetMinutes float64        `json:"budget_minutes,omitempty"`
+}
+
+// runUsage 执行 usage 子命令：按 API key 汇总某个月的转写分钟数和估算费用，并显示月度预算的使用情况
+func runUsage(args []string) {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	month := fs.String("month", time.Now().Format(usageMonthLayout), tr("统计的月份（YYYY-MM）"))
+	asJSON := fs.Bool("json", false, tr("以 JSON 输出"))
+	fs.Parse(args)
+
+	if _, err := time.Parse(usageMonthLayout, *month); err != nil {
+		fatalf(tr("无效的月份: %s（格式为 YYYY-MM）"), *month)
+	}
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		exitWith(exitConfig, tr("加载配置失败: %v"), err)
+	}
+	path := config.usageLedgerPath()
+	if path == "" {
+		exitWith(exitConfig, "%s", tr("用量账本已关闭（usage_ledger 为 off）"))
+	}
+	entries, err := readUsage(path, *month)
+	if err != nil {
+		fatalf(tr("读取用量账本失败: %v"), err)
+	}
+
+	report := usageReport{Month: *month, Ledger: path, Keys: []usageSummary{}, BudgetMinutes: config.MonthlyBudgetMinutes}
+	byKey := make(map[string]*usageSummary)
+	for _, entry := range entries {
+		summary := byKey[entry.KeyID]
+		if summary == nil {
+			summary = &usageSummary{KeyID: entry.KeyID, Key: entry.Key}
+			byKey[entry.KeyID] = summary
+		}
+		summary.Requests++
+		summary.Minutes += entry.Seconds / 60
+	}
+	for _, summary := range byKey {
+		summary.Cost = summary.Minutes * config.PricePerMinute
+		report.Keys = append(report.Keys, *summary)
+		report.Minutes += summary.Minutes
+		report.Cost += summary.Cost
+	}
+	sort.Slice(report.Keys, func(i, j int) bool { return report.Keys[i].Minutes > report.Keys[j].Minutes })
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf(tr("用量 %s（账本: %s）\n"), report.Month, report.Ledger)
+	if len(report.Keys) == 0 {
+		fmt.Println(tr("该月没有转写记录"))
+	} else {
+		fmt.Printf("  %-12s %-18s %8s %10s %10s\n", "KEY", "ID", tr("请求数"), tr("分钟"), tr("估算费用"))
+		for _, summary := range report.Keys {
+			fmt.Printf("  %-12s %-18s %8d %10.1f %10s\n", summary.Key, summary.KeyID, summary.Requests, summary.Minutes, fmt.Sprintf("$%.2f", summary.Cost))
+		}
+		fmt.Printf("  %-12s %-18s %8s %10.1f %10s\n", tr("合计"), "", "", report.Minutes, fmt.Sprintf("$%.2f", report.Cost))
+	}
+	if report.BudgetMinutes > 0 {
+		fmt.Printf(tr("月度预算: %.1f / %.1f 分钟（剩余 %.1f 分钟）\n"), report.Minutes, report.BudgetMinutes, max(report.BudgetMinutes-report.Minutes, 0))
+	}
+}
+
+// chunkSeconds 一次转写请求计入用量的音频时长：优先用接口返回的时长，没有时读取音频文件
+func chunkSeconds(result *TranscriptionResult, audioPath string) float64 {
+	if result.Duration > 0 {
+		return result.Duration
+	}
+	duration, err := getAudioDuration(audioPath)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
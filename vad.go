@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// 切点检测方式（vad 配置）
+const (
+	vadEnergy = "energy"
+	vadSilero = "silero"
+)
+
+// Silero VAD（v5）的输入格式：16kHz 单声道，每个窗口 512 个采样（32ms），模型输入前拼接上一窗口末尾的 64 个采样
+const (
+	sileroSampleRate = 16000
+	sileroWindow     = 512
+	sileroContext    = 64
+)
+
+// vadDetector 逐窗口计算语音概率的 VAD 模型
+type vadDetector interface {
+	// Probability 一个窗口（sileroWindow 个采样）为语音的概率，窗口需按时间顺序送入
+	Probability(window []float32) (float32, error)
+	Close()
+}
+
+// setVAD 按 -vad 参数设置切点检测方式
+func (c *Config) setVAD(vad string) error {
+	switch vad {
+	case vadEnergy:
+	case vadSilero:
+		if c.SileroModel == "" {
+			return errors.New(tr("vad 为 silero 时需要在 silero_model 中配置模型文件路径"))
+		}
+	default:
+		return fmt.Errorf(tr("无效的 -vad 参数: %s（可选 energy, silero）"), vad)
+	}
+	c.VAD = vad
+	return nil
+}
+
+// detectSilenceVAD 用 Silero VAD 检测非语音段作为候选切点，比能量阈值更能适应有背景噪声的录音
+func detectSilenceVAD(audioPath string, config *Config) ([]SilencePoint, error) {
+	detector, err := newSileroDetector(config)
+	if err != nil {
+		return nil, err
+	}
+	defer detector.Close()
+
+	pcm, err := openPCM16k(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	probs, err := vadProbabilities(pcm, detector)
+	if cerr := pcm.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf(tr("Silero VAD 检测失败: %w"), err)
+	}
+
+	points := vadSilencePoints(probs, config.VADThreshold, config.SilenceDuration)
+	logDebug(tr("Silero VAD 检测到 %d 个非语音段\n"), len(points))
+	return points, nil
+}
+
+// vadProbabilities 按窗口读取 16 位 PCM 并计算每个窗口的语音概率，最后不足一个窗口的部分补零
+func vadProbabilities(pcm io.Reader, detector vadDetector) ([]float32, error) {
+	reader := bufio.NewReaderSize(pcm, 1<<16)
+	buf := make([]byte, sileroWindow*2)
+	window := make([]float32, sileroWindow)
+	var probs []float32
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n -= n % 2; n > 0 {
+			for i := range window {
+				window[i] = 0
+				if i*2 < n {
+					window[i] = float32(int16(binary.LittleEndian.Uint16(buf[i*2:]))) / 32768
+				}
+			}
+			p, perr := detector.Probability(window)
+			if perr != nil {
+				return nil, perr
+			}
+			probs = append(probs, p)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return probs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// vadSilencePoints 把每个窗口的语音概率转换为静音点：概率达到 threshold 时进入语音，低于 threshold-0.15 时退出
+// （与 Silero 官方实现相同的滞回，避免在语音内部的短暂低概率处断开），不短于 minDuration 的非语音段作为静音点
+func vadSilencePoints(probs []float32, threshold, minDuration float64) []SilencePoint {
+	windowSeconds := float64(sileroWindow) / sileroSampleRate
+	negThreshold := max(threshold-0.15, 0.01)
+
+	var points []SilencePoint
+	speech := false
+	silenceStart := 0.0
+	for i, p := range probs {
+		t := float64(i) * windowSeconds
+		switch {
+		case !speech && float64(p) >= threshold:
+			speech = true
+			if t-silenceStart >= minDuration {
+				points = append(points, SilencePoint{Start: silenceStart, End: t})
+			}
+		case speech && float64(p) < negThreshold:
+			speech = false
+			silenceStart = t
+		}
+	}
+
+	// 音频以非语音结尾
+	end := float64(len(probs)) * windowSeconds
+	if !speech && end-silenceStart >= minDuration {
+		points = append(points, SilencePoint{Start: silenceStart, End: end})
+	}
+	return points
+}
+
+// openPCM16k 以 16kHz 单声道 16 位 PCM 流读取音频：已是该格式的 WAV 直接读取 data 块，
+// 其他 PCM WAV、FLAC 和 MP3 在 Go 中解码，其他格式通过 ffmpeg 解码
+func openPCM16k(audioPath string) (io.ReadCloser, error) {
+	if info := fastSliceWAVInfo(audioPath); info != nil {
+		f, err := os.Open(audioPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(info.DataOffset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(f, info.DataSize), f}, nil
+	}
+
+	if stream, err := openNativePCM16k(audioPath); err == nil {
+		return stream, nil
+	} else if !errors.Is(err, errNotNativeAudio) {
+		logDebug(tr("原生解码失败，回退到 ffmpeg: %v\n"), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, ffmpegTools.FFmpeg, ffmpegArgs(audioPath,
+		"-vn",
+		"-acodec", "pcm_s16le",
+		"-ar", "16000",
+		"-ac", "1",
+		"-f", "s16le", "pipe:1",
+	)...)
+	stream := &ffmpegStream{cmd: cmd, cancel: cancel}
+	cmd.Stderr = &stream.stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf(tr("启动 ffmpeg 失败: %w"), err)
+	}
+	stream.stdout = stdout
+	return stream, nil
+}
+
+// ffmpegStream ffmpeg 解码输出的 PCM 流
+type ffmpegStream struct {
+	stdout io.Reader
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stderr bytes.Buffer
+	eof    bool
+}
+
+// Read 读取解码后的 PCM 数据
+func (s *ffmpegStream) Read(p []byte) (int, error) {
+	n, err := s.stdout.Read(p)
+	if err == io.EOF {
+		s.eof = true
+	}
+	return n, err
+}
+
+// Close 未读完时中止 ffmpeg，读完时等待退出并返回解码错误
+func (s *ffmpegStream) Close() error {
+	if !s.eof {
+		s.cancel()
+	}
+	err := s.cmd.Wait()
+	s.cancel()
+	if s.eof && err != nil {
+		return fmt.Errorf(tr("ffmpeg 解码失败: %w: %s"), err, strings.TrimSpace(s.stderr.String()))
+	}
+	return nil
+}
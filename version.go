@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// 以下变量在发布构建时通过 -ldflags 设置，如
+// -ldflags "-X main.version=v1.2.3 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+// 未设置的 commit 和 buildDate 从 Go 记录的版本控制信息中读取
+var (
+	// version 当前版本号，未设置时为 dev（开发版本）
+	version = "dev"
+	// commit 构建时的 git 提交
+	commit = ""
+	// buildDate 构建时间（RFC 3339）
+	buildDate = ""
+)
+
+// openaiModule 版本信息中单独列出的 go-openai 依赖
+const openaiModule = "github.com/sashabaranov/go-openai"
+
+// pseudoVersion 本地构建时 Go 根据提交记录生成的伪版本号，如 v0.0.0-20240101120000-abcdef123456+dirty
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}|\+dirty$`)
+
+// buildInfo 当前程序的构建信息
+type buildInfo struct {
+	Version   string
+	Commit    string
+	Modified  bool // 构建时工作区有未提交的修改
+	BuildDate string
+	GoVersion string
+	Platform  string
+	OpenAI    string // go-openai 依赖的版本
+}
+
+// currentVersion 当前版本号：优先使用构建时设置的 version，其次为 go install 记录的模块版本（伪版本号视为开发版本）
+func currentVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" && !pseudoVersion.MatchString(info.Main.Version) {
+		return info.Main.Version
+	}
+	return version
+}
+
+// readBuildInfo 汇总 -ldflags 设置的变量和 Go 嵌入的构建信息（版本控制信息、依赖版本）
+func readBuildInfo() buildInfo {
+	b := buildInfo{
+		Version:   currentVersion(),
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if b.Commit == "" {
+				b.Commit = s.Value
+			}
+		case "vcs.time":
+			// go build 不记录构建时间，未设置 buildDate 时以提交时间代替
+			if b.BuildDate == "" {
+				b.BuildDate = s.Value
+			}
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == openaiModule {
+			b.OpenAI = dep.Version
+			if dep.Replace != nil {
+				b.OpenAI += " => " + dep.Replace.Path + " " + dep.Replace.Version
+			}
+		}
+	}
+	return b
+}
+
+// String 多行的构建信息，用于 --version 和问题反馈
+func (b buildInfo) String() string {
+	unknown := func(s string) string {
+		if s == "" {
+			return tr("未知")
+		}
+		return s
+	}
+	commit := unknown(b.Commit)
+	if b.Modified {
+		commit += tr("（有未提交的修改）")
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "whisper-go %s\n", b.Version)
+	fmt.Fprintf(&sb, "commit:     %s\n", commit)
+	fmt.Fprintf(&sb, "built:      %s\n", unknown(b.BuildDate))
+	fmt.Fprintf(&sb, "go:         %s %s\n", b.GoVersion, b.Platform)
+	fmt.Fprintf(&sb, "go-openai:  %s\n", unknown(b.OpenAI))
+	return sb.String()
+}
+
+// runVersion 执行 version 子命令：只输出版本号，便于脚本比较（update 也用它检查下载的文件）
+func runVersion() {
+	fmt.Println(currentVersion())
+}
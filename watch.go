@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileStamp 文件的大小和修改时间，用于判断文件是否写入完成，不需要调用 ffprobe
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// runWatch 执行 watch 子命令：监视目录，新文件写入完成后自动转写。
+// 空闲时轮询间隔逐步延长，扫描只读取目录和文件元数据，常驻运行时几乎不占用 CPU
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	configPath := fs.String("config", "./config.json", tr("配置文件路径"))
+	outputDir := fs.String("output", "", tr("输出目录"))
+	formats := fs.String("formats", "txt,srt,json", tr("输出格式（逗号分隔）"))
+	existing := fs.Bool("existing", false, tr("启动时同时转写目录中已有的文件"))
+	poll := fs.Float64("poll", 0, tr("最短轮询间隔（秒，默认读取配置）"))
+	maxPoll := fs.Float64("max-poll", 0, tr("空闲时的最长轮询间隔（秒，默认读取配置）"))
+	metrics := fs.String("metrics", "", tr("提供 /metrics 空闲/工作状态指标的监听地址（如 :9090）"))
+	verbose := fs.Bool("verbose", false, tr("显示详细输出"))
+	fs.Parse(args)
+	useVerboseLogging(*verbose)
+	installShutdownHandler()
+
+	if fs.NArg() < 1 {
+		fmt.Println(tr("用法: whisper-go watch <dir> [options]"))
+		fmt.Println(tr("选项:"))
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	dir := fs.Arg(0)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		fatalf(tr("监视目录不存在: %s"), dir)
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fatalf(tr("加载配置失败: %v"), err)
+	}
+	if config.APIKey == "" && config.needsAPIKey() {
+		fatal(tr("配置文件中未设置 API Key，请先在 config.json 中配置 api_key"))
+	}
+	if *outputDir != "" {
+		config.OutputDir = *outputDir
+	}
+	if *poll > 0 {
+		config.WatchPollInterval = *poll
+	}
+	if *maxPoll > 0 {
+		config.WatchMaxPollInterval = *maxPoll
+	}
+
+	state := newDaemonState()
+	if *metrics != "" {
+		go func() {
+			if err := state.serveMetrics(*metrics); err != nil {
+				logError(tr("指标服务异常退出: %v"), err)
+			}
+		}()
+	}
+
+	client := newClient(config)
+	formatList := parseFormats(*formats)
+	backoff := newPollBackoff(
+		time.Duration(config.WatchPollInterval*float64(time.Second)),
+		time.Duration(config.WatchMaxPollInterval*float64(time.Second)),
+	)
+
+	// done 已处理（或启动时已存在）的文件，pending 等待写入完成的文件
+	done := map[string]fileStamp{}
+	pending := map[string]fileStamp{}
+	if !*existing {
+		for path, stamp := range scanMediaFiles(dir) {
+			done[path] = stamp
+		}
+	}
+
+	// 第一次 Ctrl+C 在当前文件处理完后停止监视，再按一次或收到 SIGTERM 时取消处理并清理临时文件
+	stop := make(chan struct{})
+	setGracefulStop(func() { close(stop) })
+	defer setGracefulStop(nil)
+
+	logInfo(tr("正在监视: %s（Ctrl+C 结束）\n"), dir)
+
+	interval := backoff.reset()
+	for {
+		changed := false
+		for path, stamp := range scanMediaFiles(dir) {
+			if prev, ok := done[path]; ok && prev == stamp {
+				continue
+			}
+			// 连续两次扫描大小和修改时间不变，才认为文件已写入完成
+			if prev, ok := pending[path]; !ok || prev != stamp {
+				pending[path] = stamp
+				changed = true
+				continue
+			}
+			select {
+			case <-stop:
+				logInfo(tr("\n已停止监视"))
+				return
+			default:
+			}
+			delete(pending, path)
+			done[path] = stamp
+			changed = true
+
+			logInfo(tr("\n检测到新文件: %s\n"), path)
+			state.begin()
+			_, outputFiles, err := processFile(client, path, config, formatList, *verbose)
+			state.end(err)
+			if err != nil {
+				logError(tr("处理失败: %v"), err)
+				continue
+			}
+			for _, file := range outputFiles {
+				logInfo("  - %s", file)
+				// 输出目录就是监视目录时，输出的音频（tagged 格式、自定义模板）不能再被当作新文件转写
+				if stamp, ok := outputStamp(dir, file); ok {
+					done[filepath.Join(dir, filepath.Base(file))] = stamp
+				}
+			}
+		}
+
+		previous := interval
+		if changed {
+			interval = backoff.reset()
+		} else {
+			interval = backoff.idle()
+		}
+		state.pollInterval.Store(int64(interval))
+		if interval != previous {
+			logDebug(tr("轮询间隔: %s\n"), interval)
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-stop:
+			logInfo(tr("\n已停止监视"))
+			return
+		}
+	}
+}
+
+// outputStamp 输出文件位于监视目录中时返回其大小和修改时间
+func outputStamp(dir, file string) (fileStamp, bool) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fileStamp{}, false
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil || filepath.Dir(absFile) != absDir {
+		return fileStamp{}, false
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileStamp{}, false
+	}
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}, true
+}
+
+// scanMediaFiles 列出目录中的音视频文件（不递归、忽略隐藏文件和 tagged 格式的输出），只读取元数据
+func scanMediaFiles(dir string) map[string]fileStamp {
+	files := map[string]fileStamp{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		logError(tr("读取目录失败: %v"), err)
+		return files
+	}
+	for _, entry := range entries {
+		// tagged 输出（<文件名>_<时间>.tagged.mp3）在重启后也要跳过，否则会被转写并再次输出
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || strings.Contains(entry.Name(), ".tagged.") || !isMediaFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files[filepath.Join(dir, entry.Name())] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return files
+}
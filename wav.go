@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errNotPCMWAV 输入不是可直接解析的 PCM WAV 文件
+var errNotPCMWAV error = trError("不是 PCM WAV 文件")
+
+// wavSubtypePCM WAVE_FORMAT_EXTENSIBLE 中整数 PCM 的子格式 GUID（KSDATAFORMAT_SUBTYPE_PCM），按文件中的字节顺序
+var wavSubtypePCM = []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xaa, 0x00, 0x38, 0x9b, 0x71}
+
+// silenceWindowSeconds 原生静音检测的分析窗口长度
+const silenceWindowSeconds = 0.01
+
+// wavInfo WAV 文件的格式信息
+type wavInfo struct {
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+	DataOffset    int64 // data 块在文件中的起始位置
+	DataSize      int64 // data 块字节数
+}
+
+// Duration 音频时长（秒）
+func (w *wavInfo) Duration() float64 {
+	return float64(w.DataSize) / float64(w.frameSize()) / float64(w.SampleRate)
+}
+
+// frameSize 每帧（所有声道的一个采样）字节数
+func (w *wavInfo) frameSize() int {
+	return w.Channels * w.BitsPerSample / 8
+}
+
+// readWAVInfo 解析 WAV 文件头，仅支持整数 PCM 编码
+func readWAVInfo(f io.ReadSeeker) (*wavInfo, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(f, riff[:]); err != nil {
+		return nil, errNotPCMWAV
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, errNotPCMWAV
+	}
+
+	info := &wavInfo{}
+	var haveFmt bool
+	offset := int64(12)
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(f, header[:]); err != nil {
+			return nil, errors.New(tr("WAV 文件缺少 data 块"))
+		}
+		id := string(header[0:4])
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+		offset += 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New(tr("WAV fmt 块长度无效"))
+			}
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(f, buf); err != nil {
+				return nil, fmt.Errorf(tr("读取 WAV fmt 块失败: %w"), err)
+			}
+			format := binary.LittleEndian.Uint16(buf[0:2])
+			// 1 = PCM，0xFFFE = WAVE_FORMAT_EXTENSIBLE，后者的实际编码在第 24 字节起的子格式 GUID 中（浮点、A-law 等交给 ffmpeg）
+			switch format {
+			case 1:
+			case 0xFFFE:
+				if size < 40 || !bytes.Equal(buf[24:40], wavSubtypePCM) {
+					return nil, errNotPCMWAV
+				}
+			default:
+				return nil, errNotPCMWAV
+			}
+			info.Channels = int(binary.LittleEndian.Uint16(buf[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(buf[4:8]))
+			info.BitsPerSample = int(binary.LittleEndian.Uint16(buf[14:16]))
+			switch info.BitsPerSample {
+			case 8, 16, 24, 32:
+			default:
+				return nil, errNotPCMWAV
+			}
+			if info.Channels == 0 || info.SampleRate == 0 {
+				return nil, errors.New(tr("WAV 格式信息无效"))
+			}
+			haveFmt = true
+			// fmt 块已读完，只需跳过可能的填充字节
+			offset += size
+			if size%2 == 1 {
+				if _, err := f.Seek(1, io.SeekCurrent); err != nil {
+					return nil, fmt.Errorf(tr("读取 WAV 文件失败: %w"), err)
+				}
+				offset++
+			}
+			continue
+		case "data":
+			if !haveFmt {
+				return nil, errors.New(tr("WAV data 块出现在 fmt 块之前"))
+			}
+			info.DataOffset = offset
+			info.DataSize = size
+			// 流式写出的 WAV 可能把长度写成 0 或 0xFFFFFFFF，以文件实际大小为准
+			if end, err := f.Seek(0, io.SeekEnd); err == nil && (size == 0 || offset+size > end) {
+				info.DataSize = end - offset
+			}
+			info.DataSize -= info.DataSize % int64(info.frameSize())
+			return info, nil
+		}
+
+		// 块长度为奇数时有一个填充字节
+		skip := size + size%2
+		if _, err := f.Seek(skip, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf(tr("读取 WAV 文件失败: %w"), err)
+		}
+		offset += skip
+	}
+}
+
+// parseSilenceThreshold 将 ffmpeg 风格的阈值（"-30dB" 或振幅比例 "0.03"）转换为 dBFS
+func parseSilenceThreshold(threshold string) (float64, error) {
+	t := strings.TrimSpace(threshold)
+	if strings.HasSuffix(strings.ToLower(t), "db") {
+		return strconv.ParseFloat(strings.TrimSpace(t[:len(t)-2]), 64)
+	}
+	ratio, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		return 0, err
+	}
+	if ratio <= 0 {
+		return math.Inf(-1), nil
+	}
+	return 20 * math.Log10(ratio), nil
+}
+
+// sampleValue 将一个 PCM 采样解码为 [-1, 1] 区间的浮点数
+func sampleValue(b []byte, bits int) float64 {
+	switch bits {
+	case 8:
+		// 8 位 PCM 为无符号数
+		return (float64(b[0]) - 128) / 128
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+	case 24:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float64(v) / 8388608
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+	}
+}
+
+// detectSilenceNative 在 Go 中解码 PCM WAV、FLAC 或 MP3，按窗口计算 RMS 能量检测静音，不需要启动 ffmpeg
+func detectSilenceNative(audioPath, threshold string, minDuration float64) ([]SilencePoint, error) {
+	thresholdDB, err := parseSilenceThreshold(threshold)
+	if err != nil {
+		return nil, fmt.Errorf(tr("无效的静音阈值 %q: %w"), threshold, err)
+	}
+
+	audio, err := openNativeAudio(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer audio.Close()
+
+	windowFrames := int(float64(audio.SampleRate) * silenceWindowSeconds)
+	if windowFrames < 1 {
+		windowFrames = 1
+	}
+	buf := make([]float64, windowFrames*audio.Channels)
+
+	var points []SilencePoint
+	var framesRead int64
+	inSilence := false
+	silenceStart := 0.0
+
+	for {
+		n, err := audio.readFull(buf)
+		if n == 0 {
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			break
+		}
+
+		// 计算窗口内所有声道采样的均方值
+		var sum float64
+		for _, v := range buf[:n] {
+			sum += v * v
+		}
+		db := math.Inf(-1)
+		if meanSquare := sum / float64(n); meanSquare > 0 {
+			db = 10 * math.Log10(meanSquare)
+		}
+
+		windowStart := float64(framesRead) / float64(audio.SampleRate)
+		framesRead += int64(n / audio.Channels)
+
+		if db < thresholdDB {
+			if !inSilence {
+				inSilence = true
+				silenceStart = windowStart
+			}
+		} else if inSilence {
+			inSilence = false
+			if windowStart-silenceStart >= minDuration {
+				points = append(points, SilencePoint{Start: silenceStart, End: windowStart})
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 文件以静音结尾
+	end := float64(framesRead) / float64(audio.SampleRate)
+	if inSilence && end-silenceStart >= minDuration {
+		points = append(points, SilencePoint{Start: silenceStart, End: end})
+	}
+
+	return points, nil
+}
+
+// fastSliceWAVInfo 源文件为 16kHz 单声道 16 位 PCM WAV 时返回其格式信息，否则返回 nil
+func fastSliceWAVInfo(audioPath string) *wavInfo {
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	info, err := readWAVInfo(f)
+	if err != nil || info.SampleRate != 16000 || info.Channels != 1 || info.BitsPerSample != 16 {
+		return nil
+	}
+	return info
+}
+
+// writeWAVHeader 写入标准 44 字节 PCM WAV 文件头
+func writeWAVHeader(w io.Writer, info *wavInfo, dataSize int64) error {
+	frameSize := info.frameSize()
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(36+dataSize))
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], 1)
+	binary.LittleEndian.PutUint16(header[22:24], uint16(info.Channels))
+	binary.LittleEndian.PutUint32(header[24:28], uint32(info.SampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(info.SampleRate*frameSize))
+	binary.LittleEndian.PutUint16(header[32:34], uint16(frameSize))
+	binary.LittleEndian.PutUint16(header[34:36], uint16(info.BitsPerSample))
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], uint32(dataSize))
+	_, err := w.Write(header)
+	return err
+}
+
+// sliceWAV 按字节偏移截取 [start, end) 区间的 PCM 数据写入新的 WAV 文件，end 为 0 表示截取到结尾
+func sliceWAV(audioPath string, info *wavInfo, start, end float64, outputPath string) error {
+	src, err := os.Open(audioPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	frameSize := int64(info.frameSize())
+	totalFrames := info.DataSize / frameSize
+	startFrame := int64(math.Round(start * float64(info.SampleRate)))
+	endFrame := totalFrames
+	if end > 0 {
+		endFrame = int64(math.Round(end * float64(info.SampleRate)))
+	}
+	if endFrame > totalFrames {
+		endFrame = totalFrames
+	}
+	if startFrame < 0 || startFrame >= endFrame {
+		return fmt.Errorf(tr("无效的切片区间: %.3f - %.3f 秒"), start, end)
+	}
+
+	dataSize := (endFrame - startFrame) * frameSize
+	if _, err := src.Seek(info.DataOffset+startFrame*frameSize, io.SeekStart); err != nil {
+		return err
+	}
+
+	dst, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriterSize(dst, 1<<16)
+	if err := writeWAVHeader(w, info, dataSize); err != nil {
+		dst.Close()
+		return err
+	}
+	if _, err := io.CopyN(w, src, dataSize); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"crypto/subtle"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/whisper-client/go-whisper-go/proto/whisperpb"
+)
+
+// webFS 网页界面的静态资源
+//
+//go:embed web
+var webFS embed.FS
+
+// webTemplate 网页界面，界面文字在服务端按当前语言填入
+var webTemplate = template.Must(template.ParseFS(webFS, "web/index.html"))
+
+// webFormats 网页界面可选的输出格式，前三个默认勾选
+var webFormats = []string{"txt", "srt", "json", "lrc", "html"}
+
+// webPage 网页模板数据
+type webPage struct {
+	Lang    string
+	Formats []string
+	Labels  map[string]string
+}
+
+// serveWeb 在 addr 上提供网页界面和供其调用的 JSON 接口，与 gRPC 共用同一个任务队列
+func (s *transcriptionServer) serveWeb(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleWebIndex)
+	mux.HandleFunc("/api/jobs", s.handleWebSubmit)
+	mux.HandleFunc("/api/jobs/", s.handleWebJob)
+	// 实时字幕与 caption_listen 使用同一个 hub，任务转写时产生的分段推送到 /captions
+	enableCaptions(s.config).register(mux)
+	server := &http.Server{Addr: addr, Handler: s.webAuth(mux), ReadHeaderTimeout: 10 * time.Second}
+	return server.ListenAndServe()
+}
+
+// webAuth 配置 web_token 时要求每个请求携带令牌：Authorization: Bearer 请求头，或 token 查询参数（供页面链接和 WebSocket 使用）
+func (s *transcriptionServer) webAuth(next http.Handler) http.Handler {
+	token := s.config.WebToken
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(r, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="whisper-go"`)
+			http.Error(w, tr("缺少访问令牌或令牌错误"), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validToken 请求是否携带了 token（Authorization: Bearer 请求头或 token 查询参数），token 为空时不要求
+func validToken(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	got := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		got = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// handleWebIndex 返回网页界面
+func (s *transcriptionServer) handleWebIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	page := webPage{
+		Lang:    uiLang,
+		Formats: webFormats,
+		Labels: map[string]string{
+			"title":      tr("Whisper 转写"),
+			"drop":       tr("拖放音频或视频文件到这里，或点击选择"),
+			"language":   tr("语言（留空自动识别）"),
+			"model":      tr("模型（留空使用服务端配置）"),
+			"priority":   tr("优先级"),
+			"formats":    tr("输出格式"),
+			"jobs":       tr("任务"),
+			"cancel":     tr("取消"),
+			"uploading":  tr("上传中"),
+			"queued":     tr("排队中"),
+			"position":   tr("前面还有 %d 个任务"),
+			"running":    tr("转写中"),
+			"succeeded":  tr("已完成"),
+			"failed":     tr("失败"),
+			"cancelled":  tr("已取消"),
+			"download":   tr("下载"),
+			"extract":    tr("提取音频"),
+			"split":      tr("切分音频"),
+			"transcribe": tr("转写"),
+			"save":       tr("保存结果"),
+			"error":      tr("请求失败"),
+			"noFormat":   tr("请至少选择一种输出格式"),
+		},
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webTemplate.Execute(w, page); err != nil {
+		logError(tr("渲染网页失败: %v"), err)
+	}
+}
+
+// handleWebSubmit 接收 multipart 上传并提交任务。文件边读边写入磁盘，表单字段可以出现在文件之前或之后
+func (s *transcriptionServer) handleWebSubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	// 超过上限时读取请求体返回 *http.MaxBytesError，并关闭连接
+	limit := int64(s.config.WebMaxUploadMB * 1024 * 1024)
+	if r.ContentLength > limit {
+		http.Error(w, fmt.Sprintf(tr("上传内容超过 web_max_upload_mb 限制（%g MB）"), s.config.WebMaxUploadMB), http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	options := &whisperpb.TranscribeOptions{}
+	var dir, path string
+	fail := func(code int, msg string) {
+		if dir != "" {
+			os.RemoveAll(dir)
+		}
+		http.Error(w, msg, code)
+	}
+	failRead := func(code int, err error, format string) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			fail(http.StatusRequestEntityTooLarge, fmt.Sprintf(tr("上传内容超过 web_max_upload_mb 限制（%g MB）"), s.config.WebMaxUploadMB))
+			return
+		}
+		fail(code, fmt.Sprintf(format, err))
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			failRead(http.StatusBadRequest, err, "%v")
+			return
+		}
+
+		if part.FormName() == "file" && dir == "" {
+			dir, path, err = createUploadFile(s.uploadRoot, part.FileName())
+			if err == nil {
+				err = writeUpload(path, part)
+			}
+			if err != nil {
+				failRead(http.StatusInternalServerError, err, tr("保存上传文件失败: %v"))
+				return
+			}
+			continue
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, 4096))
+		if err != nil {
+			failRead(http.StatusBadRequest, err, "%v")
+			return
+		}
+		field := strings.TrimSpace(string(value))
+		switch part.FormName() {
+		case "language":
+			options.Language = field
+		case "model":
+			options.Model = field
+		case "priority":
+			if field != "" {
+				priority, err := strconv.Atoi(field)
+				if err != nil {
+					fail(http.StatusBadRequest, tr("优先级必须是整数"))
+					return
+				}
+				options.Priority = int32(priority)
+			}
+		case "formats":
+			if field != "" {
+				options.Formats = append(options.Formats, field)
+			}
+		}
+	}
+	if dir == "" {
+		http.Error(w, tr("请求缺少上传文件"), http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.submit(r.Context(), path, dir, options, false)
+	if err != nil {
+		writeWebError(w, err)
+		return
+	}
+	writeWebJob(w, job)
+}
+
+// writeUpload 将上传内容写入文件
+func writeUpload(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// handleWebJob 处理 /api/jobs/<id>、/api/jobs/<id>/cancel 和 /api/jobs/<id>/files/<文件名>
+func (s *transcriptionServer) handleWebJob(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/api/jobs/"), "/", 3)
+	id := parts[0]
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		job, err := s.getJob(id)
+		if err != nil {
+			writeWebError(w, err)
+			return
+		}
+		writeWebJob(w, job)
+	case len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost:
+		job, err := s.CancelJob(r.Context(), &whisperpb.CancelJobRequest{Id: id})
+		if err != nil {
+			writeWebError(w, err)
+			return
+		}
+		writeWebJob(w, job)
+	case len(parts) == 3 && parts[1] == "files" && r.Method == http.MethodGet:
+		s.serveOutputFile(w, r, id, parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveOutputFile 以附件形式返回任务的输出文件，只允许下载任务结果中列出的文件
+func (s *transcriptionServer) serveOutputFile(w http.ResponseWriter, r *http.Request, id, name string) {
+	job, err := s.getJob(id)
+	if err != nil {
+		writeWebError(w, err)
+		return
+	}
+	for _, output := range job.OutputFiles {
+		if filepath.Base(output) != name {
+			continue
+		}
+		f, err := os.Open(output)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		http.ServeContent(w, r, name, info.ModTime(), f)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// writeWebJob 以 JSON 返回任务，字段名与 proto 定义一致
+func writeWebJob(w http.ResponseWriter, job *whisperpb.Job) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(job)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeWebError 将 gRPC 状态错误转换为对应的 HTTP 状态码
+func writeWebError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code := http.StatusInternalServerError
+	switch st.Code() {
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.FailedPrecondition:
+		code = http.StatusConflict
+	case codes.Canceled:
+		code = http.StatusRequestTimeout
+	}
+	http.Error(w, st.Message(), code)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// webhookTimeout Webhook 请求超时时间
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload 任务完成或失败时推送的 JSON 内容
+type WebhookPayload struct {
+	Event          string   `json:"event"` // job.completed 或 job.failed
+	Input          string   `json:"input"`
+	Duration       float64  `json:"duration,omitempty"`
+	ElapsedSeconds float64  `json:"elapsed_seconds"`
+	Language       string   `json:"language,omitempty"`
+	Segments       int      `json:"segments"`
+	NoSpeech       bool     `json:"no_speech,omitempty"`
+	OutputFiles    []string `json:"output_files"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// newWebhookPayload 根据处理结果构建 Webhook 内容
+func newWebhookPayload(inputFile string, result *TranscriptionResult, outputFiles []string, jobErr error, elapsed time.Duration) WebhookPayload {
+	payload := WebhookPayload{
+		Event:          "job.completed",
+		Input:          inputFile,
+		ElapsedSeconds: elapsed.Seconds(),
+		OutputFiles:    outputFiles,
+	}
+	if !isRemoteURI(inputFile) {
+		if abs, err := filepath.Abs(inputFile); err == nil {
+			payload.Input = abs
+		}
+	}
+	if payload.OutputFiles == nil {
+		payload.OutputFiles = []string{}
+	}
+	if jobErr != nil {
+		payload.Event = "job.failed"
+		payload.Error = jobErr.Error()
+	}
+	if result != nil {
+		payload.Duration = result.Duration
+		payload.Language = result.Language
+		payload.Segments = len(result.Segments)
+		payload.NoSpeech = result.NoSpeech
+	}
+	return payload
+}
+
+// sendWebhook 以 POST 方式推送 JSON 到 Webhook 地址
+func sendWebhook(url string, payload WebhookPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf(tr("Webhook 返回状态码 %d"), resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// whisper.cpp 默认命令名和单文件阈值。本地转写没有上传大小限制，阈值调大以避免不必要的切片
+// （切片会并行启动多个 whisper.cpp 进程）
+const (
+	whisperCppDefaultPath = "whisper-cli"
+	whisperCppMaxFileSize = 1024
+)
+
+// whisperCppBackend 通过子进程运行 whisper.cpp，完全离线
+type whisperCppBackend struct {
+	path  string
+	model string
+	args  []string
+}
+
+// newWhisperCppBackend 按配置创建 whisper.cpp 后端
+func newWhisperCppBackend(config *Config) *whisperCppBackend {
+	return &whisperCppBackend{
+		path:  config.WhisperCppPath,
+		model: config.WhisperCppModel,
+		args:  config.WhisperCppArgs,
+	}
+}
+
+// whisperCppOutput whisper.cpp -ojf 输出的 JSON（只解析用到的字段）
+type whisperCppOutput struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"` // 毫秒
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text   string `json:"text"`
+		Tokens []struct {
+			Text string  `json:"text"`
+			P    float64 `json:"p"`
+		} `json:"tokens"`
+	} `json:"transcription"`
+}
+
+// Transcribe 运行 whisper.cpp 并读取其 JSON 输出。whisper.cpp 只接受 16kHz 单声道 WAV，其他音频先转换
+func (b *whisperCppBackend) Transcribe(ctx context.Context, audioPath string, req backendRequest) (*TranscriptionResult, error) {
+	logDebug(tr("正在转写音频: %s\n"), audioPath)
+
+	if _, err := exec.LookPath(b.path); err != nil {
+		return nil, fmt.Errorf(tr("未找到 whisper.cpp（%s），请安装或在配置中设置 whispercpp_path"), b.path)
+	}
+
+	wavPath := audioPath
+	if fastSliceWAVInfo(audioPath) == nil {
+		converted, err := extractAudio(audioPath, req.Verbose)
+		if err != nil {
+			return nil, err
+		}
+		defer removeTemp(converted)
+		wavPath = converted
+	}
+
+	outputPrefix := filepath.Join(workingDir(), fmt.Sprintf("whisper_cpp_%d", time.Now().UnixNano()))
+	defer removeTemp(trackTemp(outputPrefix + ".json"))
+
+	language := req.Language
+	if req.AutoDetect || language == "" {
+		language = "auto"
+	}
+	args := []string{
+		"-m", b.model,
+		"-f", wavPath,
+		"-l", language,
+		"-tp", fmt.Sprintf("%.2f", req.Temperature),
+		"-ojf",
+		"-of", outputPrefix,
+		"-np",
+	}
+	if req.Prompt != "" {
+		args = append(args, "--prompt", req.Prompt)
+	}
+	args = append(args, b.args...)
+
+	cmd := exec.CommandContext(ctx, b.path, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// 详细模式下 whisper.cpp 的进度输出到 stderr，stdout 可能是转写结果（如 --stdout 或机器模式）
+	if req.Verbose {
+		cmd.Stdout = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf(tr("whisper.cpp 运行失败: %w: %s"), err, lastLine(stderr.String()))
+	}
+
+	data, err := os.ReadFile(outputPrefix + ".json")
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取 whisper.cpp 输出失败: %w"), err)
+	}
+	var out whisperCppOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf(tr("解析 whisper.cpp 输出失败: %w"), err)
+	}
+
+	logDebug(tr("转写完成"))
+	return whisperCppResult(&out, req), nil
+}
+
+// SupportsTemperature whisper.cpp 支持 -tp 温度参数
+func (b *whisperCppBackend) SupportsTemperature() bool {
+	return true
+}
+
+// whisperCppResult 将 whisper.cpp 输出映射为转写结果，分段的 avg_logprob 取 token 概率对数的平均值
+func whisperCppResult(out *whisperCppOutput, req backendRequest) *TranscriptionResult {
+	result := &TranscriptionResult{Language: out.Result.Language}
+	if result.Language == "" {
+		result.Language = req.Language
+	}
+
+	for _, t := range out.Transcription {
+		text := strings.TrimSpace(t.Text)
+		if text == "" {
+			continue
+		}
+		seg := Segment{
+			ID:    len(result.Segments) + 1,
+			Start: float64(t.Offsets.From) / 1000,
+			End:   float64(t.Offsets.To) / 1000,
+			Text:  text,
+		}
+
+		// 跳过 [_BEG_]、[_TT_150] 等特殊 token
+		var sum float64
+		var n int
+		for _, token := range t.Tokens {
+			if strings.HasPrefix(token.Text, "[_") || token.P <= 0 {
+				continue
+			}
+			sum += math.Log(token.P)
+			n++
+		}
+		if n > 0 {
+			seg.AvgLogprob = sum / float64(n)
+		}
+		seg.CompressionRatio = compressionRatio(text)
+
+		result.Segments = append(result.Segments, seg)
+		result.Text = joinSegmentText(result.Text, text)
+		result.Duration = seg.End
+	}
+	return result
+}
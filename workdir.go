@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// diskSpaceMargin 预检剩余空间时额外保留的空间
+const diskSpaceMargin = 100 << 20
+
+// errDiskSpaceUnknown 当前平台无法获取剩余空间
+var errDiskSpaceUnknown error = trError("当前平台无法获取剩余空间")
+
+// workDirectory 中间文件（提取的音频、切片、压缩上传的音频、下载的远程文件等）所在目录，为空时使用系统临时目录
+var workDirectory string
+
+// useWorkDir 按配置设置中间文件目录
+func useWorkDir(config *Config) {
+	workDirectory = config.WorkDir
+}
+
+// workingDir 中间文件目录，配置的目录不存在时自动创建
+func workingDir() string {
+	if workDirectory == "" {
+		return os.TempDir()
+	}
+	os.MkdirAll(workDirectory, 0755)
+	return workDirectory
+}
+
+// checkFreeSpace 检查工作目录的剩余空间是否足够写入约 need 字节的中间文件，无法获取剩余空间时不检查
+func checkFreeSpace(need int64, purpose string) error {
+	dir := workingDir()
+	free, err := diskFree(dir)
+	if err != nil {
+		logDebug(tr("无法获取 %s 的剩余空间，跳过检查: %v"), dir, err)
+		return nil
+	}
+	if free < uint64(need)+diskSpaceMargin {
+		return fmt.Errorf(tr("工作目录 %s 空间不足：%s需要约 %.0f MB，剩余 %.0f MB（可通过 work_dir 配置或 --work-dir 参数指定其他目录）"),
+			dir, purpose, float64(need)/(1<<20), float64(free)/(1<<20))
+	}
+	return nil
+}